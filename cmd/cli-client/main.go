@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -49,6 +52,39 @@ func process[Req, Resp any](
 	os.Exit(0)
 }
 
+// save writes an exported file to the output path, or to stdout if the path is empty.
+func save[Req any](
+	ctx context.Context,
+	toReq func() (Req, error),
+	doReq func(context.Context, Req) (*api.ExportResp, error),
+	output string,
+) {
+	req, err := toReq()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "convert to api request: %v\n", err)
+		os.Exit(1)
+	}
+
+	resp, err := doReq(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "do request: %v\n", err)
+		os.Exit(1)
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(resp.Data)
+	} else {
+		err = os.WriteFile(output, resp.Data, 0o644)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "write export: %v\n", err)
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
+
 func main() {
 	cfg, err := bmconfig.GetForCLIClient()
 	if err != nil {
@@ -72,6 +108,18 @@ func main() {
 	cmdGetBook.Flags().Int64Var(&getBookReq.ID, "id", 0, "Source directory to read from")
 	cmdGetBook.MarkFlagRequired("id")
 
+	getBookByISBNReq := new(getBookByISBNReqCli)
+	var cmdGetBookByISBN = &cobra.Command{
+		Use:   "get_book_by_isbn",
+		Short: "returns json with data of the book with given ISBN-10 or ISBN-13",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, getBookByISBNReq.toAPIReq, c.httpClient.GetBookByISBN)
+		},
+	}
+
+	cmdGetBookByISBN.Flags().StringVar(&getBookByISBNReq.ISBN, "isbn", "", "ISBN-10 or ISBN-13 of the book (required)")
+	cmdGetBookByISBN.MarkFlagRequired("isbn")
+
 	createBookReq := new(createBookReqCli)
 	cmdCreateBook := &cobra.Command{
 		Use:   "create_book",
@@ -82,14 +130,17 @@ func main() {
 	}
 
 	cmdCreateBook.Flags().StringVarP(&createBookReq.Title, "title", "t", "", "Title of the book (required)")
-	cmdCreateBook.Flags().StringVarP(&createBookReq.Author, "author", "a", "", "Author of the book (required)")
+	cmdCreateBook.Flags().StringVarP(&createBookReq.Author, "author", "a", "", "Author of the book (required unless contributors are set)")
 	cmdCreateBook.Flags().StringVarP(&createBookReq.PublishedDate, "published_date", "d", "", "Published date of the book in the format YYYY-MM-DD (required)")
 	cmdCreateBook.Flags().StringVar(&createBookReq.Edition, "edition", "", "Edition of the book")
 	cmdCreateBook.Flags().StringVar(&createBookReq.Description, "description", "", "Description of the book")
-	cmdCreateBook.Flags().StringVar(&createBookReq.Genre, "genre", "", "Genre of the book")
+	cmdCreateBook.Flags().StringVar(&createBookReq.Genre, "genre", "", "Genre of the book, created if missing")
+	cmdCreateBook.Flags().Int64Var(&createBookReq.GenreID, "genre_id", 0, "ID of the genre of the book")
+	cmdCreateBook.Flags().StringVar(&createBookReq.ISBN, "isbn", "", "ISBN-10 or ISBN-13 of the book")
+	cmdCreateBook.Flags().StringSliceVar(&createBookReq.Tags, "tags", nil, "Tags of the book (comma-separated)")
+	cmdCreateBook.Flags().StringArrayVar(&createBookReq.Contributors, "contributor", nil, "Contributor of the book in the format role=name, role is one of author, editor, translator, illustrator (repeatable)")
 
 	cmdCreateBook.MarkFlagRequired("title")
-	cmdCreateBook.MarkFlagRequired("author")
 	cmdCreateBook.MarkFlagRequired("published_date")
 
 	getBooksReq := new(getBooksReqCli)
@@ -101,8 +152,17 @@ func main() {
 		},
 	}
 
+	cmdGetBooks.Flags().StringVar(&getBooksReq.Query, "query", "", "Part of the title or author of the books")
 	cmdGetBooks.Flags().StringVar(&getBooksReq.Author, "author", "", "Author of the books")
-	cmdGetBooks.Flags().StringVar(&getBooksReq.Genre, "genre", "", "Genre of the books")
+	cmdGetBooks.Flags().Int64Var(&getBooksReq.AuthorID, "author_id", 0, "ID of an author, editor, translator or illustrator of the books")
+	cmdGetBooks.Flags().StringVar(&getBooksReq.Genre, "genre", "", "Genre of the books, subgenres included")
+	cmdGetBooks.Flags().Int64Var(&getBooksReq.GenreID, "genre_id", 0, "ID of the genre of the books, subgenres included")
+	cmdGetBooks.Flags().StringSliceVar(&getBooksReq.Tags, "tags", nil, "Tags of the books (comma-separated)")
+	cmdGetBooks.Flags().StringVar(&getBooksReq.TagsMatch, "tags_match", "", "Match books having any (default) or all of the tags: any|all")
+	cmdGetBooks.Flags().StringVar(&getBooksReq.Status, "status", "", "Reading status of the books: want_to_read|reading|finished|abandoned")
+	cmdGetBooks.Flags().Float64Var(&getBooksReq.MinRating, "min_rating", 0, "Minimal average rating of the books")
+	cmdGetBooks.Flags().StringVar(&getBooksReq.OnLoan, "on_loan", "", "true to get only books on loan, false to get only books on the shelf")
+	cmdGetBooks.Flags().StringVar(&getBooksReq.ISBN, "isbn", "", "ISBN-10 or ISBN-13 of the book")
 	cmdGetBooks.Flags().Int64Var(&getBooksReq.CollectionID, "collection_id", 0, "ID of the collection")
 	cmdGetBooks.Flags().StringVar(&getBooksReq.StartDate, "start_date", "", "Start date in the format YYYY-MM-DD")
 	cmdGetBooks.Flags().StringVar(&getBooksReq.FinishDate, "finish_date", "", "Finish date in the format YYYY-MM-DD")
@@ -127,6 +187,9 @@ func main() {
 	cmdUpdateBooks.Flags().StringVar(&updateBooksReq.Edition, "edition", "", "Updated edition of the book")
 	cmdUpdateBooks.Flags().StringVar(&updateBooksReq.Description, "description", "", "Updated description of the book")
 	cmdUpdateBooks.Flags().StringVar(&updateBooksReq.Genre, "genre", "", "Updated genre of the book")
+	cmdUpdateBooks.Flags().Int64Var(&updateBooksReq.GenreID, "genre_id", 0, "Updated ID of the genre of the book")
+	cmdUpdateBooks.Flags().StringVar(&updateBooksReq.ISBN, "isbn", "", "Updated ISBN-10 or ISBN-13 of the book")
+	cmdUpdateBooks.Flags().StringArrayVar(&updateBooksReq.Contributors, "contributor", nil, "Updated contributor of the book in the format role=name (repeatable)")
 	cmdUpdateBooks.MarkFlagRequired("id")
 
 	var deleteBooksReq = &deleteBooksReqCli{}
@@ -139,6 +202,7 @@ func main() {
 	}
 
 	cmdDeleteBooks.Flags().Int64SliceVar(&deleteBooksReq.IDs, "ids", nil, "IDs of the books to delete (comma-separated) (required)")
+	cmdDeleteBooks.Flags().BoolVar(&deleteBooksReq.Force, "force", false, "Delete books on loan together with their loans")
 	cmdDeleteBooks.MarkFlagRequired("ids")
 
 	var getCollectionReq = &getCollectionReqCli{}
@@ -239,9 +303,563 @@ func main() {
 	cmdDeleteBooksCollection.MarkFlagRequired("collection_id")
 	cmdDeleteBooksCollection.MarkFlagRequired("book_ids")
 
+	var moveBooksReq = &moveBooksReqCli{}
+	var cmdMoveBooks = &cobra.Command{
+		Use:   "move_books",
+		Short: "Move books to another tenant (admin only)",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, moveBooksReq.toAPIReq, c.httpClient.MoveBooks)
+		},
+	}
+
+	cmdMoveBooks.Flags().Int64SliceVar(&moveBooksReq.IDs, "ids", nil, "IDs of the books to move (comma-separated) (required)")
+	cmdMoveBooks.Flags().StringVar(&moveBooksReq.Tenant, "tenant", "", "Tenant to move the books to (required)")
+	cmdMoveBooks.MarkFlagRequired("ids")
+	cmdMoveBooks.MarkFlagRequired("tenant")
+
+	var moveCollectionReq = &moveCollectionReqCli{}
+	var cmdMoveCollection = &cobra.Command{
+		Use:   "move_collection",
+		Short: "Move a collection with its books to another tenant (admin only)",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, moveCollectionReq.toAPIReq, c.httpClient.MoveCollection)
+		},
+	}
+
+	cmdMoveCollection.Flags().Int64Var(&moveCollectionReq.ID, "id", 0, "ID of the collection to move (required)")
+	cmdMoveCollection.Flags().StringVar(&moveCollectionReq.Tenant, "tenant", "", "Tenant to move the collection to (required)")
+	cmdMoveCollection.MarkFlagRequired("id")
+	cmdMoveCollection.MarkFlagRequired("tenant")
+
+	getAuthorReq := new(getAuthorReqCli)
+	cmdGetAuthor := &cobra.Command{
+		Use:   "get_author",
+		Short: "Get information about an author",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, getAuthorReq.toAPIReq, c.httpClient.GetAuthor)
+		},
+	}
+
+	cmdGetAuthor.Flags().Int64Var(&getAuthorReq.ID, "id", 0, "ID of the author to retrieve (required)")
+	cmdGetAuthor.MarkFlagRequired("id")
+
+	getAuthorsReq := new(getAuthorsReqCli)
+	cmdGetAuthors := &cobra.Command{
+		Use:   "get_authors",
+		Short: "Get a list of authors",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, getAuthorsReq.toAPIReq, c.httpClient.GetAuthors)
+		},
+	}
+
+	cmdGetAuthors.Flags().StringVar(&getAuthorsReq.Name, "name", "", "Part of the author name")
+	cmdGetAuthors.Flags().StringVar(&getAuthorsReq.OrderBy, "order_by", "", "Order by a specific field")
+	cmdGetAuthors.Flags().BoolVar(&getAuthorsReq.Desc, "desc", false, "Sort in descending order")
+	cmdGetAuthors.Flags().Int64Var(&getAuthorsReq.Page, "page", 1, "Page number")
+	cmdGetAuthors.Flags().Int64Var(&getAuthorsReq.PageSize, "page_size", 10, "Number of items per page")
+
+	createAuthorReq := new(createAuthorReqCli)
+	cmdCreateAuthor := &cobra.Command{
+		Use:   "create_author",
+		Short: "Create a new author",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, createAuthorReq.toAPIReq, c.httpClient.CreateAuthor)
+		},
+	}
+
+	cmdCreateAuthor.Flags().StringVar(&createAuthorReq.Name, "name", "", "Name of the new author (required)")
+	cmdCreateAuthor.MarkFlagRequired("name")
+
+	updateAuthorReq := new(updateAuthorReqCli)
+	cmdUpdateAuthor := &cobra.Command{
+		Use:   "update_author",
+		Short: "Rename an existing author",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, updateAuthorReq.toAPIReq, c.httpClient.UpdateAuthor)
+		},
+	}
+
+	cmdUpdateAuthor.Flags().Int64Var(&updateAuthorReq.ID, "id", 0, "ID of the author to update (required)")
+	cmdUpdateAuthor.Flags().StringVar(&updateAuthorReq.Name, "name", "", "Updated name of the author (required)")
+	cmdUpdateAuthor.MarkFlagRequired("id")
+	cmdUpdateAuthor.MarkFlagRequired("name")
+
+	deleteAuthorReq := new(deleteAuthorReqCli)
+	cmdDeleteAuthor := &cobra.Command{
+		Use:   "delete_author",
+		Short: "Delete an author without books",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, deleteAuthorReq.toAPIReq, c.httpClient.DeleteAuthor)
+		},
+	}
+
+	cmdDeleteAuthor.Flags().Int64Var(&deleteAuthorReq.ID, "id", 0, "ID of the author to delete (required)")
+	cmdDeleteAuthor.MarkFlagRequired("id")
+
+	getTagsReq := new(getTagsReqCli)
+	cmdGetTags := &cobra.Command{
+		Use:   "get_tags",
+		Short: "Get a list of tags with the number of their books",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, getTagsReq.toAPIReq, c.httpClient.GetTags)
+		},
+	}
+
+	cmdGetTags.Flags().StringVar(&getTagsReq.OrderBy, "order_by", "", "Order by a specific field: id|name|books")
+	cmdGetTags.Flags().BoolVar(&getTagsReq.Desc, "desc", false, "Sort in descending order")
+	cmdGetTags.Flags().Int64Var(&getTagsReq.Page, "page", 1, "Page number")
+	cmdGetTags.Flags().Int64Var(&getTagsReq.PageSize, "page_size", 10, "Number of items per page")
+
+	addBooksTagsReq := new(booksTagsReqCli)
+	cmdAddBooksTags := &cobra.Command{
+		Use:   "tag_books",
+		Short: "Add tags to books",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, addBooksTagsReq.toAddAPIReq, c.httpClient.AddBooksTags)
+		},
+	}
+
+	cmdAddBooksTags.Flags().Int64SliceVar(&addBooksTagsReq.BookIDs, "book_ids", nil, "IDs of the books to tag (comma-separated) (required)")
+	cmdAddBooksTags.Flags().StringSliceVar(&addBooksTagsReq.Tags, "tags", nil, "Tags to add (comma-separated) (required)")
+	cmdAddBooksTags.MarkFlagRequired("book_ids")
+	cmdAddBooksTags.MarkFlagRequired("tags")
+
+	deleteBooksTagsReq := new(booksTagsReqCli)
+	cmdDeleteBooksTags := &cobra.Command{
+		Use:   "untag_books",
+		Short: "Remove tags from books",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, deleteBooksTagsReq.toDeleteAPIReq, c.httpClient.DeleteBooksTags)
+		},
+	}
+
+	cmdDeleteBooksTags.Flags().Int64SliceVar(&deleteBooksTagsReq.BookIDs, "book_ids", nil, "IDs of the books to untag (comma-separated) (required)")
+	cmdDeleteBooksTags.Flags().StringSliceVar(&deleteBooksTagsReq.Tags, "tags", nil, "Tags to remove (comma-separated) (required)")
+	cmdDeleteBooksTags.MarkFlagRequired("book_ids")
+	cmdDeleteBooksTags.MarkFlagRequired("tags")
+
+	getReadingReq := new(getReadingReqCli)
+	cmdGetReading := &cobra.Command{
+		Use:   "get_reading",
+		Short: "Get the reading state of a book",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, getReadingReq.toAPIReq, c.httpClient.GetReading)
+		},
+	}
+
+	cmdGetReading.Flags().Int64Var(&getReadingReq.BookID, "book_id", 0, "ID of the book (required)")
+	cmdGetReading.MarkFlagRequired("book_id")
+
+	updateReadingReq := new(updateReadingReqCli)
+	cmdUpdateReading := &cobra.Command{
+		Use:   "update_reading",
+		Short: "Move a book to another reading status or update reading progress",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, updateReadingReq.toAPIReq, c.httpClient.UpdateReading)
+		},
+	}
+
+	cmdUpdateReading.Flags().Int64Var(&updateReadingReq.BookID, "book_id", 0, "ID of the book (required)")
+	cmdUpdateReading.Flags().StringVar(&updateReadingReq.Status, "status", "", "Reading status: want_to_read|reading|finished|abandoned (required)")
+	cmdUpdateReading.Flags().StringVar(&updateReadingReq.Date, "date", "", "Date of the status change in the format YYYY-MM-DD, today by default")
+	cmdUpdateReading.Flags().Int64Var(&updateReadingReq.CurrentPage, "current_page", 0, "Current page")
+	cmdUpdateReading.Flags().StringVar(&updateReadingReq.Notes, "notes", "", "Reading notes")
+	cmdUpdateReading.MarkFlagRequired("book_id")
+	cmdUpdateReading.MarkFlagRequired("status")
+
+	getReadingReportReq := new(getReadingReportReqCli)
+	cmdGetReadingReport := &cobra.Command{
+		Use:   "reading_report",
+		Short: "Get books finished within a year",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, getReadingReportReq.toAPIReq, c.httpClient.GetReadingReport)
+		},
+	}
+
+	cmdGetReadingReport.Flags().IntVar(&getReadingReportReq.Year, "year", 0, "Year of the report, the current year by default")
+
+	getCopyReq := new(getCopyReqCli)
+	cmdGetCopy := &cobra.Command{
+		Use:   "get_copy",
+		Short: "Get information about a copy of a book",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, getCopyReq.toAPIReq, c.httpClient.GetCopy)
+		},
+	}
+
+	cmdGetCopy.Flags().Int64Var(&getCopyReq.ID, "id", 0, "ID of the copy to retrieve (required)")
+	cmdGetCopy.MarkFlagRequired("id")
+
+	getCopyByBarcodeReq := new(getCopyByBarcodeReqCli)
+	cmdGetCopyByBarcode := &cobra.Command{
+		Use:   "get_copy_by_barcode",
+		Short: "Get information about a copy of a book by its barcode",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, getCopyByBarcodeReq.toAPIReq, c.httpClient.GetCopyByBarcode)
+		},
+	}
+
+	cmdGetCopyByBarcode.Flags().StringVar(&getCopyByBarcodeReq.Barcode, "barcode", "", "Barcode of the copy to retrieve (required)")
+	cmdGetCopyByBarcode.MarkFlagRequired("barcode")
+
+	getCopiesReq := new(getCopiesReqCli)
+	cmdGetCopies := &cobra.Command{
+		Use:   "get_copies",
+		Short: "Get copies of a book or the inventory of a location",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, getCopiesReq.toAPIReq, c.httpClient.GetCopies)
+		},
+	}
+
+	cmdGetCopies.Flags().Int64Var(&getCopiesReq.BookID, "book_id", 0, "ID of the book")
+	cmdGetCopies.Flags().StringVar(&getCopiesReq.Location, "location", "", "Shelf location of the copies")
+	cmdGetCopies.Flags().StringVar(&getCopiesReq.OrderBy, "order_by", "", "Order by a specific field: id|book_id|barcode|condition|acquired_at|price|location")
+	cmdGetCopies.Flags().BoolVar(&getCopiesReq.Desc, "desc", false, "Sort in descending order")
+	cmdGetCopies.Flags().Int64Var(&getCopiesReq.Page, "page", 1, "Page number")
+	cmdGetCopies.Flags().Int64Var(&getCopiesReq.PageSize, "page_size", 10, "Number of items per page")
+
+	createCopyReq := new(copyReqCli)
+	cmdCreateCopy := &cobra.Command{
+		Use:   "create_copy",
+		Short: "Add a copy of a book",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, createCopyReq.toCreateAPIReq, c.httpClient.CreateCopy)
+		},
+	}
+
+	cmdCreateCopy.Flags().Int64Var(&createCopyReq.BookID, "book_id", 0, "ID of the book (required)")
+	cmdCreateCopy.Flags().StringVar(&createCopyReq.Barcode, "barcode", "", "Barcode of the copy (required)")
+	cmdCreateCopy.Flags().StringVar(&createCopyReq.Condition, "condition", "", "Condition of the copy: new|fine|good|fair|poor, good by default")
+	cmdCreateCopy.Flags().StringVar(&createCopyReq.AcquiredAt, "acquired_at", "", "Acquisition date in the format YYYY-MM-DD")
+	cmdCreateCopy.Flags().Int64Var(&createCopyReq.Price, "price", 0, "Price in minor currency units")
+	cmdCreateCopy.Flags().StringVar(&createCopyReq.Location, "location", "", "Shelf location of the copy")
+	cmdCreateCopy.MarkFlagRequired("book_id")
+	cmdCreateCopy.MarkFlagRequired("barcode")
+
+	updateCopyReq := new(copyReqCli)
+	cmdUpdateCopy := &cobra.Command{
+		Use:   "update_copy",
+		Short: "Update an existing copy of a book",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, updateCopyReq.toUpdateAPIReq, c.httpClient.UpdateCopy)
+		},
+	}
+
+	cmdUpdateCopy.Flags().Int64Var(&updateCopyReq.ID, "id", 0, "ID of the copy to update (required)")
+	cmdUpdateCopy.Flags().StringVar(&updateCopyReq.Barcode, "barcode", "", "Updated barcode of the copy (required)")
+	cmdUpdateCopy.Flags().StringVar(&updateCopyReq.Condition, "condition", "", "Updated condition of the copy: new|fine|good|fair|poor")
+	cmdUpdateCopy.Flags().StringVar(&updateCopyReq.AcquiredAt, "acquired_at", "", "Updated acquisition date in the format YYYY-MM-DD")
+	cmdUpdateCopy.Flags().Int64Var(&updateCopyReq.Price, "price", 0, "Updated price in minor currency units")
+	cmdUpdateCopy.Flags().StringVar(&updateCopyReq.Location, "location", "", "Updated shelf location of the copy")
+	cmdUpdateCopy.MarkFlagRequired("id")
+	cmdUpdateCopy.MarkFlagRequired("barcode")
+
+	deleteCopyReq := new(deleteCopyReqCli)
+	cmdDeleteCopy := &cobra.Command{
+		Use:   "delete_copy",
+		Short: "Delete a copy of a book",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, deleteCopyReq.toAPIReq, c.httpClient.DeleteCopy)
+		},
+	}
+
+	cmdDeleteCopy.Flags().Int64Var(&deleteCopyReq.ID, "id", 0, "ID of the copy to delete (required)")
+	cmdDeleteCopy.MarkFlagRequired("id")
+
+	uploadCoverReq := new(uploadCoverReqCli)
+	cmdUploadCover := &cobra.Command{
+		Use:   "upload_cover",
+		Short: "Upload a JPEG or PNG cover of a book",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, uploadCoverReq.toAPIReq, c.httpClient.UploadCover)
+		},
+	}
+
+	cmdUploadCover.Flags().Int64Var(&uploadCoverReq.BookID, "book_id", 0, "ID of the book (required)")
+	cmdUploadCover.Flags().StringVar(&uploadCoverReq.File, "file", "", "Path to the cover image (required)")
+	cmdUploadCover.MarkFlagRequired("book_id")
+	cmdUploadCover.MarkFlagRequired("file")
+
+	addFileReq := new(addFileReqCli)
+	cmdAddFile := &cobra.Command{
+		Use:   "add_file",
+		Short: "Attach an EPUB or PDF file to a book, or create a book from an EPUB if book_id is not set",
+		Run: func(cmd *cobra.Command, args []string) {
+			if addFileReq.BookID == 0 {
+				process(ctx, addFileReq.toImportAPIReq, c.httpClient.ImportEPUB)
+
+				return
+			}
+
+			process(ctx, addFileReq.toAPIReq, c.httpClient.AddBookFile)
+		},
+	}
+
+	cmdAddFile.Flags().Int64Var(&addFileReq.BookID, "book_id", 0, "ID of the book")
+	cmdAddFile.Flags().StringVar(&addFileReq.File, "file", "", "Path to the file (required)")
+	cmdAddFile.Flags().StringVar(&addFileReq.Name, "name", "", "Name of the file, the base name of the path by default")
+	cmdAddFile.Flags().StringVar(&addFileReq.Genre, "genre", "", "Genre of the book created from an EPUB, the first subject of the EPUB by default")
+	cmdAddFile.MarkFlagRequired("file")
+
+	importBooksReq := new(importBooksReqCli)
+	cmdImportBooks := &cobra.Command{
+		Use:   "import_books",
+		Short: "Import books from a Goodreads or LibraryThing CSV export, shelves become collections",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, importBooksReq.toAPIReq, c.httpClient.ImportBooks)
+		},
+	}
+
+	cmdImportBooks.Flags().StringVar(&importBooksReq.File, "file", "", "Path to the CSV export (required)")
+	cmdImportBooks.Flags().StringVar(&importBooksReq.Genre, "genre", "", "Genre of the imported books, Unsorted by default")
+	cmdImportBooks.Flags().BoolVar(&importBooksReq.DryRun, "dry_run", false, "Show changes without making them")
+	cmdImportBooks.MarkFlagRequired("file")
+
+	importMARCReq := new(importMARCReqCli)
+	cmdImportMARC := &cobra.Command{
+		Use:   "import_marc",
+		Short: "Import books from MARC 21 or MARCXML records",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, importMARCReq.toAPIReq, c.httpClient.ImportMARC)
+		},
+	}
+
+	cmdImportMARC.Flags().StringVar(&importMARCReq.File, "file", "", "Path to the .mrc or MARCXML file (required)")
+	cmdImportMARC.Flags().StringVar(&importMARCReq.Genre, "genre", "", "Genre of the imported books, the first subject of a record or Unsorted by default")
+	cmdImportMARC.Flags().BoolVar(&importMARCReq.DryRun, "dry_run", false, "Show changes without making them")
+	cmdImportMARC.MarkFlagRequired("file")
+
+	exportReq := new(exportReqCli)
+	cmdExport := &cobra.Command{
+		Use:   "export",
+		Short: "Export a book or all books of a collection as MARC records or citations",
+		Run: func(cmd *cobra.Command, args []string) {
+			if exportReq.CollectionID != 0 {
+				save(ctx, exportReq.toCollectionAPIReq, c.httpClient.ExportCollection, exportReq.Output)
+
+				return
+			}
+
+			save(ctx, exportReq.toBookAPIReq, c.httpClient.ExportBook, exportReq.Output)
+		},
+	}
+
+	cmdExport.Flags().Int64Var(&exportReq.BookID, "book_id", 0, "ID of the book")
+	cmdExport.Flags().Int64Var(&exportReq.CollectionID, "collection_id", 0, "ID of the collection")
+	cmdExport.Flags().StringVar(&exportReq.Format, "format", "", "Format of the export: marc21, marcxml, bibtex, ris or csljson (required)")
+	cmdExport.Flags().StringVar(&exportReq.Output, "output", "", "Path to the output file, stdout by default")
+	cmdExport.MarkFlagsOneRequired("book_id", "collection_id")
+	cmdExport.MarkFlagsMutuallyExclusive("book_id", "collection_id")
+	cmdExport.MarkFlagRequired("format")
+
+	citeReq := new(exportReqCli)
+	cmdCite := &cobra.Command{
+		Use:   "cite",
+		Short: "Print citations of a book or all books of a collection",
+		Run: func(cmd *cobra.Command, args []string) {
+			if citeReq.CollectionID != 0 {
+				save(ctx, citeReq.toCollectionAPIReq, c.httpClient.ExportCollection, "")
+
+				return
+			}
+
+			save(ctx, citeReq.toBookAPIReq, c.httpClient.ExportBook, "")
+		},
+	}
+
+	cmdCite.Flags().Int64Var(&citeReq.BookID, "book_id", 0, "ID of the book")
+	cmdCite.Flags().Int64Var(&citeReq.CollectionID, "collection_id", 0, "ID of the collection")
+	cmdCite.Flags().StringVar(&citeReq.Format, "format", "bibtex", "Format of citations: bibtex, ris or csljson")
+	cmdCite.MarkFlagsOneRequired("book_id", "collection_id")
+	cmdCite.MarkFlagsMutuallyExclusive("book_id", "collection_id")
+
+	getFilesReq := new(getFilesReqCli)
+	cmdGetFiles := &cobra.Command{
+		Use:   "get_files",
+		Short: "Get digital files of a book",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, getFilesReq.toAPIReq, c.httpClient.GetBookFiles)
+		},
+	}
+
+	cmdGetFiles.Flags().Int64Var(&getFilesReq.BookID, "book_id", 0, "ID of the book (required)")
+	cmdGetFiles.MarkFlagRequired("book_id")
+
+	deleteFileReq := new(deleteFileReqCli)
+	cmdDeleteFile := &cobra.Command{
+		Use:   "delete_file",
+		Short: "Delete a digital file of a book",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, deleteFileReq.toAPIReq, c.httpClient.DeleteBookFile)
+		},
+	}
+
+	cmdDeleteFile.Flags().Int64Var(&deleteFileReq.BookID, "book_id", 0, "ID of the book (required)")
+	cmdDeleteFile.Flags().Int64Var(&deleteFileReq.ID, "id", 0, "ID of the file to delete (required)")
+	cmdDeleteFile.MarkFlagRequired("book_id")
+	cmdDeleteFile.MarkFlagRequired("id")
+
+	lendBookReq := new(lendBookReqCli)
+	cmdLendBook := &cobra.Command{
+		Use:   "lend_book",
+		Short: "Lend a book to a borrower",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, lendBookReq.toAPIReq, c.httpClient.LendBook)
+		},
+	}
+
+	cmdLendBook.Flags().Int64Var(&lendBookReq.BookID, "book_id", 0, "ID of the book to lend (required)")
+	cmdLendBook.Flags().StringVar(&lendBookReq.Borrower, "borrower", "", "Name of the borrower (required)")
+	cmdLendBook.Flags().StringVar(&lendBookReq.LentAt, "lent_at", "", "Lent date in the format YYYY-MM-DD, today by default")
+	cmdLendBook.Flags().StringVar(&lendBookReq.DueAt, "due_at", "", "Due date in the format YYYY-MM-DD (required)")
+	cmdLendBook.MarkFlagRequired("book_id")
+	cmdLendBook.MarkFlagRequired("borrower")
+	cmdLendBook.MarkFlagRequired("due_at")
+
+	returnBookReq := new(returnBookReqCli)
+	cmdReturnBook := &cobra.Command{
+		Use:   "return_book",
+		Short: "Return a lent book",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, returnBookReq.toAPIReq, c.httpClient.ReturnBook)
+		},
+	}
+
+	cmdReturnBook.Flags().Int64Var(&returnBookReq.BookID, "book_id", 0, "ID of the book to return (required)")
+	cmdReturnBook.Flags().StringVar(&returnBookReq.ReturnedAt, "returned_at", "", "Return date in the format YYYY-MM-DD, today by default")
+	cmdReturnBook.MarkFlagRequired("book_id")
+
+	getLoansReq := new(getLoansReqCli)
+	cmdGetLoans := &cobra.Command{
+		Use:   "get_loans",
+		Short: "Get a list of active loans",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, getLoansReq.toAPIReq, c.httpClient.GetLoans)
+		},
+	}
+
+	cmdGetLoans.Flags().StringVar(&getLoansReq.OrderBy, "order_by", "", "Order by a specific field: id|book_id|borrower|lent_at|due_at")
+	cmdGetLoans.Flags().BoolVar(&getLoansReq.Desc, "desc", false, "Sort in descending order")
+	cmdGetLoans.Flags().Int64Var(&getLoansReq.Page, "page", 1, "Page number")
+	cmdGetLoans.Flags().Int64Var(&getLoansReq.PageSize, "page_size", 10, "Number of items per page")
+
+	getOverdueLoansReq := new(getLoansReqCli)
+	cmdGetOverdueLoans := &cobra.Command{
+		Use:   "get_overdue_loans",
+		Short: "Get a list of overdue loans",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, getOverdueLoansReq.toAPIReq, c.httpClient.GetOverdueLoans)
+		},
+	}
+
+	cmdGetOverdueLoans.Flags().StringVar(&getOverdueLoansReq.OrderBy, "order_by", "", "Order by a specific field: id|book_id|borrower|lent_at|due_at")
+	cmdGetOverdueLoans.Flags().BoolVar(&getOverdueLoansReq.Desc, "desc", false, "Sort in descending order")
+	cmdGetOverdueLoans.Flags().Int64Var(&getOverdueLoansReq.Page, "page", 1, "Page number")
+	cmdGetOverdueLoans.Flags().Int64Var(&getOverdueLoansReq.PageSize, "page_size", 10, "Number of items per page")
+
+	createReviewReq := new(createReviewReqCli)
+	cmdCreateReview := &cobra.Command{
+		Use:   "review",
+		Short: "Review a book",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, createReviewReq.toAPIReq, c.httpClient.CreateReview)
+		},
+	}
+
+	cmdCreateReview.Flags().Int64Var(&createReviewReq.BookID, "book_id", 0, "ID of the book to review (required)")
+	cmdCreateReview.Flags().Int64Var(&createReviewReq.Rating, "rating", 0, "Rating from 1 to 5 (required)")
+	cmdCreateReview.Flags().StringVar(&createReviewReq.Text, "text", "", "Text of the review")
+	cmdCreateReview.MarkFlagRequired("book_id")
+	cmdCreateReview.MarkFlagRequired("rating")
+
+	getReviewsReq := new(getReviewsReqCli)
+	cmdGetReviews := &cobra.Command{
+		Use:   "reviews",
+		Short: "Get reviews of a book",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, getReviewsReq.toAPIReq, c.httpClient.GetReviews)
+		},
+	}
+
+	cmdGetReviews.Flags().Int64Var(&getReviewsReq.BookID, "book_id", 0, "ID of the book (required)")
+	cmdGetReviews.Flags().StringVar(&getReviewsReq.OrderBy, "order_by", "", "Order by a specific field: id|rating|created_at|updated_at")
+	cmdGetReviews.Flags().BoolVar(&getReviewsReq.Desc, "desc", false, "Sort in descending order")
+	cmdGetReviews.Flags().Int64Var(&getReviewsReq.Page, "page", 1, "Page number")
+	cmdGetReviews.Flags().Int64Var(&getReviewsReq.PageSize, "page_size", 10, "Number of items per page")
+	cmdGetReviews.MarkFlagRequired("book_id")
+
+	getGenreReq := new(getGenreReqCli)
+	cmdGetGenre := &cobra.Command{
+		Use:   "get_genre",
+		Short: "Get information about a genre",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, getGenreReq.toAPIReq, c.httpClient.GetGenre)
+		},
+	}
+
+	cmdGetGenre.Flags().Int64Var(&getGenreReq.ID, "id", 0, "ID of the genre to retrieve (required)")
+	cmdGetGenre.MarkFlagRequired("id")
+
+	getGenresReq := new(getGenresReqCli)
+	cmdGetGenres := &cobra.Command{
+		Use:   "get_genres",
+		Short: "Get a list of genres",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, getGenresReq.toAPIReq, c.httpClient.GetGenres)
+		},
+	}
+
+	cmdGetGenres.Flags().StringVar(&getGenresReq.Name, "name", "", "Part of the genre name")
+	cmdGetGenres.Flags().Int64Var(&getGenresReq.ParentID, "parent_id", 0, "ID of the parent genre")
+	cmdGetGenres.Flags().StringVar(&getGenresReq.OrderBy, "order_by", "", "Order by a specific field")
+	cmdGetGenres.Flags().BoolVar(&getGenresReq.Desc, "desc", false, "Sort in descending order")
+	cmdGetGenres.Flags().Int64Var(&getGenresReq.Page, "page", 1, "Page number")
+	cmdGetGenres.Flags().Int64Var(&getGenresReq.PageSize, "page_size", 10, "Number of items per page")
+
+	createGenreReq := new(createGenreReqCli)
+	cmdCreateGenre := &cobra.Command{
+		Use:   "create_genre",
+		Short: "Create a new genre",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, createGenreReq.toAPIReq, c.httpClient.CreateGenre)
+		},
+	}
+
+	cmdCreateGenre.Flags().StringVar(&createGenreReq.Name, "name", "", "Name of the new genre (required)")
+	cmdCreateGenre.Flags().Int64Var(&createGenreReq.ParentID, "parent_id", 0, "ID of the parent genre")
+	cmdCreateGenre.MarkFlagRequired("name")
+
+	updateGenreReq := new(updateGenreReqCli)
+	cmdUpdateGenre := &cobra.Command{
+		Use:   "update_genre",
+		Short: "Rename an existing genre or move it under another parent",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, updateGenreReq.toAPIReq, c.httpClient.UpdateGenre)
+		},
+	}
+
+	cmdUpdateGenre.Flags().Int64Var(&updateGenreReq.ID, "id", 0, "ID of the genre to update (required)")
+	cmdUpdateGenre.Flags().StringVar(&updateGenreReq.Name, "name", "", "Updated name of the genre (required)")
+	cmdUpdateGenre.Flags().Int64Var(&updateGenreReq.ParentID, "parent_id", 0, "ID of the new parent genre, 0 for a top level genre")
+	cmdUpdateGenre.MarkFlagRequired("id")
+	cmdUpdateGenre.MarkFlagRequired("name")
+
+	deleteGenreReq := new(deleteGenreReqCli)
+	cmdDeleteGenre := &cobra.Command{
+		Use:   "delete_genre",
+		Short: "Delete a genre without books and subgenres",
+		Run: func(cmd *cobra.Command, args []string) {
+			process(ctx, deleteGenreReq.toAPIReq, c.httpClient.DeleteGenre)
+		},
+	}
+
+	cmdDeleteGenre.Flags().Int64Var(&deleteGenreReq.ID, "id", 0, "ID of the genre to delete (required)")
+	cmdDeleteGenre.MarkFlagRequired("id")
+
 	rootCmd := &cobra.Command{Use: "app"}
 	rootCmd.AddCommand(
 		cmdGetBook,
+		cmdGetBookByISBN,
 		cmdCreateBook,
 		cmdGetBooks,
 		cmdUpdateBooks,
@@ -253,6 +871,44 @@ func main() {
 		cmdDeleteCollections,
 		cmdCreateBooksCollection,
 		cmdDeleteBooksCollection,
+		cmdGetAuthor,
+		cmdGetAuthors,
+		cmdCreateAuthor,
+		cmdUpdateAuthor,
+		cmdDeleteAuthor,
+		cmdGetTags,
+		cmdAddBooksTags,
+		cmdDeleteBooksTags,
+		cmdGetReading,
+		cmdUpdateReading,
+		cmdGetReadingReport,
+		cmdUploadCover,
+		cmdAddFile,
+		cmdGetFiles,
+		cmdDeleteFile,
+		cmdImportBooks,
+		cmdImportMARC,
+		cmdExport,
+		cmdCite,
+		cmdGetCopy,
+		cmdGetCopyByBarcode,
+		cmdGetCopies,
+		cmdCreateCopy,
+		cmdUpdateCopy,
+		cmdDeleteCopy,
+		cmdLendBook,
+		cmdReturnBook,
+		cmdGetLoans,
+		cmdGetOverdueLoans,
+		cmdCreateReview,
+		cmdGetReviews,
+		cmdGetGenre,
+		cmdGetGenres,
+		cmdCreateGenre,
+		cmdUpdateGenre,
+		cmdDeleteGenre,
+		cmdMoveBooks,
+		cmdMoveCollection,
 	)
 	rootCmd.Execute()
 }
@@ -260,6 +916,7 @@ func main() {
 type (
 	config struct {
 		SocketPath string
+		APIKey     string
 		Timeout    time.Duration
 	}
 
@@ -275,6 +932,7 @@ func newClient(cfg config) *cliClient {
 		httpClient: httpclient.New(httpclient.Config{
 			Address:    "http://localhost",
 			SocketPath: cfg.SocketPath,
+			APIKey:     cfg.APIKey,
 			Timeout:    cfg.Timeout,
 		}),
 	}
@@ -290,9 +948,28 @@ func (r *getBookReqCli) toAPIReq() (*api.GetBookReq, error) {
 	}, nil
 }
 
+type getBookByISBNReqCli struct {
+	ISBN string
+}
+
+func (r *getBookByISBNReqCli) toAPIReq() (*api.GetBookByISBNReq, error) {
+	return &api.GetBookByISBNReq{
+		ISBN: r.ISBN,
+	}, nil
+}
+
 type getBooksReqCli struct {
+	Query        string
 	Author       string
+	AuthorID     int64
 	Genre        string
+	GenreID      int64
+	Tags         []string
+	TagsMatch    string
+	Status       string
+	MinRating    float64
+	OnLoan       string
+	ISBN         string
 	CollectionID int64
 	StartDate    string
 	FinishDate   string
@@ -304,8 +981,16 @@ type getBooksReqCli struct {
 
 func (r *getBooksReqCli) toAPIReq() (*api.GetBooksReq, error) {
 	req := &api.GetBooksReq{
+		Query:        r.Query,
 		Author:       r.Author,
+		AuthorID:     r.AuthorID,
 		Genre:        r.Genre,
+		GenreID:      r.GenreID,
+		Tags:         r.Tags,
+		TagsMatch:    r.TagsMatch,
+		Status:       r.Status,
+		MinRating:    r.MinRating,
+		ISBN:         r.ISBN,
 		CollectionID: r.CollectionID,
 		OrderBy:      r.OrderBy,
 		Desc:         r.Desc,
@@ -315,6 +1000,15 @@ func (r *getBooksReqCli) toAPIReq() (*api.GetBooksReq, error) {
 
 	var err error
 
+	if r.OnLoan != "" {
+		onLoan, err := strconv.ParseBool(r.OnLoan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse on_loan: %v", err)
+		}
+
+		req.OnLoan = &onLoan
+	}
+
 	if r.StartDate != "" {
 		req.StartDate, err = time.Parse(formatDate, r.StartDate)
 		if err != nil {
@@ -339,6 +1033,10 @@ type createBookReqCli struct {
 	Edition       string
 	Description   string
 	Genre         string
+	GenreID       int64
+	ISBN          string
+	Contributors  []string
+	Tags          []string
 }
 
 func (r *createBookReqCli) toAPIReq() (*api.CreateBookReq, error) {
@@ -347,6 +1045,11 @@ func (r *createBookReqCli) toAPIReq() (*api.CreateBookReq, error) {
 		return nil, fmt.Errorf("failed to parse published_date: %v", err)
 	}
 
+	contributors, err := parseContributors(r.Contributors)
+	if err != nil {
+		return nil, err
+	}
+
 	return &api.CreateBookReq{
 		Title:         r.Title,
 		Author:        r.Author,
@@ -354,6 +1057,10 @@ func (r *createBookReqCli) toAPIReq() (*api.CreateBookReq, error) {
 		Edition:       r.Edition,
 		Description:   r.Description,
 		Genre:         r.Genre,
+		GenreID:       r.GenreID,
+		ISBN:          r.ISBN,
+		Contributors:  contributors,
+		Tags:          r.Tags,
 	}, nil
 }
 
@@ -365,10 +1072,14 @@ type updateBookReqCli struct {
 	Edition       string
 	Description   string
 	Genre         string
+	GenreID       int64
+	ISBN          string
+	Contributors  []string
 }
 
 type deleteBooksReqCli struct {
-	IDs []int64
+	IDs   []int64
+	Force bool
 }
 
 func (r *updateBookReqCli) toAPIReq() (*api.UpdateBookReq, error) {
@@ -382,6 +1093,11 @@ func (r *updateBookReqCli) toAPIReq() (*api.UpdateBookReq, error) {
 		}
 	}
 
+	contributors, err := parseContributors(r.Contributors)
+	if err != nil {
+		return nil, err
+	}
+
 	return &api.UpdateBookReq{
 		ID:            r.ID,
 		Title:         r.Title,
@@ -390,12 +1106,35 @@ func (r *updateBookReqCli) toAPIReq() (*api.UpdateBookReq, error) {
 		Edition:       r.Edition,
 		Description:   r.Description,
 		Genre:         r.Genre,
+		GenreID:       r.GenreID,
+		ISBN:          r.ISBN,
+		Contributors:  contributors,
 	}, nil
 }
 
+// parseContributors parses contributors in the format role=name. A name without a role is an author.
+func parseContributors(values []string) ([]api.Contributor, error) {
+	contributors := make([]api.Contributor, 0, len(values))
+	for _, v := range values {
+		role, name, found := strings.Cut(v, "=")
+		if !found {
+			role, name = "author", v
+		}
+
+		if name == "" {
+			return nil, fmt.Errorf("contributor %q has no name", v)
+		}
+
+		contributors = append(contributors, api.Contributor{Name: name, Role: role})
+	}
+
+	return contributors, nil
+}
+
 func (r *deleteBooksReqCli) toAPIReq() (*api.DeleteBooksReq, error) {
 	return &api.DeleteBooksReq{
-		IDs: r.IDs,
+		IDs:   r.IDs,
+		Force: r.Force,
 	}, nil
 }
 
@@ -484,3 +1223,557 @@ func (r *deleteBooksCollectionReqCli) toAPIReq() (*api.DeleteBooksCollectionReq,
 		BookIDs: r.BookIDs,
 	}, nil
 }
+
+type moveBooksReqCli struct {
+	IDs    []int64
+	Tenant string
+}
+
+func (r *moveBooksReqCli) toAPIReq() (*api.MoveBooksReq, error) {
+	return &api.MoveBooksReq{
+		IDs:    r.IDs,
+		Tenant: r.Tenant,
+	}, nil
+}
+
+type moveCollectionReqCli struct {
+	ID     int64
+	Tenant string
+}
+
+func (r *moveCollectionReqCli) toAPIReq() (*api.MoveCollectionReq, error) {
+	return &api.MoveCollectionReq{
+		ID:     r.ID,
+		Tenant: r.Tenant,
+	}, nil
+}
+
+type getAuthorReqCli struct {
+	ID int64
+}
+
+func (r *getAuthorReqCli) toAPIReq() (*api.GetAuthorReq, error) {
+	return &api.GetAuthorReq{
+		ID: r.ID,
+	}, nil
+}
+
+type getAuthorsReqCli struct {
+	Name     string
+	OrderBy  string
+	Desc     bool
+	Page     int64
+	PageSize int64
+}
+
+func (r *getAuthorsReqCli) toAPIReq() (*api.GetAuthorsReq, error) {
+	return &api.GetAuthorsReq{
+		Name:     r.Name,
+		OrderBy:  r.OrderBy,
+		Desc:     r.Desc,
+		Page:     r.Page,
+		PageSize: r.PageSize,
+	}, nil
+}
+
+type createAuthorReqCli struct {
+	Name string
+}
+
+func (r *createAuthorReqCli) toAPIReq() (*api.CreateAuthorReq, error) {
+	return &api.CreateAuthorReq{
+		Name: r.Name,
+	}, nil
+}
+
+type updateAuthorReqCli struct {
+	ID   int64
+	Name string
+}
+
+func (r *updateAuthorReqCli) toAPIReq() (*api.UpdateAuthorReq, error) {
+	return &api.UpdateAuthorReq{
+		ID:   r.ID,
+		Name: r.Name,
+	}, nil
+}
+
+type deleteAuthorReqCli struct {
+	ID int64
+}
+
+func (r *deleteAuthorReqCli) toAPIReq() (*api.DeleteAuthorReq, error) {
+	return &api.DeleteAuthorReq{
+		ID: r.ID,
+	}, nil
+}
+
+type getTagsReqCli struct {
+	OrderBy  string
+	Desc     bool
+	Page     int64
+	PageSize int64
+}
+
+func (r *getTagsReqCli) toAPIReq() (*api.GetTagsReq, error) {
+	return &api.GetTagsReq{
+		OrderBy:  r.OrderBy,
+		Desc:     r.Desc,
+		Page:     r.Page,
+		PageSize: r.PageSize,
+	}, nil
+}
+
+type booksTagsReqCli struct {
+	BookIDs []int64
+	Tags    []string
+}
+
+func (r *booksTagsReqCli) toAddAPIReq() (*api.AddBooksTagsReq, error) {
+	return &api.AddBooksTagsReq{
+		BookIDs: r.BookIDs,
+		Tags:    r.Tags,
+	}, nil
+}
+
+func (r *booksTagsReqCli) toDeleteAPIReq() (*api.DeleteBooksTagsReq, error) {
+	return &api.DeleteBooksTagsReq{
+		BookIDs: r.BookIDs,
+		Tags:    r.Tags,
+	}, nil
+}
+
+type getReadingReqCli struct {
+	BookID int64
+}
+
+func (r *getReadingReqCli) toAPIReq() (*api.GetReadingReq, error) {
+	return &api.GetReadingReq{
+		BookID: r.BookID,
+	}, nil
+}
+
+type updateReadingReqCli struct {
+	BookID      int64
+	Status      string
+	Date        string
+	CurrentPage int64
+	Notes       string
+}
+
+func (r *updateReadingReqCli) toAPIReq() (*api.UpdateReadingReq, error) {
+	if r.Date != "" {
+		if _, err := time.Parse(formatDate, r.Date); err != nil {
+			return nil, fmt.Errorf("failed to parse date: %v", err)
+		}
+	}
+
+	return &api.UpdateReadingReq{
+		BookID:      r.BookID,
+		Status:      r.Status,
+		Date:        r.Date,
+		CurrentPage: r.CurrentPage,
+		Notes:       r.Notes,
+	}, nil
+}
+
+type getReadingReportReqCli struct {
+	Year int
+}
+
+func (r *getReadingReportReqCli) toAPIReq() (*api.GetReadingReportReq, error) {
+	return &api.GetReadingReportReq{
+		Year: r.Year,
+	}, nil
+}
+
+type uploadCoverReqCli struct {
+	BookID int64
+	File   string
+}
+
+func (r *uploadCoverReqCli) toAPIReq() (*api.UploadCoverReq, error) {
+	data, err := os.ReadFile(r.File)
+	if err != nil {
+		return nil, fmt.Errorf("read cover: %w", err)
+	}
+
+	return &api.UploadCoverReq{
+		BookID: r.BookID,
+		Data:   data,
+	}, nil
+}
+
+type addFileReqCli struct {
+	BookID int64
+	File   string
+	Name   string
+	Genre  string
+}
+
+func (r *addFileReqCli) toAPIReq() (*api.AddBookFileReq, error) {
+	data, err := os.ReadFile(r.File)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	return &api.AddBookFileReq{
+		BookID: r.BookID,
+		Name:   r.name(),
+		Data:   data,
+	}, nil
+}
+
+func (r *addFileReqCli) toImportAPIReq() (*api.ImportEPUBReq, error) {
+	data, err := os.ReadFile(r.File)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	return &api.ImportEPUBReq{
+		Name:  r.name(),
+		Genre: r.Genre,
+		Data:  data,
+	}, nil
+}
+
+func (r *addFileReqCli) name() string {
+	if r.Name != "" {
+		return r.Name
+	}
+
+	return filepath.Base(r.File)
+}
+
+type importBooksReqCli struct {
+	File   string
+	Genre  string
+	DryRun bool
+}
+
+func (r *importBooksReqCli) toAPIReq() (*api.ImportBooksReq, error) {
+	data, err := os.ReadFile(r.File)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	return &api.ImportBooksReq{
+		Genre:  r.Genre,
+		DryRun: r.DryRun,
+		Data:   data,
+	}, nil
+}
+
+type importMARCReqCli struct {
+	File   string
+	Genre  string
+	DryRun bool
+}
+
+func (r *importMARCReqCli) toAPIReq() (*api.ImportMARCReq, error) {
+	data, err := os.ReadFile(r.File)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	return &api.ImportMARCReq{
+		Genre:  r.Genre,
+		DryRun: r.DryRun,
+		Data:   data,
+	}, nil
+}
+
+type exportReqCli struct {
+	BookID       int64
+	CollectionID int64
+	Format       string
+	Output       string
+}
+
+func (r *exportReqCli) toBookAPIReq() (*api.ExportBookReq, error) {
+	return &api.ExportBookReq{
+		BookID: r.BookID,
+		Format: r.Format,
+	}, nil
+}
+
+func (r *exportReqCli) toCollectionAPIReq() (*api.ExportCollectionReq, error) {
+	return &api.ExportCollectionReq{
+		CollectionID: r.CollectionID,
+		Format:       r.Format,
+	}, nil
+}
+
+type getFilesReqCli struct {
+	BookID int64
+}
+
+func (r *getFilesReqCli) toAPIReq() (*api.GetBookFilesReq, error) {
+	return &api.GetBookFilesReq{
+		BookID: r.BookID,
+	}, nil
+}
+
+type deleteFileReqCli struct {
+	BookID int64
+	ID     int64
+}
+
+func (r *deleteFileReqCli) toAPIReq() (*api.DeleteBookFileReq, error) {
+	return &api.DeleteBookFileReq{
+		BookID: r.BookID,
+		ID:     r.ID,
+	}, nil
+}
+
+type getCopyReqCli struct {
+	ID int64
+}
+
+func (r *getCopyReqCli) toAPIReq() (*api.GetCopyReq, error) {
+	return &api.GetCopyReq{
+		ID: r.ID,
+	}, nil
+}
+
+type getCopyByBarcodeReqCli struct {
+	Barcode string
+}
+
+func (r *getCopyByBarcodeReqCli) toAPIReq() (*api.GetCopyByBarcodeReq, error) {
+	return &api.GetCopyByBarcodeReq{
+		Barcode: r.Barcode,
+	}, nil
+}
+
+type getCopiesReqCli struct {
+	BookID   int64
+	Location string
+	OrderBy  string
+	Desc     bool
+	Page     int64
+	PageSize int64
+}
+
+func (r *getCopiesReqCli) toAPIReq() (*api.GetCopiesReq, error) {
+	return &api.GetCopiesReq{
+		BookID:   r.BookID,
+		Location: r.Location,
+		OrderBy:  r.OrderBy,
+		Desc:     r.Desc,
+		Page:     r.Page,
+		PageSize: r.PageSize,
+	}, nil
+}
+
+type copyReqCli struct {
+	ID         int64
+	BookID     int64
+	Barcode    string
+	Condition  string
+	AcquiredAt string
+	Price      int64
+	Location   string
+}
+
+func (r *copyReqCli) toCreateAPIReq() (*api.CreateCopyReq, error) {
+	if r.AcquiredAt != "" {
+		if _, err := time.Parse(formatDate, r.AcquiredAt); err != nil {
+			return nil, fmt.Errorf("failed to parse acquired_at: %v", err)
+		}
+	}
+
+	return &api.CreateCopyReq{
+		BookID:     r.BookID,
+		Barcode:    r.Barcode,
+		Condition:  r.Condition,
+		AcquiredAt: r.AcquiredAt,
+		Price:      r.Price,
+		Location:   r.Location,
+	}, nil
+}
+
+func (r *copyReqCli) toUpdateAPIReq() (*api.UpdateCopyReq, error) {
+	if r.AcquiredAt != "" {
+		if _, err := time.Parse(formatDate, r.AcquiredAt); err != nil {
+			return nil, fmt.Errorf("failed to parse acquired_at: %v", err)
+		}
+	}
+
+	return &api.UpdateCopyReq{
+		ID:         r.ID,
+		Barcode:    r.Barcode,
+		Condition:  r.Condition,
+		AcquiredAt: r.AcquiredAt,
+		Price:      r.Price,
+		Location:   r.Location,
+	}, nil
+}
+
+type deleteCopyReqCli struct {
+	ID int64
+}
+
+func (r *deleteCopyReqCli) toAPIReq() (*api.DeleteCopyReq, error) {
+	return &api.DeleteCopyReq{
+		ID: r.ID,
+	}, nil
+}
+
+type lendBookReqCli struct {
+	BookID   int64
+	Borrower string
+	LentAt   string
+	DueAt    string
+}
+
+func (r *lendBookReqCli) toAPIReq() (*api.LendBookReq, error) {
+	for _, date := range []string{r.LentAt, r.DueAt} {
+		if date == "" {
+			continue
+		}
+
+		if _, err := time.Parse(formatDate, date); err != nil {
+			return nil, fmt.Errorf("failed to parse date: %v", err)
+		}
+	}
+
+	return &api.LendBookReq{
+		BookID:   r.BookID,
+		Borrower: r.Borrower,
+		LentAt:   r.LentAt,
+		DueAt:    r.DueAt,
+	}, nil
+}
+
+type returnBookReqCli struct {
+	BookID     int64
+	ReturnedAt string
+}
+
+func (r *returnBookReqCli) toAPIReq() (*api.ReturnBookReq, error) {
+	if r.ReturnedAt != "" {
+		if _, err := time.Parse(formatDate, r.ReturnedAt); err != nil {
+			return nil, fmt.Errorf("failed to parse returned_at: %v", err)
+		}
+	}
+
+	return &api.ReturnBookReq{
+		BookID:     r.BookID,
+		ReturnedAt: r.ReturnedAt,
+	}, nil
+}
+
+type getLoansReqCli struct {
+	OrderBy  string
+	Desc     bool
+	Page     int64
+	PageSize int64
+}
+
+func (r *getLoansReqCli) toAPIReq() (*api.GetLoansReq, error) {
+	return &api.GetLoansReq{
+		OrderBy:  r.OrderBy,
+		Desc:     r.Desc,
+		Page:     r.Page,
+		PageSize: r.PageSize,
+	}, nil
+}
+
+type createReviewReqCli struct {
+	BookID int64
+	Rating int64
+	Text   string
+}
+
+func (r *createReviewReqCli) toAPIReq() (*api.CreateReviewReq, error) {
+	return &api.CreateReviewReq{
+		BookID: r.BookID,
+		Rating: r.Rating,
+		Text:   r.Text,
+	}, nil
+}
+
+type getReviewsReqCli struct {
+	BookID   int64
+	OrderBy  string
+	Desc     bool
+	Page     int64
+	PageSize int64
+}
+
+func (r *getReviewsReqCli) toAPIReq() (*api.GetReviewsReq, error) {
+	return &api.GetReviewsReq{
+		BookID:   r.BookID,
+		OrderBy:  r.OrderBy,
+		Desc:     r.Desc,
+		Page:     r.Page,
+		PageSize: r.PageSize,
+	}, nil
+}
+
+type getGenreReqCli struct {
+	ID int64
+}
+
+func (r *getGenreReqCli) toAPIReq() (*api.GetGenreReq, error) {
+	return &api.GetGenreReq{
+		ID: r.ID,
+	}, nil
+}
+
+type getGenresReqCli struct {
+	Name     string
+	ParentID int64
+	OrderBy  string
+	Desc     bool
+	Page     int64
+	PageSize int64
+}
+
+func (r *getGenresReqCli) toAPIReq() (*api.GetGenresReq, error) {
+	return &api.GetGenresReq{
+		Name:     r.Name,
+		ParentID: r.ParentID,
+		OrderBy:  r.OrderBy,
+		Desc:     r.Desc,
+		Page:     r.Page,
+		PageSize: r.PageSize,
+	}, nil
+}
+
+type createGenreReqCli struct {
+	Name     string
+	ParentID int64
+}
+
+func (r *createGenreReqCli) toAPIReq() (*api.CreateGenreReq, error) {
+	return &api.CreateGenreReq{
+		Name:     r.Name,
+		ParentID: r.ParentID,
+	}, nil
+}
+
+type updateGenreReqCli struct {
+	ID       int64
+	Name     string
+	ParentID int64
+}
+
+func (r *updateGenreReqCli) toAPIReq() (*api.UpdateGenreReq, error) {
+	return &api.UpdateGenreReq{
+		ID:       r.ID,
+		Name:     r.Name,
+		ParentID: r.ParentID,
+	}, nil
+}
+
+type deleteGenreReqCli struct {
+	ID int64
+}
+
+func (r *deleteGenreReqCli) toAPIReq() (*api.DeleteGenreReq, error) {
+	return &api.DeleteGenreReq{
+		ID: r.ID,
+	}, nil
+}
@@ -1,17 +1,34 @@
 package bmtest
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"encoding/xml"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/Tsapen/bm/internal/book-service/epubtest"
 	"github.com/Tsapen/bm/pkg/api"
 	httpclient "github.com/Tsapen/bm/pkg/http-client"
 )
 
+// Api keys from configs/test_server_config.json.
+const (
+	adminAPIKey  = "test-admin-key"
+	readerAPIKey = "test-reader-key"
+)
+
 type storage struct {
 	books       []*api.Book
 	collections []*api.Collection
@@ -173,7 +190,7 @@ func (s *storage) testBooks(ctx context.Context, t *testing.T, client *httpclien
 		},
 		{
 			modify: func(req *api.UpdateBookReq) {
-				req.Genre = uuid.NewString()
+				req.Genre = "Novel"
 			},
 		},
 	}
@@ -184,7 +201,7 @@ func (s *storage) testBooks(ctx context.Context, t *testing.T, client *httpclien
 
 		resp, err := client.UpdateBook(ctx, &req)
 		assert.NoError(t, err)
-		assert.True(t, resp.Success)
+		assert.True(t, resp)
 
 		want := req
 		got := getBook(ctx, t, client, &api.GetBookReq{ID: book.ID})
@@ -200,7 +217,7 @@ func (s *storage) testBooks(ctx context.Context, t *testing.T, client *httpclien
 	// 4. Delete testing.
 	deleteResp, err := client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{s.books[4].ID}})
 	assert.NoError(t, err)
-	assert.True(t, deleteResp.Success)
+	assert.True(t, deleteResp)
 
 	s.books = s.books[:4]
 }
@@ -300,256 +317,1668 @@ func (s *storage) testDeleteBookValidation(ctx context.Context, t *testing.T, cl
 	}
 }
 
-func (s *storage) testCollections(ctx context.Context, t *testing.T, client *httpclient.Client) {
-	// 1. Create collections.
-	s.collections = []*api.Collection{
-		{
-			Name:        "Classic Novels",
-			Description: "A collection of classic novels from various authors.",
-		},
-		{
-			Name:        "Russian Literature",
-			Description: "A collection of novels and literature from Russian authors.",
-		},
-		{
-			Name:        "Contemporary Fiction",
-			Description: "A collection of modern and contemporary fiction books.",
-		},
-	}
-	for _, c := range s.collections {
-		req := &api.CreateCollectionReq{
-			Name:        c.Name,
-			Description: c.Description,
-		}
-		resp, err := client.CreateCollection(ctx, req)
+func (s *storage) testISBN(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	// 1. ISBN-10 is stored as ISBN-13.
+	createResp, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "Numerical Recipes",
+		Author: "William H. Press",
+		Genre:  "Science",
+		ISBN:   "0-306-40615-2",
+	})
+	assert.NoError(t, err)
+
+	for _, isbn := range []string{"0306406152", "978-0-306-40615-7"} {
+		got, err := client.GetBookByISBN(ctx, &api.GetBookByISBNReq{ISBN: isbn})
 		assert.NoError(t, err)
-		c.ID = resp.ID
+		assert.Equal(t, createResp.ID, got.Book.ID)
+		assert.Equal(t, "9780306406157", got.Book.ISBN13)
+		assert.Equal(t, "0306406152", got.Book.ISBN10)
 	}
 
-	// 2. Read testing.
-	getByIDTests := []struct {
-		give     *api.GetCollectionReq
-		wantBook *api.Collection
+	got := getBooks(ctx, t, client, &api.GetBooksReq{ISBN: "9780306406157"})
+	assert.Len(t, got.Books, 1)
+
+	// 2. ISBN is unique and validated.
+	tests := []struct {
+		req *api.CreateBookReq
 	}{
 		{
-			give: &api.GetCollectionReq{
-				ID: s.books[0].ID,
+			req: &api.CreateBookReq{
+				Title:  "Numerical Recipes in C",
+				Author: "William H. Press",
+				Genre:  "Science",
+				ISBN:   "9780306406157",
 			},
-			wantBook: s.collections[0],
 		},
-	}
-	for _, tt := range getByIDTests {
-		got := getCollection(ctx, t, client, tt.give)
-		assert.Equal(t, tt.wantBook.ID, got.Collection.ID)
-		assert.Equal(t, tt.wantBook.Name, got.Collection.Name)
-	}
-
-	filterTests := []struct {
-		give            *api.GetCollectionsReq
-		wantCollections []api.Collection
-	}{
 		{
-			give: &api.GetCollectionsReq{
-				OrderBy:  "name",
-				Desc:     true,
-				Page:     2,
-				PageSize: 1,
+			req: &api.CreateBookReq{
+				Title:  "title",
+				Author: "author",
+				Genre:  "genre",
+				ISBN:   "0-306-40615-3",
 			},
-			wantCollections: []api.Collection{*s.collections[1]},
 		},
 	}
-	for _, tt := range filterTests {
-		got := getCollections(ctx, t, client, tt.give)
-		assert.Equal(t, len(tt.wantCollections), len(got.Collections))
-		for i := 0; i < len(tt.wantCollections); i++ {
-			assert.Equal(t, tt.wantCollections[i].ID, got.Collections[i].ID)
-			assert.Equal(t, tt.wantCollections[i].Name, got.Collections[i].Name)
-		}
+	for _, tt := range tests {
+		_, err := client.CreateBook(ctx, tt.req)
+		assert.Error(t, err)
 	}
 
-	// 3. Update testing.
-	collection := s.collections[2]
-	updateReq := api.UpdateCollectionReq{
-		ID:          collection.ID,
-		Name:        collection.Name,
-		Description: collection.Description,
-	}
-	updateTests := []struct {
-		modify func(*api.UpdateCollectionReq)
-	}{
-		{
-			modify: func(req *api.UpdateCollectionReq) {
-				req.Name = uuid.NewString()
-			},
-		},
-		{
-			modify: func(req *api.UpdateCollectionReq) {
-				req.Description = uuid.NewString()
-			},
+	_, err = client.GetBookByISBN(ctx, &api.GetBookByISBNReq{ISBN: "9791090636071"})
+	assert.Error(t, err)
+
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{createResp.ID}})
+	assert.NoError(t, err)
+}
+
+func (s *storage) testAuthors(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	// 1. Create a book with contributors, missing authors are created.
+	createResp, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title: "The Brothers Karamazov",
+		Genre: "Classic Fiction",
+		Contributors: []api.Contributor{
+			{Name: "Fyodor Dostoevsky"},
+			{Name: "Constance Garnett", Role: "translator"},
 		},
-	}
-	for _, tt := range updateTests {
-		req := updateReq
+	})
+	assert.NoError(t, err)
 
-		tt.modify(&req)
+	got := getBook(ctx, t, client, &api.GetBookReq{ID: createResp.ID})
+	assert.Equal(t, "Fyodor Dostoevsky", got.Book.Author)
+	assert.Len(t, got.Book.Contributors, 2)
 
-		resp, err := client.UpdateCollection(ctx, &req)
-		assert.NoError(t, err)
-		assert.True(t, resp.Success)
+	firstAuthorID := got.Book.Contributors[0].AuthorID
 
-		want := req
-		got := getCollection(ctx, t, client, &api.GetCollectionReq{ID: collection.ID})
-		gotCollection := got.Collection
-		assert.Equal(t, want.Name, gotCollection.Name)
-		assert.Equal(t, want.Description, gotCollection.Description)
-	}
+	authorsResp, err := client.GetAuthors(ctx, &api.GetAuthorsReq{Name: "garnett"})
+	assert.NoError(t, err)
+	assert.Len(t, authorsResp.Authors, 1)
 
-	// 4. Delete testing.
-	deleteResp, err := client.DeleteCollection(ctx, &api.DeleteCollectionReq{ID: s.collections[2].ID})
+	translator := authorsResp.Authors[0]
+	assert.Equal(t, "Constance Garnett", translator.Name)
+
+	books := getBooks(ctx, t, client, &api.GetBooksReq{AuthorID: translator.ID})
+	assert.Len(t, books.Books, 1)
+
+	// 2. Legacy update of the author keeps other contributors.
+	_, err = client.UpdateBook(ctx, &api.UpdateBookReq{
+		ID:     createResp.ID,
+		Title:  "The Brothers Karamazov",
+		Author: "F. M. Dostoevsky",
+		Genre:  "Classic Fiction",
+	})
 	assert.NoError(t, err)
-	assert.True(t, deleteResp.Success)
 
-	s.collections = s.collections[:2]
-}
+	got = getBook(ctx, t, client, &api.GetBookReq{ID: createResp.ID})
+	assert.Equal(t, "F. M. Dostoevsky", got.Book.Author)
+	assert.Equal(t, []api.Contributor{
+		{AuthorID: got.Book.Contributors[0].AuthorID, Name: "F. M. Dostoevsky", Role: "author"},
+		{AuthorID: translator.ID, Name: "Constance Garnett", Role: "translator"},
+	}, got.Book.Contributors)
 
-func (s *storage) testCreateCollectionValidation(ctx context.Context, t *testing.T, client *httpclient.Client) {
-	tests := []struct {
-		req *api.CreateCollectionReq
-	}{
-		{
-			req: &api.CreateCollectionReq{},
-		},
-	}
-	for _, tt := range tests {
-		_, err := client.CreateCollection(ctx, tt.req)
-		assert.Error(t, err)
+	// 3. Renaming an author renames it in books.
+	authorID := got.Book.Contributors[0].AuthorID
+	_, err = client.UpdateAuthor(ctx, &api.UpdateAuthorReq{ID: authorID, Name: "Fyodor Mikhailovich Dostoevsky"})
+	assert.NoError(t, err)
+
+	got = getBook(ctx, t, client, &api.GetBookReq{ID: createResp.ID})
+	assert.Equal(t, "Fyodor Mikhailovich Dostoevsky", got.Book.Author)
+
+	// 4. Books are filtered by any of their authors, author lines aren't limited by lengths of names.
+	coauthors := make([]api.Contributor, 0, 3)
+	for _, name := range []string{strings.Repeat("Arkady Strugatsky ", 5), strings.Repeat("Boris Strugatsky ", 5), "Fyodor Mikhailovich Dostoevsky"} {
+		coauthors = append(coauthors, api.Contributor{Name: strings.TrimSpace(name)})
 	}
-}
 
-func (s *storage) testGetCollectionValidation(ctx context.Context, t *testing.T, client *httpclient.Client) {
-	tests := []struct {
-		req *api.GetCollectionsReq
-	}{
-		{
-			req: &api.GetCollectionsReq{
-				OrderBy: "fake_field",
-			},
-		},
+	coauthored, err := client.CreateBook(ctx, &api.CreateBookReq{Title: "Roadside Picnic", Genre: "Science Fiction", Contributors: coauthors})
+	assert.NoError(t, err)
+
+	books = getBooks(ctx, t, client, &api.GetBooksReq{Author: "Fyodor Mikhailovich Dostoevsky", OrderBy: "id"})
+	assert.Len(t, books.Books, 2)
+
+	got = getBook(ctx, t, client, &api.GetBookReq{ID: coauthored.ID})
+	books = getBooks(ctx, t, client, &api.GetBooksReq{Author: got.Book.Author})
+	assert.Len(t, books.Books, 1)
+
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{coauthored.ID}})
+	assert.NoError(t, err)
+
+	// 5. Validation.
+	invalidBooks := []*api.CreateBookReq{
 		{
-			req: &api.GetCollectionsReq{
-				OrderBy: "fake_field",
-			},
+			Title:        "title",
+			Genre:        "genre",
+			Contributors: []api.Contributor{{Name: "name", Role: "narrator"}},
 		},
 		{
-			req: &api.GetCollectionsReq{
-				PageSize: -1,
-			},
+			Title:        "title",
+			Genre:        "genre",
+			Contributors: []api.Contributor{{Name: "name", Role: "editor"}},
 		},
-	}
-	for _, tt := range tests {
-		_, err := client.GetCollections(ctx, tt.req)
-		assert.Error(t, err)
-	}
-}
-
-func (s *storage) testUpdateCollectionValidation(ctx context.Context, t *testing.T, client *httpclient.Client) {
-	tests := []struct {
-		req *api.UpdateCollectionReq
-	}{
 		{
-			req: &api.UpdateCollectionReq{},
+			Title:        "title",
+			Genre:        "genre",
+			Contributors: []api.Contributor{{AuthorID: 1000000}},
 		},
 		{
-			req: &api.UpdateCollectionReq{
-				ID: -1,
-			},
+			Title:        "title",
+			Genre:        "genre",
+			Contributors: []api.Contributor{{AuthorID: translator.ID}, {Name: "Constance Garnett"}},
 		},
 		{
-			req: &api.UpdateCollectionReq{
-				ID: 1,
-			},
+			Title:        "title",
+			Genre:        "genre",
+			Contributors: []api.Contributor{{Name: strings.Repeat("a", 101)}},
 		},
 	}
-	for _, tt := range tests {
-		_, err := client.UpdateCollection(ctx, tt.req)
+	for _, req := range invalidBooks {
+		_, err := client.CreateBook(ctx, req)
 		assert.Error(t, err)
 	}
-}
 
-func (s *storage) testDeleteCollectionValidation(ctx context.Context, t *testing.T, client *httpclient.Client) {
-	tests := []struct {
-		req *api.DeleteCollectionReq
-	}{
-		{
-			req: &api.DeleteCollectionReq{},
-		},
-		{
-			req: &api.DeleteCollectionReq{
-				ID: -1,
-			},
-		},
-	}
-	for _, tt := range tests {
-		_, err := client.DeleteCollection(ctx, tt.req)
-		assert.Error(t, err)
+	_, err = client.CreateAuthor(ctx, &api.CreateAuthorReq{Name: "Constance Garnett"})
+	assert.Error(t, err)
+
+	_, err = client.DeleteAuthor(ctx, &api.DeleteAuthorReq{ID: translator.ID})
+	assert.Error(t, err)
+
+	// 6. Authors without books can be deleted.
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{createResp.ID}})
+	assert.NoError(t, err)
+
+	for _, id := range []int64{firstAuthorID, authorID, translator.ID} {
+		_, err = client.DeleteAuthor(ctx, &api.DeleteAuthorReq{ID: id})
+		assert.NoError(t, err)
 	}
+
+	_, err = client.GetAuthor(ctx, &api.GetAuthorReq{ID: translator.ID})
+	assert.Error(t, err)
 }
 
-func (s *storage) testBooksCollection(ctx context.Context, t *testing.T, client *httpclient.Client) {
-	// 1. Create books collection.
-	collectionID := s.collections[0].ID
-	createCollectionResp, err := client.CreateBooksCollection(ctx, &api.CreateBooksCollectionReq{
-		CID:     s.collections[0].ID,
-		BookIDs: []int64{s.books[0].ID, s.books[1].ID, s.books[2].ID, s.books[3].ID},
+func (s *storage) testGenres(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	// 1. Create a genre with a subgenre.
+	parentResp, err := client.CreateGenre(ctx, &api.CreateGenreReq{Name: "Speculative Fiction"})
+	assert.NoError(t, err)
+
+	childResp, err := client.CreateGenre(ctx, &api.CreateGenreReq{Name: "Cyberpunk", ParentID: parentResp.ID})
+	assert.NoError(t, err)
+
+	genresResp, err := client.GetGenres(ctx, &api.GetGenresReq{ParentID: parentResp.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, []api.Genre{{ID: childResp.ID, Name: "Cyberpunk", ParentID: parentResp.ID}}, genresResp.Genres)
+
+	// 2. Books refer to genres by id or by case-insensitive name.
+	neuromancer, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:   "Neuromancer",
+		Author:  "William Gibson",
+		GenreID: childResp.ID,
 	})
 	assert.NoError(t, err)
-	assert.True(t, createCollectionResp.Success)
 
-	got, err := client.GetBooks(ctx, &api.GetBooksReq{CollectionID: collectionID})
+	solaris, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "Solaris",
+		Author: "Stanislaw Lem",
+		Genre:  "speculative fiction",
+	})
 	assert.NoError(t, err)
-	assert.Len(t, got.Books, 4)
-	for i, b := range s.books[:4] {
-		assert.Equal(t, b.ID, got.Books[i].ID)
-		assert.Equal(t, b.Title, got.Books[i].Title)
+
+	got := getBook(ctx, t, client, &api.GetBookReq{ID: solaris.ID})
+	assert.Equal(t, "Speculative Fiction", got.Book.Genre)
+	assert.Equal(t, parentResp.ID, got.Book.GenreID)
+
+	got = getBook(ctx, t, client, &api.GetBookReq{ID: neuromancer.ID})
+	assert.Equal(t, "Cyberpunk", got.Book.Genre)
+
+	// Aliases resolve to their genres, unknown genres aren't created.
+	_, err = client.UpdateBook(ctx, &api.UpdateBookReq{
+		ID:     solaris.ID,
+		Title:  "Solaris",
+		Author: "Stanislaw Lem",
+		Genre:  "sci-fi",
+	})
+	assert.NoError(t, err)
+
+	got = getBook(ctx, t, client, &api.GetBookReq{ID: solaris.ID})
+	assert.Equal(t, "Science Fiction", got.Book.Genre)
+
+	// Books are filtered by aliases too.
+	books := getBooks(ctx, t, client, &api.GetBooksReq{Genre: " SCI-FI "})
+	found := false
+	for _, b := range books.Books {
+		found = found || b.ID == solaris.ID
 	}
 
-	// 2. Remove books collection.
-	deleteCollectionResp, err := client.DeleteBooksCollection(ctx, &api.DeleteBooksCollectionReq{
-		CID:     s.collections[0].ID,
-		BookIDs: []int64{s.books[2].ID, s.books[3].ID},
+	assert.True(t, found)
+
+	_, err = client.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "The Cyberiad",
+		Author: "Stanislaw Lem",
+		Genre:  "Robot fables",
+	})
+	assert.Error(t, err)
+
+	genresResp, err = client.GetGenres(ctx, &api.GetGenresReq{Name: "Robot fables"})
+	assert.NoError(t, err)
+	assert.Empty(t, genresResp.Genres)
+
+	_, err = client.UpdateBook(ctx, &api.UpdateBookReq{
+		ID:     solaris.ID,
+		Title:  "Solaris",
+		Author: "Stanislaw Lem",
+		Genre:  "speculative fiction",
 	})
 	assert.NoError(t, err)
-	assert.True(t, deleteCollectionResp.Success)
 
-	got, err = client.GetBooks(ctx, &api.GetBooksReq{CollectionID: collectionID})
+	// 3. Filtering by a genre includes its subgenres.
+	books = getBooks(ctx, t, client, &api.GetBooksReq{Genre: "Speculative Fiction"})
+	assert.Len(t, books.Books, 2)
+
+	books = getBooks(ctx, t, client, &api.GetBooksReq{GenreID: childResp.ID})
+	assert.Len(t, books.Books, 1)
+
+	// 4. Renaming a genre renames it in books.
+	_, err = client.UpdateGenre(ctx, &api.UpdateGenreReq{ID: childResp.ID, Name: "Cyberpunk Fiction", ParentID: parentResp.ID})
 	assert.NoError(t, err)
-	assert.Len(t, got.Books, 2)
-	for i, b := range s.books[:2] {
-		assert.Equal(t, b.ID, got.Books[i].ID)
-		assert.Equal(t, b.Title, got.Books[i].Title)
-	}
-}
 
-func (s *storage) testCreateBooksCollectionValidation(ctx context.Context, t *testing.T, client *httpclient.Client) {
-	tests := []struct {
-		req *api.CreateBooksCollectionReq
-	}{
-		{
-			req: &api.CreateBooksCollectionReq{},
-		},
-		{
-			req: &api.CreateBooksCollectionReq{
-				CID: -1,
-			},
-		},
-		{
-			req: &api.CreateBooksCollectionReq{
-				CID: 1,
-			},
-		},
+	got = getBook(ctx, t, client, &api.GetBookReq{ID: neuromancer.ID})
+	assert.Equal(t, "Cyberpunk Fiction", got.Book.Genre)
+
+	// 5. Validation.
+	invalidUpdates := []*api.UpdateGenreReq{
+		{ID: parentResp.ID, Name: "Speculative Fiction", ParentID: childResp.ID},
+		{ID: parentResp.ID, Name: "Speculative Fiction", ParentID: parentResp.ID},
+		{ID: childResp.ID, Name: "SPECULATIVE FICTION"},
+		{ID: childResp.ID, Name: ""},
 	}
-	for _, tt := range tests {
-		_, err := client.CreateBooksCollection(ctx, tt.req)
+	for _, req := range invalidUpdates {
+		_, err := client.UpdateGenre(ctx, req)
+		assert.Error(t, err)
+	}
+
+	_, err = client.CreateGenre(ctx, &api.CreateGenreReq{Name: "Space Opera", ParentID: 1000000})
+	assert.Error(t, err)
+
+	_, err = client.DeleteGenre(ctx, &api.DeleteGenreReq{ID: parentResp.ID})
+	assert.Error(t, err)
+
+	// 6. Genres without books and subgenres can be deleted.
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{neuromancer.ID, solaris.ID}})
+	assert.NoError(t, err)
+
+	for _, id := range []int64{childResp.ID, parentResp.ID} {
+		_, err = client.DeleteGenre(ctx, &api.DeleteGenreReq{ID: id})
+		assert.NoError(t, err)
+	}
+
+	_, err = client.GetGenre(ctx, &api.GetGenreReq{ID: parentResp.ID})
+	assert.Error(t, err)
+}
+
+func (s *storage) testTags(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	// 1. Create books with tags.
+	first, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "Roadside Picnic",
+		Author: "Arkady and Boris Strugatsky",
+		Genre:  "Science Fiction",
+		Tags:   []string{" To-Read ", "soviet"},
+	})
+	assert.NoError(t, err)
+
+	second, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "Hard to Be a God",
+		Author: "Arkady and Boris Strugatsky",
+		Genre:  "Science Fiction",
+	})
+	assert.NoError(t, err)
+
+	got := getBook(ctx, t, client, &api.GetBookReq{ID: first.ID})
+	assert.Equal(t, []string{"soviet", "to-read"}, got.Book.Tags)
+
+	// 2. Tag many books at once.
+	_, err = client.AddBooksTags(ctx, &api.AddBooksTagsReq{BookIDs: []int64{first.ID, second.ID}, Tags: []string{"Strugatsky"}})
+	assert.NoError(t, err)
+
+	tagsResp, err := client.GetTags(ctx, &api.GetTagsReq{OrderBy: "books", Desc: true})
+	assert.NoError(t, err)
+	assert.Len(t, tagsResp.Tags, 3)
+	assert.Equal(t, "strugatsky", tagsResp.Tags[0].Name)
+	assert.Equal(t, int64(2), tagsResp.Tags[0].Books)
+
+	// 3. Filter books by tags.
+	filterTests := []struct {
+		give    *api.GetBooksReq
+		wantIDs []int64
+	}{
+		{
+			give:    &api.GetBooksReq{Tags: []string{"to-read", "strugatsky"}},
+			wantIDs: []int64{first.ID, second.ID},
+		},
+		{
+			give:    &api.GetBooksReq{Tags: []string{"to-read", "strugatsky"}, TagsMatch: "all"},
+			wantIDs: []int64{first.ID},
+		},
+	}
+	for _, tt := range filterTests {
+		books := getBooks(ctx, t, client, tt.give)
+		ids := make([]int64, 0, len(books.Books))
+		for _, b := range books.Books {
+			ids = append(ids, b.ID)
+		}
+
+		assert.Equal(t, tt.wantIDs, ids)
+	}
+
+	// 4. Untag books, unused tags disappear.
+	_, err = client.DeleteBooksTags(ctx, &api.DeleteBooksTagsReq{BookIDs: []int64{first.ID}, Tags: []string{"to-read", "soviet"}})
+	assert.NoError(t, err)
+
+	tagsResp, err = client.GetTags(ctx, &api.GetTagsReq{})
+	assert.NoError(t, err)
+	assert.Len(t, tagsResp.Tags, 1)
+
+	// 5. Validation.
+	invalidTagReqs := []*api.AddBooksTagsReq{
+		{Tags: []string{"tag"}},
+		{BookIDs: []int64{first.ID}},
+		{BookIDs: []int64{first.ID}, Tags: []string{" "}},
+		{BookIDs: []int64{first.ID, 1000000}, Tags: []string{"tag"}},
+	}
+	for _, req := range invalidTagReqs {
+		_, err := client.AddBooksTags(ctx, req)
+		assert.Error(t, err)
+	}
+
+	_, err = client.GetBooks(ctx, &api.GetBooksReq{Tags: []string{"tag"}, TagsMatch: "some"})
+	assert.Error(t, err)
+
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{first.ID, second.ID}})
+	assert.NoError(t, err)
+
+	tagsResp, err = client.GetTags(ctx, &api.GetTagsReq{})
+	assert.NoError(t, err)
+	assert.Empty(t, tagsResp.Tags)
+}
+
+func (s *storage) testReading(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	// 1. Create books without reading status.
+	first, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "The Master and Margarita",
+		Author: "Mikhail Bulgakov",
+		Genre:  "Novel",
+	})
+	assert.NoError(t, err)
+
+	second, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "Heart of a Dog",
+		Author: "Mikhail Bulgakov",
+		Genre:  "Novel",
+	})
+	assert.NoError(t, err)
+
+	readingResp, err := client.GetReading(ctx, &api.GetReadingReq{BookID: first.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, api.Reading{}, readingResp.Reading)
+
+	got := getBook(ctx, t, client, &api.GetBookReq{ID: first.ID})
+	assert.Nil(t, got.Book.Reading)
+
+	// 2. Move books through statuses.
+	updateResp, err := client.UpdateReading(ctx, &api.UpdateReadingReq{BookID: first.ID, Status: "reading", Date: "2001-02-03", CurrentPage: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, api.Reading{Status: "reading", StartedAt: "2001-02-03", CurrentPage: 10}, updateResp.Reading)
+
+	updateResp, err = client.UpdateReading(ctx, &api.UpdateReadingReq{BookID: first.ID, Status: "reading", CurrentPage: 120, Notes: "Woland arrives"})
+	assert.NoError(t, err)
+	assert.Equal(t, api.Reading{Status: "reading", StartedAt: "2001-02-03", CurrentPage: 120, Notes: "Woland arrives"}, updateResp.Reading)
+
+	updateResp, err = client.UpdateReading(ctx, &api.UpdateReadingReq{BookID: first.ID, Status: "finished", Date: "2001-03-04"})
+	assert.NoError(t, err)
+	assert.Equal(t, api.Reading{Status: "finished", StartedAt: "2001-02-03", FinishedAt: "2001-03-04", CurrentPage: 120, Notes: "Woland arrives"}, updateResp.Reading)
+
+	_, err = client.UpdateReading(ctx, &api.UpdateReadingReq{BookID: second.ID, Status: "want_to_read"})
+	assert.NoError(t, err)
+
+	got = getBook(ctx, t, client, &api.GetBookReq{ID: first.ID})
+	assert.Equal(t, &updateResp.Reading, got.Book.Reading)
+
+	// 3. Filter books by status.
+	books := getBooks(ctx, t, client, &api.GetBooksReq{Status: "want_to_read"})
+	assert.Len(t, books.Books, 1)
+	assert.Equal(t, second.ID, books.Books[0].ID)
+
+	// 4. Get books read within the year.
+	report, err := client.GetReadingReport(ctx, &api.GetReadingReportReq{Year: 2001})
+	assert.NoError(t, err)
+	assert.Equal(t, 2001, report.Year)
+	assert.Equal(t, 1, report.Finished)
+	assert.Equal(t, first.ID, report.Books[0].ID)
+
+	report, err = client.GetReadingReport(ctx, &api.GetReadingReportReq{Year: 2002})
+	assert.NoError(t, err)
+	assert.Empty(t, report.Books)
+
+	// 5. Validation.
+	invalidReadingReqs := []*api.UpdateReadingReq{
+		{BookID: second.ID, Status: "abandoned"},
+		{BookID: first.ID, Status: "want_to_read"},
+		{BookID: second.ID, Status: "read"},
+		{BookID: second.ID, Status: "reading", CurrentPage: -1},
+		{BookID: second.ID, Status: "reading", Date: "03.02.2001"},
+		{BookID: first.ID, Status: "finished", Date: "2001-01-01"},
+		{BookID: 1000000, Status: "reading"},
+	}
+	for _, req := range invalidReadingReqs {
+		_, err := client.UpdateReading(ctx, req)
+		assert.Error(t, err)
+	}
+
+	_, err = client.GetBooks(ctx, &api.GetBooksReq{Status: "read"})
+	assert.Error(t, err)
+
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{first.ID, second.ID}})
+	assert.NoError(t, err)
+}
+
+func (s *storage) testReviews(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	// 1. Create books and review them.
+	first, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "Dead Souls",
+		Author: "Nikolai Gogol",
+		Genre:  "Satire",
+	})
+	assert.NoError(t, err)
+
+	second, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "The Overcoat",
+		Author: "Nikolai Gogol",
+		Genre:  "Satire",
+	})
+	assert.NoError(t, err)
+
+	for _, rating := range []int64{5, 4} {
+		_, err := client.CreateReview(ctx, &api.CreateReviewReq{BookID: first.ID, Rating: rating, Text: "Gogol at his best"})
+		assert.NoError(t, err)
+	}
+
+	created, err := client.CreateReview(ctx, &api.CreateReviewReq{BookID: second.ID, Rating: 2})
+	assert.NoError(t, err)
+
+	got := getBook(ctx, t, client, &api.GetBookReq{ID: first.ID})
+	assert.Equal(t, 4.5, got.Book.AverageRating)
+	assert.Equal(t, int64(2), got.Book.ReviewCount)
+
+	// 2. Get and update reviews.
+	reviewsResp, err := client.GetReviews(ctx, &api.GetReviewsReq{BookID: first.ID, OrderBy: "rating"})
+	assert.NoError(t, err)
+	assert.Len(t, reviewsResp.Reviews, 2)
+	assert.Equal(t, int64(4), reviewsResp.Reviews[0].Rating)
+
+	_, err = client.UpdateReview(ctx, &api.UpdateReviewReq{BookID: second.ID, ID: created.ID, Rating: 3, Text: "Better on rereading"})
+	assert.NoError(t, err)
+
+	reviewResp, err := client.GetReview(ctx, &api.GetReviewReq{BookID: second.ID, ID: created.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), reviewResp.Review.Rating)
+	assert.Equal(t, "Better on rereading", reviewResp.Review.Text)
+	assert.False(t, reviewResp.Review.UpdatedAt.Before(reviewResp.Review.CreatedAt))
+
+	// 3. Filter and order books by rating.
+	books := getBooks(ctx, t, client, &api.GetBooksReq{MinRating: 3, OrderBy: "rating", Desc: true})
+	assert.Len(t, books.Books, 2)
+	assert.Equal(t, first.ID, books.Books[0].ID)
+	assert.Equal(t, second.ID, books.Books[1].ID)
+
+	books = getBooks(ctx, t, client, &api.GetBooksReq{MinRating: 4})
+	assert.Len(t, books.Books, 1)
+
+	// 4. Delete a review.
+	_, err = client.DeleteReview(ctx, &api.DeleteReviewReq{BookID: second.ID, ID: created.ID})
+	assert.NoError(t, err)
+
+	got = getBook(ctx, t, client, &api.GetBookReq{ID: second.ID})
+	assert.Zero(t, got.Book.AverageRating)
+	assert.Zero(t, got.Book.ReviewCount)
+
+	// 5. Validation.
+	invalidReviewReqs := []*api.CreateReviewReq{
+		{BookID: first.ID},
+		{BookID: first.ID, Rating: 6},
+		{BookID: 1000000, Rating: 3},
+	}
+	for _, req := range invalidReviewReqs {
+		_, err := client.CreateReview(ctx, req)
+		assert.Error(t, err)
+	}
+
+	_, err = client.UpdateReview(ctx, &api.UpdateReviewReq{BookID: first.ID, ID: created.ID, Rating: 3})
+	assert.Error(t, err)
+
+	_, err = client.DeleteReview(ctx, &api.DeleteReviewReq{BookID: second.ID, ID: created.ID})
+	assert.Error(t, err)
+
+	_, err = client.GetBooks(ctx, &api.GetBooksReq{MinRating: 6})
+	assert.Error(t, err)
+
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{first.ID, second.ID}})
+	assert.NoError(t, err)
+}
+
+func (s *storage) testLoans(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	// 1. Create and lend books.
+	first, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "The Twelve Chairs",
+		Author: "Ilf and Petrov",
+		Genre:  "Satire",
+	})
+	assert.NoError(t, err)
+
+	second, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "The Little Golden Calf",
+		Author: "Ilf and Petrov",
+		Genre:  "Satire",
+	})
+	assert.NoError(t, err)
+
+	_, err = client.LendBook(ctx, &api.LendBookReq{BookID: first.ID, Borrower: "Ostap Bender", LentAt: "2001-01-01", DueAt: "2001-02-01"})
+	assert.NoError(t, err)
+
+	_, err = client.LendBook(ctx, &api.LendBookReq{BookID: second.ID, Borrower: "Kisa Vorobyaninov", DueAt: "2999-01-01"})
+	assert.NoError(t, err)
+
+	// 2. A book can have only one active loan.
+	_, err = client.LendBook(ctx, &api.LendBookReq{BookID: first.ID, Borrower: "Shura Balaganov", DueAt: "2999-01-01"})
+	assert.Error(t, err)
+
+	// 3. List active and overdue loans.
+	loansResp, err := client.GetLoans(ctx, &api.GetLoansReq{OrderBy: "due_at"})
+	assert.NoError(t, err)
+	assert.Len(t, loansResp.Loans, 2)
+	assert.Equal(t, api.Loan{
+		ID:        loansResp.Loans[0].ID,
+		BookID:    first.ID,
+		BookTitle: "The Twelve Chairs",
+		Borrower:  "Ostap Bender",
+		LentAt:    "2001-01-01",
+		DueAt:     "2001-02-01",
+	}, loansResp.Loans[0])
+
+	loansResp, err = client.GetOverdueLoans(ctx, &api.GetLoansReq{})
+	assert.NoError(t, err)
+	assert.Len(t, loansResp.Loans, 1)
+	assert.Equal(t, first.ID, loansResp.Loans[0].BookID)
+
+	onLoan := true
+	books := getBooks(ctx, t, client, &api.GetBooksReq{OnLoan: &onLoan})
+	assert.Len(t, books.Books, 2)
+
+	// 4. Books on loan can't be deleted without force.
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{first.ID}})
+	assert.Error(t, err)
+
+	// 5. Return a book.
+	_, err = client.ReturnBook(ctx, &api.ReturnBookReq{BookID: first.ID, ReturnedAt: "2000-12-31"})
+	assert.Error(t, err)
+
+	_, err = client.ReturnBook(ctx, &api.ReturnBookReq{BookID: first.ID, ReturnedAt: "2001-03-01"})
+	assert.NoError(t, err)
+
+	_, err = client.ReturnBook(ctx, &api.ReturnBookReq{BookID: first.ID})
+	assert.Error(t, err)
+
+	onLoan = false
+	books = getBooks(ctx, t, client, &api.GetBooksReq{OnLoan: &onLoan, OrderBy: "id", Desc: true, PageSize: 1})
+	assert.Equal(t, first.ID, books.Books[0].ID)
+
+	// 6. Validation.
+	invalidLendReqs := []*api.LendBookReq{
+		{BookID: first.ID, DueAt: "2999-01-01"},
+		{BookID: first.ID, Borrower: "Ostap Bender"},
+		{BookID: first.ID, Borrower: "Ostap Bender", LentAt: "2001-02-01", DueAt: "2001-01-01"},
+		{BookID: first.ID, Borrower: "Ostap Bender", DueAt: "01.01.2999"},
+		{BookID: 1000000, Borrower: "Ostap Bender", DueAt: "2999-01-01"},
+	}
+	for _, req := range invalidLendReqs {
+		_, err := client.LendBook(ctx, req)
+		assert.Error(t, err)
+	}
+
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{first.ID, second.ID}, Force: true})
+	assert.NoError(t, err)
+
+	loansResp, err = client.GetLoans(ctx, &api.GetLoansReq{})
+	assert.NoError(t, err)
+	assert.Empty(t, loansResp.Loans)
+}
+
+func (s *storage) testCopies(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	// 1. Create a book and its copies.
+	book, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "Dead Souls",
+		Author: "Nikolai Gogol",
+		Genre:  "Satire",
+	})
+	assert.NoError(t, err)
+
+	first, err := client.CreateCopy(ctx, &api.CreateCopyReq{
+		BookID:     book.ID,
+		Barcode:    "DS-0001",
+		Condition:  "fine",
+		AcquiredAt: "2020-03-01",
+		Price:      1500,
+		Location:   "Hall, shelf 1",
+	})
+	assert.NoError(t, err)
+
+	second, err := client.CreateCopy(ctx, &api.CreateCopyReq{
+		BookID:   book.ID,
+		Barcode:  "DS-0002",
+		Location: "Hall, shelf 2",
+	})
+	assert.NoError(t, err)
+
+	bookResp := getBook(ctx, t, client, &api.GetBookReq{ID: book.ID})
+	assert.Equal(t, int64(2), bookResp.Book.CopyCount)
+
+	// 2. Look up a copy by barcode.
+	copyResp, err := client.GetCopyByBarcode(ctx, &api.GetCopyByBarcodeReq{Barcode: "DS-0001"})
+	assert.NoError(t, err)
+	assert.Equal(t, api.Copy{
+		ID:         first.ID,
+		BookID:     book.ID,
+		BookTitle:  "Dead Souls",
+		Barcode:    "DS-0001",
+		Condition:  "fine",
+		AcquiredAt: "2020-03-01",
+		Price:      1500,
+		Location:   "Hall, shelf 1",
+	}, copyResp.Copy)
+
+	copyResp, err = client.GetCopy(ctx, &api.GetCopyReq{ID: second.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, "good", copyResp.Copy.Condition)
+
+	// 3. List copies of the book and the inventory of a location.
+	copiesResp, err := client.GetCopies(ctx, &api.GetCopiesReq{BookID: book.ID, OrderBy: "barcode", Desc: true})
+	assert.NoError(t, err)
+	assert.Len(t, copiesResp.Copies, 2)
+	assert.Equal(t, second.ID, copiesResp.Copies[0].ID)
+
+	copiesResp, err = client.GetCopies(ctx, &api.GetCopiesReq{Location: "Hall, shelf 2"})
+	assert.NoError(t, err)
+	assert.Len(t, copiesResp.Copies, 1)
+	assert.Equal(t, second.ID, copiesResp.Copies[0].ID)
+
+	// 4. Update and delete a copy.
+	_, err = client.UpdateCopy(ctx, &api.UpdateCopyReq{
+		ID:        second.ID,
+		Barcode:   "DS-0002",
+		Condition: "poor",
+		Location:  "Hall, shelf 1",
+	})
+	assert.NoError(t, err)
+
+	copiesResp, err = client.GetCopies(ctx, &api.GetCopiesReq{Location: "Hall, shelf 1"})
+	assert.NoError(t, err)
+	assert.Len(t, copiesResp.Copies, 2)
+
+	_, err = client.DeleteCopy(ctx, &api.DeleteCopyReq{ID: first.ID})
+	assert.NoError(t, err)
+
+	_, err = client.GetCopy(ctx, &api.GetCopyReq{ID: first.ID})
+	assert.Error(t, err)
+
+	// 5. Validation.
+	invalidCreateReqs := []*api.CreateCopyReq{
+		{BookID: book.ID, Barcode: " "},
+		{BookID: book.ID, Barcode: "DS-0003", Condition: "torn"},
+		{BookID: book.ID, Barcode: "DS-0003", Price: -1},
+		{BookID: book.ID, Barcode: "DS-0003", AcquiredAt: "01.03.2020"},
+		{BookID: book.ID, Barcode: "DS-0002"},
+		{BookID: 1000000, Barcode: "DS-0003"},
+	}
+	for _, req := range invalidCreateReqs {
+		_, err := client.CreateCopy(ctx, req)
+		assert.Error(t, err)
+	}
+
+	// 6. Copies are deleted with their book.
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{book.ID}})
+	assert.NoError(t, err)
+
+	_, err = client.GetCopy(ctx, &api.GetCopyReq{ID: second.ID})
+	assert.Error(t, err)
+}
+
+func (s *storage) testCovers(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	// 1. Upload a cover.
+	book, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "The Nose",
+		Author: "Nikolai Gogol",
+		Genre:  "Satire",
+	})
+	assert.NoError(t, err)
+
+	cover := encodeImage(t, image.NewRGBA(image.Rect(0, 0, 400, 600)), png.Encode)
+	uploadResp, err := client.UploadCover(ctx, &api.UploadCoverReq{BookID: book.ID, Data: cover})
+	assert.NoError(t, err)
+
+	bookResp := getBook(ctx, t, client, &api.GetBookReq{ID: book.ID})
+	assert.Equal(t, uploadResp.CoverURL, bookResp.Book.CoverURL)
+	assert.Equal(t, uploadResp.ThumbnailURL, bookResp.Book.ThumbnailURL)
+
+	// 2. Get the cover and its thumbnail.
+	coverResp, err := client.GetCover(ctx, &api.GetCoverReq{BookID: book.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", coverResp.ContentType)
+	assert.Equal(t, cover, coverResp.Data)
+
+	thumbResp, err := client.GetCover(ctx, &api.GetCoverReq{BookID: book.ID, Size: "thumbnail"})
+	assert.NoError(t, err)
+	assert.Equal(t, "image/jpeg", thumbResp.ContentType)
+
+	thumb, err := jpeg.DecodeConfig(bytes.NewReader(thumbResp.Data))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Config{ColorModel: thumb.ColorModel, Width: 133, Height: 200}, thumb)
+
+	// 3. Unchanged covers aren't sent again, a replaced cover gets a new ETag.
+	notModifiedResp, err := client.GetCover(ctx, &api.GetCoverReq{BookID: book.ID, ETag: coverResp.ETag})
+	assert.NoError(t, err)
+	assert.True(t, notModifiedResp.NotModified)
+	assert.Empty(t, notModifiedResp.Data)
+
+	_, err = client.UploadCover(ctx, &api.UploadCoverReq{
+		BookID: book.ID,
+		Data: encodeImage(t, image.NewGray(image.Rect(0, 0, 100, 100)), func(w io.Writer, img image.Image) error {
+			return jpeg.Encode(w, img, nil)
+		}),
+	})
+	assert.NoError(t, err)
+
+	coverResp, err = client.GetCover(ctx, &api.GetCoverReq{BookID: book.ID, ETag: coverResp.ETag})
+	assert.NoError(t, err)
+	assert.False(t, coverResp.NotModified)
+	assert.Equal(t, "image/jpeg", coverResp.ContentType)
+
+	// 4. Validation.
+	invalidReqs := []*api.UploadCoverReq{
+		{BookID: book.ID},
+		{BookID: book.ID, Data: []byte("not an image")},
+		{BookID: book.ID, Data: encodeImage(t, image.NewGray(image.Rect(0, 0, 1, 1)), func(w io.Writer, img image.Image) error {
+			return gif.Encode(w, img, nil)
+		})},
+		{BookID: book.ID, Data: make([]byte, 6<<20)},
+		{BookID: 1000000, Data: cover},
+	}
+	for _, req := range invalidReqs {
+		_, err := client.UploadCover(ctx, req)
+		assert.Error(t, err)
+	}
+
+	_, err = client.GetCover(ctx, &api.GetCoverReq{BookID: book.ID, Size: "huge"})
+	assert.Error(t, err)
+
+	// 5. Covers are deleted with their book.
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{book.ID}})
+	assert.NoError(t, err)
+
+	_, err = client.GetCover(ctx, &api.GetCoverReq{BookID: book.ID})
+	assert.Error(t, err)
+}
+
+func (s *storage) testFiles(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	// 1. Create a book from an EPUB.
+	epub := epubtest.New(t, `<package version="3.0" xmlns="http://www.idpf.org/2007/opf">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier>urn:isbn:9780140449136</dc:identifier>
+    <dc:title>Crime and Punishment</dc:title>
+    <dc:creator>Fyodor Dostoevsky</dc:creator>
+    <dc:date>1866</dc:date>
+    <dc:description>A novel</dc:description>
+    <dc:subject>Psychological fiction</dc:subject>
+    <dc:subject>Classics</dc:subject>
+  </metadata>
+</package>`)
+
+	importResp, err := client.ImportEPUB(ctx, &api.ImportEPUBReq{Name: "crime.epub", Data: epub})
+	assert.NoError(t, err)
+
+	bookResp := getBook(ctx, t, client, &api.GetBookReq{ID: importResp.ID})
+	assert.Equal(t, "Crime and Punishment", bookResp.Book.Title)
+	assert.Equal(t, "Fyodor Dostoevsky", bookResp.Book.Author)
+	assert.Equal(t, "Unsorted", bookResp.Book.Genre)
+	assert.Equal(t, "9780140449136", bookResp.Book.ISBN13)
+	assert.Equal(t, 1866, bookResp.Book.PublishedDate.Year())
+	assert.ElementsMatch(t, []string{"psychological fiction", "classics"}, bookResp.Book.Tags)
+
+	// 2. Duplicates are reported and not created again.
+	_, err = client.ImportEPUB(ctx, &api.ImportEPUBReq{Name: "crime.epub", Data: epub})
+	assert.Error(t, err)
+
+	// 3. Attach, download and delete files.
+	pdf := []byte("%PDF-1.4\n%%EOF\n")
+	addResp, err := client.AddBookFile(ctx, &api.AddBookFileReq{BookID: importResp.ID, Name: "crime.pdf", Data: pdf})
+	assert.NoError(t, err)
+
+	filesResp, err := client.GetBookFiles(ctx, &api.GetBookFilesReq{BookID: importResp.ID})
+	assert.NoError(t, err)
+	assert.Len(t, filesResp.Files, 2)
+	assert.Equal(t, importResp.FileID, filesResp.Files[0].ID)
+	assert.Equal(t, "crime.epub", filesResp.Files[0].Name)
+	assert.Equal(t, "epub", filesResp.Files[0].Format)
+	assert.Equal(t, int64(len(epub)), filesResp.Files[0].Size)
+	assert.Equal(t, addResp.URL, filesResp.Files[1].URL)
+	assert.Equal(t, "pdf", filesResp.Files[1].Format)
+
+	fileResp, err := client.GetBookFile(ctx, &api.GetBookFileReq{BookID: importResp.ID, ID: importResp.FileID})
+	assert.NoError(t, err)
+	assert.Equal(t, "application/epub+zip", fileResp.ContentType)
+	assert.Equal(t, epub, fileResp.Data)
+
+	_, err = client.DeleteBookFile(ctx, &api.DeleteBookFileReq{BookID: importResp.ID, ID: addResp.ID})
+	assert.NoError(t, err)
+
+	_, err = client.GetBookFile(ctx, &api.GetBookFileReq{BookID: importResp.ID, ID: addResp.ID})
+	assert.Error(t, err)
+
+	// 4. Validation.
+	invalidReqs := []*api.AddBookFileReq{
+		{BookID: importResp.ID, Name: "notes.txt", Data: []byte("plain text")},
+		{BookID: importResp.ID, Name: "empty.pdf"},
+		{BookID: importResp.ID, Data: pdf},
+		{BookID: 1000000, Name: "crime.pdf", Data: pdf},
+	}
+	for _, req := range invalidReqs {
+		_, err := client.AddBookFile(ctx, req)
+		assert.Error(t, err)
+	}
+
+	_, err = client.ImportEPUB(ctx, &api.ImportEPUBReq{Data: pdf})
+	assert.Error(t, err)
+
+	// 5. Files are deleted with their book.
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{importResp.ID}})
+	assert.NoError(t, err)
+
+	_, err = client.GetBookFile(ctx, &api.GetBookFileReq{BookID: importResp.ID, ID: importResp.FileID})
+	assert.Error(t, err)
+}
+
+func (s *storage) testImportBooks(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	existing := s.books[0]
+	export := "Book Id,Title,Author,ISBN,ISBN13,Year Published,Original Publication Year,Bookshelves,Exclusive Shelf\n" +
+		`1,Hyperion,Dan Simmons,="",="9780553283686",1990,1989,"sci-fi, favorites",read` + "\n" +
+		`2,"` + existing.Title + `","` + existing.Author + `",="",="",,,favorites,read` + "\n" +
+		`3,Hyperion,Dan Simmons,="",="",,,,to-read` + "\n" +
+		`4,,Nobody,="",="",,,,to-read` + "\n"
+
+	wantBooks := []api.ImportedBook{
+		{Line: 2, Title: "Hyperion", Author: "Dan Simmons", Year: 1990, ISBN: "9780553283686", Shelves: []string{"sci-fi", "favorites", "read"}, Action: "create"},
+		{Line: 3, Title: existing.Title, Author: existing.Author, Shelves: []string{"favorites", "read"}, Action: "exists", BookID: existing.ID},
+		{Line: 4, Title: "Hyperion", Author: "Dan Simmons", Shelves: []string{"to-read"}, Action: "duplicate"},
+		{Line: 5, Author: "Nobody", Shelves: []string{"to-read"}, Action: "invalid", Error: "title is empty"},
+	}
+
+	// 1. A dry run reports changes without making them.
+	resp, err := client.ImportBooks(ctx, &api.ImportBooksReq{DryRun: true, Data: []byte(export)})
+	assert.NoError(t, err)
+	assert.Equal(t, &api.ImportBooksResp{
+		Layout:         "goodreads",
+		DryRun:         true,
+		Books:          wantBooks,
+		NewCollections: []string{"sci-fi", "favorites", "read"},
+	}, resp)
+
+	got := getBooks(ctx, t, client, &api.GetBooksReq{ISBN: "9780553283686"})
+	assert.Empty(t, got.Books)
+
+	// 2. Import books and create collections for shelves.
+	resp, err = client.ImportBooks(ctx, &api.ImportBooksReq{Genre: "Science Fiction", Data: []byte(export)})
+	assert.NoError(t, err)
+	assert.False(t, resp.DryRun)
+	assert.Equal(t, []string{"sci-fi", "favorites", "read"}, resp.NewCollections)
+	assert.Len(t, resp.Books, len(wantBooks))
+
+	bookID := resp.Books[0].BookID
+	assert.NotZero(t, bookID)
+	wantBooks[0].BookID = bookID
+	assert.Equal(t, wantBooks, resp.Books)
+
+	book := getBook(ctx, t, client, &api.GetBookReq{ID: bookID})
+	assert.Equal(t, "Hyperion", book.Book.Title)
+	assert.Equal(t, "Dan Simmons", book.Book.Author)
+	assert.Equal(t, "Science Fiction", book.Book.Genre)
+	assert.Equal(t, "9780553283686", book.Book.ISBN13)
+	assert.Equal(t, 1990, book.Book.PublishedDate.Year())
+
+	collections := getCollections(ctx, t, client, &api.GetCollectionsReq{PageSize: 50})
+	var collectionIDs []int64
+	for _, c := range collections.Collections {
+		switch c.Name {
+		case "sci-fi", "favorites", "read":
+			collectionIDs = append(collectionIDs, c.ID)
+
+			got := getBooks(ctx, t, client, &api.GetBooksReq{CollectionID: c.ID})
+			assert.Len(t, got.Books, 1)
+			assert.Equal(t, bookID, got.Books[0].ID)
+		}
+	}
+
+	assert.Len(t, collectionIDs, 3)
+
+	// 3. Imported books aren't imported again.
+	resp, err = client.ImportBooks(ctx, &api.ImportBooksReq{Data: []byte(export)})
+	assert.NoError(t, err)
+	assert.Equal(t, "exists", resp.Books[0].Action)
+	assert.Equal(t, bookID, resp.Books[0].BookID)
+	assert.Empty(t, resp.NewCollections)
+
+	// Existing books are matched like rows of the export, regardless of case and spaces.
+	resp, err = client.ImportBooks(ctx, &api.ImportBooksReq{DryRun: true, Data: []byte(
+		"Book Id,Title,Author,ISBN,ISBN13,Year Published,Original Publication Year,Bookshelves,Exclusive Shelf\n" +
+			`1,hyperion ,DAN  SIMMONS,="",="",,,,read` + "\n",
+	)})
+	assert.NoError(t, err)
+	assert.Equal(t, "exists", resp.Books[0].Action)
+	assert.Equal(t, bookID, resp.Books[0].BookID)
+
+	// 4. Validation.
+	invalidExports := []string{
+		"",
+		"Title,Author\nHyperion,Dan Simmons\n",
+	}
+	for _, export := range invalidExports {
+		_, err := client.ImportBooks(ctx, &api.ImportBooksReq{Data: []byte(export)})
+		assert.Error(t, err)
+	}
+
+	_, err = client.ImportBooks(ctx, &api.ImportBooksReq{Genre: "Robot fables", DryRun: true, Data: []byte(export)})
+	assert.Error(t, err)
+
+	// 5. Cleanup.
+	for _, id := range collectionIDs {
+		_, err = client.DeleteCollection(ctx, &api.DeleteCollectionReq{ID: id})
+		assert.NoError(t, err)
+	}
+
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{bookID}})
+	assert.NoError(t, err)
+}
+
+func (s *storage) testMARC(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	existing := s.books[0]
+	records := `<?xml version="1.0" encoding="UTF-8"?>
+<collection xmlns="http://www.loc.gov/MARC21/slim">
+  <record>
+    <leader>00000nam a2200000 i 4500</leader>
+    <controlfield tag="001">ocm0001</controlfield>
+    <datafield tag="020" ind1=" " ind2=" "><subfield code="a">9780441478125</subfield></datafield>
+    <datafield tag="100" ind1="1" ind2=" "><subfield code="a">Le Guin, Ursula K.,</subfield><subfield code="e">author.</subfield></datafield>
+    <datafield tag="245" ind1="1" ind2="4"><subfield code="a">The left hand of darkness /</subfield><subfield code="c">Ursula K. Le Guin.</subfield></datafield>
+    <datafield tag="264" ind1=" " ind2="1"><subfield code="c">1969.</subfield></datafield>
+    <datafield tag="650" ind1=" " ind2="0"><subfield code="a">Planetary romance.</subfield></datafield>
+    <datafield tag="653" ind1=" " ind2=" "><subfield code="a">Gender</subfield></datafield>
+    <datafield tag="700" ind1="1" ind2=" "><subfield code="a">Doe, Jane,</subfield><subfield code="e">illustrator.</subfield></datafield>
+  </record>
+  <record>
+    <leader>00000nam a2200000 i 4500</leader>
+    <datafield tag="100" ind1="0" ind2=" "><subfield code="a">` + existing.Author + `</subfield></datafield>
+    <datafield tag="245" ind1="1" ind2="0"><subfield code="a">` + existing.Title + `</subfield></datafield>
+  </record>
+  <record>
+    <leader>00000nam a2200000 i 4500</leader>
+    <datafield tag="245" ind1="0" ind2="0"><subfield code="a">Anonymous</subfield></datafield>
+  </record>
+</collection>`
+
+	wantBooks := []api.ImportedBook{
+		{Line: 1, Title: "The left hand of darkness", Author: "Ursula K. Le Guin", Year: 1969, ISBN: "9780441478125", Unmapped: []string{"001", "245$c"}, Action: "create"},
+		{Line: 2, Title: existing.Title, Author: existing.Author, Action: "exists", BookID: existing.ID},
+		{Line: 3, Title: "Anonymous", Action: "invalid", Error: "author is empty"},
+	}
+
+	// 1. A dry run reports changes without making them.
+	resp, err := client.ImportMARC(ctx, &api.ImportMARCReq{DryRun: true, Data: []byte(records)})
+	assert.NoError(t, err)
+	assert.Equal(t, &api.ImportBooksResp{
+		Layout:         "marcxml",
+		DryRun:         true,
+		Books:          wantBooks,
+		NewCollections: []string{},
+	}, resp)
+
+	got := getBooks(ctx, t, client, &api.GetBooksReq{ISBN: "9780441478125"})
+	assert.Empty(t, got.Books)
+
+	// 2. Import books.
+	resp, err = client.ImportMARC(ctx, &api.ImportMARCReq{Data: []byte(records)})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Books, len(wantBooks))
+
+	bookID := resp.Books[0].BookID
+	assert.NotZero(t, bookID)
+	wantBooks[0].BookID = bookID
+	assert.Equal(t, wantBooks, resp.Books)
+
+	book := getBook(ctx, t, client, &api.GetBookReq{ID: bookID})
+	assert.Equal(t, "The left hand of darkness", book.Book.Title)
+	assert.Equal(t, "Ursula K. Le Guin", book.Book.Author)
+	assert.Equal(t, "Unsorted", book.Book.Genre)
+	assert.ElementsMatch(t, []string{"gender", "planetary romance"}, book.Book.Tags)
+	assert.Equal(t, 1969, book.Book.PublishedDate.Year())
+	assert.Equal(t, []api.Contributor{
+		{AuthorID: book.Book.Contributors[0].AuthorID, Name: "Ursula K. Le Guin", Role: "author"},
+		{AuthorID: book.Book.Contributors[1].AuthorID, Name: "Jane Doe", Role: "illustrator"},
+	}, book.Book.Contributors)
+
+	// 3. Exported books are found by a new import.
+	for _, format := range []string{"marc21", "marcxml"} {
+		export, err := client.ExportBook(ctx, &api.ExportBookReq{BookID: bookID, Format: format})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, export.Data)
+
+		resp, err = client.ImportMARC(ctx, &api.ImportMARCReq{DryRun: true, Data: export.Data})
+		assert.NoError(t, err)
+		assert.Equal(t, format, resp.Layout)
+		assert.Equal(t, []api.ImportedBook{
+			{Line: 1, Title: "The left hand of darkness", Author: "Ursula K. Le Guin", Year: 1969, ISBN: "9780441478125", Unmapped: []string{"001"}, Action: "exists", BookID: bookID},
+		}, resp.Books)
+	}
+
+	collection, err := client.CreateCollection(ctx, &api.CreateCollectionReq{Name: "MARC"})
+	assert.NoError(t, err)
+
+	_, err = client.CreateBooksCollection(ctx, &api.CreateBooksCollectionReq{CID: collection.ID, BookIDs: []int64{existing.ID, bookID}})
+	assert.NoError(t, err)
+
+	export, err := client.ExportCollection(ctx, &api.ExportCollectionReq{CollectionID: collection.ID, Format: "marc21"})
+	assert.NoError(t, err)
+	assert.Equal(t, "application/marc", export.ContentType)
+	assert.Equal(t, "collection-"+strconv.FormatInt(collection.ID, 10)+".mrc", export.FileName)
+
+	resp, err = client.ImportMARC(ctx, &api.ImportMARCReq{DryRun: true, Data: export.Data})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Books, 2)
+	for _, b := range resp.Books {
+		assert.Equal(t, "exists", b.Action)
+	}
+
+	// 4. Validation.
+	invalidRecords := []string{
+		"",
+		"Title,Author\nHyperion,Dan Simmons\n",
+		`<collection xmlns="http://www.loc.gov/MARC21/slim"/>`,
+	}
+	for _, records := range invalidRecords {
+		_, err := client.ImportMARC(ctx, &api.ImportMARCReq{Data: []byte(records)})
+		assert.Error(t, err)
+	}
+
+	_, err = client.ExportBook(ctx, &api.ExportBookReq{BookID: bookID, Format: "pdf"})
+	assert.Error(t, err)
+
+	_, err = client.ExportBook(ctx, &api.ExportBookReq{BookID: bookID + 1000, Format: "marc21"})
+	assert.Error(t, err)
+
+	_, err = client.ExportCollection(ctx, &api.ExportCollectionReq{CollectionID: collection.ID + 1000, Format: "marcxml"})
+	assert.Error(t, err)
+
+	// 5. Cleanup.
+	_, err = client.DeleteCollection(ctx, &api.DeleteCollectionReq{ID: collection.ID})
+	assert.NoError(t, err)
+
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{bookID}})
+	assert.NoError(t, err)
+}
+
+func (s *storage) testCitations(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	createResp, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:         "Fear & Loathing: 100% true",
+		Author:        "Hunter S. Thompson",
+		PublishedDate: time.Date(1971, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Genre:         "Gonzo",
+		Tags:          []string{"road trip"},
+	})
+	assert.NoError(t, err)
+
+	bookID := createResp.ID
+
+	// 1. Cite a book, special characters are escaped for LaTeX.
+	export, err := client.ExportBook(ctx, &api.ExportBookReq{BookID: bookID, Format: "bibtex"})
+	assert.NoError(t, err)
+	assert.Equal(t, "application/x-bibtex; charset=utf-8", export.ContentType)
+	assert.Equal(t, "book-"+strconv.FormatInt(bookID, 10)+".bib", export.FileName)
+	assert.Equal(t, `@book{thompson1971fear,
+  author = {Thompson, Hunter S.},
+  title = {Fear \& Loathing: 100\% true},
+  year = {1971},
+  keywords = {road trip},
+}
+`, string(export.Data))
+
+	export, err = client.ExportBook(ctx, &api.ExportBookReq{BookID: bookID, Format: "ris"})
+	assert.NoError(t, err)
+	assert.Equal(t, "TY  - BOOK\r\n"+
+		"ID  - thompson1971fear\r\n"+
+		"AU  - Thompson, Hunter S.\r\n"+
+		"TI  - Fear & Loathing: 100% true\r\n"+
+		"PY  - 1971\r\n"+
+		"KW  - road trip\r\n"+
+		"ER  - \r\n", string(export.Data))
+
+	// 2. Cite a collection, keys are the same as for single books.
+	collection, err := client.CreateCollection(ctx, &api.CreateCollectionReq{Name: "Citations"})
+	assert.NoError(t, err)
+
+	_, err = client.CreateBooksCollection(ctx, &api.CreateBooksCollectionReq{CID: collection.ID, BookIDs: []int64{s.books[3].ID, bookID}})
+	assert.NoError(t, err)
+
+	export, err = client.ExportCollection(ctx, &api.ExportCollectionReq{CollectionID: collection.ID, Format: "csljson"})
+	assert.NoError(t, err)
+	assert.Equal(t, "application/vnd.citationstyles.csl+json", export.ContentType)
+
+	var items []struct {
+		ID     string `json:"id"`
+		Type   string `json:"type"`
+		Title  string `json:"title"`
+		Author []struct {
+			Family string `json:"family"`
+			Given  string `json:"given"`
+		} `json:"author"`
+	}
+	assert.NoError(t, json.Unmarshal(export.Data, &items))
+	assert.Len(t, items, 2)
+	for _, item := range items {
+		assert.Equal(t, "book", item.Type)
+		assert.Len(t, item.Author, 1)
+	}
+
+	assert.Equal(t, "celine1932journey", items[0].ID)
+	assert.Equal(t, "Céline", items[0].Author[0].Family)
+	assert.Equal(t, "thompson1971fear", items[1].ID)
+	assert.Equal(t, "Fear & Loathing: 100% true", items[1].Title)
+
+	// 3. Validation.
+	_, err = client.ExportCollection(ctx, &api.ExportCollectionReq{CollectionID: collection.ID, Format: "endnote"})
+	assert.Error(t, err)
+
+	// 4. Cleanup.
+	_, err = client.DeleteCollection(ctx, &api.DeleteCollectionReq{ID: collection.ID})
+	assert.NoError(t, err)
+
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{bookID}})
+	assert.NoError(t, err)
+}
+
+func (s *storage) testOPDS(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	type feed struct {
+		ID    string `xml:"id"`
+		Links []struct {
+			Rel  string `xml:"rel,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+		Entries []struct {
+			ID    string `xml:"id"`
+			Title string `xml:"title"`
+		} `xml:"entry"`
+	}
+
+	getFeed := func(req *api.GetOPDSFeedReq, contentType string) *feed {
+		resp, err := client.GetOPDSFeed(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, contentType, resp.ContentType)
+
+		f := new(feed)
+		assert.NoError(t, xml.Unmarshal(resp.Data, f))
+
+		return f
+	}
+
+	entryIDs := func(f *feed) []string {
+		ids := make([]string, 0, len(f.Entries))
+		for _, e := range f.Entries {
+			ids = append(ids, e.ID)
+		}
+
+		return ids
+	}
+
+	bookEntryID := func(id int64) string {
+		return "urn:bm:book:" + strconv.FormatInt(id, 10)
+	}
+
+	collection, err := client.CreateCollection(ctx, &api.CreateCollectionReq{Name: "OPDS"})
+	assert.NoError(t, err)
+
+	_, err = client.CreateBooksCollection(ctx, &api.CreateBooksCollectionReq{CID: collection.ID, BookIDs: []int64{s.books[0].ID, s.books[1].ID}})
+	assert.NoError(t, err)
+
+	// 1. The root feed links recent additions and collections.
+	root := getFeed(&api.GetOPDSFeedReq{}, "application/atom+xml;profile=opds-catalog;kind=navigation")
+	assert.Equal(t, "urn:bm:opds", root.ID)
+	assert.Contains(t, entryIDs(root), "urn:bm:opds:recent")
+	assert.Contains(t, entryIDs(root), "urn:bm:collection:"+strconv.FormatInt(collection.ID, 10))
+
+	// Collections are listed by pages, recent additions are only on the first one.
+	lastRoot := getFeed(&api.GetOPDSFeedReq{Page: 100}, "application/atom+xml;profile=opds-catalog;kind=navigation")
+	assert.Empty(t, lastRoot.Entries)
+
+	// 2. Acquisition feeds list books.
+	acquisition := "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	books := getFeed(&api.GetOPDSFeedReq{CollectionID: collection.ID}, acquisition)
+	assert.ElementsMatch(t, []string{bookEntryID(s.books[0].ID), bookEntryID(s.books[1].ID)}, entryIDs(books))
+
+	recent := getFeed(&api.GetOPDSFeedReq{Recent: true}, acquisition)
+	assert.NotEmpty(t, recent.Entries)
+	assert.Contains(t, entryIDs(recent), bookEntryID(s.books[len(s.books)-1].ID))
+
+	found := getFeed(&api.GetOPDSFeedReq{Query: "bulgakov"}, acquisition)
+	assert.Contains(t, entryIDs(found), bookEntryID(s.books[0].ID))
+	assert.NotContains(t, entryIDs(found), bookEntryID(s.books[1].ID))
+
+	// Wildcards of the query are matched literally.
+	wildcard := getFeed(&api.GetOPDSFeedReq{Query: "%"}, acquisition)
+	assert.Empty(t, wildcard.Entries)
+
+	empty := getFeed(&api.GetOPDSFeedReq{Query: "bulgakov", Page: 100}, acquisition)
+	assert.Empty(t, empty.Entries)
+	for _, l := range empty.Links {
+		assert.NotEqual(t, "next", l.Rel)
+	}
+
+	// 3. Clients find the search by the OpenSearch description.
+	search, err := client.GetOpenSearch(ctx, &api.GetOpenSearchReq{})
+	assert.NoError(t, err)
+	assert.Equal(t, "application/opensearchdescription+xml", search.ContentType)
+	assert.Contains(t, string(search.Data), "/api/v1/opds/search?q={searchTerms}")
+
+	// 4. Validation.
+	_, err = client.GetOPDSFeed(ctx, &api.GetOPDSFeedReq{CollectionID: collection.ID + 1000})
+	assert.Error(t, err)
+
+	_, err = client.GetOPDSFeed(ctx, &api.GetOPDSFeedReq{Recent: true, Page: -1})
+	assert.Error(t, err)
+
+	// 5. Cleanup.
+	_, err = client.DeleteCollection(ctx, &api.DeleteCollectionReq{ID: collection.ID})
+	assert.NoError(t, err)
+}
+
+func (s *storage) testGraphQL(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	type collection struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Books []struct {
+			ID          string `json:"id"`
+			Title       string `json:"title"`
+			Collections []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"collections"`
+		} `json:"books"`
+	}
+
+	do := func(query string, variables map[string]any, data any) []api.GraphQLError {
+		resp, err := client.GraphQL(ctx, &api.GraphQLReq{Query: query, Variables: variables})
+		assert.NoError(t, err)
+
+		if data != nil && len(resp.Errors) == 0 {
+			assert.NoError(t, json.Unmarshal(resp.Data, data))
+		}
+
+		return resp.Errors
+	}
+
+	bookID := func(b *api.Book) string {
+		return strconv.FormatInt(b.ID, 10)
+	}
+
+	// 1. Mutations create collections and fill them.
+	var created struct {
+		CreateCollection collection `json:"createCollection"`
+	}
+	errs := do(`mutation($name: String!) { createCollection(input: {name: $name, description: "GraphQL"}) { id name } }`, map[string]any{"name": "GraphQL classics"}, &created)
+	assert.Empty(t, errs)
+	assert.Equal(t, "GraphQL classics", created.CreateCollection.Name)
+	classicsID := created.CreateCollection.ID
+
+	errs = do(`mutation { createCollection(input: {name: "GraphQL favourites"}) { id } }`, nil, &created)
+	assert.Empty(t, errs)
+	favouritesID := created.CreateCollection.ID
+
+	var added struct {
+		AddBooksToCollection collection `json:"addBooksToCollection"`
+	}
+	errs = do(`mutation($id: ID!, $books: [ID!]!) { addBooksToCollection(collectionId: $id, bookIds: $books) { id books { id } } }`,
+		map[string]any{"id": classicsID, "books": []string{bookID(s.books[0]), bookID(s.books[1])}}, &added)
+	assert.Empty(t, errs)
+	assert.Len(t, added.AddBooksToCollection.Books, 2)
+
+	errs = do(`mutation($id: ID!, $books: [ID!]!) { addBooksToCollection(collectionId: $id, bookIds: $books) { id } }`,
+		map[string]any{"id": favouritesID, "books": []string{bookID(s.books[0])}}, nil)
+	assert.Empty(t, errs)
+
+	// 2. A collection, its books and their collections are fetched with one request.
+	var got struct {
+		Collection collection `json:"collection"`
+	}
+	errs = do(`query($id: ID!) { collection(id: $id) { id name books { id title collections { id name } } } }`, map[string]any{"id": classicsID}, &got)
+	assert.Empty(t, errs)
+	assert.Equal(t, "GraphQL classics", got.Collection.Name)
+	assert.Len(t, got.Collection.Books, 2)
+	for _, b := range got.Collection.Books {
+		var collectionIDs []string
+		for _, c := range b.Collections {
+			collectionIDs = append(collectionIDs, c.ID)
+		}
+
+		switch b.ID {
+		case bookID(s.books[0]):
+			assert.Equal(t, s.books[0].Title, b.Title)
+			assert.ElementsMatch(t, []string{classicsID, favouritesID}, collectionIDs)
+
+		case bookID(s.books[1]):
+			assert.Equal(t, []string{classicsID}, collectionIDs)
+
+		default:
+			t.Errorf("unexpected book %s", b.ID)
+		}
+	}
+
+	var missing struct {
+		Book *struct{ ID string } `json:"book"`
+	}
+	errs = do(`{ book(id: 1000000) { id } }`, nil, &missing)
+	assert.Empty(t, errs)
+	assert.Nil(t, missing.Book)
+
+	// 3. Errors of the book service have codes.
+	errs = do(`mutation { updateCollection(id: 1000000, input: {name: "Missing"}) { id } }`, nil, nil)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "NOT_FOUND", errs[0].Extensions["code"])
+
+	errs = do(`mutation { createCollection(input: {name: "GraphQL classics"}) { id } }`, nil, nil)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "CONFLICT", errs[0].Extensions["code"])
+
+	// 4. Deep and complex queries are rejected, limits are in configs/test_server_config.json.
+	errs = do(`{ collections { books { collections { books { collections { books { collections { id } } } } } } } }`, nil, nil)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, "query depth")
+
+	errs = do(`{ books(pageSize: 50) { collections { books { id } } } }`, nil, nil)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, "query complexity")
+
+	errs = do(`{ collection(id: 1) { unknown } }`, nil, nil)
+	assert.Len(t, errs, 1)
+
+	// 5. Cleanup.
+	var deleted struct {
+		DeleteCollection bool `json:"deleteCollection"`
+	}
+	for _, id := range []string{classicsID, favouritesID} {
+		errs = do(`mutation($id: ID!) { deleteCollection(id: $id) }`, map[string]any{"id": id}, &deleted)
+		assert.Empty(t, errs)
+		assert.True(t, deleted.DeleteCollection)
+	}
+}
+
+func encodeImage(t *testing.T, img image.Image, encode func(io.Writer, image.Image) error) []byte {
+	buf := new(bytes.Buffer)
+	assert.NoError(t, encode(buf, img))
+
+	return buf.Bytes()
+}
+
+func (s *storage) testCollections(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	// 1. Create collections.
+	s.collections = []*api.Collection{
+		{
+			Name:        "Classic Novels",
+			Description: "A collection of classic novels from various authors.",
+		},
+		{
+			Name:        "Russian Literature",
+			Description: "A collection of novels and literature from Russian authors.",
+		},
+		{
+			Name:        "Contemporary Fiction",
+			Description: "A collection of modern and contemporary fiction books.",
+		},
+	}
+	for _, c := range s.collections {
+		req := &api.CreateCollectionReq{
+			Name:        c.Name,
+			Description: c.Description,
+		}
+		resp, err := client.CreateCollection(ctx, req)
+		assert.NoError(t, err)
+		c.ID = resp.ID
+	}
+
+	// 2. Read testing.
+	getByIDTests := []struct {
+		give     *api.GetCollectionReq
+		wantBook *api.Collection
+	}{
+		{
+			give: &api.GetCollectionReq{
+				ID: s.books[0].ID,
+			},
+			wantBook: s.collections[0],
+		},
+	}
+	for _, tt := range getByIDTests {
+		got := getCollection(ctx, t, client, tt.give)
+		assert.Equal(t, tt.wantBook.ID, got.Collection.ID)
+		assert.Equal(t, tt.wantBook.Name, got.Collection.Name)
+	}
+
+	filterTests := []struct {
+		give            *api.GetCollectionsReq
+		wantCollections []api.Collection
+	}{
+		{
+			give: &api.GetCollectionsReq{
+				OrderBy:  "name",
+				Desc:     true,
+				Page:     2,
+				PageSize: 1,
+			},
+			wantCollections: []api.Collection{*s.collections[1]},
+		},
+	}
+	for _, tt := range filterTests {
+		got := getCollections(ctx, t, client, tt.give)
+		assert.Equal(t, len(tt.wantCollections), len(got.Collections))
+		for i := 0; i < len(tt.wantCollections); i++ {
+			assert.Equal(t, tt.wantCollections[i].ID, got.Collections[i].ID)
+			assert.Equal(t, tt.wantCollections[i].Name, got.Collections[i].Name)
+		}
+	}
+
+	// 3. Update testing.
+	collection := s.collections[2]
+	updateReq := api.UpdateCollectionReq{
+		ID:          collection.ID,
+		Name:        collection.Name,
+		Description: collection.Description,
+	}
+	updateTests := []struct {
+		modify func(*api.UpdateCollectionReq)
+	}{
+		{
+			modify: func(req *api.UpdateCollectionReq) {
+				req.Name = uuid.NewString()
+			},
+		},
+		{
+			modify: func(req *api.UpdateCollectionReq) {
+				req.Description = uuid.NewString()
+			},
+		},
+	}
+	for _, tt := range updateTests {
+		req := updateReq
+
+		tt.modify(&req)
+
+		resp, err := client.UpdateCollection(ctx, &req)
+		assert.NoError(t, err)
+		assert.True(t, resp)
+
+		want := req
+		got := getCollection(ctx, t, client, &api.GetCollectionReq{ID: collection.ID})
+		gotCollection := got.Collection
+		assert.Equal(t, want.Name, gotCollection.Name)
+		assert.Equal(t, want.Description, gotCollection.Description)
+	}
+
+	// 4. Delete testing.
+	deleteResp, err := client.DeleteCollection(ctx, &api.DeleteCollectionReq{ID: s.collections[2].ID})
+	assert.NoError(t, err)
+	assert.True(t, deleteResp)
+
+	s.collections = s.collections[:2]
+}
+
+func (s *storage) testCreateCollectionValidation(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	tests := []struct {
+		req *api.CreateCollectionReq
+	}{
+		{
+			req: &api.CreateCollectionReq{},
+		},
+	}
+	for _, tt := range tests {
+		_, err := client.CreateCollection(ctx, tt.req)
+		assert.Error(t, err)
+	}
+}
+
+func (s *storage) testGetCollectionValidation(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	tests := []struct {
+		req *api.GetCollectionsReq
+	}{
+		{
+			req: &api.GetCollectionsReq{
+				OrderBy: "fake_field",
+			},
+		},
+		{
+			req: &api.GetCollectionsReq{
+				OrderBy: "fake_field",
+			},
+		},
+		{
+			req: &api.GetCollectionsReq{
+				PageSize: -1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		_, err := client.GetCollections(ctx, tt.req)
+		assert.Error(t, err)
+	}
+}
+
+func (s *storage) testUpdateCollectionValidation(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	tests := []struct {
+		req *api.UpdateCollectionReq
+	}{
+		{
+			req: &api.UpdateCollectionReq{},
+		},
+		{
+			req: &api.UpdateCollectionReq{
+				ID: -1,
+			},
+		},
+		{
+			req: &api.UpdateCollectionReq{
+				ID: 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		_, err := client.UpdateCollection(ctx, tt.req)
+		assert.Error(t, err)
+	}
+}
+
+func (s *storage) testDeleteCollectionValidation(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	tests := []struct {
+		req *api.DeleteCollectionReq
+	}{
+		{
+			req: &api.DeleteCollectionReq{},
+		},
+		{
+			req: &api.DeleteCollectionReq{
+				ID: -1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		_, err := client.DeleteCollection(ctx, tt.req)
+		assert.Error(t, err)
+	}
+}
+
+func (s *storage) testBooksCollection(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	// 1. Create books collection.
+	collectionID := s.collections[0].ID
+	createCollectionResp, err := client.CreateBooksCollection(ctx, &api.CreateBooksCollectionReq{
+		CID:     s.collections[0].ID,
+		BookIDs: []int64{s.books[0].ID, s.books[1].ID, s.books[2].ID, s.books[3].ID},
+	})
+	assert.NoError(t, err)
+	assert.True(t, createCollectionResp)
+
+	got, err := client.GetBooks(ctx, &api.GetBooksReq{CollectionID: collectionID})
+	assert.NoError(t, err)
+	assert.Len(t, got.Books, 4)
+	for i, b := range s.books[:4] {
+		assert.Equal(t, b.ID, got.Books[i].ID)
+		assert.Equal(t, b.Title, got.Books[i].Title)
+	}
+
+	// 2. Remove books collection.
+	deleteCollectionResp, err := client.DeleteBooksCollection(ctx, &api.DeleteBooksCollectionReq{
+		CID:     s.collections[0].ID,
+		BookIDs: []int64{s.books[2].ID, s.books[3].ID},
+	})
+	assert.NoError(t, err)
+	assert.True(t, deleteCollectionResp)
+
+	got, err = client.GetBooks(ctx, &api.GetBooksReq{CollectionID: collectionID})
+	assert.NoError(t, err)
+	assert.Len(t, got.Books, 2)
+	for i, b := range s.books[:2] {
+		assert.Equal(t, b.ID, got.Books[i].ID)
+		assert.Equal(t, b.Title, got.Books[i].Title)
+	}
+}
+
+func (s *storage) testCreateBooksCollectionValidation(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	tests := []struct {
+		req *api.CreateBooksCollectionReq
+	}{
+		{
+			req: &api.CreateBooksCollectionReq{},
+		},
+		{
+			req: &api.CreateBooksCollectionReq{
+				CID: -1,
+			},
+		},
+		{
+			req: &api.CreateBooksCollectionReq{
+				CID: 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		_, err := client.CreateBooksCollection(ctx, tt.req)
 		assert.Error(t, err)
 	}
 }
@@ -578,6 +2007,74 @@ func (s *storage) testDeleteBooksCollectionValidation(ctx context.Context, t *te
 	}
 }
 
+func (s *storage) testTenants(ctx context.Context, t *testing.T, client *httpclient.Client) {
+	admin := client.WithAPIKey(adminAPIKey)
+	reader := client.WithAPIKey(readerAPIKey)
+
+	// 1. Uniqueness is checked per tenant.
+	b := s.books[0]
+	createBookResp, err := reader.CreateBook(ctx, &api.CreateBookReq{
+		Title:         b.Title,
+		Author:        b.Author,
+		PublishedDate: b.PublishedDate,
+		Edition:       b.Edition,
+		Description:   b.Description,
+		Genre:         b.Genre,
+	})
+	assert.NoError(t, err)
+
+	_, err = reader.CreateCollection(ctx, &api.CreateCollectionReq{Name: s.collections[0].Name})
+	assert.NoError(t, err)
+
+	// 2. Tenants can't see each other's data.
+	_, err = reader.GetBook(ctx, &api.GetBookReq{ID: b.ID})
+	assert.Error(t, err)
+
+	got := getBooks(ctx, t, reader, &api.GetBooksReq{})
+	assert.Len(t, got.Books, 1)
+	assert.Equal(t, createBookResp.ID, got.Books[0].ID)
+
+	_, err = reader.CreateBooksCollection(ctx, &api.CreateBooksCollectionReq{
+		CID:     s.collections[0].ID,
+		BookIDs: []int64{createBookResp.ID},
+	})
+	assert.Error(t, err)
+
+	_, err = client.WithAPIKey(uuid.NewString()).GetBooks(ctx, &api.GetBooksReq{})
+	assert.Error(t, err)
+
+	// Callers without a key read the default tenant, but can't change it.
+	anonymous := client.WithAPIKey("")
+	_, err = anonymous.GetBook(ctx, &api.GetBookReq{ID: b.ID})
+	assert.NoError(t, err)
+
+	_, err = anonymous.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{b.ID}})
+	assert.Error(t, err)
+
+	_, err = anonymous.GraphQL(ctx, &api.GraphQLReq{Query: `mutation { deleteBooks(ids: ["` + strconv.FormatInt(b.ID, 10) + `"]) }`})
+	assert.Error(t, err)
+
+	// 3. Only admins move resources between tenants.
+	_, err = reader.MoveBooks(ctx, &api.MoveBooksReq{IDs: []int64{createBookResp.ID}, Tenant: "default"})
+	assert.Error(t, err)
+
+	_, err = admin.MoveBooks(ctx, &api.MoveBooksReq{IDs: []int64{createBookResp.ID}, Tenant: "default"})
+	assert.Error(t, err)
+
+	moved := s.books[3]
+	moveResp, err := admin.MoveBooks(ctx, &api.MoveBooksReq{IDs: []int64{moved.ID}, Tenant: "reader"})
+	assert.NoError(t, err)
+	assert.True(t, moveResp)
+
+	gotBook := getBook(ctx, t, reader, &api.GetBookReq{ID: moved.ID})
+	assert.Equal(t, moved.Title, gotBook.Book.Title)
+
+	_, err = client.GetBook(ctx, &api.GetBookReq{ID: moved.ID})
+	assert.Error(t, err)
+
+	s.books = s.books[:3]
+}
+
 func getBooks(ctx context.Context, t *testing.T, client *httpclient.Client, req *api.GetBooksReq) *api.GetBooksResp {
 	resp, err := client.GetBooks(ctx, req)
 	assert.NoError(t, err)
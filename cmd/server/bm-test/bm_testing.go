@@ -23,6 +23,21 @@ func TestBM(t *testing.T, client *httpclient.Client) {
 		{name: "test get books validation", testFunc: s.testGetBookValidation},
 		{name: "test update book validation", testFunc: s.testUpdateBookValidation},
 		{name: "test delete book validation", testFunc: s.testDeleteBookValidation},
+		{name: "test books isbn", testFunc: s.testISBN},
+		{name: "test authors", testFunc: s.testAuthors},
+		{name: "test genres", testFunc: s.testGenres},
+		{name: "test tags", testFunc: s.testTags},
+		{name: "test reading", testFunc: s.testReading},
+		{name: "test reviews", testFunc: s.testReviews},
+		{name: "test loans", testFunc: s.testLoans},
+		{name: "test copies", testFunc: s.testCopies},
+		{name: "test covers", testFunc: s.testCovers},
+		{name: "test files", testFunc: s.testFiles},
+		{name: "test import books", testFunc: s.testImportBooks},
+		{name: "test marc", testFunc: s.testMARC},
+		{name: "test citations", testFunc: s.testCitations},
+		{name: "test opds", testFunc: s.testOPDS},
+		{name: "test graphql", testFunc: s.testGraphQL},
 
 		{name: "test collections CRUD", testFunc: s.testCollections},
 		{name: "test create collection validation", testFunc: s.testCreateCollectionValidation},
@@ -33,6 +48,8 @@ func TestBM(t *testing.T, client *httpclient.Client) {
 		{name: "test books collection CRUD", testFunc: s.testBooksCollection},
 		{name: "test create books collection validation", testFunc: s.testCreateBooksCollectionValidation},
 		{name: "test remove books collection validation", testFunc: s.testDeleteBooksCollectionValidation},
+
+		{name: "test tenants", testFunc: s.testTenants},
 	}
 
 	for _, testcase := range testcases {
@@ -0,0 +1,97 @@
+package bmtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tsapen/bm/pkg/api"
+	httpclient "github.com/Tsapen/bm/pkg/http-client"
+)
+
+// TestCache does integration testing of the storage cache, it is enabled by the test server config.
+func TestCache(t *testing.T, client *httpclient.Client) {
+	ctx := context.Background()
+	admin := client.WithAPIKey(adminAPIKey)
+
+	// 1. Only admins get stats.
+	_, err := client.WithAPIKey(readerAPIKey).GetCacheStats(ctx)
+	assert.Error(t, err)
+
+	bookResp, err := admin.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "Roadside Picnic",
+		Author: "Cache author",
+		Genre:  "Cache genre",
+	})
+	require.NoError(t, err)
+
+	// 2. A book read once is cached.
+	getBook(ctx, t, admin, &api.GetBookReq{ID: bookResp.ID})
+
+	before, err := admin.GetCacheStats(ctx)
+	require.NoError(t, err)
+
+	getBook(ctx, t, admin, &api.GetBookReq{ID: bookResp.ID})
+
+	after, err := admin.GetCacheStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, before.Misses, after.Misses)
+	assert.Equal(t, before.Hits+1, after.Hits)
+	assert.NotZero(t, after.Entries)
+
+	// 3. Mutations drop the book.
+	_, err = admin.UpdateBook(ctx, &api.UpdateBookReq{
+		ID:     bookResp.ID,
+		Title:  "The Doomed City",
+		Author: "Cache author",
+		Genre:  "Cache genre",
+	})
+	require.NoError(t, err)
+
+	got := getBook(ctx, t, admin, &api.GetBookReq{ID: bookResp.ID})
+	assert.Equal(t, "The Doomed City", got.Book.Title)
+
+	_, err = admin.CreateReview(ctx, &api.CreateReviewReq{BookID: bookResp.ID, Rating: 4})
+	require.NoError(t, err)
+
+	got = getBook(ctx, t, admin, &api.GetBookReq{ID: bookResp.ID})
+	assert.Equal(t, int64(1), got.Book.ReviewCount)
+	assert.Equal(t, 4.0, got.Book.AverageRating)
+
+	// 4. Mutations of collections drop books selected by filters.
+	collectionResp, err := admin.CreateCollection(ctx, &api.CreateCollectionReq{Name: "Cache collection"})
+	require.NoError(t, err)
+
+	filter := &api.GetBooksReq{CollectionID: collectionResp.ID}
+	assert.Empty(t, getBooks(ctx, t, admin, filter).Books)
+
+	_, err = admin.CreateBooksCollection(ctx, &api.CreateBooksCollectionReq{CID: collectionResp.ID, BookIDs: []int64{bookResp.ID}})
+	require.NoError(t, err)
+
+	books := getBooks(ctx, t, admin, filter).Books
+	require.Len(t, books, 1)
+	assert.Equal(t, bookResp.ID, books[0].ID)
+
+	_, err = admin.UpdateCollection(ctx, &api.UpdateCollectionReq{ID: collectionResp.ID, Name: "Cache collection 2"})
+	require.NoError(t, err)
+
+	collection, err := admin.GetCollection(ctx, &api.GetCollectionReq{ID: collectionResp.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "Cache collection 2", collection.Collection.Name)
+
+	// 5. Deleted books and collections aren't found.
+	_, err = admin.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{bookResp.ID}})
+	require.NoError(t, err)
+
+	_, err = admin.GetBook(ctx, &api.GetBookReq{ID: bookResp.ID})
+	assert.Error(t, err)
+	assert.Empty(t, getBooks(ctx, t, admin, filter).Books)
+
+	_, err = admin.DeleteCollection(ctx, &api.DeleteCollectionReq{ID: collectionResp.ID})
+	require.NoError(t, err)
+
+	_, err = admin.GetCollection(ctx, &api.GetCollectionReq{ID: collectionResp.ID})
+	assert.Error(t, err)
+}
@@ -0,0 +1,168 @@
+package bmtest
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tsapen/bm/pkg/api"
+	httpclient "github.com/Tsapen/bm/pkg/http-client"
+)
+
+// changesWaitTimeout is the longest wait for changes of the stream.
+const changesWaitTimeout = 10 * time.Second
+
+// TestChanges does integration testing of the change feed and its stream.
+func TestChanges(t *testing.T, client *httpclient.Client) {
+	ctx := context.Background()
+
+	// 1. The feed is validated.
+	_, err := client.GetChanges(ctx, &api.GetChangesReq{Since: -1})
+	assert.Error(t, err)
+
+	_, err = client.GetChanges(ctx, &api.GetChangesReq{Limit: -1})
+	assert.Error(t, err)
+
+	// 2. Changes made earlier are skipped, the stream starts after them.
+	since := lastChangeSeq(ctx, t, client)
+	readerSince := lastChangeSeq(ctx, t, client.WithAPIKey(readerAPIKey))
+
+	streamCtx, cancel := context.WithTimeout(ctx, changesWaitTimeout)
+	defer cancel()
+
+	streamed := make(chan api.Change)
+	streamErr := make(chan error, 1)
+	go func() {
+		streamErr <- client.StreamChanges(streamCtx, &api.StreamChangesReq{Since: since}, func(c api.Change) error {
+			select {
+			case streamed <- c:
+				return nil
+
+			case <-streamCtx.Done():
+				return streamCtx.Err()
+			}
+		})
+	}()
+
+	// 3. Mutations of books, reviews and collections are recorded.
+	bookResp, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "Solaris",
+		Author: "Change feed author",
+		Genre:  "Change feed genre",
+	})
+	require.NoError(t, err)
+
+	_, err = client.UpdateBook(ctx, &api.UpdateBookReq{
+		ID:     bookResp.ID,
+		Title:  "Solaris",
+		Author: "Change feed author",
+		Genre:  "Change feed genre",
+	})
+	require.NoError(t, err)
+
+	reviewResp, err := client.CreateReview(ctx, &api.CreateReviewReq{BookID: bookResp.ID, Rating: 5, Text: "Ocean"})
+	require.NoError(t, err)
+
+	collectionResp, err := client.CreateCollection(ctx, &api.CreateCollectionReq{Name: "Change feed collection"})
+	require.NoError(t, err)
+
+	_, err = client.CreateBooksCollection(ctx, &api.CreateBooksCollectionReq{CID: collectionResp.ID, BookIDs: []int64{bookResp.ID}})
+	require.NoError(t, err)
+
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{bookResp.ID}})
+	require.NoError(t, err)
+
+	_, err = client.DeleteCollection(ctx, &api.DeleteCollectionReq{ID: collectionResp.ID})
+	require.NoError(t, err)
+
+	want := []struct {
+		entity string
+		id     int64
+		op     string
+		data   string
+	}{
+		{"author", 0, "created", `{}`},
+		{"book", bookResp.ID, "created", `{}`},
+		{"book", bookResp.ID, "updated", `{}`},
+		{"review", reviewResp.ID, "created", `{"book_id":` + strconv.FormatInt(bookResp.ID, 10) + `}`},
+		{"collection", collectionResp.ID, "created", `{}`},
+		{"collection", collectionResp.ID, "books_added", `{"book_ids":[` + strconv.FormatInt(bookResp.ID, 10) + `]}`},
+		{"book", bookResp.ID, "deleted", `{}`},
+		{"collection", collectionResp.ID, "deleted", `{}`},
+	}
+
+	changesResp, err := client.GetChanges(ctx, &api.GetChangesReq{Since: since})
+	require.NoError(t, err)
+	require.Len(t, changesResp.Changes, len(want))
+
+	for i, c := range changesResp.Changes {
+		assert.Equal(t, want[i].entity, c.Entity, "change %d", i)
+		assert.Equal(t, want[i].op, c.Op, "change %d", i)
+		assert.JSONEq(t, want[i].data, string(c.Data), "change %d", i)
+		if want[i].id != 0 {
+			assert.Equal(t, want[i].id, c.EntityID, "change %d", i)
+		}
+
+		if i > 0 {
+			assert.Greater(t, c.Seq, changesResp.Changes[i-1].Seq)
+		}
+	}
+
+	assert.Equal(t, changesResp.Changes[len(want)-1].Seq, changesResp.NextSince)
+
+	// 4. The feed is paged by limit and resumed with next_since.
+	pageResp, err := client.GetChanges(ctx, &api.GetChangesReq{Since: since, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, pageResp.Changes, 2)
+	assert.Equal(t, changesResp.Changes[1].Seq, pageResp.NextSince)
+
+	pageResp, err = client.GetChanges(ctx, &api.GetChangesReq{Since: pageResp.NextSince, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, pageResp.Changes, 2)
+	assert.Equal(t, changesResp.Changes[2].Seq, pageResp.Changes[0].Seq)
+
+	emptyResp, err := client.GetChanges(ctx, &api.GetChangesReq{Since: changesResp.NextSince})
+	require.NoError(t, err)
+	assert.Empty(t, emptyResp.Changes)
+	assert.Equal(t, changesResp.NextSince, emptyResp.NextSince)
+
+	// 5. The stream sends the same changes.
+	for i, c := range changesResp.Changes {
+		select {
+		case got := <-streamed:
+			assert.Equal(t, c, got, "change %d", i)
+
+		case err := <-streamErr:
+			t.Fatalf("stream is broken: %v", err)
+		}
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-streamErr, context.Canceled)
+
+	// 6. Changes belong to the tenant.
+	readerResp, err := client.WithAPIKey(readerAPIKey).GetChanges(ctx, &api.GetChangesReq{Since: readerSince})
+	require.NoError(t, err)
+	assert.Empty(t, readerResp.Changes)
+}
+
+// lastChangeSeq reads the whole feed and returns the seq of the last change.
+func lastChangeSeq(ctx context.Context, t *testing.T, client *httpclient.Client) int64 {
+	t.Helper()
+
+	var since int64
+	for {
+		resp, err := client.GetChanges(ctx, &api.GetChangesReq{Since: since})
+		require.NoError(t, err)
+
+		if len(resp.Changes) == 0 {
+			return since
+		}
+
+		since = resp.NextSince
+	}
+}
@@ -0,0 +1,158 @@
+package bmtest
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tsapen/bm/internal/bm-http/bmhttptest"
+	"github.com/Tsapen/bm/pkg/api"
+	httpclient "github.com/Tsapen/bm/pkg/http-client"
+)
+
+// TestConditionalGet does integration testing of validators of books and collections
+// and of the client cache which revalidates them.
+func TestConditionalGet(t *testing.T, cfg httpclient.Config) {
+	ctx := context.Background()
+	recorder := &bmhttptest.StatusRecorder{Next: cfg.Transport}
+	cfg.Transport = recorder
+	cfg.APIKey = adminAPIKey
+	client := httpclient.New(cfg)
+
+	cfg.CacheSize = 100
+	cached := httpclient.New(cfg)
+
+	bookResp, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "Solaris",
+		Author: "Conditional author",
+		Genre:  "Conditional genre",
+	})
+	require.NoError(t, err)
+
+	// 1. An unchanged book isn't sent again.
+	first := getBook(ctx, t, cached, &api.GetBookReq{ID: bookResp.ID})
+	assert.Equal(t, http.StatusOK, recorder.Status)
+	assert.False(t, first.Book.UpdatedAt.IsZero())
+
+	second := getBook(ctx, t, cached, &api.GetBookReq{ID: bookResp.ID})
+	assert.Equal(t, http.StatusNotModified, recorder.Status)
+	assert.Equal(t, first, second)
+
+	// 2. Validators are checked by the server.
+	resp := getRaw(t, cfg, "/api/v2/books/"+strconv.FormatInt(bookResp.ID, 10), nil)
+	etag := resp.Header.Get("ETag")
+	modifiedAt := resp.Header.Get("Last-Modified")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, etag)
+	assert.NotEmpty(t, modifiedAt)
+	assert.Equal(t, "private, no-cache", resp.Header.Get("Cache-Control"))
+
+	resp = getRaw(t, cfg, "/api/v2/books/"+strconv.FormatInt(bookResp.ID, 10), http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	resp = getRaw(t, cfg, "/api/v2/books/"+strconv.FormatInt(bookResp.ID, 10), http.Header{"If-Modified-Since": {modifiedAt}})
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	// If-None-Match takes precedence over If-Modified-Since.
+	resp = getRaw(t, cfg, "/api/v2/books/"+strconv.FormatInt(bookResp.ID, 10), http.Header{
+		"If-None-Match":     {`"stale"`},
+		"If-Modified-Since": {modifiedAt},
+	})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// 3. Changes of the book and of its reviews change it.
+	_, err = client.UpdateBook(ctx, &api.UpdateBookReq{
+		ID:     bookResp.ID,
+		Title:  "Solaris, revised",
+		Author: "Conditional author",
+		Genre:  "Conditional genre",
+	})
+	require.NoError(t, err)
+
+	got := getBook(ctx, t, cached, &api.GetBookReq{ID: bookResp.ID})
+	assert.Equal(t, http.StatusOK, recorder.Status)
+	assert.Equal(t, "Solaris, revised", got.Book.Title)
+	assert.False(t, got.Book.UpdatedAt.Before(first.Book.UpdatedAt))
+
+	resp = getRaw(t, cfg, "/api/v2/books/"+strconv.FormatInt(bookResp.ID, 10), http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEqual(t, etag, resp.Header.Get("ETag"))
+
+	_, err = client.CreateReview(ctx, &api.CreateReviewReq{BookID: bookResp.ID, Rating: 5})
+	require.NoError(t, err)
+
+	got = getBook(ctx, t, cached, &api.GetBookReq{ID: bookResp.ID})
+	assert.Equal(t, http.StatusOK, recorder.Status)
+	assert.Equal(t, int64(1), got.Book.ReviewCount)
+
+	// 4. Collections and lists have validators too.
+	collectionResp, err := client.CreateCollection(ctx, &api.CreateCollectionReq{Name: "Conditional collection"})
+	require.NoError(t, err)
+
+	_, err = cached.GetCollection(ctx, &api.GetCollectionReq{ID: collectionResp.ID})
+	require.NoError(t, err)
+	_, err = cached.GetCollection(ctx, &api.GetCollectionReq{ID: collectionResp.ID})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, recorder.Status)
+
+	_, err = client.UpdateCollection(ctx, &api.UpdateCollectionReq{ID: collectionResp.ID, Name: "Conditional collection 2"})
+	require.NoError(t, err)
+
+	collection, err := cached.GetCollection(ctx, &api.GetCollectionReq{ID: collectionResp.ID})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Status)
+	assert.Equal(t, "Conditional collection 2", collection.Collection.Name)
+
+	filter := &api.GetBooksReq{CollectionID: collectionResp.ID}
+	assert.Empty(t, getBooks(ctx, t, cached, filter).Books)
+	assert.Empty(t, getBooks(ctx, t, cached, filter).Books)
+	assert.Equal(t, http.StatusNotModified, recorder.Status)
+
+	_, err = client.CreateBooksCollection(ctx, &api.CreateBooksCollectionReq{CID: collectionResp.ID, BookIDs: []int64{bookResp.ID}})
+	require.NoError(t, err)
+
+	assert.Len(t, getBooks(ctx, t, cached, filter).Books, 1)
+	assert.Equal(t, http.StatusOK, recorder.Status)
+
+	// Books of a collection are its change too.
+	withBooks, err := client.GetCollection(ctx, &api.GetCollectionReq{ID: collectionResp.ID})
+	require.NoError(t, err)
+	assert.True(t, withBooks.Collection.UpdatedAt.After(collection.Collection.UpdatedAt))
+
+	_, err = client.DeleteCollection(ctx, &api.DeleteCollectionReq{ID: collectionResp.ID})
+	require.NoError(t, err)
+
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{bookResp.ID}})
+	require.NoError(t, err)
+
+	// 5. A deleted book isn't served from the cache.
+	_, err = cached.GetBook(ctx, &api.GetBookReq{ID: bookResp.ID})
+	assert.Error(t, err)
+}
+
+// getRaw sends a GET request with the headers as the admin and returns the response with a closed body.
+func getRaw(t *testing.T, cfg httpclient.Config, path string, header http.Header) *http.Response {
+	u, err := url.JoinPath(cfg.Address, path)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	require.NoError(t, err)
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	req.Header.Set("Authorization", "Bearer "+adminAPIKey)
+
+	client := &http.Client{Transport: cfg.Transport, Timeout: cfg.Timeout}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	return resp
+}
@@ -0,0 +1,159 @@
+package bmtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/Tsapen/bm/internal/bm-grpc/bmpb"
+)
+
+// TestGRPC does integration testing of the gRPC API.
+func TestGRPC(t *testing.T, conn *grpc.ClientConn, apiKey string) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+apiKey)
+	books := bmpb.NewBookServiceClient(conn)
+	collections := bmpb.NewCollectionServiceClient(conn)
+
+	// 1. Create more books than a page of the HTTP API.
+	const bookCount = 60
+	published := time.Date(1967, time.January, 1, 0, 0, 0, 0, time.UTC)
+	ids := make([]int64, 0, bookCount)
+	for i := 0; i < bookCount; i++ {
+		resp, err := books.CreateBook(ctx, &bmpb.CreateBookRequest{Book: &bmpb.Book{
+			Title:         fmt.Sprintf("Stream %02d", i),
+			Author:        "Stanisław Lem",
+			PublishedDate: timestamppb.New(published),
+			Genre:         "gRPC fiction",
+			Tags:          []string{"grpc"},
+		}})
+		require.NoError(t, err)
+
+		ids = append(ids, resp.GetId())
+	}
+
+	book, err := books.GetBook(ctx, &bmpb.GetBookRequest{Id: ids[0]})
+	assert.NoError(t, err)
+	assert.Equal(t, "Stream 00", book.GetTitle())
+	assert.Equal(t, "Stanisław Lem", book.GetAuthor())
+	assert.Equal(t, []*bmpb.Contributor{{AuthorId: book.GetContributors()[0].GetAuthorId(), Name: "Stanisław Lem", Role: "author"}}, book.GetContributors())
+	assert.True(t, published.Equal(book.GetPublishedDate().AsTime()))
+	assert.Equal(t, []string{"grpc"}, book.GetTags())
+
+	book.Edition = "2nd"
+	_, err = books.UpdateBook(ctx, &bmpb.UpdateBookRequest{Book: book})
+	assert.NoError(t, err)
+
+	book, err = books.GetBook(ctx, &bmpb.GetBookRequest{Id: ids[0]})
+	assert.NoError(t, err)
+	assert.Equal(t, "2nd", book.GetEdition())
+
+	// 2. ListBooks streams all books, not a page.
+	listIDs := func(r *bmpb.ListBooksRequest) []int64 {
+		stream, err := books.ListBooks(ctx, r)
+		require.NoError(t, err)
+
+		var got []int64
+		for {
+			b, err := stream.Recv()
+			if err == io.EOF {
+				return got
+			}
+
+			require.NoError(t, err)
+			got = append(got, b.GetId())
+		}
+	}
+
+	assert.Equal(t, ids, listIDs(&bmpb.ListBooksRequest{Genre: "gRPC fiction"}))
+	assert.Equal(t, []int64{ids[bookCount-1], ids[bookCount-2]}, listIDs(&bmpb.ListBooksRequest{Query: "Stream 5", OrderBy: "title", Desc: true})[:2])
+
+	// 3. Books of a collection.
+	createCollectionResp, err := collections.CreateCollection(ctx, &bmpb.CreateCollectionRequest{Collection: &bmpb.Collection{Name: "gRPC", Description: "Streamed books"}})
+	require.NoError(t, err)
+	cID := createCollectionResp.GetId()
+
+	_, err = collections.AddBooks(ctx, &bmpb.AddBooksRequest{CollectionId: cID, BookIds: ids})
+	assert.NoError(t, err)
+
+	_, err = collections.RemoveBooks(ctx, &bmpb.RemoveBooksRequest{CollectionId: cID, BookIds: ids[:10]})
+	assert.NoError(t, err)
+	assert.Equal(t, ids[10:], listIDs(&bmpb.ListBooksRequest{CollectionId: cID}))
+
+	_, err = collections.UpdateCollection(ctx, &bmpb.UpdateCollectionRequest{Collection: &bmpb.Collection{Id: cID, Name: "gRPC", Description: "Books"}})
+	assert.NoError(t, err)
+
+	collection, err := collections.GetCollection(ctx, &bmpb.GetCollectionRequest{Id: cID})
+	assert.NoError(t, err)
+	assert.Equal(t, "Books", collection.GetDescription())
+
+	listCollectionsResp, err := collections.ListCollections(ctx, &bmpb.ListCollectionsRequest{OrderBy: "id", Desc: true, PageSize: 1})
+	assert.NoError(t, err)
+	assert.Len(t, listCollectionsResp.GetCollections(), 1)
+	assert.Equal(t, cID, listCollectionsResp.GetCollections()[0].GetId())
+
+	// 4. Errors of the book service are mapped to status codes.
+	assertCode := func(want codes.Code, err error) {
+		t.Helper()
+
+		assert.Equal(t, want, status.Code(err), err)
+	}
+
+	_, err = books.GetBook(ctx, &bmpb.GetBookRequest{Id: ids[bookCount-1] + 1000})
+	assertCode(codes.NotFound, err)
+
+	_, err = books.CreateBook(ctx, &bmpb.CreateBookRequest{Book: &bmpb.Book{Author: "Stanisław Lem", Genre: "gRPC fiction"}})
+	assertCode(codes.InvalidArgument, err)
+
+	_, err = books.CreateBook(ctx, &bmpb.CreateBookRequest{})
+	assertCode(codes.InvalidArgument, err)
+
+	_, err = collections.CreateCollection(ctx, &bmpb.CreateCollectionRequest{Collection: &bmpb.Collection{Name: "gRPC"}})
+	assertCode(codes.AlreadyExists, err)
+
+	stream, err := books.ListBooks(ctx, &bmpb.ListBooksRequest{OrderBy: "unknown"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assertCode(codes.InvalidArgument, err)
+
+	// 5. Calls are authenticated with api keys, calls without a key can't change the library.
+	anonymousCtx := context.Background()
+	_, err = books.GetBook(metadata.AppendToOutgoingContext(anonymousCtx, "authorization", "Bearer unknown"), &bmpb.GetBookRequest{Id: ids[0]})
+	assertCode(codes.Unauthenticated, err)
+
+	_, err = books.GetBook(anonymousCtx, &bmpb.GetBookRequest{Id: ids[0]})
+	assert.NoError(t, err)
+
+	_, err = books.DeleteBooks(anonymousCtx, &bmpb.DeleteBooksRequest{Ids: ids[:1]})
+	assertCode(codes.Unauthenticated, err)
+
+	readerCtx := metadata.AppendToOutgoingContext(anonymousCtx, "authorization", "Bearer "+readerAPIKey)
+	_, err = books.GetBook(readerCtx, &bmpb.GetBookRequest{Id: ids[0]})
+	assertCode(codes.NotFound, err)
+
+	readerStream, err := books.ListBooks(readerCtx, &bmpb.ListBooksRequest{Genre: "gRPC fiction"})
+	require.NoError(t, err)
+	_, err = readerStream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	adminCtx := metadata.AppendToOutgoingContext(anonymousCtx, "authorization", "Bearer "+adminAPIKey)
+	_, err = books.GetBook(adminCtx, &bmpb.GetBookRequest{Id: ids[0]})
+	assert.NoError(t, err)
+
+	// 6. Cleanup.
+	_, err = collections.DeleteCollection(ctx, &bmpb.DeleteCollectionRequest{Id: cID})
+	assert.NoError(t, err)
+
+	_, err = books.DeleteBooks(ctx, &bmpb.DeleteBooksRequest{Ids: ids})
+	assert.NoError(t, err)
+	assert.Empty(t, listIDs(&bmpb.ListBooksRequest{Genre: "gRPC fiction"}))
+}
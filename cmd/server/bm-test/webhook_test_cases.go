@@ -0,0 +1,287 @@
+package bmtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tsapen/bm/internal/webhook"
+	"github.com/Tsapen/bm/pkg/api"
+	httpclient "github.com/Tsapen/bm/pkg/http-client"
+)
+
+// Settings of the webhooks section of configs/test_server_config.json.
+const (
+	webhookMaxAttempts = 3
+	webhookWaitTimeout = 10 * time.Second
+)
+
+// delivery is a request received by the webhook receiver. Failed is set if the receiver failed it.
+type delivery struct {
+	path    string
+	header  http.Header
+	body    []byte
+	payload webhook.Payload
+	failed  bool
+}
+
+// receiver accepts deliveries of webhooks. Requests to /failing fail until failing is unset.
+type receiver struct {
+	failing    atomic.Bool
+	mu         sync.Mutex
+	deliveries []delivery
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	d := delivery{path: r.URL.Path, header: r.Header, body: body}
+	if err = json.Unmarshal(body, &d.payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	d.failed = r.URL.Path == "/failing" && rc.failing.Load()
+
+	rc.mu.Lock()
+	rc.deliveries = append(rc.deliveries, d)
+	rc.mu.Unlock()
+
+	if d.failed {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// wait waits for a successful delivery of the event type to the path.
+func (rc *receiver) wait(t *testing.T, path, eventType string) delivery {
+	t.Helper()
+
+	deadline := time.Now().Add(webhookWaitTimeout)
+	for time.Now().Before(deadline) {
+		rc.mu.Lock()
+		for _, d := range rc.deliveries {
+			if d.path == path && d.payload.Type == eventType && !d.failed {
+				rc.mu.Unlock()
+
+				return d
+			}
+		}
+		rc.mu.Unlock()
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Fatalf("%s event is not delivered to %s", eventType, path)
+
+	return delivery{}
+}
+
+// TestWebhooks does integration testing of webhooks. The server sends deliveries to the receiver listening
+// on the address.
+func TestWebhooks(t *testing.T, client *httpclient.Client, address string) {
+	ctx := context.Background()
+
+	rc := new(receiver)
+	rc.failing.Store(true)
+
+	listener, err := net.Listen("tcp", address)
+	require.NoError(t, err)
+
+	server := &http.Server{Handler: rc, ReadHeaderTimeout: time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("serve webhooks: %v", err)
+		}
+	}()
+
+	defer server.Close()
+
+	baseURL := "http://" + address
+
+	// 1. Only admins manage webhooks, and webhooks are validated.
+	_, err = client.WithAPIKey(readerAPIKey).CreateWebhook(ctx, &api.CreateWebhookReq{URL: baseURL})
+	assert.Error(t, err)
+
+	_, err = client.WithAPIKey("").GetWebhooks(ctx, &api.GetWebhooksReq{})
+	assert.Error(t, err)
+
+	client = client.WithAPIKey(adminAPIKey)
+	for _, req := range []*api.CreateWebhookReq{
+		{URL: ""},
+		{URL: "ftp://" + address},
+		{URL: "/books"},
+		{URL: baseURL, Events: []string{"book.unknown"}},
+		{URL: baseURL, Events: []string{"loan.*"}},
+		{URL: baseURL, Secret: "short"},
+	} {
+		_, err := client.CreateWebhook(ctx, req)
+		assert.Error(t, err, "url %q, events %v", req.URL, req.Events)
+	}
+
+	// 2. Create webhooks: one for books with a given secret, one failing for new collections with a generated secret.
+	const secret = "books-webhook-secret"
+	booksResp, err := client.CreateWebhook(ctx, &api.CreateWebhookReq{
+		URL:    baseURL + "/books",
+		Secret: secret,
+		Events: []string{"book.*"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, secret, booksResp.Secret)
+
+	failingResp, err := client.CreateWebhook(ctx, &api.CreateWebhookReq{
+		URL:    baseURL + "/failing",
+		Events: []string{"collection.created"},
+	})
+	require.NoError(t, err)
+	assert.Len(t, failingResp.Secret, 64)
+
+	getResp, err := client.GetWebhook(ctx, &api.GetWebhookReq{ID: booksResp.ID})
+	require.NoError(t, err)
+	assert.Equal(t, baseURL+"/books", getResp.Webhook.URL)
+	assert.Equal(t, []string{"book.*"}, getResp.Webhook.Events)
+	assert.True(t, getResp.Webhook.Active)
+
+	webhooksResp, err := client.GetWebhooks(ctx, &api.GetWebhooksReq{})
+	require.NoError(t, err)
+	assert.Len(t, webhooksResp.Webhooks, 2)
+
+	// Webhooks belong to the tenant.
+	_, err = client.WithAPIKey(readerAPIKey).GetWebhook(ctx, &api.GetWebhookReq{ID: booksResp.ID})
+	assert.Error(t, err)
+
+	// 3. A new book is delivered with a valid signature.
+	createBookResp, err := client.CreateBook(ctx, &api.CreateBookReq{
+		Title:  "The Cyberiad",
+		Author: "Stanisław Lem",
+		Genre:  "Webhook fiction",
+	})
+	require.NoError(t, err)
+
+	d := rc.wait(t, "/books", "book.created")
+	timestamp, err := strconv.ParseInt(d.header.Get(webhook.HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, webhook.Sign(secret, timestamp, d.body), d.header.Get(webhook.HeaderSignature))
+	assert.Equal(t, "book.created", d.header.Get(webhook.HeaderEvent))
+	assert.JSONEq(t, `{"id":`+strconv.FormatInt(createBookResp.ID, 10)+`,"title":"The Cyberiad"}`, string(d.payload.Data))
+
+	deliveryID, err := strconv.ParseInt(d.header.Get(webhook.HeaderDelivery), 10, 64)
+	require.NoError(t, err)
+
+	// The attempt is recorded after the response.
+	succeeded := waitDelivery(ctx, t, client, booksResp.ID, deliveryID, "succeeded")
+	assert.Equal(t, d.payload.ID, succeeded.EventID)
+	if assert.Len(t, succeeded.Attempts, 1) {
+		assert.Equal(t, int64(http.StatusNoContent), succeeded.Attempts[0].StatusCode)
+	}
+
+	// 4. A failing delivery is retried and becomes dead.
+	_, err = client.CreateCollection(ctx, &api.CreateCollectionReq{Name: "Webhook collection"})
+	require.NoError(t, err)
+
+	var dead api.WebhookDelivery
+	deadline := time.Now().Add(webhookWaitTimeout)
+	for time.Now().Before(deadline) {
+		resp, err := client.GetWebhookDeliveries(ctx, &api.GetWebhookDeliveriesReq{WebhookID: failingResp.ID, Status: "dead"})
+		require.NoError(t, err)
+
+		if len(resp.Deliveries) != 0 {
+			dead = resp.Deliveries[0]
+
+			break
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	require.NotZero(t, dead.ID, "delivery is not dead")
+	assert.Equal(t, "collection.created", dead.EventType)
+	assert.Equal(t, int64(webhookMaxAttempts), dead.AttemptCount)
+	assert.Equal(t, "unexpected status 500", dead.LastError)
+
+	deliveryResp, err := client.GetWebhookDelivery(ctx, &api.GetWebhookDeliveryReq{WebhookID: failingResp.ID, ID: dead.ID})
+	require.NoError(t, err)
+	assert.Len(t, deliveryResp.Delivery.Attempts, webhookMaxAttempts)
+
+	// 5. The event of the dead delivery is redelivered once the receiver is fixed.
+	rc.failing.Store(false)
+
+	redeliverResp, err := client.RedeliverWebhookEvent(ctx, &api.RedeliverWebhookEventReq{WebhookID: failingResp.ID, DeliveryID: dead.ID})
+	require.NoError(t, err)
+	assert.NotEqual(t, dead.ID, redeliverResp.ID)
+
+	d = rc.wait(t, "/failing", "collection.created")
+	assert.Equal(t, dead.EventID, d.payload.ID)
+	waitDelivery(ctx, t, client, failingResp.ID, redeliverResp.ID, "succeeded")
+
+	_, err = client.RedeliverWebhookEvent(ctx, &api.RedeliverWebhookEventReq{WebhookID: booksResp.ID, DeliveryID: dead.ID})
+	assert.Error(t, err)
+
+	// 6. Inactive webhooks get no deliveries.
+	active := false
+	_, err = client.UpdateWebhook(ctx, &api.UpdateWebhookReq{
+		ID:     booksResp.ID,
+		URL:    baseURL + "/books",
+		Events: []string{"book.*"},
+		Active: &active,
+	})
+	require.NoError(t, err)
+
+	before, err := client.GetWebhookDeliveries(ctx, &api.GetWebhookDeliveriesReq{WebhookID: booksResp.ID})
+	require.NoError(t, err)
+
+	_, err = client.DeleteBooks(ctx, &api.DeleteBooksReq{IDs: []int64{createBookResp.ID}})
+	require.NoError(t, err)
+
+	after, err := client.GetWebhookDeliveries(ctx, &api.GetWebhookDeliveriesReq{WebhookID: booksResp.ID})
+	require.NoError(t, err)
+	assert.Equal(t, len(before.Deliveries), len(after.Deliveries))
+
+	// 7. Deleted webhooks are gone with their deliveries.
+	for _, id := range []int64{booksResp.ID, failingResp.ID} {
+		_, err = client.DeleteWebhook(ctx, &api.DeleteWebhookReq{ID: id})
+		assert.NoError(t, err)
+
+		_, err = client.GetWebhook(ctx, &api.GetWebhookReq{ID: id})
+		assert.Error(t, err)
+	}
+}
+
+// waitDelivery waits until the delivery has the status.
+func waitDelivery(ctx context.Context, t *testing.T, client *httpclient.Client, webhookID, id int64, status string) api.WebhookDelivery {
+	t.Helper()
+
+	deadline := time.Now().Add(webhookWaitTimeout)
+	for {
+		resp, err := client.GetWebhookDelivery(ctx, &api.GetWebhookDeliveryReq{WebhookID: webhookID, ID: id})
+		require.NoError(t, err)
+
+		if resp.Delivery.Status == status || time.Now().After(deadline) {
+			require.Equal(t, status, resp.Delivery.Status)
+
+			return resp.Delivery
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -1,13 +1,24 @@
 package main
 
 import (
+	"context"
+
 	"github.com/rs/zerolog/log"
 
+	"github.com/Tsapen/bm/internal/auth"
+	bm "github.com/Tsapen/bm/internal/bm"
+	bmgraphql "github.com/Tsapen/bm/internal/bm-graphql"
+	bmgrpc "github.com/Tsapen/bm/internal/bm-grpc"
 	bmhttp "github.com/Tsapen/bm/internal/bm-http"
 	bs "github.com/Tsapen/bm/internal/book-service"
 	"github.com/Tsapen/bm/internal/config"
+	fsstore "github.com/Tsapen/bm/internal/fs-store"
 	"github.com/Tsapen/bm/internal/migrator"
 	"github.com/Tsapen/bm/internal/postgres"
+	ratelimit "github.com/Tsapen/bm/internal/rate-limit"
+	storagecache "github.com/Tsapen/bm/internal/storage-cache"
+	unixsocket "github.com/Tsapen/bm/internal/unix-socket"
+	"github.com/Tsapen/bm/internal/webhook"
 )
 
 func main() {
@@ -25,13 +36,29 @@ func main() {
 		log.Fatal().Err(err).Msg("apply migrations")
 	}
 
-	bookService := bs.New(db)
+	covers, err := fsstore.New(fsstore.Config{Dir: cfg.Blobs.Dir})
+	if err != nil {
+		log.Fatal().Err(err).Msg("init blob store")
+	}
+
+	files, err := fsstore.New(fsstore.Config{Dir: cfg.Files.Dir})
+	if err != nil {
+		log.Fatal().Err(err).Msg("init file store")
+	}
+
+	bookService := bs.New(newStorage(db, cfg.Cache), covers, files)
+
+	go webhook.New(db, webhookConfig(cfg.Webhooks)).Run(context.Background())
 
-	httpService, err := bmhttp.NewServer(bmhttp.Config(*cfg.HTTPCfg), bookService)
+	httpService, err := bmhttp.NewServer(httpConfig(cfg), bookService)
 	if err != nil {
 		log.Fatal().Err(err).Msg("init http server")
 	}
 
+	if cfg.GRPC != nil {
+		startGRPCServer(grpcConfig(cfg), bookService)
+	}
+
 	go func() {
 		if err = httpService.StartUnixSocketServer(); err != nil {
 			log.Fatal().Err(err).Msg("run unix socket server server")
@@ -42,3 +69,150 @@ func main() {
 		log.Fatal().Err(err).Msg("run tcp server")
 	}
 }
+
+// newStorage wraps the database with the cache if it is enabled. The cache drops queries changed by other servers
+// sharing the database on their notifications.
+func newStorage(db *postgres.DB, cfg *config.CacheCfg) bm.Storage {
+	if cfg == nil {
+		return db
+	}
+
+	cache := storagecache.New(db, storagecache.Config{Size: cfg.Size, TTL: cfg.TTL})
+	go func() {
+		if err := db.ListenChanges(context.Background(), cache.Invalidate, cache.Reset); err != nil {
+			log.Fatal().Err(err).Msg("listen changes")
+		}
+	}()
+
+	return cache
+}
+
+func httpConfig(cfg *config.ServerConfig) bmhttp.Config {
+	return bmhttp.Config{
+		Addr:         cfg.HTTPCfg.Addr,
+		SocketPath:   cfg.HTTPCfg.SocketPath,
+		Socket:       socketConfig(cfg.HTTPCfg.Socket),
+		ConnMaxCount: cfg.HTTPCfg.ConnMaxCount,
+		Timeout:      cfg.HTTPCfg.Timeout,
+		TLS:          tlsConfig(cfg.HTTPCfg.TLS),
+		Auth:         authConfig(cfg.Auth),
+		RateLimit:    rateLimitConfig(cfg.RateLimit),
+		GraphQL: bmgraphql.Config{
+			MaxDepth:      cfg.GraphQL.MaxDepth,
+			MaxComplexity: cfg.GraphQL.MaxComplexity,
+		},
+	}
+}
+
+// startGRPCServer runs the gRPC server in the background on the configured transports.
+func startGRPCServer(cfg bmgrpc.Config, bookService *bs.Service) {
+	grpcService, err := bmgrpc.NewServer(cfg, bookService)
+	if err != nil {
+		log.Fatal().Err(err).Msg("init grpc server")
+	}
+
+	if cfg.Addr != "" {
+		go func() {
+			if err := grpcService.StartTCPServer(); err != nil {
+				log.Fatal().Err(err).Msg("run grpc tcp server")
+			}
+		}()
+	}
+
+	if cfg.SocketPath != "" {
+		go func() {
+			if err := grpcService.StartUnixSocketServer(); err != nil {
+				log.Fatal().Err(err).Msg("run grpc unix socket server")
+			}
+		}()
+	}
+}
+
+func grpcConfig(cfg *config.ServerConfig) bmgrpc.Config {
+	return bmgrpc.Config{
+		Addr:       cfg.GRPC.Addr,
+		SocketPath: cfg.GRPC.SocketPath,
+		Socket:     socketConfig(cfg.GRPC.Socket),
+		TLS:        tlsConfig(cfg.GRPC.TLS),
+		Auth:       authConfig(cfg.Auth),
+		RateLimit:  rateLimitConfig(cfg.RateLimit),
+	}
+}
+
+// authConfig is shared by the HTTP and gRPC servers, callers are identified by both in the same way.
+func authConfig(cfg *config.AuthCfg) auth.Config {
+	apiKeys := make([]auth.APIKey, 0, len(cfg.APIKeys))
+	for _, k := range cfg.APIKeys {
+		apiKeys = append(apiKeys, auth.APIKey(k))
+	}
+
+	clientCerts := make([]auth.ClientCert, 0, len(cfg.ClientCerts))
+	for _, c := range cfg.ClientCerts {
+		clientCerts = append(clientCerts, auth.ClientCert(c))
+	}
+
+	return auth.Config{
+		DefaultTenant: cfg.DefaultTenant,
+		APIKeys:       apiKeys,
+		ClientCerts:   clientCerts,
+	}
+}
+
+func tlsConfig(cfg *config.TLSCfg) auth.TLSConfig {
+	if cfg == nil {
+		return auth.TLSConfig{}
+	}
+
+	return auth.TLSConfig(*cfg)
+}
+
+func socketConfig(cfg *config.SocketCfg) unixsocket.Config {
+	if cfg == nil {
+		return unixsocket.Config{}
+	}
+
+	socketCfg := unixsocket.Config{
+		Mode:  cfg.Mode,
+		Group: cfg.Group,
+	}
+
+	for _, p := range cfg.Peers {
+		socketCfg.Peers = append(socketCfg.Peers, unixsocket.Peer{UID: p.UID, Access: unixsocket.Access(p.Access)})
+	}
+
+	return socketCfg
+}
+
+func webhookConfig(cfg *config.WebhooksCfg) webhook.Config {
+	return webhook.Config{
+		Workers:      cfg.Workers,
+		PollInterval: cfg.PollInterval,
+		Timeout:      cfg.Timeout,
+		MaxAttempts:  cfg.MaxAttempts,
+		BaseBackoff:  cfg.BaseBackoff,
+		MaxBackoff:   cfg.MaxBackoff,
+		BatchSize:    cfg.BatchSize,
+
+		AllowedNetworks: cfg.AllowedNetworks,
+	}
+}
+
+func rateLimitConfig(cfg *config.RateLimitCfg) ratelimit.Config {
+	if cfg == nil {
+		return ratelimit.Config{}
+	}
+
+	limit := func(c *config.LimitCfg) ratelimit.Limit {
+		if c == nil {
+			return ratelimit.Limit{}
+		}
+
+		return ratelimit.Limit(*c)
+	}
+
+	return ratelimit.Config{
+		Reads:  limit(cfg.Reads),
+		Writes: limit(cfg.Writes),
+		Bulk:   limit(cfg.Bulk),
+	}
+}
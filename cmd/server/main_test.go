@@ -5,8 +5,12 @@ import (
 	"testing"
 	"time"
 
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
 	bmtest "github.com/Tsapen/bm/cmd/server/bm-test"
 	"github.com/Tsapen/bm/internal/config"
+	"github.com/Tsapen/bm/internal/openapi"
 	"github.com/Tsapen/bm/pkg/api"
 	httpclient "github.com/Tsapen/bm/pkg/http-client"
 )
@@ -17,14 +21,47 @@ func TestBM(t *testing.T) {
 		t.Fatalf("read client configs: %v\n", err)
 	}
 
-	client := httpclient.New(httpclient.Config{
-		Address: clientCfg.Address,
-		Timeout: clientCfg.Timeout,
-	})
+	// Requests and responses of the tests must follow the OpenAPI document.
+	validator, err := openapi.NewValidator(openapi.V2)
+	if err != nil {
+		t.Fatalf("create openapi validator: %v\n", err)
+	}
+
+	httpCfg := httpclient.Config{
+		Address:   clientCfg.Address,
+		APIKey:    clientCfg.APIKey,
+		Timeout:   clientCfg.Timeout,
+		Transport: &openapi.Transport{Validator: validator},
+	}
+
+	client := httpclient.New(httpCfg)
 
 	waitRunning(t, client)
 
 	bmtest.TestBM(t, client)
+
+	if clientCfg.WebhookAddress != "" {
+		bmtest.TestWebhooks(t, client, clientCfg.WebhookAddress)
+	}
+
+	bmtest.TestChanges(t, client)
+
+	bmtest.TestCache(t, client)
+
+	bmtest.TestConditionalGet(t, httpCfg)
+
+	if clientCfg.GRPCAddress == "" {
+		return
+	}
+
+	conn, err := grpc.Dial(clientCfg.GRPCAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("connect to grpc server: %v\n", err)
+	}
+
+	defer conn.Close()
+
+	bmtest.TestGRPC(t, conn, clientCfg.APIKey)
 }
 
 func waitRunning(t *testing.T, client *httpclient.Client) {
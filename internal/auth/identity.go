@@ -0,0 +1,180 @@
+// Package auth identifies callers of the HTTP and gRPC servers by api keys and client certificates
+// and keeps TLS configurations of the servers.
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+const (
+	bearerPrefix = "Bearer "
+	basicPrefix  = "Basic "
+)
+
+// Config contains api keys and client certificates of the server users.
+type Config struct {
+	DefaultTenant string
+	APIKeys       []APIKey
+	ClientCerts   []ClientCert
+}
+
+// APIKey binds a key to identity of its owner.
+type APIKey struct {
+	Key    string
+	Name   string
+	Tenant string
+	Admin  bool
+}
+
+// ClientCert binds the common name of the subject of a verified client certificate to identity of its owner.
+type ClientCert struct {
+	CommonName string
+	Name       string
+	Tenant     string
+	Admin      bool
+}
+
+// Credentials are presented by a caller. Authorization is the value of the authorization header or metadata,
+// Cert is the verified client certificate, Socket is set for callers on the unix socket.
+type Credentials struct {
+	Authorization string
+	Cert          *x509.Certificate
+	Socket        bool
+}
+
+// Resolver identifies callers by their credentials.
+type Resolver struct {
+	defaultTenant string
+	keys          []apiKey
+
+	// certIdentities are identities by common names of client certificates.
+	certIdentities map[string]bm.Identity
+}
+
+// apiKey is a hash of a key and identity of its owner. Keys are compared by hashes in constant time,
+// so the time of a lookup doesn't tell how much of a key is guessed.
+type apiKey struct {
+	hash [sha256.Size]byte
+	id   bm.Identity
+}
+
+func NewResolver(cfg Config) *Resolver {
+	keys := make([]apiKey, 0, len(cfg.APIKeys))
+	for _, k := range cfg.APIKeys {
+		// A prefix of the hash identifies the key, e.g. for rate limits, and doesn't reveal it.
+		hash := sha256.Sum256([]byte(k.Key))
+		keys = append(keys, apiKey{
+			hash: hash,
+			id: bm.Identity{
+				Name:   k.Name,
+				Tenant: k.Tenant,
+				Key:    "key:" + hex.EncodeToString(hash[:8]),
+				Admin:  k.Admin,
+			},
+		})
+	}
+
+	certIdentities := make(map[string]bm.Identity, len(cfg.ClientCerts))
+	for _, c := range cfg.ClientCerts {
+		certIdentities[c.CommonName] = bm.Identity{
+			Name:   c.Name,
+			Tenant: c.Tenant,
+			Key:    "cert:" + c.CommonName,
+			Admin:  c.Admin,
+		}
+	}
+
+	return &Resolver{
+		defaultTenant:  cfg.DefaultTenant,
+		keys:           keys,
+		certIdentities: certIdentities,
+	}
+}
+
+// Identify resolves the caller by api key or, without a key, by the client certificate. Other callers belong
+// to the default tenant if it is set. They are read-only if the server has api keys or client certificates,
+// except callers on the unix socket: its peers are authorized by the socket.
+//
+// The key is sent as a bearer token or, by e-reader apps which support only basic authentication, as the password.
+func (r *Resolver) Identify(c Credentials) (bm.Identity, error) {
+	if c.Authorization == "" {
+		if c.Cert != nil {
+			return r.identifyCert(c.Cert)
+		}
+
+		if r.defaultTenant == "" {
+			return bm.Identity{}, bm.NewUnauthorizedError("api key is required")
+		}
+
+		readOnly := (len(r.keys) != 0 || len(r.certIdentities) != 0) && !c.Socket
+
+		return bm.Identity{Tenant: r.defaultTenant, ReadOnly: readOnly}, nil
+	}
+
+	if key, ok := strings.CutPrefix(c.Authorization, bearerPrefix); ok {
+		return r.identifyKey(key)
+	}
+
+	if key, ok := basicPassword(c.Authorization); ok {
+		return r.identifyKey(key)
+	}
+
+	return bm.Identity{}, bm.NewUnauthorizedError("unsupported authorization scheme")
+}
+
+// identifyKey finds the owner of the api key. All keys are compared, a match doesn't stop the lookup.
+func (r *Resolver) identifyKey(key string) (bm.Identity, error) {
+	hash := sha256.Sum256([]byte(key))
+
+	var id bm.Identity
+	found := 0
+	for _, k := range r.keys {
+		match := subtle.ConstantTimeCompare(hash[:], k.hash[:])
+		if match == 1 {
+			id = k.id
+		}
+
+		found |= match
+	}
+
+	if found == 0 {
+		return bm.Identity{}, bm.NewUnauthorizedError("unknown api key")
+	}
+
+	return id, nil
+}
+
+// identifyCert maps the common name of the client certificate to identity. The certificate is signed
+// by the client CA, but it must be bound to an identity too.
+func (r *Resolver) identifyCert(cert *x509.Certificate) (bm.Identity, error) {
+	id, ok := r.certIdentities[cert.Subject.CommonName]
+	if !ok {
+		return bm.Identity{}, bm.NewUnauthorizedError("unknown client certificate %q", cert.Subject.CommonName)
+	}
+
+	return id, nil
+}
+
+// basicPassword extracts the password of basic authentication like http.Request.BasicAuth does.
+func basicPassword(authorization string) (string, bool) {
+	// The scheme is case-insensitive.
+	if len(authorization) < len(basicPrefix) || !strings.EqualFold(authorization[:len(basicPrefix)], basicPrefix) {
+		return "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(authorization[len(basicPrefix):])
+	if err != nil {
+		return "", false
+	}
+
+	_, password, ok := strings.Cut(string(decoded), ":")
+
+	return password, ok
+}
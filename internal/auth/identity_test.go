@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+func TestIdentify(t *testing.T) {
+	r := NewResolver(Config{
+		DefaultTenant: "default",
+		APIKeys: []APIKey{
+			{Key: "admin-key", Name: "admin", Tenant: "default", Admin: true},
+			{Key: "reader-key", Name: "reader", Tenant: "reader"},
+		},
+		ClientCerts: []ClientCert{{CommonName: "reader-service", Name: "reader-service", Tenant: "reader"}},
+	})
+
+	basic := func(user, password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+	}
+
+	keyID := func(key string) string {
+		hash := sha256.Sum256([]byte(key))
+
+		return "key:" + hex.EncodeToString(hash[:8])
+	}
+
+	cert := func(commonName string) *x509.Certificate {
+		return &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	}
+
+	tests := []struct {
+		name  string
+		creds Credentials
+		id    bm.Identity
+		err   bool
+	}{
+		{
+			name:  "bearer key",
+			creds: Credentials{Authorization: "Bearer reader-key"},
+			id:    bm.Identity{Name: "reader", Tenant: "reader", Key: keyID("reader-key")},
+		},
+		{
+			name:  "basic password",
+			creds: Credentials{Authorization: basic("anybody", "admin-key")},
+			id:    bm.Identity{Name: "admin", Tenant: "default", Key: keyID("admin-key"), Admin: true},
+		},
+		{
+			name:  "key takes precedence over certificate",
+			creds: Credentials{Authorization: "Bearer admin-key", Cert: cert("reader-service")},
+			id:    bm.Identity{Name: "admin", Tenant: "default", Key: keyID("admin-key"), Admin: true},
+		},
+		{
+			name:  "certificate",
+			creds: Credentials{Cert: cert("reader-service")},
+			id:    bm.Identity{Name: "reader-service", Tenant: "reader", Key: "cert:reader-service"},
+		},
+		{
+			name:  "anonymous caller is read-only",
+			creds: Credentials{},
+			id:    bm.Identity{Tenant: "default", ReadOnly: true},
+		},
+		{
+			name:  "socket peer",
+			creds: Credentials{Socket: true},
+			id:    bm.Identity{Tenant: "default"},
+		},
+		{
+			name:  "unknown key",
+			creds: Credentials{Authorization: "Bearer admin-key2"},
+			err:   true,
+		},
+		{
+			name:  "basic without password",
+			creds: Credentials{Authorization: "Basic " + base64.StdEncoding.EncodeToString([]byte("admin-key"))},
+			err:   true,
+		},
+		{
+			name:  "unsupported scheme",
+			creds: Credentials{Authorization: "Digest admin-key"},
+			err:   true,
+		},
+		{
+			name:  "unknown certificate",
+			creds: Credentials{Cert: cert("unknown-service")},
+			err:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := r.Identify(tt.creds)
+			if tt.err {
+				assert.ErrorAs(t, err, &bm.UnauthorizedError{})
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.id, id)
+		})
+	}
+
+	// Without keys and certificates the server is open, callers without a default tenant are rejected.
+	id, err := NewResolver(Config{DefaultTenant: "default"}).Identify(Credentials{})
+	require.NoError(t, err)
+	assert.False(t, id.ReadOnly)
+
+	_, err = NewResolver(Config{}).Identify(Credentials{})
+	assert.Error(t, err)
+}
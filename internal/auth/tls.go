@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TLSConfig enables TLS on the tcp listener if the certificate and the key are set. ClientCAFile enables
+// authentication by client certificates signed by the CA, RequireClientCert rejects connections without them.
+// Files are reloaded after they change, so certificates are rotated without restarts.
+type TLSConfig struct {
+	CertFile          string
+	KeyFile           string
+	ClientCAFile      string
+	RequireClientCert bool
+}
+
+// Enabled checks if the certificate or the key is set.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// CertReloader keeps the TLS configuration built from the files and rebuilds it when any of them is modified.
+type CertReloader struct {
+	cfg TLSConfig
+
+	mu       sync.Mutex
+	modTimes []time.Time
+	tlsCfg   *tls.Config
+}
+
+// NewCertReloader loads the files, they must be valid on start.
+func NewCertReloader(cfg TLSConfig) (*CertReloader, error) {
+	r := &CertReloader{cfg: cfg}
+	if _, err := r.config(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// TLSConfig returns the configuration of the server, every handshake gets the current certificates.
+func (r *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.config()
+		},
+		// The configuration for the client always has a certificate, http.Server requires a way to get it anyway.
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			tlsCfg, err := r.config()
+			if err != nil {
+				return nil, err
+			}
+
+			return &tlsCfg.Certificates[0], nil
+		},
+	}
+}
+
+// config returns the configuration built from the current files. A failed reload keeps the previous
+// configuration: files are often replaced one by one, so the next handshake retries.
+func (r *CertReloader) config() (*tls.Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTimes, err := r.modifiedAt()
+	if err == nil && r.tlsCfg != nil && slices.EqualFunc(modTimes, r.modTimes, time.Time.Equal) {
+		return r.tlsCfg, nil
+	}
+
+	var tlsCfg *tls.Config
+	if err == nil {
+		tlsCfg, err = r.load()
+	}
+
+	if err != nil {
+		if r.tlsCfg == nil {
+			return nil, err
+		}
+
+		log.Error().Err(err).Msg("reload tls files, the previous ones are used")
+
+		return r.tlsCfg, nil
+	}
+
+	if r.tlsCfg != nil {
+		log.Info().Msg("tls files are reloaded")
+	}
+
+	r.tlsCfg = tlsCfg
+	r.modTimes = modTimes
+
+	return tlsCfg, nil
+}
+
+func (r *CertReloader) modifiedAt() ([]time.Time, error) {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+
+	modTimes := make([]time.Time, 0, len(files))
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return nil, fmt.Errorf("stat %s: %w", f, err)
+		}
+
+		modTimes = append(modTimes, info.ModTime())
+	}
+
+	return modTimes, nil
+}
+
+func (r *CertReloader) load() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load key pair: %w", err)
+	}
+
+	// The configuration replaces the one of the server for every handshake, so it offers the protocols itself.
+	// HTTP/2 is required by gRPC and preferred by HTTP clients.
+	tlsCfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+
+	if r.cfg.ClientCAFile == "" {
+		return tlsCfg, nil
+	}
+
+	data, err := os.ReadFile(r.cfg.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read client ca: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates in client ca %s", r.cfg.ClientCAFile)
+	}
+
+	tlsCfg.ClientCAs = pool
+	tlsCfg.ClientAuth = tls.VerifyClientCertIfGiven
+	if r.cfg.RequireClientCert {
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsCfg, nil
+}
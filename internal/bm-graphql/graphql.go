@@ -0,0 +1,105 @@
+package bmgraphql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	bs "github.com/Tsapen/bm/internal/book-service"
+)
+
+// Config limits queries. Zero values disable the limits.
+type Config struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// Executor executes GraphQL operations over books and collections.
+type Executor struct {
+	cfg         Config
+	schema      graphql.Schema
+	bookService *bs.Service
+}
+
+// Request is a GraphQL operation. QueryOnly rejects mutations, it is set for GET requests.
+type Request struct {
+	Query         string
+	OperationName string
+	Variables     map[string]any
+	QueryOnly     bool
+}
+
+func NewExecutor(cfg Config, bookService *bs.Service) (*Executor, error) {
+	schema, err := newSchema(bookService)
+	if err != nil {
+		return nil, fmt.Errorf("create schema: %w", err)
+	}
+
+	return &Executor{
+		cfg:         cfg,
+		schema:      schema,
+		bookService: bookService,
+	}, nil
+}
+
+// Execute executes the operation. Errors of the document and of resolvers are returned within the result,
+// the error is returned for requests which can't be executed at all.
+func (e *Executor) Execute(ctx context.Context, r Request) (*graphql.Result, error) {
+	if r.Query == "" {
+		return nil, bm.NewValidationError("query is empty")
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(r.Query), Name: "GraphQL request"})})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, nil
+	}
+
+	validation := graphql.ValidateDocument(&e.schema, doc, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}, nil
+	}
+
+	if r.QueryOnly && hasMutation(doc, r.OperationName) {
+		return nil, bm.NewValidationError("mutations must be sent with POST")
+	}
+
+	if bm.IdentityFromCtx(ctx).ReadOnly && hasMutation(doc, r.OperationName) {
+		return nil, bm.NewUnauthorizedError("mutations aren't allowed to read-only callers")
+	}
+
+	if err = checkLimits(e.schema, doc, r.OperationName, r.Variables, e.cfg); err != nil {
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{{
+			Message:    err.Error(),
+			Extensions: resolverError{err}.Extensions(),
+		}}}, nil
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        e.schema,
+		AST:           doc,
+		OperationName: r.OperationName,
+		Args:          r.Variables,
+		Context:       withLoaders(ctx, newLoaders(e.bookService)),
+	}), nil
+}
+
+func hasMutation(doc *ast.Document, operationName string) bool {
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok || operationName != "" && (op.Name == nil || op.Name.Value != operationName) {
+			continue
+		}
+
+		if op.Operation == ast.OperationTypeMutation {
+			return true
+		}
+	}
+
+	return false
+}
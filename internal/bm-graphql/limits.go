@@ -0,0 +1,197 @@
+package bmgraphql
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// Sizes of lists. Lists without the pageSize argument, such as contributors, are assumed to be short;
+// paginated lists are charged by the requested or the default page size capped like by the book service.
+const (
+	defaultListSize = 10
+	maxListSize     = 50
+)
+
+// cost is the depth and the complexity of a selection set.
+type cost struct {
+	depth      int
+	complexity int
+}
+
+// costMeter measures an operation of a validated document. A field costs 1, fields of list items cost
+// as many times as there are items in the list.
+type costMeter struct {
+	schema    graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+}
+
+// checkLimits returns a validation error if the operation exceeds the limits. Zero limits are not checked.
+func checkLimits(schema graphql.Schema, doc *ast.Document, operationName string, variables map[string]any, cfg Config) error {
+	if cfg.MaxDepth == 0 && cfg.MaxComplexity == 0 {
+		return nil
+	}
+
+	m := costMeter{
+		schema:    schema,
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+	}
+
+	var op *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			m.fragments[def.Name.Value] = def
+
+		case *ast.OperationDefinition:
+			if operationName == "" || def.Name != nil && def.Name.Value == operationName {
+				op = def
+			}
+		}
+	}
+
+	if op == nil {
+		return nil
+	}
+
+	root := schema.QueryType()
+	if op.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+
+	c := m.selectionSet(root, op.SelectionSet, nil)
+	if cfg.MaxDepth != 0 && c.depth > cfg.MaxDepth {
+		return bm.NewValidationError("query depth %d exceeds the limit %d", c.depth, cfg.MaxDepth)
+	}
+
+	if cfg.MaxComplexity != 0 && c.complexity > cfg.MaxComplexity {
+		return bm.NewValidationError("query complexity %d exceeds the limit %d", c.complexity, cfg.MaxComplexity)
+	}
+
+	return nil
+}
+
+// selectionSet measures a selection set of the object type. visited are names of fragments spread on the path,
+// validation rejects fragment cycles, it prevents the recursion on documents which are not validated.
+func (m costMeter) selectionSet(t *graphql.Object, set *ast.SelectionSet, visited []string) cost {
+	var c cost
+	if set == nil || t == nil {
+		return c
+	}
+
+	for _, s := range set.Selections {
+		var sc cost
+		switch s := s.(type) {
+		case *ast.Field:
+			sc = m.field(t, s, visited)
+
+		case *ast.InlineFragment:
+			sc = m.selectionSet(m.fragmentType(t, s.TypeCondition), s.SelectionSet, visited)
+
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			f, ok := m.fragments[name]
+			if !ok || slices.Contains(visited, name) {
+				continue
+			}
+
+			sc = m.selectionSet(m.fragmentType(t, f.TypeCondition), f.SelectionSet, append(visited, name))
+		}
+
+		c.depth = max(c.depth, sc.depth)
+		c.complexity += sc.complexity
+	}
+
+	return c
+}
+
+func (m costMeter) field(t *graphql.Object, f *ast.Field, visited []string) cost {
+	name := f.Name.Value
+	if strings.HasPrefix(name, "__") {
+		return cost{}
+	}
+
+	def, ok := t.Fields()[name]
+	if !ok {
+		return cost{depth: 1, complexity: 1}
+	}
+
+	fieldType := def.Type
+	if nonNull, ok := fieldType.(*graphql.NonNull); ok {
+		fieldType = nonNull.OfType
+	}
+
+	items := 1
+	if list, ok := fieldType.(*graphql.List); ok {
+		items = m.listSize(def, f)
+		fieldType = list.OfType
+		if nonNull, ok := fieldType.(*graphql.NonNull); ok {
+			fieldType = nonNull.OfType
+		}
+	}
+
+	object, _ := fieldType.(*graphql.Object)
+	c := m.selectionSet(object, f.SelectionSet, visited)
+
+	return cost{
+		depth:      c.depth + 1,
+		complexity: 1 + items*c.complexity,
+	}
+}
+
+// listSize returns the page size requested by the field, the default page size of the field or the default list size
+// if the field isn't paginated.
+func (m costMeter) listSize(def *graphql.FieldDefinition, f *ast.Field) int {
+	size, paginated := defaultListSize, false
+	for _, arg := range def.Args {
+		if arg.Name() != "pageSize" {
+			continue
+		}
+
+		size, paginated = maxListSize, true
+		if d, ok := arg.DefaultValue.(int); ok {
+			size = d
+		}
+	}
+
+	if !paginated {
+		return size
+	}
+
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != "pageSize" {
+			continue
+		}
+
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if requested, err := strconv.Atoi(v.Value); err == nil && requested > 0 {
+				size = requested
+			}
+
+		case *ast.Variable:
+			if requested, ok := m.variables[v.Name.Value].(float64); ok && requested > 0 {
+				size = int(requested)
+			}
+		}
+	}
+
+	return min(size, maxListSize)
+}
+
+func (m costMeter) fragmentType(t *graphql.Object, cond *ast.Named) *graphql.Object {
+	if cond == nil {
+		return t
+	}
+
+	object, _ := m.schema.Type(cond.Name.Value).(*graphql.Object)
+
+	return object
+}
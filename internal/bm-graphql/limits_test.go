@@ -0,0 +1,97 @@
+package bmgraphql
+
+import (
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckLimits(t *testing.T) {
+	schema, err := newSchema(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		cfg       Config
+		wantErr   string
+	}{
+		{
+			name:  "nested lists",
+			query: `{ collection(id: 1) { name books { title collections { name } } } }`,
+			// collection 1 + name 1 + books 1 + 10 books * (title 1 + collections 1 + 10 collections * name 1).
+			cfg: Config{MaxDepth: 4, MaxComplexity: 123},
+		},
+		{
+			name:    "complexity",
+			query:   `{ collection(id: 1) { name books { title collections { name } } } }`,
+			cfg:     Config{MaxComplexity: 122},
+			wantErr: "query complexity 123 exceeds the limit 122",
+		},
+		{
+			name:    "depth",
+			query:   `{ collection(id: 1) { books { collections { books { id } } } } }`,
+			cfg:     Config{MaxDepth: 4},
+			wantErr: "query depth 5 exceeds the limit 4",
+		},
+		{
+			name:    "page size literal",
+			query:   `{ books(pageSize: 50) { id } }`,
+			cfg:     Config{MaxComplexity: 50},
+			wantErr: "query complexity 51 exceeds the limit 50",
+		},
+		{
+			name: "default page size",
+			// books 1 + 50 books * (id 1 + collections 1 + 10 collections * id 1).
+			query:   `{ books { id collections { id } } }`,
+			cfg:     Config{MaxComplexity: 600},
+			wantErr: "query complexity 601 exceeds the limit 600",
+		},
+		{
+			name: "nested page size",
+			// collection 1 + books 1 + 50 books (the page size is capped) * (collections 1 + 2 collections * id 1).
+			query: `{ collection(id: 1) { books(pageSize: 1000) { collections(pageSize: 2) { id } } } }`,
+			cfg:   Config{MaxComplexity: 152},
+		},
+		{
+			name:      "page size variable",
+			query:     `query Books($size: Int) { books(pageSize: $size) { id } }`,
+			variables: map[string]any{"size": float64(2)},
+			cfg:       Config{MaxComplexity: 3},
+		},
+		{
+			name:    "fragments",
+			query:   `{ book(id: 1) { ...details collections { ... on Collection { books { ...details } } } } } fragment details on Book { id title }`,
+			cfg:     Config{MaxComplexity: 213},
+			wantErr: "query complexity 214 exceeds the limit 213",
+		},
+		{
+			name:  "introspection",
+			query: `{ __schema { types { name fields { name } } } }`,
+			cfg:   Config{MaxDepth: 1, MaxComplexity: 1},
+		},
+		{
+			name:  "disabled",
+			query: `{ collection(id: 1) { books { collections { books { id } } } } }`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: tt.query})
+			require.NoError(t, err)
+
+			err = checkLimits(schema, doc, "", tt.variables, tt.cfg)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package bmgraphql
+
+import (
+	"context"
+	"sync"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	bs "github.com/Tsapen/bm/internal/book-service"
+)
+
+// loader batches loads of values by keys. The executor resolves fields of a level of the query before it calls
+// their thunks, so keys requested by the level are fetched with a single call when the first thunk is called.
+// Values are cached for the lifetime of the loader, that is, for one request.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending map[K]bool
+	keys    []K
+	values  map[K]V
+	errs    map[K]error
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		pending: make(map[K]bool),
+		values:  make(map[K]V),
+		errs:    make(map[K]error),
+	}
+}
+
+// load requests the value of the key and returns a thunk which returns it.
+// Values of missing keys are zero values.
+func (l *loader[K, V]) load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	if !l.done(key) && !l.pending[key] {
+		l.pending[key] = true
+		l.keys = append(l.keys, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if !l.done(key) {
+			l.flush(ctx)
+		}
+
+		return l.values[key], l.errs[key]
+	}
+}
+
+// flush fetches values of all pending keys.
+func (l *loader[K, V]) flush(ctx context.Context) {
+	keys := l.keys
+	l.keys = nil
+	clear(l.pending)
+
+	values, err := l.fetch(ctx, keys)
+	for _, k := range keys {
+		if err != nil {
+			l.errs[k] = err
+
+			continue
+		}
+
+		l.values[k] = values[k]
+	}
+}
+
+func (l *loader[K, V]) done(key K) bool {
+	_, ok := l.values[key]
+	if !ok {
+		_, ok = l.errs[key]
+	}
+
+	return ok
+}
+
+// loaders are the loaders of a request.
+type loaders struct {
+	book              *loader[int64, *bm.Book]
+	collection        *loader[int64, *bm.Collection]
+	booksOfCollection *loader[pageKey, []bm.Book]
+	collectionsOfBook *loader[pageKey, []bm.Collection]
+}
+
+// pageKey identifies a page of books of a collection or of collections of a book.
+type pageKey struct {
+	id       int64
+	page     int64
+	pageSize int64
+}
+
+// loadPages fetches pages of several parents; parents sharing the page and its size are fetched at once.
+func loadPages[V any](fetch func(ctx context.Context, ids []int64, page, pageSize int64) (map[int64]V, error)) *loader[pageKey, V] {
+	return newLoader(func(ctx context.Context, keys []pageKey) (map[pageKey]V, error) {
+		type page struct{ page, pageSize int64 }
+
+		var pages []page
+		ids := make(map[page][]int64)
+		for _, k := range keys {
+			p := page{page: k.page, pageSize: k.pageSize}
+			if _, ok := ids[p]; !ok {
+				pages = append(pages, p)
+			}
+
+			ids[p] = append(ids[p], k.id)
+		}
+
+		values := make(map[pageKey]V, len(keys))
+		for _, p := range pages {
+			byID, err := fetch(ctx, ids[p], p.page, p.pageSize)
+			if err != nil {
+				return nil, err
+			}
+
+			for id, v := range byID {
+				values[pageKey{id: id, page: p.page, pageSize: p.pageSize}] = v
+			}
+		}
+
+		return values, nil
+	})
+}
+
+func newLoaders(bookService *bs.Service) *loaders {
+	return &loaders{
+		book: newLoader(func(ctx context.Context, ids []int64) (map[int64]*bm.Book, error) {
+			books, err := bookService.BooksByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			byID := make(map[int64]*bm.Book, len(books))
+			for i := range books {
+				byID[books[i].ID] = &books[i]
+			}
+
+			return byID, nil
+		}),
+		collection: newLoader(func(ctx context.Context, ids []int64) (map[int64]*bm.Collection, error) {
+			collections, err := bookService.CollectionsByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			byID := make(map[int64]*bm.Collection, len(collections))
+			for i := range collections {
+				byID[collections[i].ID] = &collections[i]
+			}
+
+			return byID, nil
+		}),
+		booksOfCollection: loadPages(bookService.BooksOfCollections),
+		collectionsOfBook: loadPages(bookService.CollectionsOfBooks),
+	}
+}
+
+type cxtKey int
+
+const loadersKey cxtKey = iota
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey, l)
+}
+
+func loadersFromCtx(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey).(*loaders)
+}
@@ -0,0 +1,666 @@
+package bmgraphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	bs "github.com/Tsapen/bm/internal/book-service"
+)
+
+const dateLayout = "2006-01-02"
+
+// resolverError adds the kind of an error of the book service to extensions of the GraphQL error.
+type resolverError struct {
+	err error
+}
+
+func (err resolverError) Error() string {
+	return err.err.Error()
+}
+
+func (err resolverError) Extensions() map[string]any {
+	return map[string]any{"code": errorCode(err.err)}
+}
+
+func errorCode(err error) string {
+	switch {
+	case errors.As(err, &bm.ValidationError{}):
+		return "BAD_USER_INPUT"
+
+	case errors.As(err, &bm.NotFoundError{}):
+		return "NOT_FOUND"
+
+	case errors.As(err, &bm.ConflictError{}):
+		return "CONFLICT"
+
+	case errors.As(err, &bm.UnauthorizedError{}):
+		return "UNAUTHENTICATED"
+
+	case errors.As(err, &bm.ForbiddenError{}):
+		return "FORBIDDEN"
+
+	default:
+		return "INTERNAL_SERVER_ERROR"
+	}
+}
+
+// resolver is a resolve function which returns errors of the book service with their codes.
+func resolver(resolve func(p graphql.ResolveParams) (any, error)) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		v, err := resolve(p)
+		if err != nil {
+			return nil, resolverError{err}
+		}
+
+		return v, nil
+	}
+}
+
+// thunk defers a load until the executor needs the value, so loads of a level of the query are batched.
+func thunk[V any](load func() (V, error), convert func(V) any) func() (any, error) {
+	return func() (any, error) {
+		v, err := load()
+		if err != nil {
+			return nil, resolverError{err}
+		}
+
+		return convert(v), nil
+	}
+}
+
+func bookField(t graphql.Output, get func(b bm.Book) any) *graphql.Field {
+	return &graphql.Field{
+		Type: t,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return get(p.Source.(bm.Book)), nil
+		},
+	}
+}
+
+func collectionField(t graphql.Output, get func(c bm.Collection) any) *graphql.Field {
+	return &graphql.Field{
+		Type: t,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return get(p.Source.(bm.Collection)), nil
+		},
+	}
+}
+
+// bookValue returns a loaded book as a value, the executor treats a nil *bm.Book as null.
+func bookValue(b *bm.Book) any {
+	if b == nil {
+		return nil
+	}
+
+	return *b
+}
+
+func collectionValue(c *bm.Collection) any {
+	if c == nil {
+		return nil
+	}
+
+	return *c
+}
+
+func booksValue(books []bm.Book) any {
+	if books == nil {
+		return []bm.Book{}
+	}
+
+	return books
+}
+
+func collectionsValue(collections []bm.Collection) any {
+	if collections == nil {
+		return []bm.Collection{}
+	}
+
+	return collections
+}
+
+func newSchema(bookService *bs.Service) (graphql.Schema, error) {
+	nonNullString := graphql.NewNonNull(graphql.String)
+	nonNullID := graphql.NewNonNull(graphql.ID)
+
+	contributorType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Contributor",
+		Fields: graphql.Fields{
+			"authorId": &graphql.Field{
+				Type: nonNullID,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(bm.Contributor).AuthorID, nil
+				},
+			},
+			"name": &graphql.Field{
+				Type: nonNullString,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(bm.Contributor).Name, nil
+				},
+			},
+			"role": &graphql.Field{
+				Type:        nonNullString,
+				Description: "author, editor, translator or illustrator.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(bm.Contributor).Role, nil
+				},
+			},
+		},
+	})
+
+	var collectionType *graphql.Object
+	bookType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Book",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":     bookField(nonNullID, func(b bm.Book) any { return b.ID }),
+				"title":  bookField(nonNullString, func(b bm.Book) any { return b.Title }),
+				"author": bookField(nonNullString, func(b bm.Book) any { return b.Author }),
+				"publishedDate": bookField(graphql.String, func(b bm.Book) any {
+					if b.PublishedDate.IsZero() {
+						return nil
+					}
+
+					return b.PublishedDate.Format(dateLayout)
+				}),
+				"edition":       bookField(nonNullString, func(b bm.Book) any { return b.Edition }),
+				"description":   bookField(nonNullString, func(b bm.Book) any { return b.Description }),
+				"genre":         bookField(nonNullString, func(b bm.Book) any { return b.Genre }),
+				"genreId":       bookField(graphql.ID, func(b bm.Book) any { return nonZero(b.GenreID) }),
+				"isbn":          bookField(graphql.String, func(b bm.Book) any { return nonEmpty(b.ISBN) }),
+				"contributors":  bookField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(contributorType))), func(b bm.Book) any { return b.Contributors }),
+				"tags":          bookField(graphql.NewNonNull(graphql.NewList(nonNullString)), func(b bm.Book) any { return nonNilStrings(b.Tags) }),
+				"averageRating": bookField(graphql.NewNonNull(graphql.Float), func(b bm.Book) any { return b.AverageRating }),
+				"reviewCount":   bookField(graphql.NewNonNull(graphql.Int), func(b bm.Book) any { return b.ReviewCount }),
+				"copyCount":     bookField(graphql.NewNonNull(graphql.Int), func(b bm.Book) any { return b.CopyCount }),
+				"hasCover":      bookField(graphql.NewNonNull(graphql.Boolean), func(b bm.Book) any { return b.Cover != "" }),
+				"collections": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(collectionType))),
+					Args: nestedPageArgs(),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						b := p.Source.(bm.Book)
+						k := pageKey{id: b.ID, page: intArg(p.Args, "page"), pageSize: intArg(p.Args, "pageSize")}
+
+						return thunk(loadersFromCtx(p.Context).collectionsOfBook.load(p.Context, k), collectionsValue), nil
+					},
+				},
+			}
+		}),
+	})
+
+	collectionType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Collection",
+		Fields: graphql.Fields{
+			"id":          collectionField(nonNullID, func(c bm.Collection) any { return c.ID }),
+			"name":        collectionField(nonNullString, func(c bm.Collection) any { return c.Name }),
+			"description": collectionField(nonNullString, func(c bm.Collection) any { return c.Description }),
+			"books": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(bookType))),
+				Args: nestedPageArgs(),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					c := p.Source.(bm.Collection)
+					k := pageKey{id: c.ID, page: intArg(p.Args, "page"), pageSize: intArg(p.Args, "pageSize")}
+
+					return thunk(loadersFromCtx(p.Context).booksOfCollection.load(p.Context, k), booksValue), nil
+				},
+			},
+		},
+	})
+
+	pageArgs := graphql.FieldConfigArgument{
+		"orderBy":  &graphql.ArgumentConfig{Type: graphql.String},
+		"desc":     &graphql.ArgumentConfig{Type: graphql.Boolean},
+		"page":     &graphql.ArgumentConfig{Type: graphql.Int},
+		"pageSize": &graphql.ArgumentConfig{Type: graphql.Int, Description: "At most 50 items are returned."},
+	}
+
+	booksArgs := graphql.FieldConfigArgument{
+		"query":        &graphql.ArgumentConfig{Type: graphql.String, Description: "A part of the title or author line."},
+		"author":       &graphql.ArgumentConfig{Type: graphql.String},
+		"genre":        &graphql.ArgumentConfig{Type: graphql.String},
+		"tags":         &graphql.ArgumentConfig{Type: graphql.NewList(nonNullString)},
+		"tagsMatch":    &graphql.ArgumentConfig{Type: graphql.String, Description: "any or all."},
+		"status":       &graphql.ArgumentConfig{Type: graphql.String},
+		"collectionId": &graphql.ArgumentConfig{Type: graphql.ID},
+	}
+	for name, arg := range pageArgs {
+		booksArgs[name] = arg
+	}
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"book": &graphql.Field{
+				Type: bookType,
+				Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: nonNullID}},
+				Resolve: resolver(func(p graphql.ResolveParams) (any, error) {
+					id, err := idArg(p.Args, "id")
+					if err != nil {
+						return nil, err
+					}
+
+					return thunk(loadersFromCtx(p.Context).book.load(p.Context, id), bookValue), nil
+				}),
+			},
+			"books": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(bookType))),
+				Args: booksArgs,
+				Resolve: resolver(func(p graphql.ResolveParams) (any, error) {
+					cID, err := idArg(p.Args, "collectionId")
+					if err != nil {
+						return nil, err
+					}
+
+					books, err := bookService.Books(p.Context, bm.BookFilter{
+						Query:        stringArg(p.Args, "query"),
+						Author:       stringArg(p.Args, "author"),
+						Genre:        stringArg(p.Args, "genre"),
+						Tags:         stringsArg(p.Args, "tags"),
+						TagsMatch:    stringArg(p.Args, "tagsMatch"),
+						Status:       stringArg(p.Args, "status"),
+						CollectionID: cID,
+						OrderBy:      stringArg(p.Args, "orderBy"),
+						Desc:         boolArg(p.Args, "desc"),
+						Page:         intArg(p.Args, "page"),
+						PageSize:     intArg(p.Args, "pageSize"),
+					})
+					if err != nil {
+						return nil, fmt.Errorf("get books: %w", err)
+					}
+
+					return booksValue(books), nil
+				}),
+			},
+			"collection": &graphql.Field{
+				Type: collectionType,
+				Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: nonNullID}},
+				Resolve: resolver(func(p graphql.ResolveParams) (any, error) {
+					id, err := idArg(p.Args, "id")
+					if err != nil {
+						return nil, err
+					}
+
+					return thunk(loadersFromCtx(p.Context).collection.load(p.Context, id), collectionValue), nil
+				}),
+			},
+			"collections": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(collectionType))),
+				Args: pageArgs,
+				Resolve: resolver(func(p graphql.ResolveParams) (any, error) {
+					collections, err := bookService.Collections(p.Context, bm.CollectionsFilter{
+						OrderBy:  stringArg(p.Args, "orderBy"),
+						Desc:     boolArg(p.Args, "desc"),
+						Page:     intArg(p.Args, "page"),
+						PageSize: intArg(p.Args, "pageSize"),
+					})
+					if err != nil {
+						return nil, fmt.Errorf("get collections: %w", err)
+					}
+
+					return collectionsValue(collections), nil
+				}),
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    queryType,
+		Mutation: newMutationType(bookService, bookType, collectionType),
+	})
+}
+
+func newMutationType(bookService *bs.Service, bookType, collectionType *graphql.Object) *graphql.Object {
+	nonNullID := graphql.NewNonNull(graphql.ID)
+	nonNullIDs := graphql.NewNonNull(graphql.NewList(nonNullID))
+
+	contributorInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "ContributorInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"authorId": &graphql.InputObjectFieldConfig{Type: graphql.ID},
+			"name":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"role":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	bookInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "BookInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":         &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"author":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"contributors":  &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(contributorInput))},
+			"publishedDate": &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "A date in the YYYY-MM-DD format."},
+			"edition":       &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"description":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"genre":         &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"genreId":       &graphql.InputObjectFieldConfig{Type: graphql.ID},
+			"isbn":          &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"tags":          &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+		},
+	})
+
+	collectionInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CollectionInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":        &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	// Mutated objects are read again from the book service, the loaders of the request may already have cached them.
+	getBook := func(ctx context.Context, id int64) (any, error) {
+		book, err := bookService.Book(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("get book: %w", err)
+		}
+
+		return *book, nil
+	}
+
+	getCollection := func(ctx context.Context, id int64) (any, error) {
+		c, err := bookService.Collection(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("get collection: %w", err)
+		}
+
+		return *c, nil
+	}
+
+	changeBooksCollection := func(change func(ctx context.Context, cID int64, bookIDs []int64) error) graphql.FieldResolveFn {
+		return resolver(func(p graphql.ResolveParams) (any, error) {
+			cID, err := idArg(p.Args, "collectionId")
+			if err != nil {
+				return nil, err
+			}
+
+			bookIDs, err := idsArg(p.Args, "bookIds")
+			if err != nil {
+				return nil, err
+			}
+
+			if err = change(p.Context, cID, bookIDs); err != nil {
+				return nil, err
+			}
+
+			return getCollection(p.Context, cID)
+		})
+	}
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createBook": &graphql.Field{
+				Type: graphql.NewNonNull(bookType),
+				Args: graphql.FieldConfigArgument{"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(bookInput)}},
+				Resolve: resolver(func(p graphql.ResolveParams) (any, error) {
+					book, err := newBook(p.Args["input"].(map[string]any))
+					if err != nil {
+						return nil, err
+					}
+
+					id, err := bookService.CreateBook(p.Context, book)
+					if err != nil {
+						return nil, fmt.Errorf("create book: %w", err)
+					}
+
+					return getBook(p.Context, id)
+				}),
+			},
+			"updateBook": &graphql.Field{
+				Type: graphql.NewNonNull(bookType),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: nonNullID},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(bookInput)},
+				},
+				Resolve: resolver(func(p graphql.ResolveParams) (any, error) {
+					id, err := idArg(p.Args, "id")
+					if err != nil {
+						return nil, err
+					}
+
+					book, err := newBook(p.Args["input"].(map[string]any))
+					if err != nil {
+						return nil, err
+					}
+
+					book.ID = id
+					if err = bookService.UpdateBook(p.Context, book); err != nil {
+						return nil, fmt.Errorf("update book: %w", err)
+					}
+
+					return getBook(p.Context, id)
+				}),
+			},
+			"deleteBooks": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"ids":   &graphql.ArgumentConfig{Type: nonNullIDs},
+					"force": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false, Description: "Delete books on loan."},
+				},
+				Resolve: resolver(func(p graphql.ResolveParams) (any, error) {
+					ids, err := idsArg(p.Args, "ids")
+					if err != nil {
+						return nil, err
+					}
+
+					if err = bookService.DeleteBooks(p.Context, ids, boolArg(p.Args, "force")); err != nil {
+						return nil, fmt.Errorf("delete books: %w", err)
+					}
+
+					return true, nil
+				}),
+			},
+			"createCollection": &graphql.Field{
+				Type: graphql.NewNonNull(collectionType),
+				Args: graphql.FieldConfigArgument{"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(collectionInput)}},
+				Resolve: resolver(func(p graphql.ResolveParams) (any, error) {
+					input := p.Args["input"].(map[string]any)
+					id, err := bookService.CreateCollection(p.Context, bm.Collection{
+						Name:        stringArg(input, "name"),
+						Description: stringArg(input, "description"),
+					})
+					if err != nil {
+						return nil, fmt.Errorf("create collection: %w", err)
+					}
+
+					return getCollection(p.Context, id)
+				}),
+			},
+			"updateCollection": &graphql.Field{
+				Type: graphql.NewNonNull(collectionType),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: nonNullID},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(collectionInput)},
+				},
+				Resolve: resolver(func(p graphql.ResolveParams) (any, error) {
+					id, err := idArg(p.Args, "id")
+					if err != nil {
+						return nil, err
+					}
+
+					input := p.Args["input"].(map[string]any)
+					err = bookService.UpdateCollection(p.Context, bm.Collection{
+						ID:          id,
+						Name:        stringArg(input, "name"),
+						Description: stringArg(input, "description"),
+					})
+					if err != nil {
+						return nil, fmt.Errorf("update collection: %w", err)
+					}
+
+					return getCollection(p.Context, id)
+				}),
+			},
+			"deleteCollection": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: nonNullID}},
+				Resolve: resolver(func(p graphql.ResolveParams) (any, error) {
+					id, err := idArg(p.Args, "id")
+					if err != nil {
+						return nil, err
+					}
+
+					if err = bookService.DeleteCollection(p.Context, id); err != nil {
+						return nil, fmt.Errorf("delete collection: %w", err)
+					}
+
+					return true, nil
+				}),
+			},
+			"addBooksToCollection": &graphql.Field{
+				Type: graphql.NewNonNull(collectionType),
+				Args: graphql.FieldConfigArgument{
+					"collectionId": &graphql.ArgumentConfig{Type: nonNullID},
+					"bookIds":      &graphql.ArgumentConfig{Type: nonNullIDs},
+				},
+				Resolve: changeBooksCollection(bookService.CreateBooksCollection),
+			},
+			"removeBooksFromCollection": &graphql.Field{
+				Type: graphql.NewNonNull(collectionType),
+				Args: graphql.FieldConfigArgument{
+					"collectionId": &graphql.ArgumentConfig{Type: nonNullID},
+					"bookIds":      &graphql.ArgumentConfig{Type: nonNullIDs},
+				},
+				Resolve: changeBooksCollection(bookService.DeleteBooksCollection),
+			},
+		},
+	})
+}
+
+// newBook converts a BookInput.
+func newBook(input map[string]any) (bm.Book, error) {
+	genreID, err := idArg(input, "genreId")
+	if err != nil {
+		return bm.Book{}, err
+	}
+
+	book := bm.Book{
+		Title:       stringArg(input, "title"),
+		Author:      stringArg(input, "author"),
+		Edition:     stringArg(input, "edition"),
+		Description: stringArg(input, "description"),
+		Genre:       stringArg(input, "genre"),
+		GenreID:     genreID,
+		ISBN:        stringArg(input, "isbn"),
+		Tags:        stringsArg(input, "tags"),
+	}
+
+	if date := stringArg(input, "publishedDate"); date != "" {
+		if book.PublishedDate, err = time.Parse(dateLayout, date); err != nil {
+			return bm.Book{}, bm.NewValidationError("incorrect publishedDate: %w", err)
+		}
+	}
+
+	contributors, _ := input["contributors"].([]any)
+	for _, c := range contributors {
+		c := c.(map[string]any)
+		authorID, err := idArg(c, "authorId")
+		if err != nil {
+			return bm.Book{}, err
+		}
+
+		book.Contributors = append(book.Contributors, bm.Contributor{
+			AuthorID: authorID,
+			Name:     stringArg(c, "name"),
+			Role:     stringArg(c, "role"),
+		})
+	}
+
+	return book, nil
+}
+
+// idArg parses an optional ID argument, a missing one is 0.
+func idArg(args map[string]any, name string) (int64, error) {
+	s, ok := args[name].(string)
+	if !ok {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, bm.NewValidationError("incorrect %s", name)
+	}
+
+	return id, nil
+}
+
+func idsArg(args map[string]any, name string) ([]int64, error) {
+	values, _ := args[name].([]any)
+	ids := make([]int64, 0, len(values))
+	for _, v := range values {
+		id, err := idArg(map[string]any{name: v}, name)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func stringArg(args map[string]any, name string) string {
+	s, _ := args[name].(string)
+
+	return s
+}
+
+func stringsArg(args map[string]any, name string) []string {
+	values, _ := args[name].([]any)
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		strs = append(strs, v.(string))
+	}
+
+	return strs
+}
+
+func boolArg(args map[string]any, name string) bool {
+	b, _ := args[name].(bool)
+
+	return b
+}
+
+// nestedPageArgs paginate lists of books of a collection and collections of a book ordered by id.
+// Their default page is short, since they are repeated for every item of the parent list.
+func nestedPageArgs() graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"page":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+		"pageSize": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultListSize, Description: "At most 50 items are returned."},
+	}
+}
+
+func intArg(args map[string]any, name string) int64 {
+	i, _ := args[name].(int)
+
+	return int64(i)
+}
+
+func nonZero(id int64) any {
+	if id == 0 {
+		return nil
+	}
+
+	return id
+}
+
+func nonEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+
+	return s
+}
@@ -0,0 +1,206 @@
+package bmgrpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/Tsapen/bm/internal/auth"
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/internal/bm-grpc/bmpb"
+	bs "github.com/Tsapen/bm/internal/book-service"
+	ratelimit "github.com/Tsapen/bm/internal/rate-limit"
+	unixsocket "github.com/Tsapen/bm/internal/unix-socket"
+)
+
+// Server serves the gRPC API over tcp, a unix socket or both. An empty address disables its transport.
+type Server struct {
+	cfg Config
+
+	grpcServer *grpc.Server
+}
+
+// Config of the server. TLS secures the tcp address, Auth and RateLimit are shared with the HTTP server,
+// but calls are limited apart from HTTP requests.
+type Config struct {
+	Addr       string
+	SocketPath string
+	Socket     unixsocket.Config
+	TLS        auth.TLSConfig
+	Auth       auth.Config
+	RateLimit  ratelimit.Config
+}
+
+// NewServer constructs a gRPC server on top of the book service. Callers are identified and peers of the unix
+// socket are authorized like the ones of the HTTP server.
+func NewServer(cfg Config, bookService *bs.Service) (*Server, error) {
+	peers, err := unixsocket.NewAuthorizer(cfg.Socket.Peers)
+	if err != nil {
+		return nil, fmt.Errorf("configure socket peers: %w", err)
+	}
+
+	var creds peerCredentials
+	if cfg.TLS.Enabled() {
+		reloader, err := auth.NewCertReloader(cfg.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("load tls files: %w", err)
+		}
+
+		creds.tls = credentials.NewTLS(reloader.TLSConfig())
+	}
+
+	a := newAuthenticator(cfg.Auth, peers)
+	rl := newRateLimiter(cfg.RateLimit, a.identify)
+	s := grpc.NewServer(
+		grpc.Creds(creds),
+		grpc.ChainUnaryInterceptor(recoverUnary, rl.unaryInterceptor, a.unaryInterceptor),
+		grpc.ChainStreamInterceptor(recoverStream, rl.streamInterceptor, a.streamInterceptor),
+	)
+
+	bmpb.RegisterBookServiceServer(s, &bookServer{bookService: bookService})
+	bmpb.RegisterCollectionServiceServer(s, &collectionServer{bookService: bookService})
+
+	return &Server{
+		cfg:        cfg,
+		grpcServer: s,
+	}, nil
+}
+
+// StartTCPServer runs server with tcp as transport.
+func (s *Server) StartTCPServer() error {
+	log.Info().Msgf("gRPC server (tcp) started to listen %s", s.cfg.Addr)
+
+	listener, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+
+	return s.grpcServer.Serve(listener)
+}
+
+// StartUnixSocketServer runs server with unix-socket as transport.
+func (s *Server) StartUnixSocketServer() error {
+	listener, err := unixsocket.Listen(s.cfg.SocketPath, s.cfg.Socket)
+	if err != nil {
+		return fmt.Errorf("listen unix socket: %w", err)
+	}
+
+	log.Info().Msgf("gRPC server (unix-socket) started to listen %s", s.cfg.SocketPath)
+
+	return s.grpcServer.Serve(listener)
+}
+
+type authenticator struct {
+	resolver *auth.Resolver
+	peers    *unixsocket.Authorizer
+}
+
+func newAuthenticator(cfg auth.Config, peers *unixsocket.Authorizer) *authenticator {
+	return &authenticator{
+		resolver: auth.NewResolver(cfg),
+		peers:    peers,
+	}
+}
+
+// identify resolves the caller by the authorization metadata or the client certificate, like the HTTP API does.
+func (a *authenticator) identify(ctx context.Context) (bm.Identity, error) {
+	var c auth.Credentials
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(peerInfo); ok {
+			c.Cert = info.cert
+			c.Socket = info.unix
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) != 0 {
+		c.Authorization = values[0]
+	}
+
+	return a.resolver.Identify(c)
+}
+
+// callContext authenticates a call and adds the request id and the caller identity into its context.
+func (a *authenticator) callContext(ctx context.Context, method string) (context.Context, error) {
+	ctx = bm.WithReqID(ctx, uuid.NewString())
+	peerCtx, err := authorizePeer(ctx, a.peers, method)
+	if err != nil {
+		log.Info().Err(err).Str("method", method).Str("request_id", bm.ReqIDFromCtx(ctx)).Msg("failed to authorize socket peer")
+
+		return nil, grpcError(fmt.Errorf("authorize socket peer: %w", err))
+	}
+
+	ctx = peerCtx
+	id, err := a.identify(ctx)
+	if err != nil {
+		log.Info().Err(err).Str("method", method).Str("request_id", bm.ReqIDFromCtx(ctx)).Msg("failed to authenticate")
+
+		return nil, grpcError(fmt.Errorf("authenticate: %w", err))
+	}
+
+	if id.ReadOnly && isWriteMethod(method) {
+		return nil, grpcError(bm.NewUnauthorizedError("api key is required to change the library"))
+	}
+
+	ctx = bm.WithIdentity(ctx, id)
+	log.Info().Str("method", method).Str("request_id", bm.ReqIDFromCtx(ctx)).Str("tenant", id.Tenant).Msg("received request")
+
+	return ctx, nil
+}
+
+func (a *authenticator) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.callContext(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := handler(ctx, req)
+	if err != nil {
+		logFailure(ctx, info.FullMethod, err)
+
+		return nil, grpcError(err)
+	}
+
+	log.Info().Str("method", info.FullMethod).Str("request_id", bm.ReqIDFromCtx(ctx)).Msg("finish processing")
+
+	return resp, nil
+}
+
+func (a *authenticator) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.callContext(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	if err = handler(srv, &serverStream{ServerStream: ss, ctx: ctx}); err != nil {
+		logFailure(ctx, info.FullMethod, err)
+
+		return grpcError(err)
+	}
+
+	log.Info().Str("method", info.FullMethod).Str("request_id", bm.ReqIDFromCtx(ctx)).Msg("finish processing")
+
+	return nil
+}
+
+func logFailure(ctx context.Context, method string, err error) {
+	log.Info().Err(err).Str("method", method).Str("request_id", bm.ReqIDFromCtx(ctx)).Str("code", grpcCode(err).String()).Msg("failed to process message")
+}
+
+// serverStream replaces the context of a stream with the authenticated one.
+type serverStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
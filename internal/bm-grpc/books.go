@@ -0,0 +1,142 @@
+package bmgrpc
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/internal/bm-grpc/bmpb"
+	bs "github.com/Tsapen/bm/internal/book-service"
+)
+
+type bookServer struct {
+	bmpb.UnimplementedBookServiceServer
+
+	bookService *bs.Service
+}
+
+func (s *bookServer) GetBook(ctx context.Context, r *bmpb.GetBookRequest) (*bmpb.Book, error) {
+	book, err := s.bookService.Book(ctx, r.GetId())
+	if err != nil {
+		return nil, fmt.Errorf("get book: %w", err)
+	}
+
+	return newPBBook(*book), nil
+}
+
+func (s *bookServer) ListBooks(r *bmpb.ListBooksRequest, stream bmpb.BookService_ListBooksServer) error {
+	f := bm.BookFilter{
+		Query:        r.GetQuery(),
+		Title:        r.GetTitle(),
+		Author:       r.GetAuthor(),
+		AuthorID:     r.GetAuthorId(),
+		Genre:        r.GetGenre(),
+		GenreID:      r.GetGenreId(),
+		Tags:         r.GetTags(),
+		TagsMatch:    r.GetTagsMatch(),
+		Status:       r.GetStatus(),
+		ISBN:         r.GetIsbn(),
+		CollectionID: r.GetCollectionId(),
+		OrderBy:      r.GetOrderBy(),
+		Desc:         r.GetDesc(),
+	}
+
+	err := s.bookService.EachBook(stream.Context(), f, func(b bm.Book) error {
+		return stream.Send(newPBBook(b))
+	})
+	if err != nil {
+		return fmt.Errorf("list books: %w", err)
+	}
+
+	return nil
+}
+
+func (s *bookServer) CreateBook(ctx context.Context, r *bmpb.CreateBookRequest) (*bmpb.CreateBookResponse, error) {
+	if r.GetBook() == nil {
+		return nil, bm.NewValidationError("book is empty")
+	}
+
+	id, err := s.bookService.CreateBook(ctx, newBMBook(r.GetBook()))
+	if err != nil {
+		return nil, fmt.Errorf("create book: %w", err)
+	}
+
+	return &bmpb.CreateBookResponse{Id: id}, nil
+}
+
+func (s *bookServer) UpdateBook(ctx context.Context, r *bmpb.UpdateBookRequest) (*bmpb.UpdateBookResponse, error) {
+	if r.GetBook() == nil {
+		return nil, bm.NewValidationError("book is empty")
+	}
+
+	if err := s.bookService.UpdateBook(ctx, newBMBook(r.GetBook())); err != nil {
+		return nil, fmt.Errorf("update book: %w", err)
+	}
+
+	return &bmpb.UpdateBookResponse{}, nil
+}
+
+func (s *bookServer) DeleteBooks(ctx context.Context, r *bmpb.DeleteBooksRequest) (*bmpb.DeleteBooksResponse, error) {
+	if err := s.bookService.DeleteBooks(ctx, r.GetIds(), r.GetForce()); err != nil {
+		return nil, fmt.Errorf("delete books: %w", err)
+	}
+
+	return &bmpb.DeleteBooksResponse{}, nil
+}
+
+func newPBBook(b bm.Book) *bmpb.Book {
+	book := &bmpb.Book{
+		Id:            b.ID,
+		Title:         b.Title,
+		Author:        b.Author,
+		Edition:       b.Edition,
+		Description:   b.Description,
+		Genre:         b.Genre,
+		GenreId:       b.GenreID,
+		Isbn:          b.ISBN,
+		Contributors:  make([]*bmpb.Contributor, 0, len(b.Contributors)),
+		Tags:          b.Tags,
+		AverageRating: b.AverageRating,
+		ReviewCount:   b.ReviewCount,
+		CopyCount:     b.CopyCount,
+		HasCover:      b.Cover != "",
+	}
+
+	if !b.PublishedDate.IsZero() {
+		book.PublishedDate = timestamppb.New(b.PublishedDate)
+	}
+
+	for _, c := range b.Contributors {
+		book.Contributors = append(book.Contributors, &bmpb.Contributor{AuthorId: c.AuthorID, Name: c.Name, Role: c.Role})
+	}
+
+	return book
+}
+
+// newBMBook converts a book of a request. Read only fields are ignored.
+func newBMBook(b *bmpb.Book) bm.Book {
+	book := bm.Book{
+		ID:           b.GetId(),
+		Title:        b.GetTitle(),
+		Author:       b.GetAuthor(),
+		Edition:      b.GetEdition(),
+		Description:  b.GetDescription(),
+		Genre:        b.GetGenre(),
+		GenreID:      b.GetGenreId(),
+		ISBN:         b.GetIsbn(),
+		Contributors: make([]bm.Contributor, 0, len(b.GetContributors())),
+		Tags:         b.GetTags(),
+	}
+
+	if b.GetPublishedDate() != nil {
+		book.PublishedDate = b.GetPublishedDate().AsTime()
+	}
+
+	for _, c := range b.GetContributors() {
+		book.Contributors = append(book.Contributors, bm.Contributor{AuthorID: c.GetAuthorId(), Name: c.GetName(), Role: c.GetRole()})
+	}
+
+	return book
+}
@@ -0,0 +1,101 @@
+package bmgrpc
+
+import (
+	"context"
+	"fmt"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/internal/bm-grpc/bmpb"
+	bs "github.com/Tsapen/bm/internal/book-service"
+)
+
+type collectionServer struct {
+	bmpb.UnimplementedCollectionServiceServer
+
+	bookService *bs.Service
+}
+
+func (s *collectionServer) GetCollection(ctx context.Context, r *bmpb.GetCollectionRequest) (*bmpb.Collection, error) {
+	c, err := s.bookService.Collection(ctx, r.GetId())
+	if err != nil {
+		return nil, fmt.Errorf("get collection: %w", err)
+	}
+
+	return newPBCollection(*c), nil
+}
+
+func (s *collectionServer) ListCollections(ctx context.Context, r *bmpb.ListCollectionsRequest) (*bmpb.ListCollectionsResponse, error) {
+	collections, err := s.bookService.Collections(ctx, bm.CollectionsFilter{
+		OrderBy:  r.GetOrderBy(),
+		Desc:     r.GetDesc(),
+		Page:     r.GetPage(),
+		PageSize: r.GetPageSize(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get collections: %w", err)
+	}
+
+	resp := &bmpb.ListCollectionsResponse{Collections: make([]*bmpb.Collection, 0, len(collections))}
+	for _, c := range collections {
+		resp.Collections = append(resp.Collections, newPBCollection(c))
+	}
+
+	return resp, nil
+}
+
+func (s *collectionServer) CreateCollection(ctx context.Context, r *bmpb.CreateCollectionRequest) (*bmpb.CreateCollectionResponse, error) {
+	if r.GetCollection() == nil {
+		return nil, bm.NewValidationError("collection is empty")
+	}
+
+	id, err := s.bookService.CreateCollection(ctx, newBMCollection(r.GetCollection()))
+	if err != nil {
+		return nil, fmt.Errorf("create collection: %w", err)
+	}
+
+	return &bmpb.CreateCollectionResponse{Id: id}, nil
+}
+
+func (s *collectionServer) UpdateCollection(ctx context.Context, r *bmpb.UpdateCollectionRequest) (*bmpb.UpdateCollectionResponse, error) {
+	if r.GetCollection() == nil {
+		return nil, bm.NewValidationError("collection is empty")
+	}
+
+	if err := s.bookService.UpdateCollection(ctx, newBMCollection(r.GetCollection())); err != nil {
+		return nil, fmt.Errorf("update collection: %w", err)
+	}
+
+	return &bmpb.UpdateCollectionResponse{}, nil
+}
+
+func (s *collectionServer) DeleteCollection(ctx context.Context, r *bmpb.DeleteCollectionRequest) (*bmpb.DeleteCollectionResponse, error) {
+	if err := s.bookService.DeleteCollection(ctx, r.GetId()); err != nil {
+		return nil, fmt.Errorf("delete collection: %w", err)
+	}
+
+	return &bmpb.DeleteCollectionResponse{}, nil
+}
+
+func (s *collectionServer) AddBooks(ctx context.Context, r *bmpb.AddBooksRequest) (*bmpb.AddBooksResponse, error) {
+	if err := s.bookService.CreateBooksCollection(ctx, r.GetCollectionId(), r.GetBookIds()); err != nil {
+		return nil, fmt.Errorf("add books to collection: %w", err)
+	}
+
+	return &bmpb.AddBooksResponse{}, nil
+}
+
+func (s *collectionServer) RemoveBooks(ctx context.Context, r *bmpb.RemoveBooksRequest) (*bmpb.RemoveBooksResponse, error) {
+	if err := s.bookService.DeleteBooksCollection(ctx, r.GetCollectionId(), r.GetBookIds()); err != nil {
+		return nil, fmt.Errorf("remove books from collection: %w", err)
+	}
+
+	return &bmpb.RemoveBooksResponse{}, nil
+}
+
+func newPBCollection(c bm.Collection) *bmpb.Collection {
+	return &bmpb.Collection{Id: c.ID, Name: c.Name, Description: c.Description}
+}
+
+func newBMCollection(c *bmpb.Collection) bm.Collection {
+	return bm.Collection{ID: c.GetId(), Name: c.GetName(), Description: c.GetDescription()}
+}
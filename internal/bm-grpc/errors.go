@@ -0,0 +1,63 @@
+package bmgrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Tsapen/bm/internal/bm"
+)
+
+// rateLimitError implements error interface.
+type rateLimitError struct {
+	retryAfter time.Duration
+}
+
+func (err rateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded, retry after %s", err.retryAfter)
+}
+
+// grpcCode maps errors of the book service to status codes like httpStatus of the HTTP API maps them to
+// HTTP statuses.
+func grpcCode(err error) codes.Code {
+	switch {
+	case errors.As(err, &bm.ValidationError{}):
+		return codes.InvalidArgument
+
+	case errors.As(err, &bm.NotFoundError{}):
+		return codes.NotFound
+
+	case errors.As(err, &bm.ConflictError{}):
+		return codes.AlreadyExists
+
+	case errors.As(err, &bm.UnauthorizedError{}):
+		return codes.Unauthenticated
+
+	case errors.As(err, &bm.ForbiddenError{}):
+		return codes.PermissionDenied
+
+	case errors.As(err, &rateLimitError{}):
+		return codes.ResourceExhausted
+
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	}
+
+	// Errors of the stream itself already carry a status.
+	if s, ok := status.FromError(err); ok {
+		return s.Code()
+	}
+
+	return codes.Internal
+}
+
+func grpcError(err error) error {
+	return status.Error(grpcCode(err), err.Error())
+}
@@ -0,0 +1,136 @@
+package bmgrpc
+
+import (
+	"context"
+	"net"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	ratelimit "github.com/Tsapen/bm/internal/rate-limit"
+)
+
+// bulkMethods change many resources at once, they are limited like bulk routes of the HTTP API.
+var bulkMethods = map[string]bool{
+	"DeleteBooks": true,
+	"AddBooks":    true,
+	"RemoveBooks": true,
+}
+
+// recoverUnary turns a panic of the call into the internal error, the server keeps serving other calls.
+func recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(info.FullMethod, r)
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+// recoverStream turns a panic of the stream into the internal error.
+func recoverStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(info.FullMethod, r)
+		}
+	}()
+
+	return handler(srv, ss)
+}
+
+func recovered(method string, r any) error {
+	log.Error().Str("method", method).Interface("panic", r).Bytes("stack", debug.Stack()).Msg("recover from panic")
+
+	return status.Error(codes.Internal, "internal error")
+}
+
+// rateLimiter runs before authentication, so attempts to guess api keys are limited too.
+type rateLimiter struct {
+	limiter  *ratelimit.Limiter
+	identify func(ctx context.Context) (bm.Identity, error)
+	now      func() time.Time
+}
+
+func newRateLimiter(cfg ratelimit.Config, identify func(ctx context.Context) (bm.Identity, error)) *rateLimiter {
+	return &rateLimiter{
+		limiter:  ratelimit.New(cfg),
+		identify: identify,
+		now:      time.Now,
+	}
+}
+
+// limit takes a token of the caller. Callers which fail authentication are limited by their address.
+func (rl *rateLimiter) limit(ctx context.Context, method string) error {
+	id, err := rl.identify(ctx)
+	if err != nil {
+		id = bm.Identity{}
+	}
+
+	q, limited := rl.limiter.Take(classifyMethod(method), clientKey(ctx, id), rl.now())
+	if !limited || q.Allowed {
+		return nil
+	}
+
+	return grpcError(rateLimitError{retryAfter: q.RetryAfter})
+}
+
+func (rl *rateLimiter) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := rl.limit(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (rl *rateLimiter) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := rl.limit(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, ss)
+}
+
+func classifyMethod(method string) ratelimit.Class {
+	if bulkMethods[method[strings.LastIndex(method, "/")+1:]] {
+		return ratelimit.Bulk
+	}
+
+	if isWriteMethod(method) {
+		return ratelimit.Write
+	}
+
+	return ratelimit.Read
+}
+
+// clientKey identifies a client by the tenant and the credential of its identity, other callers by unix socket
+// peer or remote address, like the HTTP API does.
+func clientKey(ctx context.Context, id bm.Identity) string {
+	if id.Key != "" {
+		return id.Tenant + "/" + id.Key
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if info, ok := p.AuthInfo.(peerInfo); ok && info.known {
+		return "uid:" + strconv.FormatUint(uint64(info.cred.UID), 10)
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return "ip:" + p.Addr.String()
+	}
+
+	return "ip:" + host
+}
@@ -0,0 +1,86 @@
+package bmgrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/Tsapen/bm/internal/auth"
+	"github.com/Tsapen/bm/internal/bm-grpc/bmpb"
+	ratelimit "github.com/Tsapen/bm/internal/rate-limit"
+	unixsocket "github.com/Tsapen/bm/internal/unix-socket"
+)
+
+func TestRecover(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: bmpb.BookService_GetBook_FullMethodName}
+	_, err := recoverUnary(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		panic("broken handler")
+	})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestRateLimit(t *testing.T) {
+	peers, err := unixsocket.NewAuthorizer(nil)
+	require.NoError(t, err)
+
+	a := newAuthenticator(auth.Config{
+		DefaultTenant: "default",
+		APIKeys: []auth.APIKey{
+			{Key: "reader-key", Name: "reader", Tenant: "default"},
+			{Key: "other-reader-key", Name: "reader", Tenant: "default"},
+		},
+	}, peers)
+
+	now := time.Now()
+	rl := newRateLimiter(ratelimit.Config{
+		Reads: ratelimit.Limit{Rate: 1, Burst: 2},
+		Bulk:  ratelimit.Limit{Rate: 1, Burst: 1},
+	}, a.identify)
+	rl.now = func() time.Time { return now }
+
+	call := func(key, method string) error {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1000}})
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+key))
+		_, err := rl.unaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, any) (any, error) {
+			return nil, nil
+		})
+
+		return err
+	}
+
+	// 1. Reads are limited by the burst, every key has own bucket even if keys have the same name.
+	assert.NoError(t, call("reader-key", bmpb.BookService_GetBook_FullMethodName))
+	assert.NoError(t, call("reader-key", bmpb.CollectionService_ListCollections_FullMethodName))
+
+	err = call("reader-key", bmpb.BookService_GetBook_FullMethodName)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	assert.NoError(t, call("other-reader-key", bmpb.BookService_GetBook_FullMethodName))
+
+	// 2. Unknown keys are limited by the address of the caller before they are rejected.
+	assert.NoError(t, call("guess-1", bmpb.BookService_GetBook_FullMethodName))
+	assert.NoError(t, call("guess-2", bmpb.BookService_GetBook_FullMethodName))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call("guess-3", bmpb.BookService_GetBook_FullMethodName)))
+
+	// 3. Bulk methods have own limit, writes aren't limited.
+	assert.NoError(t, call("reader-key", bmpb.BookService_DeleteBooks_FullMethodName))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call("reader-key", bmpb.CollectionService_AddBooks_FullMethodName)))
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, call("reader-key", bmpb.BookService_CreateBook_FullMethodName))
+	}
+
+	// 4. Buckets are refilled over time.
+	now = now.Add(time.Second)
+	assert.NoError(t, call("reader-key", bmpb.BookService_GetBook_FullMethodName))
+}
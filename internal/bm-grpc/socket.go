@@ -0,0 +1,117 @@
+package bmgrpc
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	unixsocket "github.com/Tsapen/bm/internal/unix-socket"
+)
+
+// peerCredentials reads credentials of unix socket peers during the handshake. Tcp connections are secured
+// by tls if it is set, other connections aren't encrypted, like with insecure credentials.
+type peerCredentials struct {
+	tls credentials.TransportCredentials
+}
+
+// peerInfo describes a connection: unix is set for unix socket connections, known is set if credentials
+// of the peer are read. Cert is the verified client certificate of a tls connection.
+type peerInfo struct {
+	credentials.CommonAuthInfo
+
+	unix  bool
+	known bool
+	cred  bm.PeerCred
+	cert  *x509.Certificate
+}
+
+func (peerInfo) AuthType() string {
+	return "peer"
+}
+
+func (c peerCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	info := peerInfo{CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity}}
+	if _, info.unix = conn.(*net.UnixConn); info.unix {
+		info.cred, info.known = unixsocket.PeerCred(conn)
+
+		return conn, info, nil
+	}
+
+	if c.tls == nil {
+		return conn, info, nil
+	}
+
+	conn, authInfo, err := c.tls.ServerHandshake(conn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsInfo := authInfo.(credentials.TLSInfo)
+	info.CommonAuthInfo = tlsInfo.CommonAuthInfo
+	if chains := tlsInfo.State.VerifiedChains; len(chains) != 0 {
+		info.cert = chains[0][0]
+	}
+
+	return conn, info, nil
+}
+
+func (peerCredentials) ClientHandshake(context.Context, string, net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errors.New("peer credentials are only for servers")
+}
+
+func (c peerCredentials) Info() credentials.ProtocolInfo {
+	if c.tls != nil {
+		return c.tls.Info()
+	}
+
+	return credentials.ProtocolInfo{SecurityProtocol: "insecure"}
+}
+
+func (c peerCredentials) Clone() credentials.TransportCredentials {
+	if c.tls != nil {
+		c.tls = c.tls.Clone()
+	}
+
+	return c
+}
+
+func (peerCredentials) OverrideServerName(string) error {
+	return nil
+}
+
+// authorizePeer checks access of the unix socket peer and adds its credentials into the context.
+// Calls over tcp aren't checked. Get and List methods are allowed to read-only peers.
+func authorizePeer(ctx context.Context, peers *unixsocket.Authorizer, method string) (context.Context, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+
+	info, ok := p.AuthInfo.(peerInfo)
+	if !ok || !info.unix {
+		return ctx, nil
+	}
+
+	if info.known {
+		ctx = bm.WithPeerCred(ctx, info.cred)
+	}
+
+	if err := peers.Authorize(ctx, isWriteMethod(method)); err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+// isWriteMethod checks if the method changes the library, only Get and List methods don't.
+func isWriteMethod(method string) bool {
+	name := method[strings.LastIndex(method, "/")+1:]
+
+	return !strings.HasPrefix(name, "Get") && !strings.HasPrefix(name, "List")
+}
@@ -0,0 +1,67 @@
+package bmgrpc
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/Tsapen/bm/internal/auth"
+	unixsocket "github.com/Tsapen/bm/internal/unix-socket"
+)
+
+func TestSocketPeers(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("SO_PEERCRED is supported only on linux")
+	}
+
+	// Check of the health service isn't a Get or List method, read-only peers can't call it.
+	check := func(t *testing.T, access unixsocket.Access) error {
+		peers, err := unixsocket.NewAuthorizer([]unixsocket.Peer{{UID: uint32(os.Getuid()), Access: access}})
+		require.NoError(t, err)
+
+		a := newAuthenticator(auth.Config{DefaultTenant: "default"}, peers)
+		srv := grpc.NewServer(grpc.Creds(peerCredentials{}), grpc.ChainUnaryInterceptor(a.unaryInterceptor))
+		healthpb.RegisterHealthServer(srv, health.NewServer())
+		defer srv.Stop()
+
+		path := filepath.Join(t.TempDir(), "bm.sock")
+		unixListener, err := unixsocket.Listen(path, unixsocket.Config{})
+		require.NoError(t, err)
+
+		tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		go srv.Serve(unixListener)
+		go srv.Serve(tcpListener)
+
+		call := func(target string) error {
+			conn, err := grpc.Dial(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			require.NoError(t, err)
+			defer conn.Close()
+
+			_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+
+			return err
+		}
+
+		// Calls over tcp aren't checked.
+		require.NoError(t, call(tcpListener.Addr().String()))
+
+		return call("unix://" + path)
+	}
+
+	assert.NoError(t, check(t, unixsocket.ReadWriteAccess))
+	assert.Equal(t, codes.PermissionDenied, status.Code(check(t, unixsocket.ReadOnlyAccess)))
+}
@@ -0,0 +1,86 @@
+package bmgrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/Tsapen/bm/internal/auth"
+	"github.com/Tsapen/bm/internal/auth/authtest"
+	bm "github.com/Tsapen/bm/internal/bm"
+	unixsocket "github.com/Tsapen/bm/internal/unix-socket"
+)
+
+func TestTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := authtest.NewCert(t, nil, "bm ca", 1)
+	caFile, _ := ca.Write(t, dir, "ca")
+	certFile, keyFile := authtest.NewCert(t, ca, "server", 2).Write(t, dir, "server")
+
+	reloader, err := auth.NewCertReloader(auth.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile})
+	require.NoError(t, err)
+
+	peers, err := unixsocket.NewAuthorizer(nil)
+	require.NoError(t, err)
+
+	a := newAuthenticator(auth.Config{
+		DefaultTenant: "default",
+		ClientCerts:   []auth.ClientCert{{CommonName: "reader-service", Name: "reader", Tenant: "reader"}},
+	}, peers)
+
+	// The health service doesn't know callers, their names are caught after authentication.
+	var caller string
+	catchCaller := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		caller = bm.IdentityFromCtx(ctx).Name
+
+		return handler(ctx, req)
+	}
+
+	srv := grpc.NewServer(
+		grpc.Creds(peerCredentials{tls: credentials.NewTLS(reloader.TLSConfig())}),
+		grpc.ChainUnaryInterceptor(a.unaryInterceptor, catchCaller),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	defer srv.Stop()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go srv.Serve(listener)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	call := func(certs ...tls.Certificate) error {
+		creds := credentials.NewTLS(&tls.Config{RootCAs: pool, Certificates: certs})
+		conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(creds))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+
+		return err
+	}
+
+	// 1. Client certificates are mapped to identities.
+	require.NoError(t, call(authtest.NewCert(t, ca, "reader-service", 3).TLSCertificate()))
+	assert.Equal(t, "reader", caller)
+
+	// 2. Clients without certificates are read-only, Check of the health service isn't a Get or List method.
+	// Unknown certificates are rejected.
+	err = call()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	err = call(authtest.NewCert(t, ca, "unknown-service", 4).TLSCertificate())
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,66 @@
+package bmhttp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Tsapen/bm/internal/auth"
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+type authenticator struct {
+	resolver *auth.Resolver
+}
+
+func newAuthenticator(cfg auth.Config) *authenticator {
+	return &authenticator{resolver: auth.NewResolver(cfg)}
+}
+
+// identify resolves the caller by the authorization header or the verified client certificate.
+func (a *authenticator) identify(r *http.Request) (bm.Identity, error) {
+	c := auth.Credentials{
+		Authorization: r.Header.Get("Authorization"),
+		Socket:        viaSocket(r.Context()),
+	}
+
+	if r.TLS != nil && len(r.TLS.VerifiedChains) != 0 {
+		c.Cert = r.TLS.VerifiedChains[0][0]
+	}
+
+	return a.resolver.Identify(c)
+}
+
+func (a *authenticator) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := a.identify(r)
+		if err != nil {
+			// OPDS clients ask for credentials only when they are challenged.
+			if strings.HasPrefix(routePath(r), "/opds") {
+				w.Header().Set("WWW-Authenticate", `Basic realm="bm", charset="UTF-8"`)
+			}
+
+			logger := log.With().Str("method", r.Method).Str("path", r.URL.String()).Logger()
+			renderErr(r.Context(), logger, fmt.Errorf("authenticate: %w", err), w)
+
+			return
+		}
+
+		if readOnlyPeer(r.Context()) {
+			id.ReadOnly = true
+		}
+
+		// GraphQL requests of read-only callers are checked by the executor, queries are sent with POST too.
+		write := r.Method != http.MethodGet && r.Method != http.MethodHead && routePath(r) != "/graphql"
+		if id.ReadOnly && write {
+			logger := log.With().Str("method", r.Method).Str("path", r.URL.String()).Logger()
+			renderErr(r.Context(), logger, bm.NewUnauthorizedError("api key is required to change the library"), w)
+
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(bm.WithIdentity(r.Context(), id)))
+	})
+}
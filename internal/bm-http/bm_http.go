@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"mime"
 	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,11 +16,18 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
+	"github.com/Tsapen/bm/internal/auth"
 	bm "github.com/Tsapen/bm/internal/bm"
+	bmgraphql "github.com/Tsapen/bm/internal/bm-graphql"
 	bs "github.com/Tsapen/bm/internal/book-service"
+	"github.com/Tsapen/bm/internal/openapi"
+	ratelimit "github.com/Tsapen/bm/internal/rate-limit"
+	unixsocket "github.com/Tsapen/bm/internal/unix-socket"
 	"github.com/Tsapen/bm/pkg/api"
 )
 
+const jsonContentType = "application/json"
+
 type Server struct {
 	cfg Config
 
@@ -28,35 +38,46 @@ type Server struct {
 type Config struct {
 	Addr         string
 	SocketPath   string
+	Socket       unixsocket.Config
 	ConnMaxCount int
 	Timeout      time.Duration
+	TLS          auth.TLSConfig
+	Auth         auth.Config
+	RateLimit    ratelimit.Config
+	GraphQL      bmgraphql.Config
 }
 
 type serviceBundle struct {
-	bookService *bs.Service
+	bookService     *bs.Service
+	graphQLExecutor *bmgraphql.Executor
 }
 
 func NewServer(cfg Config, bookService *bs.Service) (*Server, error) {
+	graphQLExecutor, err := bmgraphql.NewExecutor(cfg.GraphQL, bookService)
+	if err != nil {
+		return nil, fmt.Errorf("create graphql executor: %w", err)
+	}
+
 	b := &serviceBundle{
-		bookService: bookService,
+		bookService:     bookService,
+		graphQLExecutor: graphQLExecutor,
 	}
 
-	r := mux.NewRouter()
-	r = r.PathPrefix("/api/v1").Subrouter()
-	r.HandleFunc("/books/{book_id}", handleFunc(parseGetBookReq, b.getBook)).Methods(http.MethodGet)
-	r.HandleFunc("/books", handleFunc(parseGetBooksReq, b.getBooks)).Methods(http.MethodGet)
-	r.HandleFunc("/books", handleFunc(parseJSONReq[api.CreateBookReq], b.createBook)).Methods(http.MethodPost)
-	r.HandleFunc("/books/{book_id}", handleFunc(parseUpdateBookReq, b.updateBook)).Methods(http.MethodPut)
-	r.HandleFunc("/books", handleFunc(parseJSONReq[api.DeleteBooksReq], b.deleteBooks)).Methods(http.MethodDelete)
+	peers, err := unixsocket.NewAuthorizer(cfg.Socket.Peers)
+	if err != nil {
+		return nil, fmt.Errorf("configure socket peers: %w", err)
+	}
 
-	r.HandleFunc("/collections/{collection_id}", handleFunc(parseGetCollectionReq, b.getCollection)).Methods(http.MethodGet)
-	r.HandleFunc("/collections", handleFunc(parseGetCollectionsReq, b.getCollections)).Methods(http.MethodGet)
-	r.HandleFunc("/collections", handleFunc(parseJSONReq[api.CreateCollectionReq], b.createCollection)).Methods(http.MethodPost)
-	r.HandleFunc("/collections/{collection_id}", handleFunc(parseUpdateCollectionReq, b.updateCollection)).Methods(http.MethodPut)
-	r.HandleFunc("/collections/{collection_id}", handleFunc(parseDeleteCollectionReq, b.deleteCollection)).Methods(http.MethodDelete)
+	r := mux.NewRouter()
+	a := newAuthenticator(cfg.Auth)
+	r.Use(newRateLimiter(cfg.RateLimit, a.identify).middleware)
+	r.Use(a.middleware)
 
-	r.HandleFunc("/collections/{collection_id}/books", handleFunc(parseCreateBooksCollectionReq, b.createBooksCollection)).Methods(http.MethodPost)
-	r.HandleFunc("/collections/{collection_id}/books", handleFunc(parseDeleteBooksCollectionReq, b.deleteBooksCollection)).Methods(http.MethodDelete)
+	for _, version := range openapi.Versions {
+		if err = b.routes(r.PathPrefix(version.Prefix()).Subrouter(), version); err != nil {
+			return nil, fmt.Errorf("route api v%d: %w", version, err)
+		}
+	}
 
 	var s = &Server{
 		cfg: cfg,
@@ -67,15 +88,159 @@ func NewServer(cfg Config, bookService *bs.Service) (*Server, error) {
 			WriteTimeout: cfg.Timeout,
 		},
 		unixSocketServer: &http.Server{
-			Handler:      r,
+			Handler:      peerMiddleware(peers, r),
 			ReadTimeout:  cfg.Timeout,
 			WriteTimeout: cfg.Timeout,
+			ConnContext:  socketConnContext,
 		},
 	}
 
+	if cfg.TLS.Enabled() {
+		reloader, err := auth.NewCertReloader(cfg.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("load tls files: %w", err)
+		}
+
+		s.tcpServer.TLSConfig = reloader.TLSConfig()
+	}
+
 	return s, nil
 }
 
+// routes registers handlers of the API version. Versions differ only by names of a few collection fields.
+func (b *serviceBundle) routes(r *mux.Router, version openapi.Version) error {
+	getOpenAPIDoc, err := getOpenAPI(version)
+	if err != nil {
+		return err
+	}
+
+	r.HandleFunc("/openapi.json", handleFunc(parseGetOpenAPIReq, getOpenAPIDoc)).Methods(http.MethodGet)
+
+	r.HandleFunc("/books/{book_id}", handleFunc(parseGetBookReq, b.getBook)).Methods(http.MethodGet)
+	r.HandleFunc("/books/isbn/{isbn}", handleFunc(parseGetBookByISBNReq, b.getBookByISBN)).Methods(http.MethodGet)
+	r.HandleFunc("/books", handleFunc(parseGetBooksReq, b.getBooks)).Methods(http.MethodGet)
+	r.HandleFunc("/books", handleFunc(parseJSONReq[api.CreateBookReq], b.createBook)).Methods(http.MethodPost)
+	r.HandleFunc("/books/{book_id}", handleFunc(parseUpdateBookReq, b.updateBook)).Methods(http.MethodPut)
+	r.HandleFunc("/books", handleFunc(parseJSONReq[api.DeleteBooksReq], b.deleteBooks)).Methods(http.MethodDelete)
+
+	if version == openapi.V1 {
+		r.HandleFunc("/collections/{collection_id}", handleFunc(parseGetCollectionReq, b.getCollectionV1)).Methods(http.MethodGet)
+		r.HandleFunc("/collections", handleFunc(parseGetCollectionsReq, b.getCollectionsV1)).Methods(http.MethodGet)
+		r.HandleFunc("/collections", handleFunc(parseCreateCollectionReqV1, b.createCollection)).Methods(http.MethodPost)
+		r.HandleFunc("/collections/{collection_id}", handleFunc(parseUpdateCollectionReqV1, b.updateCollection)).Methods(http.MethodPut)
+		r.HandleFunc("/collections/{collection_id}/books", handleFunc(parseDeleteBooksCollectionReqV1, b.deleteBooksCollection)).Methods(http.MethodDelete)
+	} else {
+		r.HandleFunc("/collections/{collection_id}", handleFunc(parseGetCollectionReq, b.getCollection)).Methods(http.MethodGet)
+		r.HandleFunc("/collections", handleFunc(parseGetCollectionsReq, b.getCollections)).Methods(http.MethodGet)
+		r.HandleFunc("/collections", handleFunc(parseJSONReq[api.CreateCollectionReq], b.createCollection)).Methods(http.MethodPost)
+		r.HandleFunc("/collections/{collection_id}", handleFunc(parseUpdateCollectionReq, b.updateCollection)).Methods(http.MethodPut)
+		r.HandleFunc("/collections/{collection_id}/books", handleFunc(parseDeleteBooksCollectionReq, b.deleteBooksCollection)).Methods(http.MethodDelete)
+	}
+
+	r.HandleFunc("/collections/{collection_id}", handleFunc(parseDeleteCollectionReq, b.deleteCollection)).Methods(http.MethodDelete)
+	r.HandleFunc("/collections/{collection_id}/books", handleFunc(parseCreateBooksCollectionReq, b.createBooksCollection)).Methods(http.MethodPost)
+
+	r.HandleFunc("/authors/{author_id}", handleFunc(parseGetAuthorReq, b.getAuthor)).Methods(http.MethodGet)
+	r.HandleFunc("/authors", handleFunc(parseGetAuthorsReq, b.getAuthors)).Methods(http.MethodGet)
+	r.HandleFunc("/authors", handleFunc(parseJSONReq[api.CreateAuthorReq], b.createAuthor)).Methods(http.MethodPost)
+	r.HandleFunc("/authors/{author_id}", handleFunc(parseUpdateAuthorReq, b.updateAuthor)).Methods(http.MethodPut)
+	r.HandleFunc("/authors/{author_id}", handleFunc(parseDeleteAuthorReq, b.deleteAuthor)).Methods(http.MethodDelete)
+
+	r.HandleFunc("/tags", handleFunc(parseGetTagsReq, b.getTags)).Methods(http.MethodGet)
+	r.HandleFunc("/books/tags", handleFunc(parseJSONReq[api.AddBooksTagsReq], b.addBooksTags)).Methods(http.MethodPost)
+	r.HandleFunc("/books/tags", handleFunc(parseJSONReq[api.DeleteBooksTagsReq], b.deleteBooksTags)).Methods(http.MethodDelete)
+
+	r.HandleFunc("/books/{book_id}/cover", handleFunc(parseGetCoverReq, b.getCover)).Methods(http.MethodGet)
+	r.HandleFunc("/books/{book_id}/cover", handleFunc(parseUploadCoverReq, b.uploadCover)).Methods(http.MethodPut)
+
+	r.HandleFunc("/books/import/epub", handleFunc(parseImportEPUBReq, b.importEPUB)).Methods(http.MethodPost)
+	r.HandleFunc("/books/import/csv", handleFunc(parseImportBooksReq, b.importBooks)).Methods(http.MethodPost)
+	r.HandleFunc("/books/import/marc", handleFunc(parseImportMARCReq, b.importMARC)).Methods(http.MethodPost)
+	r.HandleFunc("/books/{book_id}/export", handleFunc(parseExportBookReq, b.exportBook)).Methods(http.MethodGet)
+	r.HandleFunc("/collections/{collection_id}/export", handleFunc(parseExportCollectionReq, b.exportCollection)).Methods(http.MethodGet)
+
+	r.HandleFunc("/opds", handleFunc(parseGetOPDSFeedReq, b.getOPDSRoot)).Methods(http.MethodGet)
+	r.HandleFunc("/opds/recent", handleFunc(parseGetOPDSFeedReq, b.getOPDSRecent)).Methods(http.MethodGet)
+	r.HandleFunc("/opds/search", handleFunc(parseGetOPDSFeedReq, b.searchOPDS)).Methods(http.MethodGet)
+	r.HandleFunc("/opds/collections/{collection_id}", handleFunc(parseGetOPDSFeedReq, b.getOPDSCollection)).Methods(http.MethodGet)
+	r.HandleFunc("/opds/opensearch.xml", handleFunc(parseGetOpenSearchReq, b.getOpenSearch)).Methods(http.MethodGet)
+
+	r.HandleFunc("/graphql", handleFunc(parseGraphQLReq, b.graphQL)).Methods(http.MethodGet, http.MethodPost)
+
+	r.HandleFunc("/books/{book_id}/files", handleFunc(parseGetBookFilesReq, b.getBookFiles)).Methods(http.MethodGet)
+	r.HandleFunc("/books/{book_id}/files", handleFunc(parseAddBookFileReq, b.addBookFile)).Methods(http.MethodPost)
+	r.HandleFunc("/books/{book_id}/files/{file_id}", handleFunc(parseGetBookFileReq, b.getBookFile)).Methods(http.MethodGet)
+	r.HandleFunc("/books/{book_id}/files/{file_id}", handleFunc(parseDeleteBookFileReq, b.deleteBookFile)).Methods(http.MethodDelete)
+
+	r.HandleFunc("/books/{book_id}/reading", handleFunc(parseGetReadingReq, b.getReading)).Methods(http.MethodGet)
+	r.HandleFunc("/books/{book_id}/reading", handleFunc(parseUpdateReadingReq, b.updateReading)).Methods(http.MethodPut)
+	r.HandleFunc("/reading/report", handleFunc(parseGetReadingReportReq, b.getReadingReport)).Methods(http.MethodGet)
+
+	r.HandleFunc("/books/{book_id}/reviews/{review_id}", handleFunc(parseGetReviewReq, b.getReview)).Methods(http.MethodGet)
+	r.HandleFunc("/books/{book_id}/reviews", handleFunc(parseGetReviewsReq, b.getReviews)).Methods(http.MethodGet)
+	r.HandleFunc("/books/{book_id}/reviews", handleFunc(parseCreateReviewReq, b.createReview)).Methods(http.MethodPost)
+	r.HandleFunc("/books/{book_id}/reviews/{review_id}", handleFunc(parseUpdateReviewReq, b.updateReview)).Methods(http.MethodPut)
+	r.HandleFunc("/books/{book_id}/reviews/{review_id}", handleFunc(parseDeleteReviewReq, b.deleteReview)).Methods(http.MethodDelete)
+
+	r.HandleFunc("/books/{book_id}/copies", handleFunc(parseGetCopiesReq, b.getCopies)).Methods(http.MethodGet)
+	r.HandleFunc("/books/{book_id}/copies", handleFunc(parseCreateCopyReq, b.createCopy)).Methods(http.MethodPost)
+	r.HandleFunc("/copies", handleFunc(parseGetCopiesReq, b.getCopies)).Methods(http.MethodGet)
+	r.HandleFunc("/copies/barcode/{barcode}", handleFunc(parseGetCopyByBarcodeReq, b.getCopyByBarcode)).Methods(http.MethodGet)
+	r.HandleFunc("/copies/{copy_id}", handleFunc(parseGetCopyReq, b.getCopy)).Methods(http.MethodGet)
+	r.HandleFunc("/copies/{copy_id}", handleFunc(parseUpdateCopyReq, b.updateCopy)).Methods(http.MethodPut)
+	r.HandleFunc("/copies/{copy_id}", handleFunc(parseDeleteCopyReq, b.deleteCopy)).Methods(http.MethodDelete)
+
+	r.HandleFunc("/books/{book_id}/loan", handleFunc(parseLendBookReq, b.lendBook)).Methods(http.MethodPost)
+	r.HandleFunc("/books/{book_id}/return", handleFunc(parseReturnBookReq, b.returnBook)).Methods(http.MethodPost)
+	r.HandleFunc("/loans", handleFunc(parseGetLoansReq, b.getLoans)).Methods(http.MethodGet)
+	r.HandleFunc("/loans/overdue", handleFunc(parseGetLoansReq, b.getOverdueLoans)).Methods(http.MethodGet)
+
+	r.HandleFunc("/genres/{genre_id}", handleFunc(parseGetGenreReq, b.getGenre)).Methods(http.MethodGet)
+	r.HandleFunc("/genres", handleFunc(parseGetGenresReq, b.getGenres)).Methods(http.MethodGet)
+	r.HandleFunc("/genres", handleFunc(parseJSONReq[api.CreateGenreReq], b.createGenre)).Methods(http.MethodPost)
+	r.HandleFunc("/genres/{genre_id}", handleFunc(parseUpdateGenreReq, b.updateGenre)).Methods(http.MethodPut)
+	r.HandleFunc("/genres/{genre_id}", handleFunc(parseDeleteGenreReq, b.deleteGenre)).Methods(http.MethodDelete)
+
+	r.HandleFunc("/admin/books/move", handleFunc(parseJSONReq[api.MoveBooksReq], b.moveBooks)).Methods(http.MethodPost)
+	r.HandleFunc("/admin/collections/{collection_id}/move", handleFunc(parseMoveCollectionReq, b.moveCollection)).Methods(http.MethodPost)
+	r.HandleFunc("/admin/cache", handleFunc(parseGetCacheStatsReq, b.getCacheStats)).Methods(http.MethodGet)
+
+	r.HandleFunc("/webhooks/{webhook_id}", handleFunc(parseGetWebhookReq, b.getWebhook)).Methods(http.MethodGet)
+	r.HandleFunc("/webhooks", handleFunc(parseGetWebhooksReq, b.getWebhooks)).Methods(http.MethodGet)
+	r.HandleFunc("/webhooks", handleFunc(parseJSONReq[api.CreateWebhookReq], b.createWebhook)).Methods(http.MethodPost)
+	r.HandleFunc("/webhooks/{webhook_id}", handleFunc(parseUpdateWebhookReq, b.updateWebhook)).Methods(http.MethodPut)
+	r.HandleFunc("/webhooks/{webhook_id}", handleFunc(parseDeleteWebhookReq, b.deleteWebhook)).Methods(http.MethodDelete)
+	r.HandleFunc("/webhooks/{webhook_id}/deliveries", handleFunc(parseGetWebhookDeliveriesReq, b.getWebhookDeliveries)).Methods(http.MethodGet)
+	r.HandleFunc("/webhooks/{webhook_id}/deliveries/{delivery_id}", handleFunc(parseGetWebhookDeliveryReq, b.getWebhookDelivery)).Methods(http.MethodGet)
+	r.HandleFunc("/webhooks/{webhook_id}/deliveries/{delivery_id}/redeliver", handleFunc(parseRedeliverWebhookEventReq, b.redeliverWebhookEvent)).Methods(http.MethodPost)
+
+	r.HandleFunc("/changes", handleFunc(parseGetChangesReq, b.getChanges)).Methods(http.MethodGet)
+	r.HandleFunc("/changes/stream", handleFunc(parseStreamChangesReq, b.streamChanges)).Methods(http.MethodGet)
+
+	return nil
+}
+
+// routePath returns the path template of the matched route without the version prefix, like /books/{book_id}.
+func routePath(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+
+	tmpl, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+
+	for _, version := range openapi.Versions {
+		if p, ok := strings.CutPrefix(tmpl, version.Prefix()+"/"); ok {
+			return "/" + p
+		}
+	}
+
+	return tmpl
+}
+
 func handleFunc[Req any](
 	parseReq func(r *http.Request) (Req, error),
 	handle func(context.Context, Req) (any, error),
@@ -83,7 +248,7 @@ func handleFunc[Req any](
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := bm.WithReqID(r.Context(), uuid.NewString())
 
-		logger := log.With().Str("method", r.Method).Str("path", r.URL.String()).Str("request_id", bm.ReqIDFromCtx(ctx)).Logger()
+		logger := log.With().Str("method", r.Method).Str("path", r.URL.String()).Str("request_id", bm.ReqIDFromCtx(ctx)).Str("tenant", bm.TenantFromCtx(ctx)).Logger()
 		logger.Info().Msg("received request")
 
 		req, err := parseReq(r)
@@ -93,7 +258,7 @@ func handleFunc[Req any](
 			return
 		}
 
-		log.Info().Any("request", req).Msg("request is parsed")
+		log.Info().Any("request", redactSecrets(req)).Msg("request is parsed")
 
 		resp, err := handle(ctx, req)
 		if err != nil {
@@ -102,12 +267,25 @@ func handleFunc[Req any](
 			return
 		}
 
+		if resp, err = withValidators(r, resp); err != nil {
+			renderErr(ctx, logger, fmt.Errorf("render response: %w", err), w)
+
+			return
+		}
+
 		renderResponse(ctx, logger, resp, w)
 	}
 }
 
 // Start runs server with tcp as transport.
 func (s *Server) StartTCPServer() error {
+	if s.tcpServer.TLSConfig != nil {
+		log.Info().Msgf("HTTPS server (tcp) started to listen %s", s.cfg.Addr)
+
+		// Certificates come from the TLS config, they are reloaded after their files change.
+		return s.tcpServer.ListenAndServeTLS("", "")
+	}
+
 	log.Info().Msgf("HTTP server (tcp) started to listen %s", s.cfg.Addr)
 
 	return s.tcpServer.ListenAndServe()
@@ -115,16 +293,39 @@ func (s *Server) StartTCPServer() error {
 
 // Start runs server with unix-socket as transport.
 func (s *Server) StartUnixSocketServer() error {
-	log.Info().Msgf("HTTP server (unix-socket) started to listen %s", s.cfg.Addr)
-
-	unixListener, err := net.Listen("unix", s.cfg.SocketPath)
+	unixListener, err := unixsocket.Listen(s.cfg.SocketPath, s.cfg.Socket)
 	if err != nil {
-		return err
+		return fmt.Errorf("listen unix socket: %w", err)
 	}
 
+	log.Info().Msgf("HTTP server (unix-socket) started to listen %s", s.cfg.SocketPath)
+
 	return s.unixSocketServer.Serve(unixListener)
 }
 
+// socketConnKey marks contexts of unix socket connections.
+type socketConnKey struct{}
+
+// socketConnContext marks the connection context as the one of the unix socket and puts credentials
+// of the peer into it.
+func socketConnContext(ctx context.Context, conn net.Conn) context.Context {
+	ctx = context.WithValue(ctx, socketConnKey{}, true)
+
+	cred, ok := unixsocket.PeerCred(conn)
+	if !ok {
+		return ctx
+	}
+
+	return bm.WithPeerCred(ctx, cred)
+}
+
+// viaSocket checks if the request came over the unix socket.
+func viaSocket(ctx context.Context) bool {
+	socket, _ := ctx.Value(socketConnKey{}).(bool)
+
+	return socket
+}
+
 func parseJSONReq[Req any](r *http.Request) (*Req, error) {
 	req := new(Req)
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -136,6 +337,7 @@ func parseJSONReq[Req any](r *http.Request) (*Req, error) {
 
 func renderErr(ctx context.Context, logger zerolog.Logger, err error, w http.ResponseWriter) {
 	statusCode := httpStatus(err)
+	w.Header().Set("Content-Type", jsonContentType)
 	w.WriteHeader(statusCode)
 
 	logger.Info().Err(err).Int("status code", statusCode).Msg("failed to process message")
@@ -149,9 +351,106 @@ func renderResponse(ctx context.Context, logger zerolog.Logger, resp any, w http
 		return
 	}
 
-	logger.Info().Any("response", resp).Msg("finish processing")
+	if blob, ok := resp.(*blobResp); ok {
+		logger.Info().Str("content type", blob.contentType).Bool("not modified", blob.notModified).Msg("finish processing")
+
+		if err := blob.render(w); err != nil {
+			log.Info().Err(err).Msg("send blob")
+		}
+
+		return
+	}
+
+	if stream, ok := resp.(*eventStream); ok {
+		logger.Info().Msg("start streaming")
+
+		if err := stream.render(ctx, w); err != nil {
+			log.Info().Err(err).Msg("send events")
+		}
+
+		logger.Info().Msg("finish processing")
+
+		return
+	}
+
+	logger.Info().Any("response", redactSecrets(resp)).Msg("finish processing")
 
+	w.Header().Set("Content-Type", jsonContentType)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		log.Info().Err(err).Msg("send message")
 	}
 }
+
+// redactSecrets masks secrets of webhooks in copies of requests and responses before they are logged.
+func redactSecrets(v any) any {
+	const mask = "***"
+
+	switch v := v.(type) {
+	case *api.CreateWebhookReq:
+		if v != nil && v.Secret != "" {
+			redacted := *v
+			redacted.Secret = mask
+
+			return &redacted
+		}
+
+	case *api.UpdateWebhookReq:
+		if v != nil && v.Secret != "" {
+			redacted := *v
+			redacted.Secret = mask
+
+			return &redacted
+		}
+
+	case *api.CreateWebhookResp:
+		if v != nil && v.Secret != "" {
+			redacted := *v
+			redacted.Secret = mask
+
+			return &redacted
+		}
+	}
+
+	return v
+}
+
+// blobResp is rendered as the blob itself instead of JSON.
+type blobResp struct {
+	contentType  string
+	fileName     string
+	cacheControl string
+	etag         string
+	lastModified time.Time
+	notModified  bool
+	data         []byte
+}
+
+func (resp *blobResp) render(w http.ResponseWriter) error {
+	if resp.etag != "" {
+		w.Header().Set("ETag", resp.etag)
+	}
+
+	if !resp.lastModified.IsZero() {
+		w.Header().Set("Last-Modified", resp.lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if resp.cacheControl != "" {
+		w.Header().Set("Cache-Control", resp.cacheControl)
+	}
+
+	if resp.notModified {
+		w.WriteHeader(http.StatusNotModified)
+
+		return nil
+	}
+
+	if resp.fileName != "" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": resp.fileName}))
+	}
+
+	w.Header().Set("Content-Type", resp.contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(resp.data)))
+	_, err := w.Write(resp.data)
+
+	return err
+}
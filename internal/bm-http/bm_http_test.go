@@ -0,0 +1,27 @@
+package bmhttp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	out := new(bytes.Buffer)
+	logger := zerolog.New(out)
+
+	req := &api.CreateWebhookReq{URL: "http://example.com/hook", Secret: "create-secret"}
+	logger.Info().Any("request", redactSecrets(req)).Send()
+	logger.Info().Any("request", redactSecrets(&api.UpdateWebhookReq{ID: 1, Secret: "update-secret"})).Send()
+	logger.Info().Any("response", redactSecrets(&api.CreateWebhookResp{ID: 1, Secret: "generated-secret"})).Send()
+
+	assert.NotContains(t, out.String(), "-secret")
+	assert.Contains(t, out.String(), "http://example.com/hook")
+
+	// Requests keep their secrets for handlers.
+	assert.Equal(t, "create-secret", req.Secret)
+}
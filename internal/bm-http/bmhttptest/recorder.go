@@ -0,0 +1,26 @@
+// Package bmhttptest contains helpers for tests of clients of the HTTP server.
+package bmhttptest
+
+import (
+	"net/http"
+)
+
+// StatusRecorder keeps the status of the last response. Requests are sent by Next or by the default transport.
+type StatusRecorder struct {
+	Next   http.RoundTripper
+	Status int
+}
+
+func (r *StatusRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	resp, err := next.RoundTrip(req)
+	if err == nil {
+		r.Status = resp.StatusCode
+	}
+
+	return resp, err
+}
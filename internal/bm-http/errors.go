@@ -2,11 +2,22 @@ package bmhttp
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/Tsapen/bm/internal/bm"
 )
 
+// rateLimitError implements error interface.
+type rateLimitError struct {
+	retryAfter time.Duration
+}
+
+func (err rateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded, retry after %s", err.retryAfter)
+}
+
 func httpStatus(err error) int {
 	switch {
 	case errors.As(err, &bm.ValidationError{}):
@@ -18,6 +29,15 @@ func httpStatus(err error) int {
 	case errors.As(err, &bm.ConflictError{}):
 		return http.StatusConflict
 
+	case errors.As(err, &bm.UnauthorizedError{}):
+		return http.StatusUnauthorized
+
+	case errors.As(err, &bm.ForbiddenError{}):
+		return http.StatusForbidden
+
+	case errors.As(err, &rateLimitError{}):
+		return http.StatusTooManyRequests
+
 	default:
 		return http.StatusInternalServerError
 	}
@@ -0,0 +1,106 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Tsapen/bm/internal/openapi"
+)
+
+const (
+	// eventsPollInterval is the interval between checks for new events of a stream.
+	eventsPollInterval = time.Second
+
+	// heartbeatInterval is the longest silence of a stream, a comment is sent to keep idle connections open.
+	heartbeatInterval = 15 * time.Second
+)
+
+// serverEvent is a server-sent event. ID is sent back by reconnecting clients in the Last-Event-ID header.
+type serverEvent struct {
+	id   string
+	name string
+	data []byte
+}
+
+// eventStream is rendered as a stream of server-sent events instead of JSON. The stream starts with events
+// and goes on with the ones returned by next until the client disconnects. Next is polled every
+// eventsPollInterval and returns events following the ones it returned before.
+type eventStream struct {
+	events []serverEvent
+	next   func(ctx context.Context) ([]serverEvent, error)
+}
+
+func (s *eventStream) render(ctx context.Context, w http.ResponseWriter) error {
+	rc := http.NewResponseController(w)
+
+	// A stream outlives the write timeout of the server.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("reset write deadline: %w", err)
+	}
+
+	w.Header().Set("Content-Type", openapi.EventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(eventsPollInterval)
+	defer ticker.Stop()
+
+	events := s.events
+	lastWrite := time.Now()
+	for {
+		for _, e := range events {
+			if _, err := w.Write(e.marshal()); err != nil {
+				return fmt.Errorf("write event: %w", err)
+			}
+
+			lastWrite = time.Now()
+		}
+
+		if time.Since(lastWrite) >= heartbeatInterval {
+			if _, err := w.Write([]byte(": heartbeat\n\n")); err != nil {
+				return fmt.Errorf("write heartbeat: %w", err)
+			}
+
+			lastWrite = time.Now()
+		}
+
+		if err := rc.Flush(); err != nil {
+			return fmt.Errorf("flush events: %w", err)
+		}
+
+		// A stream behind the events catches up without waiting.
+		if len(events) == 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case <-ticker.C:
+			}
+		}
+
+		var err error
+		if events, err = s.next(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("get events: %w", err)
+		}
+	}
+}
+
+// marshal formats the event. Data is split into lines since a data field can't contain a line break.
+func (e serverEvent) marshal() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "id: %s\nevent: %s\n", e.id, e.name)
+	for _, line := range strings.Split(string(e.data), "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+
+	b.WriteString("\n")
+
+	return []byte(b.String())
+}
@@ -0,0 +1,72 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tsapen/bm/pkg/api"
+	httpclient "github.com/Tsapen/bm/pkg/http-client"
+)
+
+func TestServerEventMarshal(t *testing.T) {
+	e := serverEvent{id: "7", name: "book.created", data: []byte("{\n\"seq\":7}")}
+	assert.Equal(t, "id: 7\nevent: book.created\ndata: {\ndata: \"seq\":7}\n\n", string(e.marshal()))
+}
+
+func TestEventStream(t *testing.T) {
+	event := func(seq int64) serverEvent {
+		data, err := json.Marshal(api.Change{Seq: seq, Entity: "book", EntityID: seq, Op: "created", Data: json.RawMessage(`{}`)})
+		require.NoError(t, err)
+
+		return serverEvent{id: strconv.FormatInt(seq, 10), name: "book.created", data: data}
+	}
+
+	// The stream starts with the first event and gets the next ones on the following polls.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls := [][]serverEvent{{event(2), event(3)}, nil, {event(4)}}
+		stream := &eventStream{
+			events: []serverEvent{event(1)},
+			next: func(context.Context) ([]serverEvent, error) {
+				if len(polls) == 0 {
+					return nil, nil
+				}
+
+				events := polls[0]
+				polls = polls[1:]
+
+				return events, nil
+			},
+		}
+
+		assert.NoError(t, stream.render(r.Context(), w))
+	}))
+	defer server.Close()
+
+	client := httpclient.New(httpclient.Config{Address: server.URL, Timeout: time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errEnough := errors.New("enough")
+
+	var seqs []int64
+	err := client.StreamChanges(ctx, &api.StreamChangesReq{}, func(c api.Change) error {
+		seqs = append(seqs, c.Seq)
+		if len(seqs) == 4 {
+			return errEnough
+		}
+
+		return nil
+	})
+	assert.ErrorIs(t, err, errEnough)
+	assert.Equal(t, []int64{1, 2, 3, 4}, seqs)
+}
@@ -0,0 +1,45 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	bs "github.com/Tsapen/bm/internal/book-service"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseAddBookFileReq(r *http.Request) (*api.AddBookFileReq, error) {
+	bookID, err := strconv.ParseInt(mux.Vars(r)["book_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, bs.MaxFileSize+1))
+	if err != nil {
+		return nil, bm.NewValidationError("read file: %w", err)
+	}
+
+	return &api.AddBookFileReq{
+		BookID: bookID,
+		Name:   r.URL.Query().Get("name"),
+		Data:   data,
+	}, nil
+}
+
+func (b *serviceBundle) addBookFile(ctx context.Context, r *api.AddBookFileReq) (any, error) {
+	id, err := b.bookService.AddBookFile(ctx, r.BookID, r.Name, r.Data)
+	if err != nil {
+		return nil, fmt.Errorf("add book file: %w", err)
+	}
+
+	return &api.AddBookFileResp{
+		ID:  id,
+		URL: bookFileURL(r.BookID, id),
+	}, nil
+}
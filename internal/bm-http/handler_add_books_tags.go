@@ -0,0 +1,17 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func (b *serviceBundle) addBooksTags(ctx context.Context, r *api.AddBooksTagsReq) (any, error) {
+	err := b.bookService.AddBooksTags(ctx, r.BookIDs, r.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("add tags to books: %w", err)
+	}
+
+	return nil, nil
+}
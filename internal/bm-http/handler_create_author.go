@@ -0,0 +1,20 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func (b *serviceBundle) createAuthor(ctx context.Context, r *api.CreateAuthorReq) (any, error) {
+	id, err := b.bookService.CreateAuthor(ctx, bm.Author{Name: r.Name})
+	if err != nil {
+		return nil, fmt.Errorf("create author: %w", err)
+	}
+
+	return &api.CreateAuthorResp{
+		ID: id,
+	}, nil
+}
@@ -16,6 +16,10 @@ func (b *serviceBundle) createBook(ctx context.Context, r *api.CreateBookReq) (a
 		Edition:       r.Edition,
 		Description:   r.Description,
 		Genre:         r.Genre,
+		GenreID:       r.GenreID,
+		ISBN:          r.ISBN,
+		Contributors:  newBMContributors(r.Contributors),
+		Tags:          r.Tags,
 	}
 
 	id, err := b.bookService.CreateBook(ctx, bookData)
@@ -27,3 +31,12 @@ func (b *serviceBundle) createBook(ctx context.Context, r *api.CreateBookReq) (a
 		ID: id,
 	}, nil
 }
+
+func newBMContributors(contributors []api.Contributor) []bm.Contributor {
+	bmContributors := make([]bm.Contributor, 0, len(contributors))
+	for _, c := range contributors {
+		bmContributors = append(bmContributors, bm.Contributor(c))
+	}
+
+	return bmContributors
+}
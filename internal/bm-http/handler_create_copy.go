@@ -0,0 +1,57 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseCreateCopyReq(r *http.Request) (*api.CreateCopyReq, error) {
+	req := new(api.CreateCopyReq)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	bookID, err := strconv.ParseInt(mux.Vars(r)["book_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	req.BookID = bookID
+
+	return req, nil
+}
+
+func (b *serviceBundle) createCopy(ctx context.Context, r *api.CreateCopyReq) (any, error) {
+	c := bm.Copy{
+		BookID:    r.BookID,
+		Barcode:   r.Barcode,
+		Condition: r.Condition,
+		Price:     r.Price,
+		Location:  r.Location,
+	}
+
+	if r.AcquiredAt != "" {
+		var err error
+		if c.AcquiredAt, err = time.Parse(time.DateOnly, r.AcquiredAt); err != nil {
+			return nil, bm.NewValidationError("incorrect acquired_at: %w", err)
+		}
+	}
+
+	id, err := b.bookService.CreateCopy(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("create copy: %w", err)
+	}
+
+	return &api.CreateCopyResp{
+		ID: id,
+	}, nil
+}
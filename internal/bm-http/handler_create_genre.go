@@ -0,0 +1,20 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func (b *serviceBundle) createGenre(ctx context.Context, r *api.CreateGenreReq) (any, error) {
+	id, err := b.bookService.CreateGenre(ctx, bm.Genre{Name: r.Name, ParentID: r.ParentID})
+	if err != nil {
+		return nil, fmt.Errorf("create genre: %w", err)
+	}
+
+	return &api.CreateGenreResp{
+		ID: id,
+	}, nil
+}
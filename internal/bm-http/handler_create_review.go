@@ -0,0 +1,41 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseCreateReviewReq(r *http.Request) (*api.CreateReviewReq, error) {
+	req := new(api.CreateReviewReq)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	bookID, err := strconv.ParseInt(mux.Vars(r)["book_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	req.BookID = bookID
+
+	return req, nil
+}
+
+func (b *serviceBundle) createReview(ctx context.Context, r *api.CreateReviewReq) (any, error) {
+	id, err := b.bookService.CreateReview(ctx, bm.Review{BookID: r.BookID, Rating: r.Rating, Text: r.Text})
+	if err != nil {
+		return nil, fmt.Errorf("create review: %w", err)
+	}
+
+	return &api.CreateReviewResp{
+		ID: id,
+	}, nil
+}
@@ -0,0 +1,26 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func (b *serviceBundle) createWebhook(ctx context.Context, r *api.CreateWebhookReq) (any, error) {
+	id, secret, err := b.bookService.CreateWebhook(ctx, bm.Webhook{
+		URL:    r.URL,
+		Secret: r.Secret,
+		Events: r.Events,
+		Active: r.Active == nil || *r.Active,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create webhook: %w", err)
+	}
+
+	return &api.CreateWebhookResp{
+		ID:     id,
+		Secret: secret,
+	}, nil
+}
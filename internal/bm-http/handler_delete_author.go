@@ -0,0 +1,34 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseDeleteAuthorReq(r *http.Request) (*api.DeleteAuthorReq, error) {
+	v := mux.Vars(r)
+
+	req := new(api.DeleteAuthorReq)
+	var err error
+	req.ID, err = strconv.ParseInt(v["author_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) deleteAuthor(ctx context.Context, r *api.DeleteAuthorReq) (any, error) {
+	err := b.bookService.DeleteAuthor(ctx, r.ID)
+	if err != nil {
+		return nil, fmt.Errorf("delete author: %w", err)
+	}
+
+	return nil, nil
+}
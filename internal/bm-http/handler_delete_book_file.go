@@ -0,0 +1,37 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseDeleteBookFileReq(r *http.Request) (*api.DeleteBookFileReq, error) {
+	v := mux.Vars(r)
+
+	req := new(api.DeleteBookFileReq)
+	var err error
+	if req.BookID, err = strconv.ParseInt(v["book_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	if req.ID, err = strconv.ParseInt(v["file_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) deleteBookFile(ctx context.Context, r *api.DeleteBookFileReq) (any, error) {
+	err := b.bookService.DeleteBookFile(ctx, r.BookID, r.ID)
+	if err != nil {
+		return nil, fmt.Errorf("delete book file: %w", err)
+	}
+
+	return nil, nil
+}
@@ -8,7 +8,7 @@ import (
 )
 
 func (b *serviceBundle) deleteBooks(ctx context.Context, r *api.DeleteBooksReq) (any, error) {
-	err := b.bookService.DeleteBooks(ctx, r.IDs)
+	err := b.bookService.DeleteBooks(ctx, r.IDs, r.Force)
 	if err != nil {
 		return nil, fmt.Errorf("delete book: %w", err)
 	}
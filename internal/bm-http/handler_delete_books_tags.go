@@ -0,0 +1,17 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func (b *serviceBundle) deleteBooksTags(ctx context.Context, r *api.DeleteBooksTagsReq) (any, error) {
+	err := b.bookService.DeleteBooksTags(ctx, r.BookIDs, r.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("remove tags from books: %w", err)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,30 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseDeleteCopyReq(r *http.Request) (*api.DeleteCopyReq, error) {
+	req := new(api.DeleteCopyReq)
+	var err error
+	if req.ID, err = strconv.ParseInt(mux.Vars(r)["copy_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) deleteCopy(ctx context.Context, r *api.DeleteCopyReq) (any, error) {
+	if err := b.bookService.DeleteCopy(ctx, r.ID); err != nil {
+		return nil, fmt.Errorf("delete copy: %w", err)
+	}
+
+	return nil, nil
+}
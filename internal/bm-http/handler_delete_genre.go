@@ -0,0 +1,34 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseDeleteGenreReq(r *http.Request) (*api.DeleteGenreReq, error) {
+	v := mux.Vars(r)
+
+	req := new(api.DeleteGenreReq)
+	var err error
+	req.ID, err = strconv.ParseInt(v["genre_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) deleteGenre(ctx context.Context, r *api.DeleteGenreReq) (any, error) {
+	err := b.bookService.DeleteGenre(ctx, r.ID)
+	if err != nil {
+		return nil, fmt.Errorf("delete genre: %w", err)
+	}
+
+	return nil, nil
+}
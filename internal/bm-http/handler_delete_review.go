@@ -0,0 +1,37 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseDeleteReviewReq(r *http.Request) (*api.DeleteReviewReq, error) {
+	v := mux.Vars(r)
+
+	req := new(api.DeleteReviewReq)
+	var err error
+	if req.BookID, err = strconv.ParseInt(v["book_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	if req.ID, err = strconv.ParseInt(v["review_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) deleteReview(ctx context.Context, r *api.DeleteReviewReq) (any, error) {
+	err := b.bookService.DeleteReview(ctx, r.BookID, r.ID)
+	if err != nil {
+		return nil, fmt.Errorf("delete review: %w", err)
+	}
+
+	return nil, nil
+}
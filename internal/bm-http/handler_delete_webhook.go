@@ -0,0 +1,30 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseDeleteWebhookReq(r *http.Request) (*api.DeleteWebhookReq, error) {
+	req := new(api.DeleteWebhookReq)
+	var err error
+	if req.ID, err = strconv.ParseInt(mux.Vars(r)["webhook_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) deleteWebhook(ctx context.Context, r *api.DeleteWebhookReq) (any, error) {
+	if err := b.bookService.DeleteWebhook(ctx, r.ID); err != nil {
+		return nil, fmt.Errorf("delete webhook: %w", err)
+	}
+
+	return nil, nil
+}
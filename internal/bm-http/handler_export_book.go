@@ -0,0 +1,37 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseExportBookReq(r *http.Request) (*api.ExportBookReq, error) {
+	bookID, err := strconv.ParseInt(mux.Vars(r)["book_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	return &api.ExportBookReq{
+		BookID: bookID,
+		Format: r.URL.Query().Get("format"),
+	}, nil
+}
+
+func (b *serviceBundle) exportBook(ctx context.Context, r *api.ExportBookReq) (any, error) {
+	export, err := b.bookService.ExportBook(ctx, r.BookID, r.Format)
+	if err != nil {
+		return nil, fmt.Errorf("export book: %w", err)
+	}
+
+	return &blobResp{
+		contentType: export.ContentType,
+		fileName:    export.FileName,
+		data:        export.Data,
+	}, nil
+}
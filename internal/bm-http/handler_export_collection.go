@@ -0,0 +1,37 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseExportCollectionReq(r *http.Request) (*api.ExportCollectionReq, error) {
+	cID, err := strconv.ParseInt(mux.Vars(r)["collection_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	return &api.ExportCollectionReq{
+		CollectionID: cID,
+		Format:       r.URL.Query().Get("format"),
+	}, nil
+}
+
+func (b *serviceBundle) exportCollection(ctx context.Context, r *api.ExportCollectionReq) (any, error) {
+	export, err := b.bookService.ExportCollection(ctx, r.CollectionID, r.Format)
+	if err != nil {
+		return nil, fmt.Errorf("export collection: %w", err)
+	}
+
+	return &blobResp{
+		contentType: export.ContentType,
+		fileName:    export.FileName,
+		data:        export.Data,
+	}, nil
+}
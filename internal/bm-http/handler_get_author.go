@@ -0,0 +1,38 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetAuthorReq(r *http.Request) (*api.GetAuthorReq, error) {
+	req := &api.GetAuthorReq{}
+
+	var err error
+	v := mux.Vars(r)
+	if idStr := v["author_id"]; idStr != "" {
+		req.ID, err = strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect id: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getAuthor(ctx context.Context, r *api.GetAuthorReq) (any, error) {
+	author, err := b.bookService.Author(ctx, r.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get author: %w", err)
+	}
+
+	return &api.GetAuthorResp{
+		Author: api.Author(*author),
+	}, nil
+}
@@ -0,0 +1,60 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetAuthorsReq(r *http.Request) (*api.GetAuthorsReq, error) {
+	q := r.URL.Query()
+	req := &api.GetAuthorsReq{
+		Name:    q.Get("name"),
+		OrderBy: q.Get("order_by"),
+	}
+
+	var err error
+
+	if descStr := q.Get("desc"); descStr != "" {
+		req.Desc, err = strconv.ParseBool(descStr)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect desc: %w", err)
+		}
+	}
+
+	if pageStr := q.Get("page"); pageStr != "" {
+		req.Page, err = strconv.ParseInt(pageStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page: %w", err)
+		}
+	}
+
+	if pageSizeStr := q.Get("page_size"); pageSizeStr != "" {
+		req.PageSize, err = strconv.ParseInt(pageSizeStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page_size: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getAuthors(ctx context.Context, r *api.GetAuthorsReq) (any, error) {
+	authors, err := b.bookService.Authors(ctx, bm.AuthorsFilter(*r))
+	if err != nil {
+		return nil, fmt.Errorf("get authors: %w", err)
+	}
+
+	authorsResp := make([]api.Author, 0, len(authors))
+	for _, a := range authors {
+		authorsResp = append(authorsResp, api.Author(a))
+	}
+
+	return &api.GetAuthorsResp{
+		Authors: authorsResp,
+	}, nil
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/gorilla/mux"
 
+	bm "github.com/Tsapen/bm/internal/bm"
+	bs "github.com/Tsapen/bm/internal/book-service"
 	"github.com/Tsapen/bm/pkg/api"
 )
 
@@ -33,6 +35,54 @@ func (b *serviceBundle) getBook(ctx context.Context, r *api.GetBookReq) (any, er
 	}
 
 	return &api.GetBookResp{
-		Book: api.Book(*book),
+		Book: newAPIBook(*book),
 	}, nil
 }
+
+func newAPIBook(b bm.Book) api.Book {
+	book := api.Book{
+		ID:            b.ID,
+		Title:         b.Title,
+		Author:        b.Author,
+		PublishedDate: b.PublishedDate,
+		Edition:       b.Edition,
+		Description:   b.Description,
+		Genre:         b.Genre,
+		GenreID:       b.GenreID,
+		ISBN13:        b.ISBN,
+		ISBN10:        bs.ISBN10(b.ISBN),
+		Contributors:  newAPIContributors(b.Contributors),
+		Tags:          append([]string{}, b.Tags...),
+		AverageRating: b.AverageRating,
+		ReviewCount:   b.ReviewCount,
+		CopyCount:     b.CopyCount,
+		Reading:       newAPIBookReading(b.Reading),
+		UpdatedAt:     b.UpdatedAt,
+	}
+
+	if b.Cover != "" {
+		book.CoverURL = coverURL(b.ID)
+		book.ThumbnailURL = thumbnailURL(b.ID)
+	}
+
+	return book
+}
+
+func newAPIBookReading(r *bm.Reading) *api.Reading {
+	if r == nil {
+		return nil
+	}
+
+	reading := newAPIReading(*r)
+
+	return &reading
+}
+
+func newAPIContributors(contributors []bm.Contributor) []api.Contributor {
+	apiContributors := make([]api.Contributor, 0, len(contributors))
+	for _, c := range contributors {
+		apiContributors = append(apiContributors, api.Contributor(c))
+	}
+
+	return apiContributors
+}
@@ -0,0 +1,28 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetBookByISBNReq(r *http.Request) (*api.GetBookByISBNReq, error) {
+	return &api.GetBookByISBNReq{
+		ISBN: mux.Vars(r)["isbn"],
+	}, nil
+}
+
+func (b *serviceBundle) getBookByISBN(ctx context.Context, r *api.GetBookByISBNReq) (any, error) {
+	book, err := b.bookService.BookByISBN(ctx, r.ISBN)
+	if err != nil {
+		return nil, fmt.Errorf("get book by isbn: %w", err)
+	}
+
+	return &api.GetBookResp{
+		Book: newAPIBook(*book),
+	}, nil
+}
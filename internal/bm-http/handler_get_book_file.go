@@ -0,0 +1,42 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	bs "github.com/Tsapen/bm/internal/book-service"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetBookFileReq(r *http.Request) (*api.GetBookFileReq, error) {
+	v := mux.Vars(r)
+
+	req := new(api.GetBookFileReq)
+	var err error
+	if req.BookID, err = strconv.ParseInt(v["book_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	if req.ID, err = strconv.ParseInt(v["file_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getBookFile(ctx context.Context, r *api.GetBookFileReq) (any, error) {
+	file, data, err := b.bookService.BookFile(ctx, r.BookID, r.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get book file: %w", err)
+	}
+
+	return &blobResp{
+		contentType: bs.FileContentType(file.Format),
+		fileName:    file.Name,
+		data:        data,
+	}, nil
+}
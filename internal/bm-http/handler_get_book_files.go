@@ -0,0 +1,56 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetBookFilesReq(r *http.Request) (*api.GetBookFilesReq, error) {
+	bookID, err := strconv.ParseInt(mux.Vars(r)["book_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	return &api.GetBookFilesReq{
+		BookID: bookID,
+	}, nil
+}
+
+func (b *serviceBundle) getBookFiles(ctx context.Context, r *api.GetBookFilesReq) (any, error) {
+	files, err := b.bookService.BookFiles(ctx, r.BookID)
+	if err != nil {
+		return nil, fmt.Errorf("get book files: %w", err)
+	}
+
+	apiFiles := make([]api.BookFile, 0, len(files))
+	for _, f := range files {
+		apiFiles = append(apiFiles, newAPIBookFile(f))
+	}
+
+	return &api.GetBookFilesResp{
+		Files: apiFiles,
+	}, nil
+}
+
+func newAPIBookFile(f bm.BookFile) api.BookFile {
+	return api.BookFile{
+		ID:        f.ID,
+		BookID:    f.BookID,
+		Name:      f.Name,
+		Format:    f.Format,
+		Size:      f.Size,
+		URL:       bookFileURL(f.BookID, f.ID),
+		CreatedAt: f.CreatedAt,
+	}
+}
+
+func bookFileURL(bookID, id int64) string {
+	return "/api/v1/books/" + strconv.FormatInt(bookID, 10) + "/files/" + strconv.FormatInt(id, 10)
+}
@@ -14,13 +14,32 @@ import (
 func parseGetBooksReq(r *http.Request) (*api.GetBooksReq, error) {
 	q := r.URL.Query()
 	req := &api.GetBooksReq{
-		Author:  q.Get("author"),
-		Genre:   q.Get("genre"),
-		OrderBy: q.Get("order_by"),
+		Query:     q.Get("q"),
+		Author:    q.Get("author"),
+		Genre:     q.Get("genre"),
+		ISBN:      q.Get("isbn"),
+		Tags:      q["tags"],
+		TagsMatch: q.Get("tags_match"),
+		Status:    q.Get("status"),
+		OrderBy:   q.Get("order_by"),
 	}
 
 	var err error
 
+	if authorIDStr := q.Get("author_id"); authorIDStr != "" {
+		req.AuthorID, err = strconv.ParseInt(authorIDStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect author_id: %w", err)
+		}
+	}
+
+	if genreIDStr := q.Get("genre_id"); genreIDStr != "" {
+		req.GenreID, err = strconv.ParseInt(genreIDStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect genre_id: %w", err)
+		}
+	}
+
 	if cidStr := q.Get("collection_id"); cidStr != "" {
 		req.CollectionID, err = strconv.ParseInt(cidStr, 10, 64)
 		if err != nil {
@@ -42,6 +61,22 @@ func parseGetBooksReq(r *http.Request) (*api.GetBooksReq, error) {
 		}
 	}
 
+	if minRatingStr := q.Get("min_rating"); minRatingStr != "" {
+		req.MinRating, err = strconv.ParseFloat(minRatingStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect min_rating: %w", err)
+		}
+	}
+
+	if onLoanStr := q.Get("on_loan"); onLoanStr != "" {
+		onLoan, err := strconv.ParseBool(onLoanStr)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect on_loan: %w", err)
+		}
+
+		req.OnLoan = &onLoan
+	}
+
 	if descStr := q.Get("desc"); descStr != "" {
 		req.Desc, err = strconv.ParseBool(descStr)
 		if err != nil {
@@ -68,8 +103,17 @@ func parseGetBooksReq(r *http.Request) (*api.GetBooksReq, error) {
 
 func (b *serviceBundle) getBooks(ctx context.Context, r *api.GetBooksReq) (any, error) {
 	f := bm.BookFilter{
+		Query:        r.Query,
 		Author:       r.Author,
+		AuthorID:     r.AuthorID,
 		Genre:        r.Genre,
+		GenreID:      r.GenreID,
+		Tags:         r.Tags,
+		TagsMatch:    r.TagsMatch,
+		Status:       r.Status,
+		MinRating:    r.MinRating,
+		OnLoan:       r.OnLoan,
+		ISBN:         r.ISBN,
 		CollectionID: r.CollectionID,
 		StartDate:    r.StartDate,
 		FinishDate:   r.FinishDate,
@@ -86,7 +130,7 @@ func (b *serviceBundle) getBooks(ctx context.Context, r *api.GetBooksReq) (any,
 
 	booksResp := make([]api.Book, 0, len(books))
 	for _, b := range books {
-		booksResp = append(booksResp, api.Book(b))
+		booksResp = append(booksResp, newAPIBook(b))
 	}
 
 	return &api.GetBooksResp{
@@ -0,0 +1,26 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetCacheStatsReq(*http.Request) (struct{}, error) {
+	return struct{}{}, nil
+}
+
+func (b *serviceBundle) getCacheStats(ctx context.Context, _ struct{}) (any, error) {
+	stats, err := b.bookService.CacheStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get cache stats: %w", err)
+	}
+
+	return &api.GetCacheStatsResp{
+		Hits:    stats.Hits,
+		Misses:  stats.Misses,
+		Entries: stats.Entries,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetChangesReq(r *http.Request) (*api.GetChangesReq, error) {
+	q := r.URL.Query()
+	req := new(api.GetChangesReq)
+
+	var err error
+	if sinceStr := q.Get("since"); sinceStr != "" {
+		req.Since, err = strconv.ParseInt(sinceStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect since: %w", err)
+		}
+	}
+
+	if limitStr := q.Get("limit"); limitStr != "" {
+		req.Limit, err = strconv.ParseInt(limitStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect limit: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getChanges(ctx context.Context, r *api.GetChangesReq) (any, error) {
+	changes, err := b.bookService.Changes(ctx, bm.ChangesFilter(*r))
+	if err != nil {
+		return nil, fmt.Errorf("get changes: %w", err)
+	}
+
+	// An empty page is resumed from the same seq.
+	resp := &api.GetChangesResp{
+		Changes:   make([]api.Change, 0, len(changes)),
+		NextSince: r.Since,
+	}
+
+	for _, c := range changes {
+		resp.Changes = append(resp.Changes, newAPIChange(c))
+		resp.NextSince = c.Seq
+	}
+
+	return resp, nil
+}
+
+func newAPIChange(c bm.Change) api.Change {
+	return api.Change{
+		Seq:       c.Seq,
+		Entity:    c.Entity,
+		EntityID:  c.EntityID,
+		Op:        c.Op,
+		Data:      c.Data,
+		CreatedAt: c.CreatedAt,
+	}
+}
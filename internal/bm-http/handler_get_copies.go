@@ -0,0 +1,75 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+// parseGetCopiesReq parses a request for copies of a book from /books/{book_id}/copies or for copies selected by query.
+func parseGetCopiesReq(r *http.Request) (*api.GetCopiesReq, error) {
+	q := r.URL.Query()
+	req := &api.GetCopiesReq{
+		Location: q.Get("location"),
+		OrderBy:  q.Get("order_by"),
+	}
+
+	var err error
+
+	bookIDStr := mux.Vars(r)["book_id"]
+	if bookIDStr == "" {
+		bookIDStr = q.Get("book_id")
+	}
+
+	if bookIDStr != "" {
+		req.BookID, err = strconv.ParseInt(bookIDStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect book_id: %w", err)
+		}
+	}
+
+	if descStr := q.Get("desc"); descStr != "" {
+		req.Desc, err = strconv.ParseBool(descStr)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect desc: %w", err)
+		}
+	}
+
+	if pageStr := q.Get("page"); pageStr != "" {
+		req.Page, err = strconv.ParseInt(pageStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page: %w", err)
+		}
+	}
+
+	if pageSizeStr := q.Get("page_size"); pageSizeStr != "" {
+		req.PageSize, err = strconv.ParseInt(pageSizeStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page_size: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getCopies(ctx context.Context, r *api.GetCopiesReq) (any, error) {
+	copies, err := b.bookService.Copies(ctx, bm.CopiesFilter(*r))
+	if err != nil {
+		return nil, fmt.Errorf("get copies: %w", err)
+	}
+
+	copiesResp := make([]api.Copy, 0, len(copies))
+	for _, c := range copies {
+		copiesResp = append(copiesResp, newAPICopy(c))
+	}
+
+	return &api.GetCopiesResp{
+		Copies: copiesResp,
+	}, nil
+}
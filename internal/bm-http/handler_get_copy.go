@@ -0,0 +1,48 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetCopyReq(r *http.Request) (*api.GetCopyReq, error) {
+	req := &api.GetCopyReq{}
+
+	var err error
+	if req.ID, err = strconv.ParseInt(mux.Vars(r)["copy_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("incorrect id: %w", err)
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getCopy(ctx context.Context, r *api.GetCopyReq) (any, error) {
+	c, err := b.bookService.Copy(ctx, r.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get copy: %w", err)
+	}
+
+	return &api.GetCopyResp{
+		Copy: newAPICopy(*c),
+	}, nil
+}
+
+func newAPICopy(c bm.Copy) api.Copy {
+	return api.Copy{
+		ID:         c.ID,
+		BookID:     c.BookID,
+		BookTitle:  c.BookTitle,
+		Barcode:    c.Barcode,
+		Condition:  c.Condition,
+		AcquiredAt: formatDate(c.AcquiredAt),
+		Price:      c.Price,
+		Location:   c.Location,
+	}
+}
@@ -0,0 +1,28 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetCopyByBarcodeReq(r *http.Request) (*api.GetCopyByBarcodeReq, error) {
+	return &api.GetCopyByBarcodeReq{
+		Barcode: mux.Vars(r)["barcode"],
+	}, nil
+}
+
+func (b *serviceBundle) getCopyByBarcode(ctx context.Context, r *api.GetCopyByBarcodeReq) (any, error) {
+	c, err := b.bookService.CopyByBarcode(ctx, r.Barcode)
+	if err != nil {
+		return nil, fmt.Errorf("get copy by barcode: %w", err)
+	}
+
+	return &api.GetCopyResp{
+		Copy: newAPICopy(*c),
+	}, nil
+}
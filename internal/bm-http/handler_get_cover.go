@@ -0,0 +1,80 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+const (
+	coverSizeThumbnail = "thumbnail"
+
+	// coverCacheControl lets clients reuse covers for a day; replaced covers get a new ETag.
+	coverCacheControl = "private, max-age=86400"
+)
+
+func parseGetCoverReq(r *http.Request) (*api.GetCoverReq, error) {
+	bookID, err := strconv.ParseInt(mux.Vars(r)["book_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	return &api.GetCoverReq{
+		BookID: bookID,
+		Size:   r.URL.Query().Get("size"),
+		ETag:   r.Header.Get("If-None-Match"),
+	}, nil
+}
+
+func (b *serviceBundle) getCover(ctx context.Context, r *api.GetCoverReq) (any, error) {
+	switch r.Size {
+	case "", coverSizeThumbnail:
+	default:
+		return nil, bm.NewValidationError("incorrect size")
+	}
+
+	cover, err := b.bookService.Cover(ctx, r.BookID, r.Size == coverSizeThumbnail)
+	if err != nil {
+		return nil, fmt.Errorf("get cover: %w", err)
+	}
+
+	etag := strconv.Quote(cover.ID)
+	if r.Size != "" {
+		etag = strconv.Quote(cover.ID + "-" + r.Size)
+	}
+
+	return &blobResp{
+		contentType:  cover.ContentType,
+		cacheControl: coverCacheControl,
+		etag:         etag,
+		notModified:  etagMatches(r.ETag, etag),
+		data:         cover.Data,
+	}, nil
+}
+
+// etagMatches checks an If-None-Match header against the current ETag using weak comparison.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func coverURL(bookID int64) string {
+	return "/api/v1/books/" + strconv.FormatInt(bookID, 10) + "/cover"
+}
+
+func thumbnailURL(bookID int64) string {
+	return coverURL(bookID) + "?size=" + coverSizeThumbnail
+}
@@ -0,0 +1,38 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetGenreReq(r *http.Request) (*api.GetGenreReq, error) {
+	req := &api.GetGenreReq{}
+
+	var err error
+	v := mux.Vars(r)
+	if idStr := v["genre_id"]; idStr != "" {
+		req.ID, err = strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect id: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getGenre(ctx context.Context, r *api.GetGenreReq) (any, error) {
+	genre, err := b.bookService.Genre(ctx, r.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get genre: %w", err)
+	}
+
+	return &api.GetGenreResp{
+		Genre: api.Genre(*genre),
+	}, nil
+}
@@ -0,0 +1,67 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetGenresReq(r *http.Request) (*api.GetGenresReq, error) {
+	q := r.URL.Query()
+	req := &api.GetGenresReq{
+		Name:    q.Get("name"),
+		OrderBy: q.Get("order_by"),
+	}
+
+	var err error
+
+	if parentIDStr := q.Get("parent_id"); parentIDStr != "" {
+		req.ParentID, err = strconv.ParseInt(parentIDStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect parent_id: %w", err)
+		}
+	}
+
+	if descStr := q.Get("desc"); descStr != "" {
+		req.Desc, err = strconv.ParseBool(descStr)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect desc: %w", err)
+		}
+	}
+
+	if pageStr := q.Get("page"); pageStr != "" {
+		req.Page, err = strconv.ParseInt(pageStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page: %w", err)
+		}
+	}
+
+	if pageSizeStr := q.Get("page_size"); pageSizeStr != "" {
+		req.PageSize, err = strconv.ParseInt(pageSizeStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page_size: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getGenres(ctx context.Context, r *api.GetGenresReq) (any, error) {
+	genres, err := b.bookService.Genres(ctx, bm.GenresFilter(*r))
+	if err != nil {
+		return nil, fmt.Errorf("get genres: %w", err)
+	}
+
+	genresResp := make([]api.Genre, 0, len(genres))
+	for _, a := range genres {
+		genresResp = append(genresResp, api.Genre(a))
+	}
+
+	return &api.GetGenresResp{
+		Genres: genresResp,
+	}, nil
+}
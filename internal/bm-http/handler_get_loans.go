@@ -0,0 +1,89 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetLoansReq(r *http.Request) (*api.GetLoansReq, error) {
+	q := r.URL.Query()
+	req := &api.GetLoansReq{
+		OrderBy: q.Get("order_by"),
+	}
+
+	var err error
+
+	if descStr := q.Get("desc"); descStr != "" {
+		req.Desc, err = strconv.ParseBool(descStr)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect desc: %w", err)
+		}
+	}
+
+	if pageStr := q.Get("page"); pageStr != "" {
+		req.Page, err = strconv.ParseInt(pageStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page: %w", err)
+		}
+	}
+
+	if pageSizeStr := q.Get("page_size"); pageSizeStr != "" {
+		req.PageSize, err = strconv.ParseInt(pageSizeStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page_size: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getLoans(ctx context.Context, r *api.GetLoansReq) (any, error) {
+	loans, err := b.bookService.Loans(ctx, newLoansFilter(r))
+	if err != nil {
+		return nil, fmt.Errorf("get loans: %w", err)
+	}
+
+	return newGetLoansResp(loans), nil
+}
+
+func (b *serviceBundle) getOverdueLoans(ctx context.Context, r *api.GetLoansReq) (any, error) {
+	loans, err := b.bookService.OverdueLoans(ctx, newLoansFilter(r))
+	if err != nil {
+		return nil, fmt.Errorf("get overdue loans: %w", err)
+	}
+
+	return newGetLoansResp(loans), nil
+}
+
+func newLoansFilter(r *api.GetLoansReq) bm.LoansFilter {
+	return bm.LoansFilter{
+		OrderBy:  r.OrderBy,
+		Desc:     r.Desc,
+		Page:     r.Page,
+		PageSize: r.PageSize,
+	}
+}
+
+func newGetLoansResp(loans []bm.Loan) *api.GetLoansResp {
+	loansResp := make([]api.Loan, 0, len(loans))
+	for _, l := range loans {
+		loansResp = append(loansResp, api.Loan{
+			ID:        l.ID,
+			BookID:    l.BookID,
+			BookTitle: l.BookTitle,
+			Borrower:  l.Borrower,
+			LentAt:    l.LentAt.Format(time.DateOnly),
+			DueAt:     l.DueAt.Format(time.DateOnly),
+		})
+	}
+
+	return &api.GetLoansResp{
+		Loans: loansResp,
+	}
+}
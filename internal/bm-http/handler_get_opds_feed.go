@@ -0,0 +1,116 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetOPDSFeedReq(r *http.Request) (*api.GetOPDSFeedReq, error) {
+	req := &api.GetOPDSFeedReq{
+		Query: r.URL.Query().Get("q"),
+	}
+
+	var err error
+	if cidStr, ok := mux.Vars(r)["collection_id"]; ok {
+		req.CollectionID, err = strconv.ParseInt(cidStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse request: %w", err)
+		}
+	}
+
+	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+		req.Page, err = strconv.ParseInt(pageStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+// getOPDSRoot lists recent additions and collections of the caller by pages, the first page starts
+// with recent additions.
+func (b *serviceBundle) getOPDSRoot(ctx context.Context, r *api.GetOPDSFeedReq) (any, error) {
+	if r.Page < 0 {
+		return nil, bm.NewValidationError("incorrect page")
+	}
+
+	page := max(r.Page, 1)
+	collections, err := b.bookService.Collections(ctx, bm.CollectionsFilter{OrderBy: "name", Page: page, PageSize: opdsPageSize})
+	if err != nil {
+		return nil, fmt.Errorf("get collections: %w", err)
+	}
+
+	feed := newFeed("urn:bm:opds", "Library", opdsPageURL(opdsPath, nil, page), opdsNavigation)
+	addPageLinks(feed, opdsPath, nil, page, len(collections) == opdsPageSize, opdsNavigation)
+	if page == 1 {
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      "urn:bm:opds:recent",
+			Title:   "Recent additions",
+			Updated: feed.Updated,
+			Content: &atomContent{Type: "text", Value: "Books added to the library most recently."},
+			Links:   []atomLink{{Rel: opdsSortNewRel, Href: opdsPath + "/recent", Type: opdsAcquisition}},
+		})
+	}
+
+	for _, c := range collections {
+		e := atomEntry{
+			ID:      "urn:bm:collection:" + strconv.FormatInt(c.ID, 10),
+			Title:   c.Name,
+			Updated: feed.Updated,
+			Links:   []atomLink{{Rel: "subsection", Href: opdsCollectionURL(c.ID), Type: opdsAcquisition}},
+		}
+
+		if c.Description != "" {
+			e.Content = &atomContent{Type: "text", Value: c.Description}
+		}
+
+		feed.Entries = append(feed.Entries, e)
+	}
+
+	return renderXML(feed, opdsNavigation)
+}
+
+// getOPDSRecent lists books from the most recently added.
+func (b *serviceBundle) getOPDSRecent(ctx context.Context, r *api.GetOPDSFeedReq) (any, error) {
+	f := bm.BookFilter{OrderBy: "id", Desc: true, Page: r.Page}
+
+	return b.acquisitionFeed(ctx, "urn:bm:opds:recent", "Recent additions", opdsPath+"/recent", nil, f)
+}
+
+// getOPDSCollection lists books of a collection by title.
+func (b *serviceBundle) getOPDSCollection(ctx context.Context, r *api.GetOPDSFeedReq) (any, error) {
+	c, err := b.bookService.Collection(ctx, r.CollectionID)
+	if err != nil {
+		return nil, fmt.Errorf("get collection: %w", err)
+	}
+
+	f := bm.BookFilter{CollectionID: c.ID, OrderBy: "title", Page: r.Page}
+	id := "urn:bm:collection:" + strconv.FormatInt(c.ID, 10)
+
+	return b.acquisitionFeed(ctx, id, c.Name, opdsCollectionURL(c.ID), nil, f)
+}
+
+// searchOPDS lists books which title or author contain the query.
+func (b *serviceBundle) searchOPDS(ctx context.Context, r *api.GetOPDSFeedReq) (any, error) {
+	if r.Query == "" {
+		return nil, bm.NewValidationError("q is empty")
+	}
+
+	f := bm.BookFilter{Query: r.Query, OrderBy: "title", Page: r.Page}
+	query := url.Values{"q": []string{r.Query}}
+
+	return b.acquisitionFeed(ctx, "urn:bm:opds:search", "Search: "+r.Query, opdsPath+"/search", query, f)
+}
+
+func opdsCollectionURL(id int64) string {
+	return opdsPath + "/collections/" + strconv.FormatInt(id, 10)
+}
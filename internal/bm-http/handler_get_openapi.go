@@ -0,0 +1,34 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Tsapen/bm/internal/openapi"
+)
+
+func parseGetOpenAPIReq(*http.Request) (struct{}, error) {
+	return struct{}{}, nil
+}
+
+// getOpenAPI serves the OpenAPI document of the version. The document is encoded once.
+func getOpenAPI(version openapi.Version) (func(context.Context, struct{}) (any, error), error) {
+	doc, err := openapi.Load(version)
+	if err != nil {
+		return nil, fmt.Errorf("load openapi document: %w", err)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal openapi document: %w", err)
+	}
+
+	return func(context.Context, struct{}) (any, error) {
+		return &blobResp{
+			contentType: jsonContentType,
+			data:        data,
+		}, nil
+	}, nil
+}
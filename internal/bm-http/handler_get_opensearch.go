@@ -0,0 +1,30 @@
+package bmhttp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetOpenSearchReq(r *http.Request) (*api.GetOpenSearchReq, error) {
+	return &api.GetOpenSearchReq{
+		BaseURL: baseURL(r),
+	}, nil
+}
+
+// getOpenSearch describes the search of the catalog. The template is absolute, since clients don't resolve
+// relative templates against the address of the description.
+func (b *serviceBundle) getOpenSearch(_ context.Context, r *api.GetOpenSearchReq) (any, error) {
+	return renderXML(&openSearchDescription{
+		Xmlns:       "http://a9.com/-/spec/opensearch/1.1/",
+		ShortName:   "bm",
+		Description: "Search books by title or author",
+		InputEnc:    "UTF-8",
+		OutputEnc:   "UTF-8",
+		URL: openSearchURL{
+			Type:     opdsAcquisition,
+			Template: r.BaseURL + opdsPath + "/search?q={searchTerms}",
+		},
+	}, openSearchType)
+}
@@ -0,0 +1,58 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetReadingReq(r *http.Request) (*api.GetReadingReq, error) {
+	req := &api.GetReadingReq{}
+
+	var err error
+	v := mux.Vars(r)
+	if idStr := v["book_id"]; idStr != "" {
+		req.BookID, err = strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect book_id: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getReading(ctx context.Context, r *api.GetReadingReq) (any, error) {
+	reading, err := b.bookService.Reading(ctx, r.BookID)
+	if err != nil {
+		return nil, fmt.Errorf("get reading: %w", err)
+	}
+
+	return &api.GetReadingResp{
+		Reading: newAPIReading(*reading),
+	}, nil
+}
+
+func newAPIReading(r bm.Reading) api.Reading {
+	return api.Reading{
+		Status:      r.Status,
+		StartedAt:   formatDate(r.StartedAt),
+		FinishedAt:  formatDate(r.FinishedAt),
+		CurrentPage: r.CurrentPage,
+		Notes:       r.Notes,
+	}
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.DateOnly)
+}
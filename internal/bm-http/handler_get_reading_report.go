@@ -0,0 +1,42 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetReadingReportReq(r *http.Request) (*api.GetReadingReportReq, error) {
+	req := &api.GetReadingReportReq{}
+
+	var err error
+	if yearStr := r.URL.Query().Get("year"); yearStr != "" {
+		req.Year, err = strconv.Atoi(yearStr)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect year: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getReadingReport(ctx context.Context, r *api.GetReadingReportReq) (any, error) {
+	report, err := b.bookService.ReadingReport(ctx, r.Year)
+	if err != nil {
+		return nil, fmt.Errorf("get reading report: %w", err)
+	}
+
+	booksResp := make([]api.Book, 0, len(report.Books))
+	for _, b := range report.Books {
+		booksResp = append(booksResp, newAPIBook(b))
+	}
+
+	return &api.GetReadingReportResp{
+		Year:     report.Year,
+		Finished: len(report.Books),
+		Books:    booksResp,
+	}, nil
+}
@@ -0,0 +1,39 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetReviewReq(r *http.Request) (*api.GetReviewReq, error) {
+	req := &api.GetReviewReq{}
+
+	var err error
+	v := mux.Vars(r)
+	if req.BookID, err = strconv.ParseInt(v["book_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("incorrect book_id: %w", err)
+	}
+
+	if req.ID, err = strconv.ParseInt(v["review_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("incorrect id: %w", err)
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getReview(ctx context.Context, r *api.GetReviewReq) (any, error) {
+	review, err := b.bookService.Review(ctx, r.BookID, r.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get review: %w", err)
+	}
+
+	return &api.GetReviewResp{
+		Review: api.Review(*review),
+	}, nil
+}
@@ -0,0 +1,65 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetReviewsReq(r *http.Request) (*api.GetReviewsReq, error) {
+	q := r.URL.Query()
+	req := &api.GetReviewsReq{
+		OrderBy: q.Get("order_by"),
+	}
+
+	var err error
+
+	if req.BookID, err = strconv.ParseInt(mux.Vars(r)["book_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("incorrect book_id: %w", err)
+	}
+
+	if descStr := q.Get("desc"); descStr != "" {
+		req.Desc, err = strconv.ParseBool(descStr)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect desc: %w", err)
+		}
+	}
+
+	if pageStr := q.Get("page"); pageStr != "" {
+		req.Page, err = strconv.ParseInt(pageStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page: %w", err)
+		}
+	}
+
+	if pageSizeStr := q.Get("page_size"); pageSizeStr != "" {
+		req.PageSize, err = strconv.ParseInt(pageSizeStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page_size: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getReviews(ctx context.Context, r *api.GetReviewsReq) (any, error) {
+	reviews, err := b.bookService.Reviews(ctx, bm.ReviewsFilter(*r))
+	if err != nil {
+		return nil, fmt.Errorf("get reviews: %w", err)
+	}
+
+	reviewsResp := make([]api.Review, 0, len(reviews))
+	for _, r := range reviews {
+		reviewsResp = append(reviewsResp, api.Review(r))
+	}
+
+	return &api.GetReviewsResp{
+		Reviews: reviewsResp,
+	}, nil
+}
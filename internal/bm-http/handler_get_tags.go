@@ -0,0 +1,59 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetTagsReq(r *http.Request) (*api.GetTagsReq, error) {
+	q := r.URL.Query()
+	req := &api.GetTagsReq{
+		OrderBy: q.Get("order_by"),
+	}
+
+	var err error
+
+	if descStr := q.Get("desc"); descStr != "" {
+		req.Desc, err = strconv.ParseBool(descStr)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect desc: %w", err)
+		}
+	}
+
+	if pageStr := q.Get("page"); pageStr != "" {
+		req.Page, err = strconv.ParseInt(pageStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page: %w", err)
+		}
+	}
+
+	if pageSizeStr := q.Get("page_size"); pageSizeStr != "" {
+		req.PageSize, err = strconv.ParseInt(pageSizeStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page_size: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getTags(ctx context.Context, r *api.GetTagsReq) (any, error) {
+	tags, err := b.bookService.Tags(ctx, bm.TagsFilter(*r))
+	if err != nil {
+		return nil, fmt.Errorf("get tags: %w", err)
+	}
+
+	tagsResp := make([]api.Tag, 0, len(tags))
+	for _, a := range tags {
+		tagsResp = append(tagsResp, api.Tag(a))
+	}
+
+	return &api.GetTagsResp{
+		Tags: tagsResp,
+	}, nil
+}
@@ -0,0 +1,46 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetWebhookReq(r *http.Request) (*api.GetWebhookReq, error) {
+	req := &api.GetWebhookReq{}
+
+	var err error
+	if req.ID, err = strconv.ParseInt(mux.Vars(r)["webhook_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("incorrect id: %w", err)
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getWebhook(ctx context.Context, r *api.GetWebhookReq) (any, error) {
+	w, err := b.bookService.Webhook(ctx, r.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get webhook: %w", err)
+	}
+
+	return &api.GetWebhookResp{
+		Webhook: newAPIWebhook(*w),
+	}, nil
+}
+
+// newAPIWebhook converts a webhook without its secret.
+func newAPIWebhook(w bm.Webhook) api.Webhook {
+	return api.Webhook{
+		ID:        w.ID,
+		URL:       w.URL,
+		Events:    w.Events,
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt,
+	}
+}
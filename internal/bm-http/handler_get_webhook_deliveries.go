@@ -0,0 +1,57 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetWebhookDeliveriesReq(r *http.Request) (*api.GetWebhookDeliveriesReq, error) {
+	q := r.URL.Query()
+	req := &api.GetWebhookDeliveriesReq{
+		Status: q.Get("status"),
+	}
+
+	var err error
+	if req.WebhookID, err = strconv.ParseInt(mux.Vars(r)["webhook_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("incorrect webhook_id: %w", err)
+	}
+
+	if pageStr := q.Get("page"); pageStr != "" {
+		req.Page, err = strconv.ParseInt(pageStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page: %w", err)
+		}
+	}
+
+	if pageSizeStr := q.Get("page_size"); pageSizeStr != "" {
+		req.PageSize, err = strconv.ParseInt(pageSizeStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page_size: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getWebhookDeliveries(ctx context.Context, r *api.GetWebhookDeliveriesReq) (any, error) {
+	deliveries, err := b.bookService.WebhookDeliveries(ctx, bm.WebhookDeliveriesFilter(*r))
+	if err != nil {
+		return nil, fmt.Errorf("get webhook deliveries: %w", err)
+	}
+
+	deliveriesResp := make([]api.WebhookDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		deliveriesResp = append(deliveriesResp, newAPIWebhookDelivery(d))
+	}
+
+	return &api.GetWebhookDeliveriesResp{
+		Deliveries: deliveriesResp,
+	}, nil
+}
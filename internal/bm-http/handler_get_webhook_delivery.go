@@ -0,0 +1,70 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetWebhookDeliveryReq(r *http.Request) (*api.GetWebhookDeliveryReq, error) {
+	vars := mux.Vars(r)
+	req := &api.GetWebhookDeliveryReq{}
+
+	var err error
+	if req.WebhookID, err = strconv.ParseInt(vars["webhook_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("incorrect webhook_id: %w", err)
+	}
+
+	if req.ID, err = strconv.ParseInt(vars["delivery_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("incorrect id: %w", err)
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getWebhookDelivery(ctx context.Context, r *api.GetWebhookDeliveryReq) (any, error) {
+	d, err := b.bookService.WebhookDelivery(ctx, r.WebhookID, r.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get webhook delivery: %w", err)
+	}
+
+	return &api.GetWebhookDeliveryResp{
+		Delivery: newAPIWebhookDelivery(*d),
+	}, nil
+}
+
+func newAPIWebhookDelivery(d bm.WebhookDelivery) api.WebhookDelivery {
+	delivery := api.WebhookDelivery{
+		ID:           d.ID,
+		WebhookID:    d.WebhookID,
+		EventID:      d.EventID,
+		EventType:    d.EventType,
+		Status:       d.Status,
+		AttemptCount: d.Attempts,
+		LastError:    d.LastError,
+		CreatedAt:    d.CreatedAt,
+		UpdatedAt:    d.UpdatedAt,
+	}
+
+	if d.Status == bm.DeliveryPending {
+		delivery.NextAttemptAt = &d.NextAttemptAt
+	}
+
+	for _, a := range d.AttemptLog {
+		delivery.Attempts = append(delivery.Attempts, api.WebhookAttempt{
+			Attempt:    a.Attempt,
+			StatusCode: a.StatusCode,
+			Error:      a.Error,
+			DurationMS: a.Duration.Milliseconds(),
+			CreatedAt:  a.CreatedAt,
+		})
+	}
+
+	return delivery
+}
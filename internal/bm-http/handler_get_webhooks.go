@@ -0,0 +1,50 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseGetWebhooksReq(r *http.Request) (*api.GetWebhooksReq, error) {
+	q := r.URL.Query()
+	req := &api.GetWebhooksReq{}
+
+	var err error
+
+	if pageStr := q.Get("page"); pageStr != "" {
+		req.Page, err = strconv.ParseInt(pageStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page: %w", err)
+		}
+	}
+
+	if pageSizeStr := q.Get("page_size"); pageSizeStr != "" {
+		req.PageSize, err = strconv.ParseInt(pageSizeStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect page_size: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) getWebhooks(ctx context.Context, r *api.GetWebhooksReq) (any, error) {
+	webhooks, err := b.bookService.Webhooks(ctx, bm.WebhooksFilter(*r))
+	if err != nil {
+		return nil, fmt.Errorf("get webhooks: %w", err)
+	}
+
+	webhooksResp := make([]api.Webhook, 0, len(webhooks))
+	for _, w := range webhooks {
+		webhooksResp = append(webhooksResp, newAPIWebhook(w))
+	}
+
+	return &api.GetWebhooksResp{
+		Webhooks: webhooksResp,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	bmgraphql "github.com/Tsapen/bm/internal/bm-graphql"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+// graphQLReq is a GraphQL request. GET requests carry the operation in the query string and can't mutate.
+type graphQLReq struct {
+	api.GraphQLReq
+
+	QueryOnly bool
+}
+
+func parseGraphQLReq(r *http.Request) (*graphQLReq, error) {
+	if r.Method == http.MethodPost {
+		req, err := parseJSONReq[api.GraphQLReq](r)
+		if err != nil {
+			return nil, err
+		}
+
+		return &graphQLReq{GraphQLReq: *req}, nil
+	}
+
+	q := r.URL.Query()
+	req := &graphQLReq{
+		GraphQLReq: api.GraphQLReq{
+			Query:         q.Get("query"),
+			OperationName: q.Get("operationName"),
+		},
+		QueryOnly: true,
+	}
+
+	if variables := q.Get("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+			return nil, bm.NewValidationError("incorrect variables: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) graphQL(ctx context.Context, r *graphQLReq) (any, error) {
+	result, err := b.graphQLExecutor.Execute(ctx, bmgraphql.Request{
+		Query:         r.Query,
+		OperationName: r.OperationName,
+		Variables:     r.Variables,
+		QueryOnly:     r.QueryOnly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("execute: %w", err)
+	}
+
+	return result, nil
+}
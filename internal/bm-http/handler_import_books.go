@@ -0,0 +1,73 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	bs "github.com/Tsapen/bm/internal/book-service"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseImportBooksReq(r *http.Request) (*api.ImportBooksReq, error) {
+	req := &api.ImportBooksReq{
+		Genre: r.URL.Query().Get("genre"),
+	}
+
+	var err error
+	if dryRunStr := r.URL.Query().Get("dry_run"); dryRunStr != "" {
+		req.DryRun, err = strconv.ParseBool(dryRunStr)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect dry_run: %w", err)
+		}
+	}
+
+	req.Data, err = io.ReadAll(io.LimitReader(r.Body, bs.MaxImportSize+1))
+	if err != nil {
+		return nil, bm.NewValidationError("read export: %w", err)
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) importBooks(ctx context.Context, r *api.ImportBooksReq) (any, error) {
+	report, err := b.bookService.ImportBooks(ctx, r.Data, r.Genre, r.DryRun)
+	if err != nil {
+		return nil, fmt.Errorf("import books: %w", err)
+	}
+
+	return newImportBooksResp(report), nil
+}
+
+func newImportBooksResp(report *bs.ImportReport) *api.ImportBooksResp {
+	books := make([]api.ImportedBook, 0, len(report.Books))
+	for _, b := range report.Books {
+		books = append(books, api.ImportedBook{
+			Line:     b.Line,
+			Title:    b.Title,
+			Author:   b.Author,
+			Year:     b.Year,
+			ISBN:     b.ISBN,
+			Shelves:  b.Shelves,
+			Unmapped: b.Unmapped,
+			Action:   b.Action,
+			BookID:   b.BookID,
+			Error:    b.Error,
+		})
+	}
+
+	newCollections := report.NewCollections
+	if newCollections == nil {
+		newCollections = []string{}
+	}
+
+	return &api.ImportBooksResp{
+		Layout:         report.Layout,
+		DryRun:         report.DryRun,
+		Books:          books,
+		NewCollections: newCollections,
+	}
+}
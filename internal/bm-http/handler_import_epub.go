@@ -0,0 +1,37 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	bs "github.com/Tsapen/bm/internal/book-service"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseImportEPUBReq(r *http.Request) (*api.ImportEPUBReq, error) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, bs.MaxFileSize+1))
+	if err != nil {
+		return nil, bm.NewValidationError("read file: %w", err)
+	}
+
+	return &api.ImportEPUBReq{
+		Name:  r.URL.Query().Get("name"),
+		Genre: r.URL.Query().Get("genre"),
+		Data:  data,
+	}, nil
+}
+
+func (b *serviceBundle) importEPUB(ctx context.Context, r *api.ImportEPUBReq) (any, error) {
+	bookID, fileID, err := b.bookService.ImportEPUB(ctx, r.Name, r.Genre, r.Data)
+	if err != nil {
+		return nil, fmt.Errorf("import epub: %w", err)
+	}
+
+	return &api.ImportEPUBResp{
+		ID:     bookID,
+		FileID: fileID,
+	}, nil
+}
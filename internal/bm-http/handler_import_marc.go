@@ -0,0 +1,43 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	bs "github.com/Tsapen/bm/internal/book-service"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseImportMARCReq(r *http.Request) (*api.ImportMARCReq, error) {
+	req := &api.ImportMARCReq{
+		Genre: r.URL.Query().Get("genre"),
+	}
+
+	var err error
+	if dryRunStr := r.URL.Query().Get("dry_run"); dryRunStr != "" {
+		req.DryRun, err = strconv.ParseBool(dryRunStr)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect dry_run: %w", err)
+		}
+	}
+
+	req.Data, err = io.ReadAll(io.LimitReader(r.Body, bs.MaxImportSize+1))
+	if err != nil {
+		return nil, bm.NewValidationError("read records: %w", err)
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) importMARC(ctx context.Context, r *api.ImportMARCReq) (any, error) {
+	report, err := b.bookService.ImportMARC(ctx, r.Data, r.Genre, r.DryRun)
+	if err != nil {
+		return nil, fmt.Errorf("import marc: %w", err)
+	}
+
+	return newImportBooksResp(report), nil
+}
@@ -0,0 +1,60 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseLendBookReq(r *http.Request) (*api.LendBookReq, error) {
+	req := new(api.LendBookReq)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	bookID, err := strconv.ParseInt(mux.Vars(r)["book_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	req.BookID = bookID
+
+	return req, nil
+}
+
+func (b *serviceBundle) lendBook(ctx context.Context, r *api.LendBookReq) (any, error) {
+	loan := bm.Loan{
+		BookID:   r.BookID,
+		Borrower: r.Borrower,
+	}
+
+	var err error
+	if r.LentAt != "" {
+		if loan.LentAt, err = time.Parse(time.DateOnly, r.LentAt); err != nil {
+			return nil, bm.NewValidationError("incorrect lent_at: %w", err)
+		}
+	}
+
+	if r.DueAt != "" {
+		if loan.DueAt, err = time.Parse(time.DateOnly, r.DueAt); err != nil {
+			return nil, bm.NewValidationError("incorrect due_at: %w", err)
+		}
+	}
+
+	id, err := b.bookService.LendBook(ctx, loan)
+	if err != nil {
+		return nil, fmt.Errorf("lend book: %w", err)
+	}
+
+	return &api.LendBookResp{
+		ID: id,
+	}, nil
+}
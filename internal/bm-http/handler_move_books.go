@@ -0,0 +1,17 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func (b *serviceBundle) moveBooks(ctx context.Context, r *api.MoveBooksReq) (any, error) {
+	err := b.bookService.MoveBooks(ctx, r.IDs, r.Tenant)
+	if err != nil {
+		return nil, fmt.Errorf("move books: %w", err)
+	}
+
+	return nil, nil
+}
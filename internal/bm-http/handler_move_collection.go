@@ -0,0 +1,40 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseMoveCollectionReq(r *http.Request) (*api.MoveCollectionReq, error) {
+	req := new(api.MoveCollectionReq)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	v := mux.Vars(r)
+
+	reqID, err := strconv.ParseInt(v["collection_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	req.ID = reqID
+
+	return req, nil
+}
+
+func (b *serviceBundle) moveCollection(ctx context.Context, r *api.MoveCollectionReq) (any, error) {
+	err := b.bookService.MoveCollection(ctx, r.ID, r.Tenant)
+	if err != nil {
+		return nil, fmt.Errorf("move collection: %w", err)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,39 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseRedeliverWebhookEventReq(r *http.Request) (*api.RedeliverWebhookEventReq, error) {
+	vars := mux.Vars(r)
+	req := &api.RedeliverWebhookEventReq{}
+
+	var err error
+	if req.WebhookID, err = strconv.ParseInt(vars["webhook_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("incorrect webhook_id: %w", err)
+	}
+
+	if req.DeliveryID, err = strconv.ParseInt(vars["delivery_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("incorrect delivery_id: %w", err)
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) redeliverWebhookEvent(ctx context.Context, r *api.RedeliverWebhookEventReq) (any, error) {
+	id, err := b.bookService.RedeliverWebhookEvent(ctx, r.WebhookID, r.DeliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("redeliver webhook event: %w", err)
+	}
+
+	return &api.RedeliverWebhookEventResp{
+		ID: id,
+	}, nil
+}
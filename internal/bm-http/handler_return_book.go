@@ -0,0 +1,49 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseReturnBookReq(r *http.Request) (*api.ReturnBookReq, error) {
+	req := new(api.ReturnBookReq)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	bookID, err := strconv.ParseInt(mux.Vars(r)["book_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	req.BookID = bookID
+
+	return req, nil
+}
+
+func (b *serviceBundle) returnBook(ctx context.Context, r *api.ReturnBookReq) (any, error) {
+	var (
+		returnedAt time.Time
+		err        error
+	)
+	if r.ReturnedAt != "" {
+		if returnedAt, err = time.Parse(time.DateOnly, r.ReturnedAt); err != nil {
+			return nil, bm.NewValidationError("incorrect returned_at: %w", err)
+		}
+	}
+
+	if err = b.bookService.ReturnBook(ctx, r.BookID, returnedAt); err != nil {
+		return nil, fmt.Errorf("return book: %w", err)
+	}
+
+	return nil, nil
+}
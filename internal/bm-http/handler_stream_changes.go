@@ -0,0 +1,69 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+// parseStreamChangesReq takes the seq to resume the stream after from the Last-Event-ID header sent by
+// reconnecting clients or from the since parameter.
+func parseStreamChangesReq(r *http.Request) (*api.StreamChangesReq, error) {
+	req := new(api.StreamChangesReq)
+
+	sinceStr := r.Header.Get("Last-Event-ID")
+	if sinceStr == "" {
+		sinceStr = r.URL.Query().Get("since")
+	}
+
+	if sinceStr != "" {
+		var err error
+		if req.Since, err = strconv.ParseInt(sinceStr, 10, 64); err != nil {
+			return nil, fmt.Errorf("incorrect since: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+// streamChanges sends changes as server-sent events named like "book.created", ids of events are seqs of changes.
+func (b *serviceBundle) streamChanges(ctx context.Context, r *api.StreamChangesReq) (any, error) {
+	since := r.Since
+	next := func(ctx context.Context) ([]serverEvent, error) {
+		changes, err := b.bookService.Changes(ctx, bm.ChangesFilter{Since: since})
+		if err != nil {
+			return nil, fmt.Errorf("get changes: %w", err)
+		}
+
+		events := make([]serverEvent, 0, len(changes))
+		for _, c := range changes {
+			data, err := json.Marshal(newAPIChange(c))
+			if err != nil {
+				return nil, fmt.Errorf("marshal change: %w", err)
+			}
+
+			events = append(events, serverEvent{
+				id:   strconv.FormatInt(c.Seq, 10),
+				name: c.Entity + "." + c.Op,
+				data: data,
+			})
+
+			since = c.Seq
+		}
+
+		return events, nil
+	}
+
+	// The first changes are read before the stream starts, so errors are still rendered as JSON.
+	events, err := next(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eventStream{events: events, next: next}, nil
+}
@@ -0,0 +1,41 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseUpdateAuthorReq(r *http.Request) (*api.UpdateAuthorReq, error) {
+	req := new(api.UpdateAuthorReq)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	v := mux.Vars(r)
+
+	reqID, err := strconv.ParseInt(v["author_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	req.ID = reqID
+
+	return req, nil
+}
+
+func (b *serviceBundle) updateAuthor(ctx context.Context, r *api.UpdateAuthorReq) (any, error) {
+	err := b.bookService.UpdateAuthor(ctx, bm.Author(*r))
+	if err != nil {
+		return nil, fmt.Errorf("update author: %w", err)
+	}
+
+	return nil, nil
+}
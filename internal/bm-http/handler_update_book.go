@@ -31,7 +31,20 @@ func parseUpdateBookReq(r *http.Request) (*api.UpdateBookReq, error) {
 }
 
 func (b *serviceBundle) updateBook(ctx context.Context, r *api.UpdateBookReq) (any, error) {
-	err := b.bookService.UpdateBook(ctx, bm.Book(*r))
+	bookData := bm.Book{
+		ID:            r.ID,
+		Title:         r.Title,
+		Author:        r.Author,
+		PublishedDate: r.PublishedDate,
+		Edition:       r.Edition,
+		Description:   r.Description,
+		Genre:         r.Genre,
+		GenreID:       r.GenreID,
+		ISBN:          r.ISBN,
+		Contributors:  newBMContributors(r.Contributors),
+	}
+
+	err := b.bookService.UpdateBook(ctx, bookData)
 	if err != nil {
 		return nil, fmt.Errorf("update book: %w", err)
 	}
@@ -31,7 +31,7 @@ func parseUpdateCollectionReq(r *http.Request) (*api.UpdateCollectionReq, error)
 }
 
 func (b *serviceBundle) updateCollection(ctx context.Context, r *api.UpdateCollectionReq) (any, error) {
-	err := b.bookService.UpdateCollection(ctx, bm.Collection(*r))
+	err := b.bookService.UpdateCollection(ctx, bm.Collection{ID: r.ID, Name: r.Name, Description: r.Description})
 	if err != nil {
 		return nil, fmt.Errorf("update collection: %w", err)
 	}
@@ -0,0 +1,54 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseUpdateCopyReq(r *http.Request) (*api.UpdateCopyReq, error) {
+	req := new(api.UpdateCopyReq)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["copy_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	req.ID = id
+
+	return req, nil
+}
+
+func (b *serviceBundle) updateCopy(ctx context.Context, r *api.UpdateCopyReq) (any, error) {
+	c := bm.Copy{
+		ID:        r.ID,
+		Barcode:   r.Barcode,
+		Condition: r.Condition,
+		Price:     r.Price,
+		Location:  r.Location,
+	}
+
+	if r.AcquiredAt != "" {
+		var err error
+		if c.AcquiredAt, err = time.Parse(time.DateOnly, r.AcquiredAt); err != nil {
+			return nil, bm.NewValidationError("incorrect acquired_at: %w", err)
+		}
+	}
+
+	if err := b.bookService.UpdateCopy(ctx, c); err != nil {
+		return nil, fmt.Errorf("update copy: %w", err)
+	}
+
+	return nil, nil
+}
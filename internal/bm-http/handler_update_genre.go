@@ -0,0 +1,41 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseUpdateGenreReq(r *http.Request) (*api.UpdateGenreReq, error) {
+	req := new(api.UpdateGenreReq)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	v := mux.Vars(r)
+
+	reqID, err := strconv.ParseInt(v["genre_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	req.ID = reqID
+
+	return req, nil
+}
+
+func (b *serviceBundle) updateGenre(ctx context.Context, r *api.UpdateGenreReq) (any, error) {
+	err := b.bookService.UpdateGenre(ctx, bm.Genre(*r))
+	if err != nil {
+		return nil, fmt.Errorf("update genre: %w", err)
+	}
+
+	return nil, nil
+}
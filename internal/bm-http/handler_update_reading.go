@@ -0,0 +1,59 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseUpdateReadingReq(r *http.Request) (*api.UpdateReadingReq, error) {
+	req := new(api.UpdateReadingReq)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	v := mux.Vars(r)
+
+	bookID, err := strconv.ParseInt(v["book_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	req.BookID = bookID
+
+	return req, nil
+}
+
+func (b *serviceBundle) updateReading(ctx context.Context, r *api.UpdateReadingReq) (any, error) {
+	var date time.Time
+	if r.Date != "" {
+		var err error
+		if date, err = time.Parse(time.DateOnly, r.Date); err != nil {
+			return nil, bm.NewValidationError("incorrect date: %w", err)
+		}
+	}
+
+	reading := bm.Reading{
+		BookID:      r.BookID,
+		Status:      r.Status,
+		CurrentPage: r.CurrentPage,
+		Notes:       r.Notes,
+	}
+
+	updated, err := b.bookService.UpdateReading(ctx, reading, date)
+	if err != nil {
+		return nil, fmt.Errorf("update reading: %w", err)
+	}
+
+	return &api.UpdateReadingResp{
+		Reading: newAPIReading(*updated),
+	}, nil
+}
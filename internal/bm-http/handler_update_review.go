@@ -0,0 +1,43 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseUpdateReviewReq(r *http.Request) (*api.UpdateReviewReq, error) {
+	req := new(api.UpdateReviewReq)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	v := mux.Vars(r)
+
+	var err error
+	if req.BookID, err = strconv.ParseInt(v["book_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	if req.ID, err = strconv.ParseInt(v["review_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	return req, nil
+}
+
+func (b *serviceBundle) updateReview(ctx context.Context, r *api.UpdateReviewReq) (any, error) {
+	err := b.bookService.UpdateReview(ctx, bm.Review{ID: r.ID, BookID: r.BookID, Rating: r.Rating, Text: r.Text})
+	if err != nil {
+		return nil, fmt.Errorf("update review: %w", err)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,45 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseUpdateWebhookReq(r *http.Request) (*api.UpdateWebhookReq, error) {
+	req := new(api.UpdateWebhookReq)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["webhook_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	req.ID = id
+
+	return req, nil
+}
+
+func (b *serviceBundle) updateWebhook(ctx context.Context, r *api.UpdateWebhookReq) (any, error) {
+	err := b.bookService.UpdateWebhook(ctx, bm.Webhook{
+		ID:     r.ID,
+		URL:    r.URL,
+		Secret: r.Secret,
+		Events: r.Events,
+		Active: r.Active == nil || *r.Active,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update webhook: %w", err)
+	}
+
+	return nil, nil
+}
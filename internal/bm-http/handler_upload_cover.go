@@ -0,0 +1,44 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	bs "github.com/Tsapen/bm/internal/book-service"
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+func parseUploadCoverReq(r *http.Request) (*api.UploadCoverReq, error) {
+	bookID, err := strconv.ParseInt(mux.Vars(r)["book_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	// One extra byte tells an oversized cover from a cover of exactly the maximum size.
+	data, err := io.ReadAll(io.LimitReader(r.Body, bs.MaxCoverSize+1))
+	if err != nil {
+		return nil, bm.NewValidationError("read cover: %w", err)
+	}
+
+	return &api.UploadCoverReq{
+		BookID: bookID,
+		Data:   data,
+	}, nil
+}
+
+func (b *serviceBundle) uploadCover(ctx context.Context, r *api.UploadCoverReq) (any, error) {
+	if err := b.bookService.UploadCover(ctx, r.BookID, r.Data); err != nil {
+		return nil, fmt.Errorf("upload cover: %w", err)
+	}
+
+	return &api.UploadCoverResp{
+		CoverURL:     coverURL(r.BookID),
+		ThumbnailURL: thumbnailURL(r.BookID),
+	}, nil
+}
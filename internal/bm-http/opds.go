@@ -0,0 +1,259 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	bs "github.com/Tsapen/bm/internal/book-service"
+)
+
+const (
+	opdsPath         = "/api/v1/opds"
+	opdsPageSize     = 50
+	opdsNavigation   = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	opdsAcquisition  = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	openSearchType   = "application/opensearchdescription+xml"
+	opdsAcquireRel   = "http://opds-spec.org/acquisition"
+	opdsImageRel     = "http://opds-spec.org/image"
+	opdsThumbnailRel = "http://opds-spec.org/image/thumbnail"
+	opdsSortNewRel   = "http://opds-spec.org/sort/new"
+)
+
+// atomFeed is an OPDS 1.2 catalog feed. Prefixed names are written as is, their namespaces are declared on the feed.
+type atomFeed struct {
+	XMLName      xml.Name    `xml:"feed"`
+	Xmlns        string      `xml:"xmlns,attr"`
+	XmlnsDC      string      `xml:"xmlns:dc,attr"`
+	XmlnsOPDS    string      `xml:"xmlns:opds,attr"`
+	XmlnsSearch  string      `xml:"xmlns:opensearch,attr"`
+	ID           string      `xml:"id"`
+	Title        string      `xml:"title"`
+	Updated      string      `xml:"updated"`
+	Author       *atomAuthor `xml:"author"`
+	ItemsPerPage int         `xml:"opensearch:itemsPerPage,omitempty"`
+	StartIndex   int64       `xml:"opensearch:startIndex,omitempty"`
+	Links        []atomLink  `xml:"link"`
+	Entries      []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel   string `xml:"rel,attr,omitempty"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Authors    []atomAuthor   `xml:"author"`
+	Issued     string         `xml:"dc:issued,omitempty"`
+	Identifier string         `xml:"dc:identifier,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Content    *atomContent   `xml:"content"`
+	Links      []atomLink     `xml:"link"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr,omitempty"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// openSearchDescription describes the search of the catalog, https://github.com/dewitt/opensearch.
+type openSearchDescription struct {
+	XMLName     xml.Name      `xml:"OpenSearchDescription"`
+	Xmlns       string        `xml:"xmlns,attr"`
+	ShortName   string        `xml:"ShortName"`
+	Description string        `xml:"Description"`
+	InputEnc    string        `xml:"InputEncoding"`
+	OutputEnc   string        `xml:"OutputEncoding"`
+	URL         openSearchURL `xml:"Url"`
+}
+
+type openSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Template string `xml:"template,attr"`
+}
+
+func newFeed(id, title, self, kind string) *atomFeed {
+	return &atomFeed{
+		Xmlns:       "http://www.w3.org/2005/Atom",
+		XmlnsDC:     "http://purl.org/dc/terms/",
+		XmlnsOPDS:   "http://opds-spec.org/2010/catalog",
+		XmlnsSearch: "http://a9.com/-/spec/opensearch/1.1/",
+		ID:          id,
+		Title:       title,
+		Updated:     time.Now().UTC().Format(time.RFC3339),
+		Author:      &atomAuthor{Name: "bm"},
+		Links: []atomLink{
+			{Rel: "self", Href: self, Type: kind},
+			{Rel: "start", Href: opdsPath, Type: opdsNavigation},
+			{Rel: "search", Href: opdsPath + "/opensearch.xml", Type: openSearchType},
+		},
+	}
+}
+
+// acquisitionFeed builds a page of books. Links to the next and previous pages keep the query of the feed.
+func (b *serviceBundle) acquisitionFeed(ctx context.Context, id, title, path string, query url.Values, f bm.BookFilter) (*blobResp, error) {
+	if f.Page < 0 {
+		return nil, bm.NewValidationError("incorrect page")
+	}
+
+	if f.Page == 0 {
+		f.Page = 1
+	}
+
+	f.PageSize = opdsPageSize
+	books, err := b.bookService.Books(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("get books: %w", err)
+	}
+
+	feed := newFeed(id, title, opdsPageURL(path, query, f.Page), opdsAcquisition)
+	addPageLinks(feed, path, query, f.Page, len(books) == opdsPageSize, opdsAcquisition)
+
+	for _, book := range books {
+		files, err := b.bookService.BookFiles(ctx, book.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get files of book %d: %w", book.ID, err)
+		}
+
+		feed.Entries = append(feed.Entries, newBookEntry(book, files, feed.Updated))
+	}
+
+	return renderXML(feed, opdsAcquisition)
+}
+
+// addPageLinks adds links to the first, previous and next pages of the feed. Links keep the query of the feed.
+func addPageLinks(feed *atomFeed, path string, query url.Values, page int64, hasNext bool, kind string) {
+	feed.ItemsPerPage = opdsPageSize
+	feed.StartIndex = (page-1)*opdsPageSize + 1
+	feed.Links = append(feed.Links, atomLink{Rel: "first", Href: opdsPageURL(path, query, 1), Type: kind})
+	if page > 1 {
+		feed.Links = append(feed.Links, atomLink{Rel: "previous", Href: opdsPageURL(path, query, page-1), Type: kind})
+	}
+
+	if hasNext {
+		feed.Links = append(feed.Links, atomLink{Rel: "next", Href: opdsPageURL(path, query, page+1), Type: kind})
+	}
+}
+
+func opdsPageURL(path string, query url.Values, page int64) string {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+
+	if page > 1 {
+		q.Set("page", strconv.FormatInt(page, 10))
+	}
+
+	if len(q) == 0 {
+		return path
+	}
+
+	return path + "?" + q.Encode()
+}
+
+// newBookEntry builds an entry of a book. Files of the book are acquisition links, books without files are listed
+// for browsing only.
+func newBookEntry(b bm.Book, files []bm.BookFile, updated string) atomEntry {
+	e := atomEntry{
+		ID:      "urn:bm:book:" + strconv.FormatInt(b.ID, 10),
+		Title:   b.Title,
+		Updated: updated,
+		Links: []atomLink{
+			{Rel: "alternate", Href: "/api/v1/books/" + strconv.FormatInt(b.ID, 10), Type: "application/json"},
+		},
+	}
+
+	for _, c := range b.Contributors {
+		if c.Role == bm.RoleAuthor {
+			e.Authors = append(e.Authors, atomAuthor{Name: c.Name})
+		}
+	}
+
+	if len(e.Authors) == 0 && b.Author != "" {
+		e.Authors = []atomAuthor{{Name: b.Author}}
+	}
+
+	if !b.PublishedDate.IsZero() {
+		e.Issued = strconv.Itoa(b.PublishedDate.Year())
+	}
+
+	if b.ISBN != "" {
+		e.Identifier = "urn:isbn:" + b.ISBN
+	}
+
+	if b.Genre != "" {
+		e.Categories = append(e.Categories, atomCategory{Term: b.Genre, Label: b.Genre})
+	}
+
+	for _, tag := range b.Tags {
+		e.Categories = append(e.Categories, atomCategory{Term: tag})
+	}
+
+	if b.Description != "" {
+		e.Content = &atomContent{Type: "text", Value: b.Description}
+	}
+
+	if b.Cover != "" {
+		e.Links = append(e.Links,
+			atomLink{Rel: opdsImageRel, Href: coverURL(b.ID)},
+			atomLink{Rel: opdsThumbnailRel, Href: thumbnailURL(b.ID)},
+		)
+	}
+
+	for _, f := range files {
+		e.Links = append(e.Links, atomLink{
+			Rel:   opdsAcquireRel,
+			Href:  fmt.Sprintf("/api/v1/books/%d/files/%d", b.ID, f.ID),
+			Type:  bs.FileContentType(f.Format),
+			Title: f.Name,
+		})
+	}
+
+	return e
+}
+
+func renderXML(v any, contentType string) (*blobResp, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, bm.NewInternalError("marshal xml: %w", err)
+	}
+
+	return &blobResp{
+		contentType: contentType,
+		data:        append([]byte(xml.Header), append(data, '\n')...),
+	}, nil
+}
+
+// baseURL returns the scheme and host the client used to reach the server, honouring a reverse proxy.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+
+	return scheme + "://" + r.Host
+}
@@ -0,0 +1,60 @@
+package bmhttp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tsapen/bm/internal/openapi"
+)
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	s, err := NewServer(Config{}, nil)
+	require.NoError(t, err)
+
+	router := s.tcpServer.Handler.(*mux.Router)
+
+	for _, version := range openapi.Versions {
+		doc, err := openapi.Load(version)
+		require.NoError(t, err)
+
+		// 1. Every route of the version is documented.
+		routed := make(map[string]bool)
+		err = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			tmpl, err := route.GetPathTemplate()
+			if err != nil {
+				return err
+			}
+
+			// Subrouters of versions have no methods.
+			methods, err := route.GetMethods()
+			if err != nil {
+				return nil
+			}
+
+			p, ok := strings.CutPrefix(tmpl, version.Prefix())
+			if !ok {
+				return nil
+			}
+
+			item := doc.Paths.Value(p)
+			for _, method := range methods {
+				routed[method+" "+p] = true
+				assert.True(t, item != nil && item.GetOperation(method) != nil, "%s %s is not documented", method, tmpl)
+			}
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		// 2. Every documented operation is routed.
+		for p, item := range doc.Paths.Map() {
+			for method := range item.Operations() {
+				assert.True(t, routed[method+" "+p], "%s %s%s is not routed", method, version.Prefix(), p)
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package bmhttp
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	ratelimit "github.com/Tsapen/bm/internal/rate-limit"
+)
+
+// bulkRoutes contains routes that modify many resources at once. Routes are the same in all API versions.
+var bulkRoutes = map[string]bool{
+	http.MethodDelete + " /books":                                true,
+	http.MethodPost + " /books/tags":                             true,
+	http.MethodDelete + " /books/tags":                           true,
+	http.MethodPost + " /books/import/csv":                       true,
+	http.MethodPost + " /books/import/marc":                      true,
+	http.MethodPost + " /collections/{collection_id}/books":      true,
+	http.MethodDelete + " /collections/{collection_id}/books":    true,
+	http.MethodPost + " /admin/books/move":                       true,
+	http.MethodPost + " /admin/collections/{collection_id}/move": true,
+}
+
+func classifyRoute(r *http.Request) ratelimit.Class {
+	if bulkRoutes[r.Method+" "+routePath(r)] {
+		return ratelimit.Bulk
+	}
+
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return ratelimit.Read
+	}
+
+	return ratelimit.Write
+}
+
+// clientKey identifies a client by the tenant and the credential of its identity. Anonymous callers and callers
+// with unknown credentials are identified by unix socket peer or remote address.
+func clientKey(r *http.Request, id bm.Identity) string {
+	if id.Key != "" {
+		return id.Tenant + "/" + id.Key
+	}
+
+	if cred, ok := bm.PeerCredFromCtx(r.Context()); ok {
+		return "uid:" + strconv.FormatUint(uint64(cred.UID), 10)
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return "ip:" + r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+// rateLimiter runs before authentication, so attempts to guess api keys are limited too.
+type rateLimiter struct {
+	limiter  *ratelimit.Limiter
+	identify func(r *http.Request) (bm.Identity, error)
+	now      func() time.Time
+}
+
+func newRateLimiter(cfg ratelimit.Config, identify func(r *http.Request) (bm.Identity, error)) *rateLimiter {
+	return &rateLimiter{
+		limiter:  ratelimit.New(cfg),
+		identify: identify,
+		now:      time.Now,
+	}
+}
+
+func (rl *rateLimiter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Callers which fail authentication are limited by their address.
+		id, err := rl.identify(r)
+		if err != nil {
+			id = bm.Identity{}
+		}
+
+		key := clientKey(r, id)
+		q, limited := rl.limiter.Take(classifyRoute(r), key, rl.now())
+		if !limited {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(q.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(q.Remaining))
+		w.Header().Set("RateLimit-Reset", strconv.FormatInt(ceilSeconds(q.Reset), 10))
+
+		if !q.Allowed {
+			w.Header().Set("Retry-After", strconv.FormatInt(ceilSeconds(q.RetryAfter), 10))
+
+			logger := log.With().Str("method", r.Method).Str("path", r.URL.String()).Str("client", key).Logger()
+			renderErr(r.Context(), logger, fmt.Errorf("limit requests: %w", rateLimitError{retryAfter: q.RetryAfter}), w)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
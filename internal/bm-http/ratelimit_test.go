@@ -0,0 +1,142 @@
+package bmhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tsapen/bm/internal/auth"
+	"github.com/Tsapen/bm/internal/openapi"
+	ratelimit "github.com/Tsapen/bm/internal/rate-limit"
+	"github.com/Tsapen/bm/pkg/api"
+	httpclient "github.com/Tsapen/bm/pkg/http-client"
+)
+
+func newLimitedRouter(cfg ratelimit.Config, now *time.Time) http.Handler {
+	a := newAuthenticator(auth.Config{
+		DefaultTenant: "default",
+		APIKeys: []auth.APIKey{
+			{Key: "reader-key", Name: "reader", Tenant: "default"},
+			{Key: "other-reader-key", Name: "reader", Tenant: "default"},
+		},
+	})
+
+	rl := newRateLimiter(cfg, a.identify)
+	rl.now = func() time.Time { return *now }
+
+	ok := func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{}`))
+	}
+
+	r := mux.NewRouter()
+	r.Use(rl.middleware)
+	r.Use(a.middleware)
+	for _, version := range openapi.Versions {
+		sub := r.PathPrefix(version.Prefix()).Subrouter()
+		sub.HandleFunc("/books", ok).Methods(http.MethodGet)
+		sub.HandleFunc("/books/{book_id}", ok).Methods(http.MethodPut)
+		sub.HandleFunc("/books", ok).Methods(http.MethodDelete)
+	}
+
+	return r
+}
+
+func doRequest(h http.Handler, method, target, remoteAddr string, key ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	req.RemoteAddr = remoteAddr
+	if len(key) != 0 {
+		req.Header.Set("Authorization", "Bearer "+key[0])
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	h := newLimitedRouter(ratelimit.Config{
+		Reads: ratelimit.Limit{Rate: 1, Burst: 2},
+		Bulk:  ratelimit.Limit{Rate: 0.1, Burst: 1},
+	}, &now)
+
+	// 1. A client spends its burst.
+	for i := 1; i >= 0; i-- {
+		w := doRequest(h, http.MethodGet, "/api/v1/books", "10.0.0.1:1000")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, strconv.Itoa(i), w.Header().Get("RateLimit-Remaining"))
+	}
+
+	w := doRequest(h, http.MethodGet, "/api/v1/books", "10.0.0.1:1001")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Reset"))
+
+	// 2. Other clients and route classes have their own buckets.
+	w = doRequest(h, http.MethodGet, "/api/v1/books", "10.0.0.2:1000")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = doRequest(h, http.MethodPut, "/api/v1/books/1", "10.0.0.1:1000", "reader-key")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+
+	w = doRequest(h, http.MethodDelete, "/api/v1/books", "10.0.0.1:1000", "reader-key")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Versions of a route share the bucket.
+	w = doRequest(h, http.MethodDelete, "/api/v2/books", "10.0.0.1:1000", "reader-key")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "10", w.Header().Get("Retry-After"))
+
+	// Keys with the same name have own buckets, unknown keys share the bucket of the address
+	// and are limited before they are rejected.
+	w = doRequest(h, http.MethodDelete, "/api/v1/books", "10.0.0.1:1000", "other-reader-key")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = doRequest(h, http.MethodGet, "/api/v1/books", "10.0.0.2:1000", "guess-1")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = doRequest(h, http.MethodGet, "/api/v1/books", "10.0.0.2:1000", "guess-2")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	// 3. Tokens are restored with time.
+	now = now.Add(time.Second)
+
+	w = doRequest(h, http.MethodGet, "/api/v1/books", "10.0.0.1:1000")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+}
+
+func TestRateLimitClientError(t *testing.T) {
+	now := time.Now()
+	srv := httptest.NewServer(newLimitedRouter(ratelimit.Config{
+		Reads: ratelimit.Limit{Rate: 0.5, Burst: 1},
+	}, &now))
+	defer srv.Close()
+
+	client := httpclient.New(httpclient.Config{
+		Address: srv.URL,
+		Timeout: time.Second,
+	})
+
+	ctx := context.Background()
+	_, err := client.GetBooks(ctx, &api.GetBooksReq{})
+	require.NoError(t, err)
+
+	_, err = client.GetBooks(ctx, &api.GetBooksReq{})
+	rateLimitErr := new(httpclient.RateLimitError)
+	require.True(t, errors.As(err, &rateLimitErr))
+	assert.Equal(t, 2*time.Second, rateLimitErr.RetryAfter)
+	assert.Equal(t, 1, rateLimitErr.Limit)
+	assert.Equal(t, 0, rateLimitErr.Remaining)
+}
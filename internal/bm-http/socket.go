@@ -0,0 +1,56 @@
+package bmhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Tsapen/bm/internal/openapi"
+	unixsocket "github.com/Tsapen/bm/internal/unix-socket"
+)
+
+// readOnlyPeerKey marks contexts of GraphQL requests of read-only peers, their mutations are rejected
+// by the executor.
+type readOnlyPeerKey struct{}
+
+// peerMiddleware authorizes peers of the unix socket, only GET and HEAD requests of read-only peers are allowed.
+// GraphQL queries are sent with POST too, so GraphQL requests are allowed and read-only peers are marked.
+func peerMiddleware(a *unixsocket.Authorizer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		graphQL := isGraphQLPath(r.URL.Path)
+		write := r.Method != http.MethodGet && r.Method != http.MethodHead && !graphQL
+		if err := a.Authorize(r.Context(), write); err != nil {
+			logger := log.With().Str("method", r.Method).Str("path", r.URL.String()).Logger()
+			renderErr(r.Context(), logger, fmt.Errorf("authorize socket peer: %w", err), w)
+
+			return
+		}
+
+		if graphQL && a.Authorize(r.Context(), true) != nil {
+			r = r.WithContext(context.WithValue(r.Context(), readOnlyPeerKey{}, true))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// readOnlyPeer checks if the request came from a read-only peer of the unix socket.
+func readOnlyPeer(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyPeerKey{}).(bool)
+
+	return readOnly
+}
+
+// isGraphQLPath checks if the path is the GraphQL endpoint of any API version. Peers are authorized
+// before routing, so the path is compared as is.
+func isGraphQLPath(path string) bool {
+	for _, version := range openapi.Versions {
+		if path == version.Prefix()+"/graphql" {
+			return true
+		}
+	}
+
+	return false
+}
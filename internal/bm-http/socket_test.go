@@ -0,0 +1,75 @@
+package bmhttp
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	unixsocket "github.com/Tsapen/bm/internal/unix-socket"
+	"github.com/Tsapen/bm/pkg/api"
+	httpclient "github.com/Tsapen/bm/pkg/http-client"
+)
+
+func TestSocketPeers(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("SO_PEERCRED is supported only on linux")
+	}
+
+	a, err := unixsocket.NewAuthorizer([]unixsocket.Peer{{UID: uint32(os.Getuid()), Access: unixsocket.ReadOnlyAccess}})
+	require.NoError(t, err)
+
+	parse := func(*http.Request) (struct{}, error) { return struct{}{}, nil }
+	handle := func(ctx context.Context, _ struct{}) (any, error) {
+		cred, _ := bm.PeerCredFromCtx(ctx)
+
+		return &api.GetBooksResp{Books: []api.Book{{ID: int64(cred.UID)}}}, nil
+	}
+
+	path := filepath.Join(t.TempDir(), "bm.sock")
+	listener, err := unixsocket.Listen(path, unixsocket.Config{})
+	require.NoError(t, err)
+
+	// GraphQL requests of read-only peers are passed with the mark.
+	graphQL := func(ctx context.Context, _ struct{}) (any, error) {
+		return &api.GraphQLResp{Data: []byte(strconv.FormatBool(readOnlyPeer(ctx)))}, nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/v2/graphql", handleFunc(parse, graphQL))
+	mux.Handle("/", handleFunc(parse, handle))
+
+	srv := &http.Server{
+		Handler:     peerMiddleware(a, mux),
+		ConnContext: socketConnContext,
+	}
+
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	client := httpclient.New(httpclient.Config{
+		Address:    "http://localhost",
+		SocketPath: path,
+		Timeout:    time.Second,
+	})
+
+	ctx := context.Background()
+	resp, err := client.GetBooks(ctx, &api.GetBooksReq{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(os.Getuid()), resp.Books[0].ID)
+
+	_, err = client.CreateBook(ctx, &api.CreateBookReq{Title: "Solaris", Author: "Stanislaw Lem", Genre: "Science fiction"})
+	assert.Error(t, err)
+
+	gqlResp, err := client.GraphQL(ctx, &api.GraphQLReq{Query: "{ books { id } }"})
+	require.NoError(t, err)
+	assert.Equal(t, "true", string(gqlResp.Data))
+}
@@ -0,0 +1,180 @@
+package bmhttp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tsapen/bm/internal/auth"
+	"github.com/Tsapen/bm/internal/auth/authtest"
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/pkg/api"
+	httpclient "github.com/Tsapen/bm/pkg/http-client"
+)
+
+// startTLSServer serves names of callers as titles of books.
+func startTLSServer(t *testing.T, cfg auth.TLSConfig, authCfg auth.Config) string {
+	reloader, err := auth.NewCertReloader(cfg)
+	require.NoError(t, err)
+
+	parse := func(*http.Request) (struct{}, error) { return struct{}{}, nil }
+	handle := func(ctx context.Context, _ struct{}) (any, error) {
+		return &api.GetBooksResp{Books: []api.Book{{Title: bm.IdentityFromCtx(ctx).Name}}}, nil
+	}
+
+	srv := &http.Server{
+		Handler:   newAuthenticator(authCfg).middleware(handleFunc(parse, handle)),
+		TLSConfig: reloader.TLSConfig(),
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go srv.ServeTLS(ln, "", "")
+	t.Cleanup(func() { srv.Close() })
+
+	return "https://" + ln.Addr().String()
+}
+
+func TestTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := authtest.NewCert(t, nil, "bm ca", 1)
+	caFile, _ := ca.Write(t, dir, "ca")
+	certFile, keyFile := authtest.NewCert(t, ca, "server", 2).Write(t, dir, "server")
+	readerCert, readerKey := authtest.NewCert(t, ca, "reader-service", 3).Write(t, dir, "reader")
+	unknownCert, unknownKey := authtest.NewCert(t, ca, "unknown-service", 4).Write(t, dir, "unknown")
+
+	addr := startTLSServer(t, auth.TLSConfig{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: caFile,
+	}, auth.Config{
+		DefaultTenant: "default",
+		ClientCerts:   []auth.ClientCert{{CommonName: "reader-service", Name: "reader", Tenant: "reader"}},
+	})
+
+	ctx := context.Background()
+	caller := func(cfg httpclient.Config) (string, error) {
+		cfg.Address = addr
+		cfg.Timeout = time.Second
+
+		resp, err := httpclient.New(cfg).GetBooks(ctx, &api.GetBooksReq{})
+		if err != nil {
+			return "", err
+		}
+
+		return resp.Books[0].Title, nil
+	}
+
+	// 1. Client certificates are mapped to identities.
+	name, err := caller(httpclient.Config{CAFile: caFile, CertFile: readerCert, KeyFile: readerKey})
+	require.NoError(t, err)
+	assert.Equal(t, "reader", name)
+
+	// 2. Clients without certificates belong to the default tenant, api keys take precedence over certificates.
+	name, err = caller(httpclient.Config{CAFile: caFile})
+	require.NoError(t, err)
+	assert.Empty(t, name)
+
+	// They can't change the library while the server has client certificates.
+	_, err = httpclient.New(httpclient.Config{Address: addr, CAFile: caFile, Timeout: time.Second}).
+		CreateBook(ctx, &api.CreateBookReq{Title: "Solaris", Author: "Stanislaw Lem", Genre: "Science Fiction"})
+	assert.ErrorContains(t, err, "api key is required")
+
+	_, err = caller(httpclient.Config{CAFile: caFile, CertFile: readerCert, KeyFile: readerKey, APIKey: "unknown"})
+	assert.Error(t, err)
+
+	// 3. Certificates without identities are rejected.
+	_, err = caller(httpclient.Config{CAFile: caFile, CertFile: unknownCert, KeyFile: unknownKey})
+	assert.Error(t, err)
+
+	// 4. The server is verified by the CA.
+	_, err = caller(httpclient.Config{CertFile: readerCert, KeyFile: readerKey})
+	assert.Error(t, err)
+
+	_, err = caller(httpclient.Config{CAFile: filepath.Join(dir, "missing.crt")})
+	assert.Error(t, err)
+}
+
+func TestTLSRequireClientCert(t *testing.T) {
+	dir := t.TempDir()
+	ca := authtest.NewCert(t, nil, "bm ca", 1)
+	caFile, _ := ca.Write(t, dir, "ca")
+	certFile, keyFile := authtest.NewCert(t, ca, "server", 2).Write(t, dir, "server")
+
+	// Certificates signed by another CA aren't trusted.
+	other := authtest.NewCert(t, nil, "other ca", 3)
+	otherCert, otherKey := authtest.NewCert(t, other, "reader-service", 4).Write(t, dir, "other")
+
+	addr := startTLSServer(t, auth.TLSConfig{
+		CertFile:          certFile,
+		KeyFile:           keyFile,
+		ClientCAFile:      caFile,
+		RequireClientCert: true,
+	}, auth.Config{
+		DefaultTenant: "default",
+		ClientCerts:   []auth.ClientCert{{CommonName: "reader-service", Name: "reader", Tenant: "reader"}},
+	})
+
+	ctx := context.Background()
+	for _, cfg := range []httpclient.Config{
+		{CAFile: caFile},
+		{CAFile: caFile, CertFile: otherCert, KeyFile: otherKey},
+	} {
+		cfg.Address = addr
+		cfg.Timeout = time.Second
+
+		_, err := httpclient.New(cfg).GetBooks(ctx, &api.GetBooksReq{})
+		assert.Error(t, err)
+	}
+}
+
+func TestCertReload(t *testing.T) {
+	dir := t.TempDir()
+	ca := authtest.NewCert(t, nil, "bm ca", 1)
+	certFile, keyFile := authtest.NewCert(t, ca, "server", 2).Write(t, dir, "server")
+
+	addr := startTLSServer(t, auth.TLSConfig{CertFile: certFile, KeyFile: keyFile}, auth.Config{DefaultTenant: "default"})
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	serial := func() int64 {
+		conn, err := tls.Dial("tcp", addr[len("https://"):], &tls.Config{RootCAs: pool, NextProtos: []string{"h2", "http/1.1"}})
+		require.NoError(t, err)
+		defer conn.Close()
+
+		// Reloaded configurations negotiate HTTP/2 like the one of the server.
+		assert.Equal(t, "h2", conn.ConnectionState().NegotiatedProtocol)
+
+		return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+	}
+
+	assert.Equal(t, int64(2), serial())
+
+	// Rotated certificates are used by new connections.
+	modifiedAt := time.Now().Add(time.Minute)
+	authtest.NewCert(t, ca, "server", 3).Write(t, dir, "server")
+	require.NoError(t, os.Chtimes(certFile, modifiedAt, modifiedAt))
+	require.NoError(t, os.Chtimes(keyFile, modifiedAt, modifiedAt))
+	assert.Equal(t, int64(3), serial())
+
+	// Broken files don't stop the server, the previous certificates are kept.
+	modifiedAt = modifiedAt.Add(time.Minute)
+	require.NoError(t, os.WriteFile(keyFile, []byte("broken"), 0o600))
+	require.NoError(t, os.Chtimes(keyFile, modifiedAt, modifiedAt))
+	assert.Equal(t, int64(3), serial())
+
+	// Missing files fail the server on start.
+	_, err := auth.NewCertReloader(auth.TLSConfig{CertFile: filepath.Join(dir, "missing.crt"), KeyFile: keyFile})
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
@@ -0,0 +1,122 @@
+package bmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+// API v1 misspells the description of collections and names book ids of the removal from a collection books_ids.
+// v1 handlers translate these fields and reuse handlers of the current version.
+type (
+	v1Collection struct {
+		ID          int64     `json:"id"`
+		Name        string    `json:"name"`
+		Description string    `json:"decription"`
+		UpdatedAt   time.Time `json:"-"`
+	}
+
+	v1GetCollectionResp struct {
+		Collection v1Collection `json:"collection"`
+	}
+
+	v1GetCollectionsResp struct {
+		Collections []v1Collection `json:"collections"`
+	}
+
+	v1CreateCollectionReq struct {
+		Name        string `json:"name"`
+		Description string `json:"decription"`
+	}
+
+	v1UpdateCollectionReq struct {
+		Name        string `json:"name"`
+		Description string `json:"decription"`
+	}
+
+	v1DeleteBooksCollectionReq struct {
+		BookIDs []int64 `json:"books_ids"`
+	}
+)
+
+func (b *serviceBundle) getCollectionV1(ctx context.Context, r *api.GetCollectionReq) (any, error) {
+	resp, err := b.getCollection(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1GetCollectionResp{
+		Collection: v1Collection(resp.(*api.GetCollectionResp).Collection),
+	}, nil
+}
+
+func (b *serviceBundle) getCollectionsV1(ctx context.Context, r *api.GetCollectionsReq) (any, error) {
+	resp, err := b.getCollections(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	collections := resp.(*api.GetCollectionsResp).Collections
+	collectionsResp := make([]v1Collection, 0, len(collections))
+	for _, c := range collections {
+		collectionsResp = append(collectionsResp, v1Collection(c))
+	}
+
+	return &v1GetCollectionsResp{
+		Collections: collectionsResp,
+	}, nil
+}
+
+func parseCreateCollectionReqV1(r *http.Request) (*api.CreateCollectionReq, error) {
+	req, err := parseJSONReq[v1CreateCollectionReq](r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CreateCollectionReq{
+		Name:        req.Name,
+		Description: req.Description,
+	}, nil
+}
+
+func parseUpdateCollectionReqV1(r *http.Request) (*api.UpdateCollectionReq, error) {
+	req := new(v1UpdateCollectionReq)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["collection_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	return &api.UpdateCollectionReq{
+		ID:          id,
+		Name:        req.Name,
+		Description: req.Description,
+	}, nil
+}
+
+func parseDeleteBooksCollectionReqV1(r *http.Request) (*api.DeleteBooksCollectionReq, error) {
+	req := new(v1DeleteBooksCollectionReq)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	cid, err := strconv.ParseInt(mux.Vars(r)["collection_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	return &api.DeleteBooksCollectionReq{
+		CID:     cid,
+		BookIDs: req.BookIDs,
+	}, nil
+}
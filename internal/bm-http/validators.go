@@ -0,0 +1,83 @@
+package bmhttp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Tsapen/bm/pkg/api"
+)
+
+// validatedCacheControl makes clients revalidate responses with validators before reusing them.
+const validatedCacheControl = "private, no-cache"
+
+// validators tells whether the response is a book, a collection or a list of them and returns
+// the modification time of a single book or collection.
+func validators(resp any) (bool, time.Time) {
+	switch resp := resp.(type) {
+	case *api.GetBookResp:
+		return true, resp.Book.UpdatedAt
+
+	case *api.GetCollectionResp:
+		return true, resp.Collection.UpdatedAt
+
+	case *v1GetCollectionResp:
+		return true, resp.Collection.UpdatedAt
+
+	case *api.GetBooksResp, *api.GetCollectionsResp, *v1GetCollectionsResp:
+		return true, time.Time{}
+	}
+
+	return false, time.Time{}
+}
+
+// withValidators renders books and collections as JSON with a strong ETag, the hash of the body, and
+// Last-Modified for single ones. Conditions of the request are checked like by http.ServeContent:
+// If-None-Match takes precedence over If-Modified-Since. Other responses are returned as is.
+func withValidators(r *http.Request, resp any) (any, error) {
+	ok, modifiedAt := validators(resp)
+	if !ok || r.Method != http.MethodGet {
+		return resp, nil
+	}
+
+	body := new(bytes.Buffer)
+	if err := json.NewEncoder(body).Encode(resp); err != nil {
+		return nil, fmt.Errorf("encode response: %w", err)
+	}
+
+	sum := sha256.Sum256(body.Bytes())
+	etag := strconv.Quote(hex.EncodeToString(sum[:16]))
+
+	return &blobResp{
+		contentType:  jsonContentType,
+		cacheControl: validatedCacheControl,
+		etag:         etag,
+		lastModified: modifiedAt,
+		notModified:  notModified(r, etag, modifiedAt),
+		data:         body.Bytes(),
+	}, nil
+}
+
+func notModified(r *http.Request, etag string, modifiedAt time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		return etagMatches(header, etag)
+	}
+
+	header := r.Header.Get("If-Modified-Since")
+	if header == "" || modifiedAt.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return false
+	}
+
+	// Last-Modified has a precision of seconds.
+	return !modifiedAt.Truncate(time.Second).After(since)
+}
@@ -0,0 +1,95 @@
+package bmhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Tsapen/bm/internal/bm-http/bmhttptest"
+	"github.com/Tsapen/bm/pkg/api"
+	httpclient "github.com/Tsapen/bm/pkg/http-client"
+)
+
+func TestNotModified(t *testing.T) {
+	modifiedAt := time.Date(2024, time.March, 10, 15, 30, 0, 500, time.UTC)
+	etag := `"abc"`
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   bool
+	}{
+		{name: "no conditions", want: false},
+		{name: "matching etag", header: http.Header{"If-None-Match": {`"xyz", "abc"`}}, want: true},
+		{name: "any etag", header: http.Header{"If-None-Match": {"*"}}, want: true},
+		{name: "other etag", header: http.Header{"If-None-Match": {`"xyz"`}}, want: false},
+		{name: "not modified since", header: http.Header{"If-Modified-Since": {modifiedAt.Format(http.TimeFormat)}}, want: true},
+		{name: "modified since", header: http.Header{"If-Modified-Since": {modifiedAt.Add(-time.Second).Format(http.TimeFormat)}}, want: false},
+		{name: "invalid date", header: http.Header{"If-Modified-Since": {"yesterday"}}, want: false},
+		{
+			name: "etag takes precedence",
+			header: http.Header{
+				"If-None-Match":     {`"xyz"`},
+				"If-Modified-Since": {modifiedAt.Format(http.TimeFormat)},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v2/books/1", nil)
+			for k, v := range tt.header {
+				r.Header[k] = v
+			}
+
+			assert.Equal(t, tt.want, notModified(r, etag, modifiedAt))
+		})
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	book := api.Book{ID: 1, Title: "Solaris", UpdatedAt: time.Date(2024, time.March, 10, 15, 30, 0, 0, time.UTC)}
+	parse := func(*http.Request) (struct{}, error) { return struct{}{}, nil }
+	handle := func(context.Context, struct{}) (any, error) { return &api.GetBookResp{Book: book}, nil }
+
+	srv := httptest.NewServer(handleFunc(parse, handle))
+	defer srv.Close()
+
+	recorder := new(bmhttptest.StatusRecorder)
+	client := httpclient.New(httpclient.Config{
+		Address:   srv.URL,
+		Timeout:   time.Second,
+		Transport: recorder,
+		CacheSize: 10,
+	})
+
+	ctx := context.Background()
+	get := func() *api.GetBookResp {
+		resp, err := client.GetBook(ctx, &api.GetBookReq{ID: 1})
+		require.NoError(t, err)
+
+		return resp
+	}
+
+	assert.Equal(t, "Solaris", get().Book.Title)
+	assert.Equal(t, http.StatusOK, recorder.Status)
+
+	assert.Equal(t, "Solaris", get().Book.Title)
+	assert.Equal(t, http.StatusNotModified, recorder.Status)
+
+	book.Title = "The Invincible"
+	book.UpdatedAt = book.UpdatedAt.Add(time.Minute)
+	assert.Equal(t, "The Invincible", get().Book.Title)
+	assert.Equal(t, http.StatusOK, recorder.Status)
+
+	// Other api keys don't share cached responses.
+	_, err := client.WithAPIKey("other").GetBook(ctx, &api.GetBookReq{ID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Status)
+}
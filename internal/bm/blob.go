@@ -0,0 +1,19 @@
+package bm
+
+import (
+	"context"
+	"io"
+)
+
+// BlobStore keeps binary objects such as cover images by key.
+// Keys are slash-separated paths.
+type BlobStore interface {
+	// Put creates or replaces an object.
+	Put(ctx context.Context, key string, r io.Reader) error
+
+	// Get opens an object for reading. Missing objects cause NotFoundError.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete deletes objects. Missing objects are skipped.
+	Delete(ctx context.Context, keys ...string) error
+}
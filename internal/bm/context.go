@@ -8,8 +8,20 @@ type cxtKey int
 
 const (
 	reqIDKey cxtKey = iota
+	identityKey
+	peerCredKey
 )
 
+// Identity describes an authenticated caller. ReadOnly callers can't change the library.
+// Key tells apart credentials of callers with the same name, it is empty for anonymous callers.
+type Identity struct {
+	Name     string
+	Tenant   string
+	Key      string
+	Admin    bool
+	ReadOnly bool
+}
+
 // WithReqID adds request id into context.
 func WithReqID(ctx context.Context, reqID string) context.Context {
 	return context.WithValue(ctx, reqIDKey, reqID)
@@ -19,3 +31,38 @@ func WithReqID(ctx context.Context, reqID string) context.Context {
 func ReqIDFromCtx(ctx context.Context) string {
 	return ctx.Value(reqIDKey).(string)
 }
+
+// WithIdentity adds caller identity into context.
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey, id)
+}
+
+// IdentityFromCtx gets caller identity from context.
+func IdentityFromCtx(ctx context.Context) Identity {
+	id, _ := ctx.Value(identityKey).(Identity)
+
+	return id
+}
+
+// TenantFromCtx gets tenant of the caller from context.
+func TenantFromCtx(ctx context.Context) string {
+	return IdentityFromCtx(ctx).Tenant
+}
+
+// PeerCred contains credentials of a process connected to the unix socket.
+type PeerCred struct {
+	UID uint32
+	GID uint32
+}
+
+// WithPeerCred adds unix socket peer credentials into context.
+func WithPeerCred(ctx context.Context, cred PeerCred) context.Context {
+	return context.WithValue(ctx, peerCredKey, cred)
+}
+
+// PeerCredFromCtx gets unix socket peer credentials from context.
+func PeerCredFromCtx(ctx context.Context) (PeerCred, bool) {
+	cred, ok := ctx.Value(peerCredKey).(PeerCred)
+
+	return cred, ok
+}
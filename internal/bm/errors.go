@@ -56,6 +56,32 @@ func NewConflictError(format string, a ...any) ConflictError {
 	return ConflictError{fmt.Errorf(format, a...)}
 }
 
+// UnauthorizedError implements error interface.
+type UnauthorizedError struct {
+	Err error
+}
+
+func (err UnauthorizedError) Error() string {
+	return err.Err.Error()
+}
+
+func NewUnauthorizedError(format string, a ...any) UnauthorizedError {
+	return UnauthorizedError{fmt.Errorf(format, a...)}
+}
+
+// ForbiddenError implements error interface.
+type ForbiddenError struct {
+	Err error
+}
+
+func (err ForbiddenError) Error() string {
+	return err.Err.Error()
+}
+
+func NewForbiddenError(format string, a ...any) ForbiddenError {
+	return ForbiddenError{fmt.Errorf(format, a...)}
+}
+
 // ErrPair contains deferred and returned error.
 type ErrPair struct {
 	Def error
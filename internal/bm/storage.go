@@ -2,13 +2,124 @@ package bm
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"time"
 )
 
+// Ways to match books by tags.
+const (
+	TagsMatchAny = "any"
+	TagsMatchAll = "all"
+)
+
+// Roles of contributors of a book.
+const (
+	RoleAuthor      = "author"
+	RoleEditor      = "editor"
+	RoleTranslator  = "translator"
+	RoleIllustrator = "illustrator"
+)
+
+// Conditions of a copy of a book.
+const (
+	ConditionNew  = "new"
+	ConditionFine = "fine"
+	ConditionGood = "good"
+	ConditionFair = "fair"
+	ConditionPoor = "poor"
+)
+
+// Formats of digital files of a book.
+const (
+	FormatEPUB = "epub"
+	FormatPDF  = "pdf"
+)
+
+// Reading statuses of a book.
+const (
+	StatusWantToRead = "want_to_read"
+	StatusReading    = "reading"
+	StatusFinished   = "finished"
+	StatusAbandoned  = "abandoned"
+)
+
+// Types of events webhooks are notified about.
+const (
+	EventBookCreated            = "book.created"
+	EventBookUpdated            = "book.updated"
+	EventBookDeleted            = "book.deleted"
+	EventCollectionCreated      = "collection.created"
+	EventCollectionUpdated      = "collection.updated"
+	EventCollectionDeleted      = "collection.deleted"
+	EventCollectionBooksAdded   = "collection.books_added"
+	EventCollectionBooksRemoved = "collection.books_removed"
+)
+
+// EventTypes are all types of events.
+var EventTypes = []string{
+	EventBookCreated,
+	EventBookUpdated,
+	EventBookDeleted,
+	EventCollectionCreated,
+	EventCollectionUpdated,
+	EventCollectionDeleted,
+	EventCollectionBooksAdded,
+	EventCollectionBooksRemoved,
+}
+
+// EventPatterns returns filters of webhooks matching events of the type:
+// the type itself, all events of its category like "book.*" and all events "*".
+func EventPatterns(eventType string) []string {
+	category, _, _ := strings.Cut(eventType, ".")
+
+	return []string{eventType, category + ".*", "*"}
+}
+
+// Statuses of webhook deliveries.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+)
+
+// Entities of the change feed.
+const (
+	EntityBook       = "book"
+	EntityCollection = "collection"
+	EntityAuthor     = "author"
+	EntityGenre      = "genre"
+	EntityCopy       = "copy"
+	EntityLoan       = "loan"
+	EntityReview     = "review"
+	EntityBookFile   = "book_file"
+	EntityReading    = "reading"
+)
+
+// Operations of the change feed. Books added to or removed from a collection are changes of the collection.
+const (
+	OpCreated      = "created"
+	OpUpdated      = "updated"
+	OpDeleted      = "deleted"
+	OpBooksAdded   = "books_added"
+	OpBooksRemoved = "books_removed"
+)
+
 type (
 	BookFilter struct {
+		// Query matches books by a part of their title or author line.
+		Query        string
+		Title        string
 		Author       string
+		AuthorID     int64
 		Genre        string
+		GenreID      int64
+		Tags         []string
+		TagsMatch    string
+		Status       string
+		MinRating    float64
+		OnLoan       *bool
+		ISBN         string
 		CollectionID int64
 		StartDate    time.Time
 		FinishDate   time.Time
@@ -26,12 +137,182 @@ type (
 		Edition       string    `db:"edition"`
 		Description   string    `db:"description"`
 		Genre         string    `db:"genre"`
+		GenreID       int64     `db:"genre_id"`
+		ISBN          string    `db:"isbn"`
+
+		// Contributors are authors, editors, translators and illustrators of the book.
+		// Author contains names of the contributors with author role.
+		Contributors []Contributor `db:"-"`
+		Tags         []string      `db:"-"`
+
+		// AverageRating is 0 for books without reviews.
+		AverageRating float64 `db:"average_rating"`
+		ReviewCount   int64   `db:"review_count"`
+
+		// CopyCount is the number of physical copies of the book.
+		CopyCount int64 `db:"copy_count"`
+
+		// Cover is the id of the cover image in the blob store, empty for books without a cover.
+		Cover string `db:"cover"`
+
+		// Reading is nil for books the reader hasn't set a reading status for.
+		Reading *Reading `db:"-"`
+
+		// UpdatedAt is the last time the book, its reviews, number of copies or reading state were changed.
+		UpdatedAt time.Time `db:"updated_at"`
+	}
+
+	// Reading is the reading state of a book. Dates are zero when they aren't set.
+	// FinishedAt is the date the book was finished or abandoned.
+	Reading struct {
+		BookID      int64     `db:"book_id"`
+		Status      string    `db:"status"`
+		StartedAt   time.Time `db:"started_at"`
+		FinishedAt  time.Time `db:"finished_at"`
+		CurrentPage int64     `db:"current_page"`
+		Notes       string    `db:"notes"`
+	}
+
+	// BookFile is a digital file of a book. Blob is the id of the file in the file store.
+	BookFile struct {
+		ID        int64     `db:"id"`
+		BookID    int64     `db:"book_id"`
+		Name      string    `db:"name"`
+		Format    string    `db:"format"`
+		Size      int64     `db:"size"`
+		Blob      string    `db:"blob"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+
+	// DeletedBooks are ids of deleted books with ids of their covers and files.
+	DeletedBooks struct {
+		IDs    []int64
+		Covers []string
+		Files  []string
+	}
+
+	// Review is a rating of a book from 1 to 5 with an optional text.
+	Review struct {
+		ID        int64     `db:"id"`
+		BookID    int64     `db:"book_id"`
+		Rating    int64     `db:"rating"`
+		Text      string    `db:"text"`
+		CreatedAt time.Time `db:"created_at"`
+		UpdatedAt time.Time `db:"updated_at"`
+	}
+
+	ReviewsFilter struct {
+		BookID   int64
+		OrderBy  string
+		Desc     bool
+		Page     int64
+		PageSize int64
+	}
+
+	// Copy is a physical copy of a book. Price is in minor currency units, AcquiredAt is zero if unknown.
+	Copy struct {
+		ID         int64     `db:"id"`
+		BookID     int64     `db:"book_id"`
+		BookTitle  string    `db:"title"`
+		Barcode    string    `db:"barcode"`
+		Condition  string    `db:"condition"`
+		AcquiredAt time.Time `db:"acquired_at"`
+		Price      int64     `db:"price"`
+		Location   string    `db:"location"`
+	}
+
+	// CopiesFilter selects copies of a book, copies kept in a location or both.
+	CopiesFilter struct {
+		BookID   int64
+		Location string
+		OrderBy  string
+		Desc     bool
+		Page     int64
+		PageSize int64
+	}
+
+	// Loan is a lending of a book to a borrower. ReturnedAt is zero for active loans.
+	Loan struct {
+		ID         int64     `db:"id"`
+		BookID     int64     `db:"book_id"`
+		BookTitle  string    `db:"title"`
+		Borrower   string    `db:"borrower"`
+		LentAt     time.Time `db:"lent_at"`
+		DueAt      time.Time `db:"due_at"`
+		ReturnedAt time.Time `db:"returned_at"`
+	}
+
+	// LoansFilter selects active loans. Non-zero DueBefore selects loans due before the date.
+	LoansFilter struct {
+		DueBefore time.Time
+		OrderBy   string
+		Desc      bool
+		Page      int64
+		PageSize  int64
+	}
+
+	// ReadingReport lists books finished within a year.
+	ReadingReport struct {
+		Year  int
+		Books []Book
+	}
+
+	Contributor struct {
+		AuthorID int64  `db:"author_id"`
+		Name     string `db:"name"`
+		Role     string `db:"role"`
+	}
+
+	Author struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+
+	AuthorsFilter struct {
+		Name     string
+		OrderBy  string
+		Desc     bool
+		Page     int64
+		PageSize int64
+	}
+
+	// Genre is a node of the genre taxonomy. ParentID is 0 for top level genres.
+	Genre struct {
+		ID       int64  `db:"id"`
+		Name     string `db:"name"`
+		ParentID int64  `db:"parent_id"`
+	}
+
+	GenresFilter struct {
+		Name     string
+		ParentID int64
+		OrderBy  string
+		Desc     bool
+		Page     int64
+		PageSize int64
+	}
+
+	// Tag is a label of books with the number of books it is used by.
+	Tag struct {
+		ID    int64  `db:"id"`
+		Name  string `db:"name"`
+		Books int64  `db:"books"`
+	}
+
+	TagsFilter struct {
+		OrderBy  string
+		Desc     bool
+		Page     int64
+		PageSize int64
 	}
 
 	Collection struct {
 		ID          int64  `db:"id"`
 		Name        string `db:"name"`
 		Description string `db:"description"`
+
+		// UpdatedAt is the last time the collection or the set of its books were changed.
+		UpdatedAt time.Time `db:"updated_at"`
 	}
 
 	CollectionInfo struct {
@@ -53,9 +334,100 @@ type (
 		Page     int64
 		PageSize int64
 	}
+
+	// Webhook is a subscription of a URL to events of the tenant. Events are event types or patterns
+	// like "collection.*" and "*". Secret signs deliveries, an inactive webhook keeps its deliveries pending.
+	Webhook struct {
+		ID        int64
+		URL       string
+		Secret    string
+		Events    []string
+		Active    bool
+		CreatedAt time.Time
+	}
+
+	WebhooksFilter struct {
+		Page     int64
+		PageSize int64
+	}
+
+	// WebhookEvent is a change of the library. Data is the JSON description of the change.
+	WebhookEvent struct {
+		ID        int64           `db:"id"`
+		Type      string          `db:"type"`
+		Data      json.RawMessage `db:"data"`
+		CreatedAt time.Time       `db:"created_at"`
+	}
+
+	// WebhookDelivery is a delivery of an event to a webhook. Attempts are filled only for a single delivery.
+	WebhookDelivery struct {
+		ID            int64     `db:"id"`
+		WebhookID     int64     `db:"webhook_id"`
+		EventID       int64     `db:"event_id"`
+		EventType     string    `db:"event_type"`
+		Status        string    `db:"status"`
+		Attempts      int64     `db:"attempts"`
+		NextAttemptAt time.Time `db:"next_attempt_at"`
+		LastError     string    `db:"last_error"`
+		CreatedAt     time.Time `db:"created_at"`
+		UpdatedAt     time.Time `db:"updated_at"`
+
+		AttemptLog []WebhookAttempt `db:"-"`
+	}
+
+	// WebhookAttempt is a record of the delivery log. StatusCode is 0 if no response was received.
+	WebhookAttempt struct {
+		DeliveryID int64         `db:"delivery_id"`
+		Attempt    int64         `db:"attempt"`
+		StatusCode int64         `db:"status_code"`
+		Error      string        `db:"error"`
+		Duration   time.Duration `db:"-"`
+		CreatedAt  time.Time     `db:"created_at"`
+	}
+
+	// WebhookDeliveriesFilter selects deliveries of a webhook, optionally with the status.
+	WebhookDeliveriesFilter struct {
+		WebhookID int64
+		Status    string
+		Page      int64
+		PageSize  int64
+	}
+
+	// WebhookTask is a claimed delivery together with everything needed to send it.
+	WebhookTask struct {
+		Delivery WebhookDelivery
+		URL      string
+		Secret   string
+		Event    WebhookEvent
+	}
+
+	// Change is a record of the change feed of the tenant. Seq grows in the commit order, so a consumer
+	// resumes the feed after the last seq it has seen. Data refers to related entities, like the book of a review.
+	Change struct {
+		Seq       int64           `db:"seq"`
+		Entity    string          `db:"entity"`
+		EntityID  int64           `db:"entity_id"`
+		Op        string          `db:"op"`
+		Data      json.RawMessage `db:"data"`
+		CreatedAt time.Time       `db:"created_at"`
+	}
+
+	// ChangesFilter selects at most Limit changes following the change with seq Since.
+	ChangesFilter struct {
+		Since int64
+		Limit int64
+	}
+
+	// CacheStats are counters of a storage cache. Entries is the number of cached queries.
+	CacheStats struct {
+		Hits    int64
+		Misses  int64
+		Entries int64
+	}
 )
 
 // Storage is a database interface.
+// Every query is scoped by the tenant of the caller taken from the context.
 type Storage interface {
 	// Book retrieves a book by its id.
 	Book(ctx context.Context, id int64) (*Book, error)
@@ -69,8 +441,24 @@ type Storage interface {
 	// UpdateBook updates an existing book with the provided details.
 	UpdateBook(ctx context.Context, b Book) error
 
-	// DeleteBooks deletes books based on their IDs.
-	DeleteBooks(ctx context.Context, ids []int64) error
+	// DeleteBooks deletes books based on their IDs and returns ids and blobs of the deleted books.
+	// Books on loan are deleted only if force is set.
+	DeleteBooks(ctx context.Context, ids []int64, force bool) (*DeletedBooks, error)
+
+	// SetBookCover replaces the cover of a book and returns the previous one.
+	SetBookCover(ctx context.Context, bookID int64, cover string) (previous string, err error)
+
+	// BookFile retrieves a digital file of a book by its id.
+	BookFile(ctx context.Context, bookID, id int64) (*BookFile, error)
+
+	// BookFiles retrieves digital files of a book.
+	BookFiles(ctx context.Context, bookID int64) ([]BookFile, error)
+
+	// CreateBookFile attaches a digital file to a book.
+	CreateBookFile(ctx context.Context, f BookFile) (int64, error)
+
+	// DeleteBookFile detaches a digital file from a book and returns its blob.
+	DeleteBookFile(ctx context.Context, bookID, id int64) (blob string, err error)
 
 	// Collection retrieves a collection by its id.
 	Collection(ctx context.Context, id int64) (*Collection, error)
@@ -92,4 +480,157 @@ type Storage interface {
 
 	// DeleteBooksCollection removes a list of books from an existing collection.
 	DeleteBooksCollection(ctx context.Context, collectionID int64, bookIDs []int64) error
+
+	// BooksByIDs retrieves books by their ids ordered by id. Missing books are skipped.
+	BooksByIDs(ctx context.Context, ids []int64) ([]Book, error)
+
+	// CollectionsByIDs retrieves collections by their ids ordered by id. Missing collections are skipped.
+	CollectionsByIDs(ctx context.Context, ids []int64) ([]Collection, error)
+
+	// BooksOfCollections retrieves a page of books of each collection ordered by id and grouped by collection id.
+	BooksOfCollections(ctx context.Context, collectionIDs []int64, page, pageSize int64) (map[int64][]Book, error)
+
+	// CollectionsOfBooks retrieves a page of collections of each book ordered by id and grouped by book id.
+	CollectionsOfBooks(ctx context.Context, bookIDs []int64, page, pageSize int64) (map[int64][]Collection, error)
+
+	// Author retrieves an author by its id.
+	Author(ctx context.Context, id int64) (*Author, error)
+
+	// Authors retrieves a list of authors based on the provided filter criteria.
+	Authors(ctx context.Context, f AuthorsFilter) ([]Author, error)
+
+	// CreateAuthor creates a new author.
+	CreateAuthor(ctx context.Context, a Author) (int64, error)
+
+	// UpdateAuthor renames an existing author.
+	UpdateAuthor(ctx context.Context, a Author) error
+
+	// DeleteAuthor deletes an author without books.
+	DeleteAuthor(ctx context.Context, id int64) error
+
+	// Tags retrieves a list of tags with their usage counts.
+	Tags(ctx context.Context, f TagsFilter) ([]Tag, error)
+
+	// AddBooksTags adds tags to books. Missing tags are created.
+	AddBooksTags(ctx context.Context, bookIDs []int64, tags []string) error
+
+	// DeleteBooksTags removes tags from books. Tags left without books are deleted.
+	DeleteBooksTags(ctx context.Context, bookIDs []int64, tags []string) error
+
+	// Reading retrieves the reading state of a book. Status is empty if it was never set.
+	Reading(ctx context.Context, bookID int64) (*Reading, error)
+
+	// UpdateReading replaces the reading state of a book with the result of update called with the current one.
+	// The state can't change between the call of update and the replacement.
+	UpdateReading(ctx context.Context, bookID int64, update func(current Reading) (Reading, error)) (*Reading, error)
+
+	// FinishedBooks retrieves books finished within [from, to) ordered by finish date.
+	FinishedBooks(ctx context.Context, from, to time.Time) ([]Book, error)
+
+	// Review retrieves a review of a book by its id.
+	Review(ctx context.Context, bookID, id int64) (*Review, error)
+
+	// Reviews retrieves reviews of a book.
+	Reviews(ctx context.Context, f ReviewsFilter) ([]Review, error)
+
+	// CreateReview creates a review of a book.
+	CreateReview(ctx context.Context, r Review) (int64, error)
+
+	// UpdateReview updates rating and text of a review.
+	UpdateReview(ctx context.Context, r Review) error
+
+	// DeleteReview deletes a review of a book.
+	DeleteReview(ctx context.Context, bookID, id int64) error
+
+	// Copy retrieves a copy of a book by its id.
+	Copy(ctx context.Context, id int64) (*Copy, error)
+
+	// CopyByBarcode retrieves a copy of a book by its barcode.
+	CopyByBarcode(ctx context.Context, barcode string) (*Copy, error)
+
+	// Copies retrieves a list of copies based on the provided filter criteria.
+	Copies(ctx context.Context, f CopiesFilter) ([]Copy, error)
+
+	// CreateCopy creates a copy of a book.
+	CreateCopy(ctx context.Context, c Copy) (int64, error)
+
+	// UpdateCopy updates an existing copy. The book of a copy can't be changed.
+	UpdateCopy(ctx context.Context, c Copy) error
+
+	// DeleteCopy deletes a copy.
+	DeleteCopy(ctx context.Context, id int64) error
+
+	// LendBook creates an active loan of a book. A book can have only one active loan.
+	LendBook(ctx context.Context, l Loan) (int64, error)
+
+	// ReturnBook closes the active loan of a book.
+	ReturnBook(ctx context.Context, bookID int64, returnedAt time.Time) error
+
+	// Loans retrieves active loans based on the provided filter criteria.
+	Loans(ctx context.Context, f LoansFilter) ([]Loan, error)
+
+	// Genre retrieves a genre by its id.
+	Genre(ctx context.Context, id int64) (*Genre, error)
+
+	// GenreByName retrieves a genre by its case-insensitive name or alias.
+	GenreByName(ctx context.Context, name string) (*Genre, error)
+
+	// Genres retrieves a list of genres based on the provided filter criteria.
+	Genres(ctx context.Context, f GenresFilter) ([]Genre, error)
+
+	// CreateGenre creates a new genre.
+	CreateGenre(ctx context.Context, g Genre) (int64, error)
+
+	// UpdateGenre renames or moves an existing genre within the taxonomy.
+	UpdateGenre(ctx context.Context, g Genre) error
+
+	// DeleteGenre deletes a genre without books and subgenres.
+	DeleteGenre(ctx context.Context, id int64) error
+
+	// MoveBooks transfers books of any tenant to the given tenant and returns ids of the moved books
+	// by their previous tenants. Books which already belong to the tenant aren't returned.
+	MoveBooks(ctx context.Context, ids []int64, tenant string) (map[string][]int64, error)
+
+	// MoveCollection transfers a collection of any tenant together with its books to the given tenant.
+	MoveCollection(ctx context.Context, id int64, tenant string) error
+
+	// Webhook retrieves a webhook by its id.
+	Webhook(ctx context.Context, id int64) (*Webhook, error)
+
+	// Webhooks retrieves webhooks ordered by id.
+	Webhooks(ctx context.Context, f WebhooksFilter) ([]Webhook, error)
+
+	// CreateWebhook creates a new webhook.
+	CreateWebhook(ctx context.Context, w Webhook) (int64, error)
+
+	// UpdateWebhook updates url, events and activity of a webhook. The secret is replaced only if it is set.
+	UpdateWebhook(ctx context.Context, w Webhook) error
+
+	// DeleteWebhook deletes a webhook together with its deliveries.
+	DeleteWebhook(ctx context.Context, id int64) error
+
+	// CreateWebhookEvents stores events and creates their pending deliveries to active webhooks
+	// subscribed to them.
+	CreateWebhookEvents(ctx context.Context, events ...WebhookEvent) error
+
+	// WebhookDelivery retrieves a delivery of a webhook with its attempts.
+	WebhookDelivery(ctx context.Context, webhookID, id int64) (*WebhookDelivery, error)
+
+	// WebhookDeliveries retrieves deliveries of a webhook ordered from the newest.
+	WebhookDeliveries(ctx context.Context, f WebhookDeliveriesFilter) ([]WebhookDelivery, error)
+
+	// RedeliverWebhookEvent creates a new pending delivery of the event of a delivery.
+	RedeliverWebhookEvent(ctx context.Context, webhookID, deliveryID int64) (int64, error)
+
+	// ClaimWebhookDeliveries takes due pending deliveries of active webhooks of any tenant and postpones
+	// their next attempts until leaseUntil, so other dispatchers skip them while they are being sent.
+	ClaimWebhookDeliveries(ctx context.Context, limit int64, leaseUntil time.Time) ([]WebhookTask, error)
+
+	// RecordWebhookAttempt logs an attempt of a delivery and sets status, attempts, next attempt time
+	// and last error of the delivery.
+	RecordWebhookAttempt(ctx context.Context, d WebhookDelivery, a WebhookAttempt) error
+
+	// Changes retrieves changes of the library ordered by seq. Every mutation above but the ones of webhooks
+	// records its changes in its own transaction.
+	Changes(ctx context.Context, f ChangesFilter) ([]Change, error)
 }
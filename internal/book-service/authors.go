@@ -0,0 +1,160 @@
+package bookservice
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+const maxAuthorNameLen = 100
+
+// Author retrieves an author by its id.
+func (s *Service) Author(ctx context.Context, id int64) (*bm.Author, error) {
+	if id < 0 {
+		return nil, bm.NewValidationError("incorrect id")
+	}
+
+	author, err := s.storage.Author(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get author: %w", err)
+	}
+
+	return author, nil
+}
+
+// Authors retrieves a list of authors based on the provided filter criteria.
+func (s *Service) Authors(ctx context.Context, f bm.AuthorsFilter) ([]bm.Author, error) {
+	switch f.OrderBy {
+	case "id", "name":
+	case "":
+		f.OrderBy = "id"
+	default:
+		return nil, bm.NewValidationError("incorrect order_by")
+	}
+
+	if f.Page < 0 {
+		return nil, bm.NewValidationError("incorrect page")
+	}
+
+	if f.Page == 0 {
+		f.Page = 1
+	}
+
+	if f.PageSize < 0 {
+		return nil, bm.NewValidationError("page_size is negative")
+	}
+
+	if f.PageSize == 0 || f.PageSize > maxPageSize {
+		f.PageSize = maxPageSize
+	}
+
+	authors, err := s.storage.Authors(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("get authors: %w", err)
+	}
+
+	return authors, nil
+}
+
+// CreateAuthor creates a new author.
+func (s *Service) CreateAuthor(ctx context.Context, a bm.Author) (int64, error) {
+	if a.Name == "" {
+		return 0, bm.NewValidationError("name is empty")
+	}
+
+	if utf8.RuneCountInString(a.Name) > maxAuthorNameLen {
+		return 0, bm.NewValidationError("name is longer than %d characters", maxAuthorNameLen)
+	}
+
+	id, err := s.storage.CreateAuthor(ctx, a)
+	if err != nil {
+		return 0, fmt.Errorf("create author: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateAuthor renames an existing author.
+func (s *Service) UpdateAuthor(ctx context.Context, a bm.Author) error {
+	if a.ID <= 0 {
+		return bm.NewValidationError("incorrect id")
+	}
+
+	if a.Name == "" {
+		return bm.NewValidationError("name is empty")
+	}
+
+	if utf8.RuneCountInString(a.Name) > maxAuthorNameLen {
+		return bm.NewValidationError("name is longer than %d characters", maxAuthorNameLen)
+	}
+
+	if err := s.storage.UpdateAuthor(ctx, a); err != nil {
+		return fmt.Errorf("update author: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAuthor deletes an author without books.
+func (s *Service) DeleteAuthor(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return bm.NewValidationError("incorrect id")
+	}
+
+	if err := s.storage.DeleteAuthor(ctx, id); err != nil {
+		return fmt.Errorf("delete author: %w", err)
+	}
+
+	return nil
+}
+
+// validateContributors checks roles and authors of contributors. Empty role means author.
+// At least one contributor must have author role.
+func validateContributors(contributors []bm.Contributor) ([]bm.Contributor, error) {
+	validated := make([]bm.Contributor, 0, len(contributors))
+	hasAuthor := false
+	for _, c := range contributors {
+		switch c.Role {
+		case "":
+			c.Role = bm.RoleAuthor
+		case bm.RoleAuthor, bm.RoleEditor, bm.RoleTranslator, bm.RoleIllustrator:
+		default:
+			return nil, bm.NewValidationError("incorrect role %s", c.Role)
+		}
+
+		if c.AuthorID < 0 {
+			return nil, bm.NewValidationError("incorrect author_id")
+		}
+
+		if c.AuthorID == 0 && c.Name == "" {
+			return nil, bm.NewValidationError("contributor has neither author_id nor name")
+		}
+
+		if utf8.RuneCountInString(c.Name) > maxAuthorNameLen {
+			return nil, bm.NewValidationError("name of contributor is longer than %d characters", maxAuthorNameLen)
+		}
+
+		hasAuthor = hasAuthor || c.Role == bm.RoleAuthor
+		validated = append(validated, c)
+	}
+
+	if !hasAuthor {
+		return nil, bm.NewValidationError("author is empty")
+	}
+
+	return validated, nil
+}
+
+// replaceAuthors replaces contributors with author role by a single author keeping other contributors.
+func replaceAuthors(contributors []bm.Contributor, author string) []bm.Contributor {
+	replaced := []bm.Contributor{{Name: author, Role: bm.RoleAuthor}}
+	for _, c := range contributors {
+		if c.Role != bm.RoleAuthor {
+			replaced = append(replaced, c)
+		}
+	}
+
+	return replaced
+}
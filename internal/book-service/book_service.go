@@ -11,11 +11,15 @@ const (
 // Service stores and manages books and collections.
 type Service struct {
 	storage bm.Storage
+	covers  bm.BlobStore
+	files   bm.BlobStore
 }
 
-// New constructs new book service.
-func New(db bm.Storage) *Service {
+// New constructs new book service. Covers keep cover images of books, files keep their digital files.
+func New(db bm.Storage, covers, files bm.BlobStore) *Service {
 	return &Service{
 		storage: db,
+		covers:  covers,
+		files:   files,
 	}
 }
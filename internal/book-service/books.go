@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/rs/zerolog/log"
+
 	bm "github.com/Tsapen/bm/internal/bm"
 )
 
@@ -22,16 +24,39 @@ func (s *Service) Book(ctx context.Context, id int64) (*bm.Book, error) {
 	return book, nil
 }
 
+// BookByISBN retrieves a book by its ISBN-10 or ISBN-13.
+func (s *Service) BookByISBN(ctx context.Context, isbn string) (*bm.Book, error) {
+	isbn, err := NormalizeISBN(isbn)
+	if err != nil {
+		return nil, fmt.Errorf("normalize isbn: %w", err)
+	}
+
+	books, err := s.storage.Books(ctx, bm.BookFilter{ISBN: isbn, OrderBy: "id", Page: 1, PageSize: 1})
+	if err != nil {
+		return nil, fmt.Errorf("get books: %w", err)
+	}
+
+	if len(books) == 0 {
+		return nil, bm.NewNotFoundError("book with isbn %s not found", isbn)
+	}
+
+	return &books[0], nil
+}
+
 // Books retrieves a list of books based on the provided filter criteria.
 func (s *Service) Books(ctx context.Context, f bm.BookFilter) ([]bm.Book, error) {
 	switch f.OrderBy {
-	case "id", "title", "author", "genre", "published_date", "edition":
+	case "id", "title", "author", "genre", "published_date", "edition", "rating":
 	case "":
 		f.OrderBy = "id"
 	default:
 		return nil, bm.NewValidationError("incorrect order_by")
 	}
 
+	if f.MinRating < 0 || f.MinRating > maxRating {
+		return nil, bm.NewValidationError("incorrect min_rating")
+	}
+
 	if f.Page < 0 {
 		return nil, bm.NewValidationError("incorrect page")
 	}
@@ -48,6 +73,46 @@ func (s *Service) Books(ctx context.Context, f bm.BookFilter) ([]bm.Book, error)
 		f.PageSize = maxPageSize
 	}
 
+	if f.AuthorID < 0 {
+		return nil, bm.NewValidationError("incorrect author_id")
+	}
+
+	if f.GenreID < 0 {
+		return nil, bm.NewValidationError("incorrect genre_id")
+	}
+
+	switch f.TagsMatch {
+	case bm.TagsMatchAny, bm.TagsMatchAll:
+	case "":
+		f.TagsMatch = bm.TagsMatchAny
+	default:
+		return nil, bm.NewValidationError("incorrect tags_match")
+	}
+
+	if f.Status != "" {
+		if err := validateStatus(f.Status); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(f.Tags) != 0 {
+		tags, err := NormalizeTags(f.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("normalize tags: %w", err)
+		}
+
+		f.Tags = tags
+	}
+
+	if f.ISBN != "" {
+		isbn, err := NormalizeISBN(f.ISBN)
+		if err != nil {
+			return nil, fmt.Errorf("normalize isbn: %w", err)
+		}
+
+		f.ISBN = isbn
+	}
+
 	books, err := s.storage.Books(ctx, f)
 	if err != nil {
 		return nil, fmt.Errorf("get books: %w", err)
@@ -56,29 +121,91 @@ func (s *Service) Books(ctx context.Context, f bm.BookFilter) ([]bm.Book, error)
 	return books, nil
 }
 
+// BooksByIDs retrieves books by their ids, missing books are skipped.
+func (s *Service) BooksByIDs(ctx context.Context, ids []int64) ([]bm.Book, error) {
+	books, err := s.storage.BooksByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get books: %w", err)
+	}
+
+	return books, nil
+}
+
+// EachBook calls fn for every book matching the filter page by page, so the number of books isn't limited
+// by the page size. Pagination of the filter is ignored. Iteration stops at the first error of fn.
+func (s *Service) EachBook(ctx context.Context, f bm.BookFilter, fn func(bm.Book) error) error {
+	f.PageSize = maxPageSize
+	for f.Page = 1; ; f.Page++ {
+		books, err := s.Books(ctx, f)
+		if err != nil {
+			return err
+		}
+
+		for _, b := range books {
+			if err = fn(b); err != nil {
+				return err
+			}
+		}
+
+		if len(books) < maxPageSize {
+			return nil
+		}
+	}
+}
+
 // CreateBook creates a new book with the provided details.
 func (s *Service) CreateBook(ctx context.Context, b bm.Book) (int64, error) {
 	if b.Title == "" {
 		return 0, bm.NewValidationError("title is empty")
 	}
 
-	if b.Author == "" {
+	if len(b.Contributors) == 0 && b.Author == "" {
 		return 0, bm.NewValidationError("author is empty")
 	}
 
-	if b.Genre == "" {
+	if len(b.Contributors) == 0 {
+		b.Contributors = []bm.Contributor{{Name: b.Author, Role: bm.RoleAuthor}}
+	}
+
+	contributors, err := validateContributors(b.Contributors)
+	if err != nil {
+		return 0, fmt.Errorf("validate contributors: %w", err)
+	}
+
+	b.Contributors = contributors
+
+	if b.Genre == "" && b.GenreID == 0 {
 		return 0, bm.NewValidationError("genre is empty")
 	}
 
+	if b.GenreID < 0 {
+		return 0, bm.NewValidationError("incorrect genre_id")
+	}
+
+	if b.Tags, err = NormalizeTags(b.Tags); err != nil {
+		return 0, fmt.Errorf("normalize tags: %w", err)
+	}
+
 	if !b.PublishedDate.IsZero() {
 		b.PublishedDate = b.PublishedDate.Truncate(24 * time.Hour)
 	}
 
+	if b.ISBN != "" {
+		isbn, err := NormalizeISBN(b.ISBN)
+		if err != nil {
+			return 0, fmt.Errorf("normalize isbn: %w", err)
+		}
+
+		b.ISBN = isbn
+	}
+
 	id, err := s.storage.CreateBook(ctx, b)
 	if err != nil {
 		return 0, fmt.Errorf("create book: %w", err)
 	}
 
+	s.publish(ctx, bm.EventBookCreated, bookEvent{ID: id, Title: b.Title, ISBN: b.ISBN})
+
 	return id, nil
 }
 
@@ -92,34 +219,110 @@ func (s *Service) UpdateBook(ctx context.Context, b bm.Book) error {
 		return bm.NewValidationError("title is empty")
 	}
 
-	if b.Author == "" {
+	if len(b.Contributors) == 0 && b.Author == "" {
 		return bm.NewValidationError("author is empty")
 	}
 
-	if b.Genre == "" {
+	if len(b.Contributors) == 0 {
+		// Legacy clients send only the author line, other contributors of the book are kept.
+		current, err := s.storage.Book(ctx, b.ID)
+		if err != nil {
+			return fmt.Errorf("get book: %w", err)
+		}
+
+		b.Contributors = current.Contributors
+		if b.Author != current.Author {
+			b.Contributors = replaceAuthors(current.Contributors, b.Author)
+		}
+	}
+
+	contributors, err := validateContributors(b.Contributors)
+	if err != nil {
+		return fmt.Errorf("validate contributors: %w", err)
+	}
+
+	b.Contributors = contributors
+
+	if b.Genre == "" && b.GenreID == 0 {
 		return bm.NewValidationError("genre is empty")
 	}
 
+	if b.GenreID < 0 {
+		return bm.NewValidationError("incorrect genre_id")
+	}
+
 	if !b.PublishedDate.IsZero() {
 		b.PublishedDate = b.PublishedDate.Truncate(24 * time.Hour)
 	}
 
+	if b.ISBN != "" {
+		isbn, err := NormalizeISBN(b.ISBN)
+		if err != nil {
+			return fmt.Errorf("normalize isbn: %w", err)
+		}
+
+		b.ISBN = isbn
+	}
+
 	if err := s.storage.UpdateBook(ctx, b); err != nil {
 		return fmt.Errorf("update book: %w", err)
 	}
 
+	s.publish(ctx, bm.EventBookUpdated, bookEvent{ID: b.ID, Title: b.Title, ISBN: b.ISBN})
+
 	return nil
 }
 
-// DeleteBooks deletes multiple books based on their IDs.
-func (s *Service) DeleteBooks(ctx context.Context, ids []int64) error {
+// DeleteBooks deletes multiple books based on their IDs together with their covers and files.
+// Books on loan are refused with a conflict unless force is set.
+func (s *Service) DeleteBooks(ctx context.Context, ids []int64, force bool) error {
 	if len(ids) == 0 {
 		return bm.NewValidationError("ids list is empty")
 	}
 
-	if err := s.storage.DeleteBooks(ctx, ids); err != nil {
+	deleted, err := s.storage.DeleteBooks(ctx, ids, force)
+	if err != nil {
 		return fmt.Errorf("delete books: %w", err)
 	}
 
+	// Books are already deleted, so a failure leaves orphaned blobs only.
+	if err = s.deleteCovers(ctx, deleted.Covers...); err != nil {
+		log.Error().Err(err).Strs("covers", deleted.Covers).Msg("delete covers of deleted books")
+	}
+
+	if err = s.deleteFiles(ctx, deleted.Files...); err != nil {
+		log.Error().Err(err).Strs("files", deleted.Files).Msg("delete files of deleted books")
+	}
+
+	s.publish(ctx, bm.EventBookDeleted, bookEvents(deleted.IDs)...)
+
+	return nil
+}
+
+// MoveBooks transfers books to another tenant. Only admins are allowed to do it.
+// Moved books are deleted for webhooks of their previous tenants and created for webhooks of the tenant.
+func (s *Service) MoveBooks(ctx context.Context, ids []int64, tenant string) error {
+	if !bm.IdentityFromCtx(ctx).Admin {
+		return bm.NewForbiddenError("only admins can move books")
+	}
+
+	if len(ids) == 0 {
+		return bm.NewValidationError("ids list is empty")
+	}
+
+	if tenant == "" {
+		return bm.NewValidationError("tenant is empty")
+	}
+
+	moved, err := s.storage.MoveBooks(ctx, ids, tenant)
+	if err != nil {
+		return fmt.Errorf("move books: %w", err)
+	}
+
+	for previous, movedIDs := range moved {
+		s.publish(withTenant(ctx, previous), bm.EventBookDeleted, bookEvents(movedIDs)...)
+		s.publish(withTenant(ctx, tenant), bm.EventBookCreated, bookEvents(movedIDs)...)
+	}
+
 	return nil
 }
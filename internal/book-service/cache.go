@@ -0,0 +1,28 @@
+package bookservice
+
+import (
+	"context"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// cachedStorage is a storage which caches queries.
+type cachedStorage interface {
+	Stats() bm.CacheStats
+}
+
+// CacheStats returns counters of the storage cache. Only admins are allowed to get them.
+func (s *Service) CacheStats(ctx context.Context) (*bm.CacheStats, error) {
+	if !bm.IdentityFromCtx(ctx).Admin {
+		return nil, bm.NewForbiddenError("only admins can get cache stats")
+	}
+
+	cache, ok := s.storage.(cachedStorage)
+	if !ok {
+		return nil, bm.NewNotFoundError("storage cache is disabled")
+	}
+
+	stats := cache.Stats()
+
+	return &stats, nil
+}
@@ -0,0 +1,33 @@
+package bookservice
+
+import (
+	"context"
+	"fmt"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// maxChangesLimit limits the number of changes returned at once.
+const maxChangesLimit = 500
+
+// Changes retrieves changes of the library following the change with seq f.Since.
+func (s *Service) Changes(ctx context.Context, f bm.ChangesFilter) ([]bm.Change, error) {
+	if f.Since < 0 {
+		return nil, bm.NewValidationError("since is negative")
+	}
+
+	if f.Limit < 0 {
+		return nil, bm.NewValidationError("limit is negative")
+	}
+
+	if f.Limit == 0 || f.Limit > maxChangesLimit {
+		f.Limit = maxChangesLimit
+	}
+
+	changes, err := s.storage.Changes(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("get changes: %w", err)
+	}
+
+	return changes, nil
+}
@@ -55,6 +55,67 @@ func (s *Service) Collections(ctx context.Context, f bm.CollectionsFilter) ([]bm
 	return collections, nil
 }
 
+// CollectionsByIDs retrieves collections by their ids, missing collections are skipped.
+func (s *Service) CollectionsByIDs(ctx context.Context, ids []int64) ([]bm.Collection, error) {
+	collections, err := s.storage.CollectionsByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get collections: %w", err)
+	}
+
+	return collections, nil
+}
+
+// BooksOfCollections retrieves a page of books of each of several collections at once, grouped by collection id.
+func (s *Service) BooksOfCollections(ctx context.Context, cIDs []int64, page, pageSize int64) (map[int64][]bm.Book, error) {
+	page, pageSize, err := validatePage(page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	books, err := s.storage.BooksOfCollections(ctx, cIDs, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("get books of collections: %w", err)
+	}
+
+	return books, nil
+}
+
+// CollectionsOfBooks retrieves a page of collections of each of several books at once, grouped by book id.
+func (s *Service) CollectionsOfBooks(ctx context.Context, bookIDs []int64, page, pageSize int64) (map[int64][]bm.Collection, error) {
+	page, pageSize, err := validatePage(page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	collections, err := s.storage.CollectionsOfBooks(ctx, bookIDs, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("get collections of books: %w", err)
+	}
+
+	return collections, nil
+}
+
+// validatePage checks the page and its size, zero values are replaced by the first page and the maximum size.
+func validatePage(page, pageSize int64) (int64, int64, error) {
+	if page < 0 {
+		return 0, 0, bm.NewValidationError("incorrect page")
+	}
+
+	if page == 0 {
+		page = 1
+	}
+
+	if pageSize < 0 {
+		return 0, 0, bm.NewValidationError("page_size is negative")
+	}
+
+	if pageSize == 0 || pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	return page, pageSize, nil
+}
+
 // CreateCollection creates a new collection with the provided details.
 func (s *Service) CreateCollection(ctx context.Context, c bm.Collection) (int64, error) {
 	if c.Name == "" {
@@ -66,6 +127,8 @@ func (s *Service) CreateCollection(ctx context.Context, c bm.Collection) (int64,
 		return 0, fmt.Errorf("create collection: %w", err)
 	}
 
+	s.publish(ctx, bm.EventCollectionCreated, collectionEvent{ID: id, Name: c.Name, Description: c.Description})
+
 	return id, nil
 }
 
@@ -83,6 +146,8 @@ func (s *Service) CreateBooksCollection(ctx context.Context, cID int64, bookIDs
 		return fmt.Errorf("add books to collection: %w", err)
 	}
 
+	s.publish(ctx, bm.EventCollectionBooksAdded, collectionBooksEvent{CollectionID: cID, BookIDs: bookIDs})
+
 	return nil
 }
 
@@ -100,6 +165,8 @@ func (s *Service) DeleteBooksCollection(ctx context.Context, cID int64, bookIDs
 		return fmt.Errorf("remove books from collection: %w", err)
 	}
 
+	s.publish(ctx, bm.EventCollectionBooksRemoved, collectionBooksEvent{CollectionID: cID, BookIDs: bookIDs})
+
 	return nil
 }
 
@@ -117,6 +184,8 @@ func (s *Service) UpdateCollection(ctx context.Context, c bm.Collection) error {
 		return fmt.Errorf("update collection: %w", err)
 	}
 
+	s.publish(ctx, bm.EventCollectionUpdated, collectionEvent{ID: c.ID, Name: c.Name, Description: c.Description})
+
 	return nil
 }
 
@@ -130,5 +199,28 @@ func (s *Service) DeleteCollection(ctx context.Context, cID int64) error {
 		return fmt.Errorf("delete collection: %w", err)
 	}
 
+	s.publish(ctx, bm.EventCollectionDeleted, collectionEvent{ID: cID})
+
+	return nil
+}
+
+// MoveCollection transfers a collection with its books to another tenant. Only admins are allowed to do it.
+func (s *Service) MoveCollection(ctx context.Context, cID int64, tenant string) error {
+	if !bm.IdentityFromCtx(ctx).Admin {
+		return bm.NewForbiddenError("only admins can move collections")
+	}
+
+	if cID <= 0 {
+		return bm.NewValidationError("incorrect id")
+	}
+
+	if tenant == "" {
+		return bm.NewValidationError("tenant is empty")
+	}
+
+	if err := s.storage.MoveCollection(ctx, cID, tenant); err != nil {
+		return fmt.Errorf("move collection: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,170 @@
+package bookservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+const (
+	maxBarcodeLen  = 50
+	maxLocationLen = 100
+)
+
+// Copy retrieves a copy of a book by its id.
+func (s *Service) Copy(ctx context.Context, id int64) (*bm.Copy, error) {
+	if id <= 0 {
+		return nil, bm.NewValidationError("incorrect id")
+	}
+
+	c, err := s.storage.Copy(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get copy: %w", err)
+	}
+
+	return c, nil
+}
+
+// CopyByBarcode retrieves a copy of a book by its barcode.
+func (s *Service) CopyByBarcode(ctx context.Context, barcode string) (*bm.Copy, error) {
+	barcode = strings.TrimSpace(barcode)
+	if barcode == "" {
+		return nil, bm.NewValidationError("barcode is empty")
+	}
+
+	c, err := s.storage.CopyByBarcode(ctx, barcode)
+	if err != nil {
+		return nil, fmt.Errorf("get copy: %w", err)
+	}
+
+	return c, nil
+}
+
+// Copies retrieves copies of a book or the inventory of a location.
+func (s *Service) Copies(ctx context.Context, f bm.CopiesFilter) ([]bm.Copy, error) {
+	switch f.OrderBy {
+	case "id", "book_id", "barcode", "condition", "acquired_at", "price", "location":
+	case "":
+		f.OrderBy = "id"
+	default:
+		return nil, bm.NewValidationError("incorrect order_by")
+	}
+
+	if f.BookID < 0 {
+		return nil, bm.NewValidationError("incorrect book_id")
+	}
+
+	f.Location = strings.TrimSpace(f.Location)
+
+	if f.Page < 0 {
+		return nil, bm.NewValidationError("incorrect page")
+	}
+
+	if f.Page == 0 {
+		f.Page = 1
+	}
+
+	if f.PageSize < 0 {
+		return nil, bm.NewValidationError("page_size is negative")
+	}
+
+	if f.PageSize == 0 || f.PageSize > maxPageSize {
+		f.PageSize = maxPageSize
+	}
+
+	copies, err := s.storage.Copies(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("get copies: %w", err)
+	}
+
+	return copies, nil
+}
+
+// CreateCopy creates a copy of a book.
+func (s *Service) CreateCopy(ctx context.Context, c bm.Copy) (int64, error) {
+	if c.BookID <= 0 {
+		return 0, bm.NewValidationError("incorrect book_id")
+	}
+
+	c, err := validateCopy(c)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := s.storage.CreateCopy(ctx, c)
+	if err != nil {
+		return 0, fmt.Errorf("create copy: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateCopy updates an existing copy.
+func (s *Service) UpdateCopy(ctx context.Context, c bm.Copy) error {
+	if c.ID <= 0 {
+		return bm.NewValidationError("incorrect id")
+	}
+
+	c, err := validateCopy(c)
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.UpdateCopy(ctx, c); err != nil {
+		return fmt.Errorf("update copy: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCopy deletes a copy.
+func (s *Service) DeleteCopy(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return bm.NewValidationError("incorrect id")
+	}
+
+	if err := s.storage.DeleteCopy(ctx, id); err != nil {
+		return fmt.Errorf("delete copy: %w", err)
+	}
+
+	return nil
+}
+
+// validateCopy trims barcode and location of a copy and sets good condition by default.
+func validateCopy(c bm.Copy) (bm.Copy, error) {
+	c.Barcode = strings.TrimSpace(c.Barcode)
+	if c.Barcode == "" {
+		return c, bm.NewValidationError("barcode is empty")
+	}
+
+	if utf8.RuneCountInString(c.Barcode) > maxBarcodeLen {
+		return c, bm.NewValidationError("barcode is longer than %d characters", maxBarcodeLen)
+	}
+
+	switch c.Condition {
+	case bm.ConditionNew, bm.ConditionFine, bm.ConditionGood, bm.ConditionFair, bm.ConditionPoor:
+	case "":
+		c.Condition = bm.ConditionGood
+	default:
+		return c, bm.NewValidationError("incorrect condition")
+	}
+
+	if c.Price < 0 {
+		return c, bm.NewValidationError("price is negative")
+	}
+
+	c.Location = strings.TrimSpace(c.Location)
+	if utf8.RuneCountInString(c.Location) > maxLocationLen {
+		return c, bm.NewValidationError("location is longer than %d characters", maxLocationLen)
+	}
+
+	if !c.AcquiredAt.IsZero() {
+		c.AcquiredAt = c.AcquiredAt.Truncate(24 * time.Hour)
+	}
+
+	return c, nil
+}
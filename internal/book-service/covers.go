@@ -0,0 +1,218 @@
+package bookservice
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+const (
+	// MaxCoverSize is the maximum size of a cover image in bytes.
+	MaxCoverSize = 5 << 20
+
+	// maxCoverSide limits dimensions of a cover image, so small files can't expand into huge bitmaps.
+	maxCoverSide  = 6000
+	thumbnailSide = 200
+)
+
+// CoverImage is an image of a book cover. ID changes every time the cover is replaced.
+type CoverImage struct {
+	ID          string
+	ContentType string
+	Data        []byte
+}
+
+// UploadCover validates a JPEG or PNG image, stores it together with its thumbnail and makes it the cover of a book.
+func (s *Service) UploadCover(ctx context.Context, bookID int64, data []byte) error {
+	if bookID <= 0 {
+		return bm.NewValidationError("incorrect book_id")
+	}
+
+	img, err := decodeCover(data)
+	if err != nil {
+		return fmt.Errorf("decode cover: %w", err)
+	}
+
+	thumb := new(bytes.Buffer)
+	if err = jpeg.Encode(thumb, thumbnail(img, thumbnailSide), &jpeg.Options{Quality: 85}); err != nil {
+		return bm.NewInternalError("encode thumbnail: %w", err)
+	}
+
+	cover := uuid.NewString()
+	if err = s.covers.Put(ctx, coverKey(cover), bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("put cover: %w", err)
+	}
+
+	if err = s.covers.Put(ctx, thumbnailKey(cover), thumb); err != nil {
+		return bm.HandleErrPair(s.covers.Delete(ctx, coverKey(cover)), fmt.Errorf("put thumbnail: %w", err))
+	}
+
+	previous, err := s.storage.SetBookCover(ctx, bookID, cover)
+	if err != nil {
+		return bm.HandleErrPair(s.deleteCovers(ctx, cover), fmt.Errorf("set book cover: %w", err))
+	}
+
+	if previous != "" {
+		if err = s.deleteCovers(ctx, previous); err != nil {
+			log.Error().Err(err).Str("cover", previous).Msg("delete previous cover")
+		}
+	}
+
+	s.publish(ctx, bm.EventBookUpdated, bookEvent{ID: bookID})
+
+	return nil
+}
+
+// Cover retrieves the cover image of a book or its JPEG thumbnail.
+func (s *Service) Cover(ctx context.Context, bookID int64, thumb bool) (*CoverImage, error) {
+	if bookID <= 0 {
+		return nil, bm.NewValidationError("incorrect book_id")
+	}
+
+	book, err := s.storage.Book(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("get book: %w", err)
+	}
+
+	if book.Cover == "" {
+		return nil, bm.NewNotFoundError("book with ID %d has no cover", bookID)
+	}
+
+	key := coverKey(book.Cover)
+	if thumb {
+		key = thumbnailKey(book.Cover)
+	}
+
+	r, err := s.covers.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("get cover: %w", err)
+	}
+
+	data, err := io.ReadAll(r)
+	if err = bm.HandleErrPair(r.Close(), err); err != nil {
+		return nil, bm.NewInternalError("read cover: %w", err)
+	}
+
+	return &CoverImage{
+		ID:          book.Cover,
+		ContentType: http.DetectContentType(data),
+		Data:        data,
+	}, nil
+}
+
+// deleteCovers deletes cover images and thumbnails.
+func (s *Service) deleteCovers(ctx context.Context, covers ...string) error {
+	keys := make([]string, 0, 2*len(covers))
+	for _, c := range covers {
+		keys = append(keys, coverKey(c), thumbnailKey(c))
+	}
+
+	if err := s.covers.Delete(ctx, keys...); err != nil {
+		return fmt.Errorf("delete blobs: %w", err)
+	}
+
+	return nil
+}
+
+func coverKey(cover string) string {
+	return "covers/" + cover
+}
+
+func thumbnailKey(cover string) string {
+	return "covers/" + cover + "_thumbnail"
+}
+
+// decodeCover checks size, format and dimensions of a cover image and decodes it.
+func decodeCover(data []byte) (image.Image, error) {
+	if len(data) == 0 {
+		return nil, bm.NewValidationError("cover is empty")
+	}
+
+	if len(data) > MaxCoverSize {
+		return nil, bm.NewValidationError("cover is larger than %d bytes", MaxCoverSize)
+	}
+
+	var decode func(io.Reader) (image.Image, error)
+	var decodeConfig func(io.Reader) (image.Config, error)
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		decode, decodeConfig = jpeg.Decode, jpeg.DecodeConfig
+
+	case "image/png":
+		decode, decodeConfig = png.Decode, png.DecodeConfig
+
+	default:
+		return nil, bm.NewValidationError("cover must be a JPEG or PNG image")
+	}
+
+	cfg, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, bm.NewValidationError("incorrect image: %w", err)
+	}
+
+	if cfg.Width > maxCoverSide || cfg.Height > maxCoverSide {
+		return nil, bm.NewValidationError("cover is larger than %dx%d pixels", maxCoverSide, maxCoverSide)
+	}
+
+	img, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, bm.NewValidationError("incorrect image: %w", err)
+	}
+
+	return img, nil
+}
+
+// thumbnail scales an image down to fit a side x side square by averaging source pixels.
+// Transparent pixels are blended with white, smaller images keep their size.
+func thumbnail(src image.Image, side int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	tw, th := w, h
+	switch {
+	case w > side && w >= h:
+		tw, th = side, max(1, h*side/w)
+
+	case h > side:
+		tw, th = max(1, w*side/h), side
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := b.Min.Y+y*h/th, b.Min.Y+(y+1)*h/th
+		for x := 0; x < tw; x++ {
+			x0, x1 := b.Min.X+x*w/tw, b.Min.X+(x+1)*w/tw
+
+			// Colors are alpha-premultiplied, so blending with white adds the missing alpha to every channel.
+			var r, g, bl, a uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(pr), g+uint64(pg), bl+uint64(pb), a+uint64(pa)
+				}
+			}
+
+			n := uint64((y1 - y0) * (x1 - x0))
+			white := n*0xffff - a
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8((r + white) / n >> 8),
+				G: uint8((g + white) / n >> 8),
+				B: uint8((bl + white) / n >> 8),
+				A: 0xff,
+			})
+		}
+	}
+
+	return dst
+}
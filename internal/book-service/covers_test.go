@@ -0,0 +1,61 @@
+package bookservice
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThumbnail(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 400, 100))
+	for x := 0; x < 400; x++ {
+		for y := 0; y < 100; y++ {
+			src.SetNRGBA(x, y, color.NRGBA{R: 0xff, A: 0xff})
+		}
+	}
+
+	thumb := thumbnail(src, 200)
+	assert.Equal(t, image.Rect(0, 0, 200, 50), thumb.Bounds())
+	assert.Equal(t, color.RGBA{R: 0xff, A: 0xff}, thumb.RGBAAt(100, 25))
+
+	// Transparent pixels become white, small images keep their size.
+	thumb = thumbnail(image.NewNRGBA(image.Rect(0, 0, 30, 60)), 200)
+	assert.Equal(t, image.Rect(0, 0, 30, 60), thumb.Bounds())
+	assert.Equal(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, thumb.RGBAAt(0, 0))
+
+	thumb = thumbnail(image.NewGray(image.Rect(0, 0, 10, 1000)), 200)
+	assert.Equal(t, image.Rect(0, 0, 2, 200), thumb.Bounds())
+}
+
+func TestDecodeCover(t *testing.T) {
+	encode := func(img image.Image, encoder func(*bytes.Buffer, image.Image) error) []byte {
+		buf := new(bytes.Buffer)
+		assert.NoError(t, encoder(buf, img))
+
+		return buf.Bytes()
+	}
+
+	encodePNG := func(buf *bytes.Buffer, img image.Image) error { return png.Encode(buf, img) }
+	encodeGIF := func(buf *bytes.Buffer, img image.Image) error { return gif.Encode(buf, img, nil) }
+
+	img, err := decodeCover(encode(image.NewGray(image.Rect(0, 0, 3, 4)), encodePNG))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 3, 4), img.Bounds())
+
+	invalid := [][]byte{
+		nil,
+		[]byte("not an image"),
+		encode(image.NewGray(image.Rect(0, 0, 3, 4)), encodeGIF),
+		encode(image.NewGray(image.Rect(0, 0, maxCoverSide+1, 1)), encodePNG),
+		make([]byte, MaxCoverSize+1),
+	}
+	for _, data := range invalid {
+		_, err := decodeCover(data)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,236 @@
+package bookservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// Layouts of CSV exports.
+const (
+	LayoutGoodreads    = "goodreads"
+	LayoutLibraryThing = "librarything"
+)
+
+var yearPattern = regexp.MustCompile(`\d{4}`)
+
+// csvLayout lists columns of an export layout. Columns of a field are tried in order until a non-empty value.
+type csvLayout struct {
+	name    string
+	title   []string
+	author  []string
+	year    []string
+	isbn    []string
+	shelves []string
+
+	// authorLastFirst is set for layouts which keep names as "Last, First".
+	authorLastFirst bool
+}
+
+var csvLayouts = []csvLayout{
+	{
+		name:    LayoutGoodreads,
+		title:   []string{"title"},
+		author:  []string{"author"},
+		year:    []string{"year published", "original publication year"},
+		isbn:    []string{"isbn13", "isbn"},
+		shelves: []string{"bookshelves", "exclusive shelf"},
+	},
+	{
+		name:            LayoutLibraryThing,
+		title:           []string{"title"},
+		author:          []string{"primary author"},
+		year:            []string{"date"},
+		isbn:            []string{"isbn", "isbns"},
+		shelves:         []string{"collections"},
+		authorLastFirst: true,
+	},
+}
+
+// ImportBooks imports books from a Goodreads or LibraryThing CSV export. Shelves become collections of the imported
+// books, missing collections are created. Books which already exist by ISBN or by title and author are skipped,
+// so an export can be imported again. A dry run reports changes without making them.
+func (s *Service) ImportBooks(ctx context.Context, data []byte, genre string, dryRun bool) (*ImportReport, error) {
+	if len(data) > MaxImportSize {
+		return nil, bm.NewValidationError("export is larger than %d bytes", MaxImportSize)
+	}
+
+	layout, rows, err := parseExport(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse export: %w", err)
+	}
+
+	books := make([]bm.Book, 0, len(rows))
+	for _, r := range rows {
+		b := bm.Book{Title: r.Title, Author: r.Author, ISBN: r.ISBN}
+		if r.Year != 0 {
+			b.PublishedDate = time.Date(r.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		}
+
+		books = append(books, b)
+	}
+
+	return s.importBooks(ctx, layout, rows, books, genre, dryRun)
+}
+
+// parseExport detects the layout of a CSV export by its header and reads its rows.
+// Rows without a title or an author and rows with an incorrect ISBN are marked invalid.
+func parseExport(data []byte) (string, []ImportedBook, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	// LibraryThing exports tab-separated files as well.
+	if header, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(header, []byte("\t")) > bytes.Count(header, []byte(",")) {
+		r.Comma = '\t'
+	}
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return "", nil, bm.NewValidationError("export is empty")
+	}
+
+	if err != nil {
+		return "", nil, bm.NewValidationError("incorrect csv: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	layout, err := detectLayout(columns)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var books []ImportedBook
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return "", nil, bm.NewValidationError("incorrect csv: %w", err)
+		}
+
+		line, _ := r.FieldPos(0)
+		books = append(books, layout.book(line, columns, record))
+	}
+
+	return layout.name, books, nil
+}
+
+func detectLayout(columns map[string]int) (*csvLayout, error) {
+	for i := range csvLayouts {
+		layout := &csvLayouts[i]
+
+		_, hasTitle := columns[layout.title[0]]
+		_, hasAuthor := columns[layout.author[0]]
+		_, hasShelves := columns[layout.shelves[0]]
+		if hasTitle && hasAuthor && hasShelves {
+			return layout, nil
+		}
+	}
+
+	return nil, bm.NewValidationError("unknown csv layout, expected a Goodreads or LibraryThing export")
+}
+
+func (l *csvLayout) book(line int, columns map[string]int, record []string) ImportedBook {
+	value := func(names []string, clean func(string) string) string {
+		for _, name := range names {
+			if i, ok := columns[name]; ok && i < len(record) {
+				if v := clean(record[i]); v != "" {
+					return v
+				}
+			}
+		}
+
+		return ""
+	}
+
+	b := ImportedBook{
+		Line:   line,
+		Title:  value(l.title, strings.TrimSpace),
+		Author: value(l.author, strings.TrimSpace),
+		Action: ImportCreate,
+	}
+
+	if l.authorLastFirst {
+		b.Author = firstLastName(b.Author)
+	}
+
+	if year := yearPattern.FindString(value(l.year, strings.TrimSpace)); year != "" {
+		b.Year, _ = strconv.Atoi(year)
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range l.shelves {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			continue
+		}
+
+		for _, shelf := range strings.Split(record[i], ",") {
+			if shelf = strings.TrimSpace(shelf); shelf != "" && !seen[shelf] {
+				seen[shelf] = true
+				b.Shelves = append(b.Shelves, shelf)
+			}
+		}
+	}
+
+	switch {
+	case b.Title == "":
+		b.Action, b.Error = ImportInvalid, "title is empty"
+
+	case b.Author == "":
+		b.Action, b.Error = ImportInvalid, "author is empty"
+	}
+
+	// LibraryThing lists all ISBNs of a book in one column, the first one is used.
+	isbn, _, _ := strings.Cut(value(l.isbn, cleanISBN), ",")
+	if isbn == "" {
+		return b
+	}
+
+	normalized, err := NormalizeISBN(strings.TrimSpace(isbn))
+	if err != nil && b.Action == ImportCreate {
+		b.Action, b.Error = ImportInvalid, err.Error()
+	}
+
+	b.ISBN = normalized
+
+	return b
+}
+
+// cleanISBN removes spreadsheet formula quoting of Goodreads, ="0140449136", and brackets of LibraryThing, [0140449136].
+func cleanISBN(v string) string {
+	v = strings.TrimSpace(v)
+	if strings.HasPrefix(v, `="`) && strings.HasSuffix(v, `"`) {
+		v = v[2 : len(v)-1]
+	}
+
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(v, "["), "]"))
+}
+
+// firstLastName turns "Dostoevsky, Fyodor" into "Fyodor Dostoevsky". Other names are kept.
+func firstLastName(name string) string {
+	last, first, ok := strings.Cut(name, ",")
+	if !ok || strings.Contains(first, ",") {
+		return name
+	}
+
+	return strings.TrimSpace(strings.TrimSpace(first) + " " + strings.TrimSpace(last))
+}
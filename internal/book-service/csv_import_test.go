@@ -0,0 +1,102 @@
+package bookservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testGoodreadsExport = "\ufeffBook Id,Title,Author,Author l-f,Additional Authors,ISBN,ISBN13,My Rating,Year Published,Original Publication Year,Bookshelves,Exclusive Shelf\n" +
+	`2623,Great Expectations,Charles Dickens,"Dickens, Charles",,="0141439564",="9780141439563",4,2002,1861,"classics, favorites",read` + "\n" +
+	`7624,Lord of the Flies,William Golding,"Golding, William",,="",="",0,,1954,,to-read` + "\n" +
+	`1,,Nobody,,,="",="",0,,,,to-read` + "\n" +
+	`2,Broken,Somebody,,,="12345",="",0,,,,read` + "\n" +
+	`"5107","The Catcher
+in the Rye",J.D. Salinger,"Salinger, J.D.",,="",="",0,1951,,,read` + "\n"
+
+const testLibraryThingExport = "Book Id\tTitle\tPrimary Author\tDate\tCollections\tISBN\tISBNs\n" +
+	"11\tDead Souls\tGogol, Nikolai\tc2004\tYour library, Russian\t[0140448071]\t0140448071, 9780140448078\n" +
+	"12\tWar and Peace\tLeo Tolstoy\t\tWishlist\t\t9781400079988\n"
+
+func TestParseExport(t *testing.T) {
+	layout, books, err := parseExport([]byte(testGoodreadsExport))
+	assert.NoError(t, err)
+	assert.Equal(t, LayoutGoodreads, layout)
+	assert.Equal(t, []ImportedBook{
+		{
+			Line:    2,
+			Title:   "Great Expectations",
+			Author:  "Charles Dickens",
+			Year:    2002,
+			ISBN:    "9780141439563",
+			Shelves: []string{"classics", "favorites", "read"},
+			Action:  ImportCreate,
+		},
+		{
+			Line:    3,
+			Title:   "Lord of the Flies",
+			Author:  "William Golding",
+			Year:    1954,
+			Shelves: []string{"to-read"},
+			Action:  ImportCreate,
+		},
+		{
+			Line:    4,
+			Author:  "Nobody",
+			Shelves: []string{"to-read"},
+			Action:  ImportInvalid,
+			Error:   "title is empty",
+		},
+		{
+			Line:    5,
+			Title:   "Broken",
+			Author:  "Somebody",
+			Shelves: []string{"read"},
+			Action:  ImportInvalid,
+			Error:   "isbn 12345 must contain 10 or 13 characters",
+		},
+		{
+			Line:    6,
+			Title:   "The Catcher\nin the Rye",
+			Author:  "J.D. Salinger",
+			Year:    1951,
+			Shelves: []string{"read"},
+			Action:  ImportCreate,
+		},
+	}, books)
+
+	layout, books, err = parseExport([]byte(testLibraryThingExport))
+	assert.NoError(t, err)
+	assert.Equal(t, LayoutLibraryThing, layout)
+	assert.Equal(t, []ImportedBook{
+		{
+			Line:    2,
+			Title:   "Dead Souls",
+			Author:  "Nikolai Gogol",
+			Year:    2004,
+			ISBN:    "9780140448078",
+			Shelves: []string{"Your library", "Russian"},
+			Action:  ImportCreate,
+		},
+		{
+			Line:    3,
+			Title:   "War and Peace",
+			Author:  "Leo Tolstoy",
+			ISBN:    "9781400079988",
+			Shelves: []string{"Wishlist"},
+			Action:  ImportCreate,
+		},
+	}, books)
+
+	for _, give := range []string{"", "Title,Author\nDune,Frank Herbert\n", "a,\"b\n"} {
+		_, _, err = parseExport([]byte(give))
+		assert.Error(t, err, give)
+	}
+}
+
+func TestFirstLastName(t *testing.T) {
+	assert.Equal(t, "Fyodor Dostoevsky", firstLastName("Dostoevsky, Fyodor"))
+	assert.Equal(t, "Leo Tolstoy", firstLastName("Leo Tolstoy"))
+	assert.Equal(t, "Homer", firstLastName("Homer"))
+	assert.Equal(t, "a, b, c", firstLastName("a, b, c"))
+}
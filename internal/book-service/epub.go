@@ -0,0 +1,274 @@
+package bookservice
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+const epubMimetype = "application/epub+zip"
+
+// maxEPUBMetaSize limits the uncompressed size of the mimetype, the container and the package document:
+// a small archive may expand to gigabytes.
+const maxEPUBMetaSize = 1 << 20
+
+// relatorRoles maps MARC relator codes used by EPUB creators to contributor roles.
+var relatorRoles = map[string]string{
+	"aut": bm.RoleAuthor,
+	"edt": bm.RoleEditor,
+	"trl": bm.RoleTranslator,
+	"ill": bm.RoleIllustrator,
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type opfPackage struct {
+	Metadata struct {
+		Titles       []string        `xml:"title"`
+		Creators     []opfCreator    `xml:"creator"`
+		Contributors []opfCreator    `xml:"contributor"`
+		Dates        []opfDate       `xml:"date"`
+		Descriptions []string        `xml:"description"`
+		Subjects     []string        `xml:"subject"`
+		Identifiers  []opfIdentifier `xml:"identifier"`
+		Metas        []opfMeta       `xml:"meta"`
+	} `xml:"metadata"`
+}
+
+// opfCreator is a creator or a contributor. EPUB 2 keeps the role in an attribute, EPUB 3 refines it with a meta.
+type opfCreator struct {
+	ID   string `xml:"id,attr"`
+	Role string `xml:"role,attr"`
+	Name string `xml:",chardata"`
+}
+
+type opfDate struct {
+	Event string `xml:"event,attr"`
+	Value string `xml:",chardata"`
+}
+
+type opfIdentifier struct {
+	Value string `xml:",chardata"`
+}
+
+type opfMeta struct {
+	Refines  string `xml:"refines,attr"`
+	Property string `xml:"property,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// ParseEPUB builds a book from OPF metadata of an EPUB: title, creators, date, description, subjects and identifiers.
+// The first subject becomes the genre of the book and all subjects become its tags.
+func ParseEPUB(data []byte) (bm.Book, error) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return bm.Book{}, bm.NewValidationError("incorrect epub: %w", err)
+	}
+
+	if !hasEPUBMimetype(r) {
+		return bm.Book{}, bm.NewValidationError("incorrect epub: mimetype is not %s", epubMimetype)
+	}
+
+	container := new(epubContainer)
+	if err = unmarshalZipFile(r, "META-INF/container.xml", container); err != nil {
+		return bm.Book{}, err
+	}
+
+	if len(container.Rootfiles) == 0 {
+		return bm.Book{}, bm.NewValidationError("incorrect epub: container has no rootfile")
+	}
+
+	pkg := new(opfPackage)
+	if err = unmarshalZipFile(r, container.Rootfiles[0].FullPath, pkg); err != nil {
+		return bm.Book{}, err
+	}
+
+	return newEPUBBook(pkg)
+}
+
+func newEPUBBook(pkg *opfPackage) (bm.Book, error) {
+	m := pkg.Metadata
+
+	var b bm.Book
+	for _, title := range m.Titles {
+		if b.Title = strings.TrimSpace(title); b.Title != "" {
+			break
+		}
+	}
+
+	if b.Title == "" {
+		return bm.Book{}, bm.NewValidationError("epub has no title")
+	}
+
+	roles := make(map[string]string)
+	for _, meta := range m.Metas {
+		if meta.Property == "role" && strings.HasPrefix(meta.Refines, "#") {
+			roles[strings.TrimPrefix(meta.Refines, "#")] = strings.TrimSpace(meta.Value)
+		}
+	}
+
+	seen := make(map[bm.Contributor]bool)
+	addContributors := func(creators []opfCreator, defaultRole string) {
+		for _, c := range creators {
+			code := c.Role
+			if code == "" {
+				code = roles[c.ID]
+			}
+
+			role, ok := relatorRoles[strings.ToLower(code)]
+			if code == "" {
+				role, ok = defaultRole, defaultRole != ""
+			}
+
+			contributor := bm.Contributor{Name: strings.TrimSpace(c.Name), Role: role}
+			if !ok || contributor.Name == "" || seen[contributor] {
+				continue
+			}
+
+			seen[contributor] = true
+			b.Contributors = append(b.Contributors, contributor)
+		}
+	}
+
+	// Creators without a role are authors, contributors without a role are skipped.
+	addContributors(m.Creators, bm.RoleAuthor)
+	addContributors(m.Contributors, "")
+
+	for _, d := range m.Dates {
+		if d.Event != "" && d.Event != "publication" {
+			continue
+		}
+
+		if b.PublishedDate = parseEPUBDate(d.Value); !b.PublishedDate.IsZero() {
+			break
+		}
+	}
+
+	if len(m.Descriptions) != 0 {
+		b.Description = strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(m.Descriptions[0], "")))
+	}
+
+	for _, subject := range m.Subjects {
+		subject = strings.TrimSpace(subject)
+		if subject == "" {
+			continue
+		}
+
+		if b.Genre == "" {
+			b.Genre = subject
+		}
+
+		// Subjects which can't be tags are still fine as a genre.
+		if tags, err := NormalizeTags([]string{subject}); err == nil {
+			b.Tags = append(b.Tags, tags...)
+		}
+	}
+
+	for _, id := range m.Identifiers {
+		value := strings.TrimSpace(id.Value)
+		if len(value) > len("urn:isbn:") && strings.EqualFold(value[:len("urn:isbn:")], "urn:isbn:") {
+			value = value[len("urn:isbn:"):]
+		}
+
+		if isbn, err := NormalizeISBN(value); err == nil {
+			b.ISBN = isbn
+
+			break
+		}
+	}
+
+	return b, nil
+}
+
+// parseEPUBDate parses dates in W3CDTF format, which allows omitting the month and the day.
+func parseEPUBDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.RFC3339, time.DateOnly, "2006-01", "2006"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC().Truncate(24 * time.Hour)
+		}
+	}
+
+	return time.Time{}
+}
+
+func unmarshalZipFile(r *zip.Reader, name string, v any) error {
+	data, err := readZipFile(r, name)
+	if err != nil {
+		return err
+	}
+
+	if err = xml.Unmarshal(data, v); err != nil {
+		return bm.NewValidationError("incorrect epub: parse %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func readZipFile(r *zip.Reader, name string) ([]byte, error) {
+	f, err := r.Open(name)
+	if err != nil {
+		return nil, bm.NewValidationError("incorrect epub: open %s: %w", name, err)
+	}
+
+	// The declared size is checked first, the reader is limited anyway since the header may lie.
+	var data []byte
+	info, err := f.Stat()
+	if err == nil && info.Size() > maxEPUBMetaSize {
+		err = fmt.Errorf("size %d exceeds %d bytes", info.Size(), maxEPUBMetaSize)
+	}
+
+	if err == nil {
+		data, err = io.ReadAll(io.LimitReader(f, maxEPUBMetaSize+1))
+	}
+
+	if err == nil && len(data) > maxEPUBMetaSize {
+		err = fmt.Errorf("size exceeds %d bytes", maxEPUBMetaSize)
+	}
+
+	if err = bm.HandleErrPair(f.Close(), err); err != nil {
+		return nil, bm.NewValidationError("incorrect epub: read %s: %w", name, err)
+	}
+
+	return data, nil
+}
+
+// isEPUB checks that data is a zip archive with the EPUB mimetype.
+func isEPUB(data []byte) bool {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return false
+	}
+
+	return hasEPUBMimetype(r)
+}
+
+func hasEPUBMimetype(r *zip.Reader) bool {
+	mimetype, err := readZipFile(r, "mimetype")
+
+	return err == nil && strings.TrimSpace(string(mimetype)) == epubMimetype
+}
+
+func epubFileName(title string) string {
+	return fmt.Sprintf("%s.epub", strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+
+		return r
+	}, title))
+}
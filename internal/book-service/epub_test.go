@@ -0,0 +1,98 @@
+package bookservice
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/internal/book-service/epubtest"
+)
+
+const testEPUB2OPF = `<?xml version="1.0"?>
+<package version="2.0" xmlns="http://www.idpf.org/2007/opf">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">
+    <dc:title>The Master and Margarita</dc:title>
+    <dc:creator opf:role="aut">Mikhail Bulgakov</dc:creator>
+    <dc:creator opf:role="trl">Michael Glenny</dc:creator>
+    <dc:contributor opf:role="bkp">calibre</dc:contributor>
+    <dc:date opf:event="modification">2020-01-01</dc:date>
+    <dc:date opf:event="publication">1967-11</dc:date>
+    <dc:description>&lt;p&gt;The devil visits &lt;b&gt;Moscow&lt;/b&gt; &amp;amp; stays.&lt;/p&gt;</dc:description>
+    <dc:subject>Fiction</dc:subject>
+    <dc:subject>Satire</dc:subject>
+    <dc:identifier opf:scheme="uuid">0c8e4b2a-1111-2222-3333-444455556666</dc:identifier>
+    <dc:identifier opf:scheme="ISBN">978-0-14-118014-4</dc:identifier>
+  </metadata>
+</package>`
+
+const testEPUB3OPF = `<?xml version="1.0"?>
+<package version="3.0" xmlns="http://www.idpf.org/2007/opf">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="uid">urn:isbn:0140449264</dc:identifier>
+    <dc:title>Dead Souls</dc:title>
+    <dc:creator id="creator01">Nikolai Gogol</dc:creator>
+    <meta refines="#creator01" property="role" scheme="marc:relators">aut</meta>
+    <dc:creator id="creator02">Robert Maguire</dc:creator>
+    <meta refines="#creator02" property="role" scheme="marc:relators">trl</meta>
+    <dc:date>1842-05-21T00:00:00Z</dc:date>
+  </metadata>
+</package>`
+
+func TestParseEPUB(t *testing.T) {
+	book, err := ParseEPUB(epubtest.New(t, testEPUB2OPF))
+	assert.NoError(t, err)
+	assert.Equal(t, bm.Book{
+		Title:         "The Master and Margarita",
+		PublishedDate: time.Date(1967, 11, 1, 0, 0, 0, 0, time.UTC),
+		Description:   "The devil visits Moscow & stays.",
+		Genre:         "Fiction",
+		ISBN:          "9780141180144",
+		Contributors: []bm.Contributor{
+			{Name: "Mikhail Bulgakov", Role: bm.RoleAuthor},
+			{Name: "Michael Glenny", Role: bm.RoleTranslator},
+		},
+		Tags: []string{"fiction", "satire"},
+	}, book)
+
+	book, err = ParseEPUB(epubtest.New(t, testEPUB3OPF))
+	assert.NoError(t, err)
+	assert.Equal(t, bm.Book{
+		Title:         "Dead Souls",
+		PublishedDate: time.Date(1842, 5, 21, 0, 0, 0, 0, time.UTC),
+		ISBN:          "9780140449266",
+		Contributors: []bm.Contributor{
+			{Name: "Nikolai Gogol", Role: bm.RoleAuthor},
+			{Name: "Robert Maguire", Role: bm.RoleTranslator},
+		},
+	}, book)
+
+	invalid := [][]byte{
+		[]byte("not a zip"),
+		epubtest.New(t, `<package><metadata><dc:creator>Nobody</dc:creator></metadata></package>`),
+		epubtest.New(t, `<package><metadata>`),
+		// The package document expands beyond the limit.
+		epubtest.New(t, testEPUB3OPF+strings.Repeat(" ", maxEPUBMetaSize)),
+	}
+	for _, data := range invalid {
+		_, err := ParseEPUB(data)
+		assert.Error(t, err)
+	}
+}
+
+func TestFileFormat(t *testing.T) {
+	format, err := fileFormat(epubtest.New(t, testEPUB3OPF))
+	assert.NoError(t, err)
+	assert.Equal(t, bm.FormatEPUB, format)
+
+	format, err = fileFormat([]byte("%PDF-1.7\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, bm.FormatPDF, format)
+
+	for _, data := range [][]byte{nil, []byte("plain text"), make([]byte, MaxFileSize+1)} {
+		_, err := fileFormat(data)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,38 @@
+// Package epubtest builds EPUB files for tests of imports of books.
+package epubtest
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const container = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`
+
+// New builds an EPUB file with the package document and without content.
+func New(t testing.TB, opf string) []byte {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	for _, f := range []struct{ name, content string }{
+		{"mimetype", "application/epub+zip"},
+		{"META-INF/container.xml", container},
+		{"OEBPS/content.opf", opf},
+	} {
+		fw, err := w.Create(f.name)
+		require.NoError(t, err)
+
+		_, err = fw.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
@@ -0,0 +1,129 @@
+package bookservice
+
+import (
+	"context"
+	"fmt"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/internal/citation"
+	"github.com/Tsapen/bm/internal/marc"
+)
+
+// Formats of exported books.
+const (
+	ExportMARC21  = marc.FormatMARC21
+	ExportMARCXML = marc.FormatMARCXML
+	ExportBibTeX  = citation.FormatBibTeX
+	ExportRIS     = citation.FormatRIS
+	ExportCSLJSON = citation.FormatCSLJSON
+)
+
+// exportFormat describes how books are encoded in a format.
+type exportFormat struct {
+	contentType string
+	extension   string
+	encode      func(books []bm.Book) ([]byte, error)
+}
+
+var exportFormats = map[string]exportFormat{
+	ExportMARC21: {
+		contentType: "application/marc",
+		extension:   ".mrc",
+		encode:      marcEncoder(marc.EncodeBinary),
+	},
+	ExportMARCXML: {
+		contentType: "application/marcxml+xml",
+		extension:   ".xml",
+		encode:      marcEncoder(marc.EncodeXML),
+	},
+	ExportBibTeX: {
+		contentType: "application/x-bibtex; charset=utf-8",
+		extension:   ".bib",
+		encode:      citation.BibTeX,
+	},
+	ExportRIS: {
+		contentType: "application/x-research-info-systems; charset=utf-8",
+		extension:   ".ris",
+		encode:      citation.RIS,
+	},
+	ExportCSLJSON: {
+		contentType: "application/vnd.citationstyles.csl+json",
+		extension:   ".json",
+		encode:      citation.CSLJSON,
+	},
+}
+
+// Export is an encoded file of books.
+type Export struct {
+	ContentType string
+	FileName    string
+	Data        []byte
+}
+
+// ExportBook encodes a book in the format.
+func (s *Service) ExportBook(ctx context.Context, bookID int64, format string) (*Export, error) {
+	if bookID <= 0 {
+		return nil, bm.NewValidationError("incorrect book_id")
+	}
+
+	f, ok := exportFormats[format]
+	if !ok {
+		return nil, bm.NewValidationError("incorrect format")
+	}
+
+	book, err := s.storage.Book(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("get book: %w", err)
+	}
+
+	return f.export(fmt.Sprintf("book-%d", bookID), []bm.Book{*book})
+}
+
+// ExportCollection encodes all books of a collection in the format.
+func (s *Service) ExportCollection(ctx context.Context, cID int64, format string) (*Export, error) {
+	if cID <= 0 {
+		return nil, bm.NewValidationError("incorrect collection_id")
+	}
+
+	f, ok := exportFormats[format]
+	if !ok {
+		return nil, bm.NewValidationError("incorrect format")
+	}
+
+	// Empty collections are exported as well, so the collection is checked to exist.
+	if _, err := s.storage.Collection(ctx, cID); err != nil {
+		return nil, fmt.Errorf("get collection: %w", err)
+	}
+
+	var books []bm.Book
+	err := s.EachBook(ctx, bm.BookFilter{CollectionID: cID, OrderBy: "id"}, func(b bm.Book) error {
+		books = append(books, b)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get books: %w", err)
+	}
+
+	return f.export(fmt.Sprintf("collection-%d", cID), books)
+}
+
+func (f exportFormat) export(name string, books []bm.Book) (*Export, error) {
+	data, err := f.encode(books)
+	if err != nil {
+		return nil, fmt.Errorf("encode books: %w", err)
+	}
+
+	return &Export{ContentType: f.contentType, FileName: name + f.extension, Data: data}, nil
+}
+
+func marcEncoder(encode func([]marc.Record) ([]byte, error)) func([]bm.Book) ([]byte, error) {
+	return func(books []bm.Book) ([]byte, error) {
+		records := make([]marc.Record, 0, len(books))
+		for _, b := range books {
+			records = append(records, marc.FromBook(b))
+		}
+
+		return encode(records)
+	}
+}
@@ -0,0 +1,228 @@
+package bookservice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+const (
+	// MaxFileSize is the maximum size of a digital file of a book in bytes.
+	MaxFileSize = 50 << 20
+
+	maxFileNameLen = 255
+)
+
+var fileContentTypes = map[string]string{
+	bm.FormatEPUB: epubMimetype,
+	bm.FormatPDF:  "application/pdf",
+}
+
+// FileContentType returns the MIME type of a file format.
+func FileContentType(format string) string {
+	return fileContentTypes[format]
+}
+
+// BookFile retrieves a digital file of a book with its content.
+func (s *Service) BookFile(ctx context.Context, bookID, id int64) (*bm.BookFile, []byte, error) {
+	if bookID <= 0 {
+		return nil, nil, bm.NewValidationError("incorrect book_id")
+	}
+
+	if id <= 0 {
+		return nil, nil, bm.NewValidationError("incorrect id")
+	}
+
+	file, err := s.storage.BookFile(ctx, bookID, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get book file: %w", err)
+	}
+
+	r, err := s.files.Get(ctx, fileKey(file.Blob))
+	if err != nil {
+		return nil, nil, fmt.Errorf("get file: %w", err)
+	}
+
+	data, err := io.ReadAll(r)
+	if err = bm.HandleErrPair(r.Close(), err); err != nil {
+		return nil, nil, bm.NewInternalError("read file: %w", err)
+	}
+
+	return file, data, nil
+}
+
+// BookFiles retrieves digital files of a book.
+func (s *Service) BookFiles(ctx context.Context, bookID int64) ([]bm.BookFile, error) {
+	if bookID <= 0 {
+		return nil, bm.NewValidationError("incorrect book_id")
+	}
+
+	if _, err := s.storage.Book(ctx, bookID); err != nil {
+		return nil, fmt.Errorf("get book: %w", err)
+	}
+
+	files, err := s.storage.BookFiles(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("get book files: %w", err)
+	}
+
+	return files, nil
+}
+
+// AddBookFile attaches an EPUB or PDF file to a book.
+func (s *Service) AddBookFile(ctx context.Context, bookID int64, name string, data []byte) (int64, error) {
+	if bookID <= 0 {
+		return 0, bm.NewValidationError("incorrect book_id")
+	}
+
+	name = path.Base(strings.ReplaceAll(strings.TrimSpace(name), `\`, "/"))
+	if name == "" || name == "." || name == "/" {
+		return 0, bm.NewValidationError("file name is empty")
+	}
+
+	if utf8.RuneCountInString(name) > maxFileNameLen {
+		return 0, bm.NewValidationError("file name is longer than %d characters", maxFileNameLen)
+	}
+
+	format, err := fileFormat(data)
+	if err != nil {
+		return 0, fmt.Errorf("check file: %w", err)
+	}
+
+	blob := uuid.NewString()
+	if err = s.files.Put(ctx, fileKey(blob), bytes.NewReader(data)); err != nil {
+		return 0, fmt.Errorf("put file: %w", err)
+	}
+
+	id, err := s.storage.CreateBookFile(ctx, bm.BookFile{
+		BookID: bookID,
+		Name:   name,
+		Format: format,
+		Size:   int64(len(data)),
+		Blob:   blob,
+	})
+	if err != nil {
+		return 0, bm.HandleErrPair(s.deleteFiles(ctx, blob), fmt.Errorf("create book file: %w", err))
+	}
+
+	return id, nil
+}
+
+// DeleteBookFile detaches a digital file from a book and deletes it.
+func (s *Service) DeleteBookFile(ctx context.Context, bookID, id int64) error {
+	if bookID <= 0 {
+		return bm.NewValidationError("incorrect book_id")
+	}
+
+	if id <= 0 {
+		return bm.NewValidationError("incorrect id")
+	}
+
+	blob, err := s.storage.DeleteBookFile(ctx, bookID, id)
+	if err != nil {
+		return fmt.Errorf("delete book file: %w", err)
+	}
+
+	if err = s.deleteFiles(ctx, blob); err != nil {
+		log.Error().Err(err).Str("file", blob).Msg("delete file of book")
+	}
+
+	return nil
+}
+
+// ImportEPUB creates a book from metadata of an EPUB and attaches the EPUB to it.
+// The genre replaces the one taken from subjects of the EPUB if it is set. A subject which isn't a known genre
+// is replaced with the default genre of imports.
+// Books which already exist are reported with a conflict and aren't created again.
+func (s *Service) ImportEPUB(ctx context.Context, name, genre string, data []byte) (bookID, fileID int64, err error) {
+	if len(data) > MaxFileSize {
+		return 0, 0, bm.NewValidationError("file is larger than %d bytes", MaxFileSize)
+	}
+
+	book, err := ParseEPUB(data)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse epub: %w", err)
+	}
+
+	if genre != "" {
+		book.Genre = genre
+	} else if err = s.resolveImportGenre(ctx, &book, make(map[string]bool)); err != nil {
+		return 0, 0, fmt.Errorf("resolve genre: %w", err)
+	}
+
+	if name == "" {
+		name = epubFileName(book.Title)
+	}
+
+	bookID, err = s.CreateBook(ctx, book)
+	if errors.As(err, &bm.ConflictError{}) {
+		return 0, 0, bm.NewConflictError("book %q already exists: %w", book.Title, err)
+	}
+
+	if err != nil {
+		return 0, 0, fmt.Errorf("create book: %w", err)
+	}
+
+	fileID, err = s.AddBookFile(ctx, bookID, name, data)
+	if err != nil {
+		if _, deleteErr := s.storage.DeleteBooks(ctx, []int64{bookID}, true); deleteErr != nil {
+			err = bm.HandleErrPair(fmt.Errorf("delete imported book: %w", deleteErr), err)
+		} else {
+			s.publish(ctx, bm.EventBookDeleted, bookEvent{ID: bookID})
+		}
+
+		return 0, 0, fmt.Errorf("add book file: %w", err)
+	}
+
+	return bookID, fileID, nil
+}
+
+// deleteFiles deletes digital files of books.
+func (s *Service) deleteFiles(ctx context.Context, blobs ...string) error {
+	keys := make([]string, 0, len(blobs))
+	for _, b := range blobs {
+		keys = append(keys, fileKey(b))
+	}
+
+	if err := s.files.Delete(ctx, keys...); err != nil {
+		return fmt.Errorf("delete blobs: %w", err)
+	}
+
+	return nil
+}
+
+func fileKey(blob string) string {
+	return "files/" + blob
+}
+
+// fileFormat checks size of a file and detects its format by content.
+func fileFormat(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", bm.NewValidationError("file is empty")
+	}
+
+	if len(data) > MaxFileSize {
+		return "", bm.NewValidationError("file is larger than %d bytes", MaxFileSize)
+	}
+
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return bm.FormatPDF, nil
+
+	case isEPUB(data):
+		return bm.FormatEPUB, nil
+
+	default:
+		return "", bm.NewValidationError("file must be an EPUB or PDF document")
+	}
+}
@@ -0,0 +1,112 @@
+package bookservice
+
+import (
+	"context"
+	"fmt"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// Genre retrieves a genre by its id.
+func (s *Service) Genre(ctx context.Context, id int64) (*bm.Genre, error) {
+	if id < 0 {
+		return nil, bm.NewValidationError("incorrect id")
+	}
+
+	genre, err := s.storage.Genre(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get genre: %w", err)
+	}
+
+	return genre, nil
+}
+
+// Genres retrieves a list of genres based on the provided filter criteria.
+func (s *Service) Genres(ctx context.Context, f bm.GenresFilter) ([]bm.Genre, error) {
+	switch f.OrderBy {
+	case "id", "name", "parent_id":
+	case "":
+		f.OrderBy = "id"
+	default:
+		return nil, bm.NewValidationError("incorrect order_by")
+	}
+
+	if f.ParentID < 0 {
+		return nil, bm.NewValidationError("incorrect parent_id")
+	}
+
+	if f.Page < 0 {
+		return nil, bm.NewValidationError("incorrect page")
+	}
+
+	if f.Page == 0 {
+		f.Page = 1
+	}
+
+	if f.PageSize < 0 {
+		return nil, bm.NewValidationError("page_size is negative")
+	}
+
+	if f.PageSize == 0 || f.PageSize > maxPageSize {
+		f.PageSize = maxPageSize
+	}
+
+	genres, err := s.storage.Genres(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("get genres: %w", err)
+	}
+
+	return genres, nil
+}
+
+// CreateGenre creates a new genre, optionally as a subgenre of an existing one.
+func (s *Service) CreateGenre(ctx context.Context, g bm.Genre) (int64, error) {
+	if g.Name == "" {
+		return 0, bm.NewValidationError("name is empty")
+	}
+
+	if g.ParentID < 0 {
+		return 0, bm.NewValidationError("incorrect parent_id")
+	}
+
+	id, err := s.storage.CreateGenre(ctx, g)
+	if err != nil {
+		return 0, fmt.Errorf("create genre: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateGenre renames an existing genre or moves it under another parent.
+func (s *Service) UpdateGenre(ctx context.Context, g bm.Genre) error {
+	if g.ID <= 0 {
+		return bm.NewValidationError("incorrect id")
+	}
+
+	if g.Name == "" {
+		return bm.NewValidationError("name is empty")
+	}
+
+	if g.ParentID < 0 || g.ParentID == g.ID {
+		return bm.NewValidationError("incorrect parent_id")
+	}
+
+	if err := s.storage.UpdateGenre(ctx, g); err != nil {
+		return fmt.Errorf("update genre: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteGenre deletes a genre without books and subgenres.
+func (s *Service) DeleteGenre(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return bm.NewValidationError("incorrect id")
+	}
+
+	if err := s.storage.DeleteGenre(ctx, id); err != nil {
+		return fmt.Errorf("delete genre: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,334 @@
+package bookservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// MaxImportSize is the maximum size of an export in bytes.
+const MaxImportSize = 10 << 20
+
+// defaultImportGenre is the genre of imported books if neither the caller nor the export set a known one.
+// It is created on the first import which needs it.
+const defaultImportGenre = "Unsorted"
+
+// Actions an import takes for rows of an export.
+const (
+	ImportCreate    = "create"
+	ImportExists    = "exists"
+	ImportDuplicate = "duplicate"
+	ImportInvalid   = "invalid"
+	ImportFailed    = "failed"
+)
+
+// ImportedBook is a row of an export and the action the import takes for it. Line is the line of a CSV row
+// or the number of a MARC record. BookID is the existing book for rows which already exist and the created book
+// after a real import. Unmapped lists fields of the row which have no place in a book.
+type ImportedBook struct {
+	Line     int
+	Title    string
+	Author   string
+	Year     int
+	ISBN     string
+	Shelves  []string
+	Unmapped []string
+	Action   string
+	BookID   int64
+	Error    string
+}
+
+// ImportReport describes changes made by an import, or changes it would make for a dry run.
+type ImportReport struct {
+	Layout         string
+	DryRun         bool
+	Books          []ImportedBook
+	NewCollections []string
+}
+
+// importBooks creates books of rows marked for creation and adds them to collections of their shelves.
+// Books are parsed books of the rows. The genre replaces genres of the books if it is set, it must be a known genre.
+//
+// The import isn't atomic: collections and books are created one by one, and an internal error stops the import
+// with the error instead of the report. Books created before the error are kept. They are matched as existing
+// books by the next import of the same export, so a failed import is retried without duplicates.
+func (s *Service) importBooks(ctx context.Context, layout string, rows []ImportedBook, books []bm.Book, genre string, dryRun bool) (*ImportReport, error) {
+	known := make(map[string]bool)
+	if genre != "" {
+		ok, err := s.genreExists(ctx, genre, known)
+		if err != nil {
+			return nil, fmt.Errorf("check genre: %w", err)
+		}
+
+		if !ok {
+			return nil, bm.NewValidationError("unknown genre %q", genre)
+		}
+	}
+
+	if err := s.dedupeImport(ctx, rows, books); err != nil {
+		return nil, fmt.Errorf("dedupe books: %w", err)
+	}
+
+	collections, err := s.collectionIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get collections: %w", err)
+	}
+
+	report := &ImportReport{Layout: layout, DryRun: dryRun, Books: rows}
+	for _, r := range rows {
+		if r.Action != ImportCreate {
+			continue
+		}
+
+		for _, shelf := range r.Shelves {
+			if _, ok := collections[shelf]; !ok {
+				collections[shelf] = 0
+				report.NewCollections = append(report.NewCollections, shelf)
+			}
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	// Collections are created first, so a failure doesn't leave imported books out of their shelves.
+	for _, name := range report.NewCollections {
+		if collections[name], err = s.CreateCollection(ctx, bm.Collection{Name: name}); err != nil {
+			return nil, fmt.Errorf("create collection %q: %w", name, err)
+		}
+	}
+
+	var shelved []int64
+	shelves := make(map[int64][]int64)
+	for i := range rows {
+		r := &rows[i]
+		if r.Action != ImportCreate {
+			continue
+		}
+
+		book := books[i]
+		if genre != "" {
+			book.Genre, book.GenreID = genre, 0
+		} else if err = s.resolveImportGenre(ctx, &book, known); err != nil {
+			return nil, fmt.Errorf("resolve genre of %q: %w", r.Title, err)
+		}
+
+		r.BookID, err = s.CreateBook(ctx, book)
+		if errors.As(err, &bm.InternalError{}) {
+			return nil, fmt.Errorf("create book %q: %w", r.Title, err)
+		}
+
+		if err != nil {
+			r.Action, r.Error = ImportFailed, err.Error()
+
+			continue
+		}
+
+		for _, shelf := range r.Shelves {
+			cID := collections[shelf]
+			if len(shelves[cID]) == 0 {
+				shelved = append(shelved, cID)
+			}
+
+			shelves[cID] = append(shelves[cID], r.BookID)
+		}
+	}
+
+	for _, cID := range shelved {
+		if err = s.CreateBooksCollection(ctx, cID, shelves[cID]); err != nil {
+			return nil, fmt.Errorf("add books to collection %d: %w", cID, err)
+		}
+	}
+
+	return report, nil
+}
+
+// resolveImportGenre replaces the genre of an imported book with the default one if the export has no genre or
+// its genre matches neither a genre nor an alias. Unknown genres are kept as tags of the book, so imports don't
+// create genres from free text. Known caches lookups by lowercase names.
+func (s *Service) resolveImportGenre(ctx context.Context, book *bm.Book, known map[string]bool) error {
+	if book.GenreID != 0 {
+		return nil
+	}
+
+	if book.Genre != "" {
+		ok, err := s.genreExists(ctx, book.Genre, known)
+		if err != nil || ok {
+			return err
+		}
+
+		// Genres which can't be tags are dropped.
+		if tags, err := NormalizeTags([]string{book.Genre}); err == nil {
+			book.Tags = append(book.Tags, tags...)
+		}
+	}
+
+	ok, err := s.genreExists(ctx, defaultImportGenre, known)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		// A concurrent import may create the genre first.
+		_, err = s.storage.CreateGenre(ctx, bm.Genre{Name: defaultImportGenre})
+		if err != nil && !errors.As(err, &bm.ConflictError{}) {
+			return fmt.Errorf("create genre %q: %w", defaultImportGenre, err)
+		}
+
+		known[strings.ToLower(defaultImportGenre)] = true
+	}
+
+	book.Genre = defaultImportGenre
+
+	return nil
+}
+
+// genreExists checks if the name matches a genre or an alias.
+func (s *Service) genreExists(ctx context.Context, name string, known map[string]bool) (bool, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if ok, found := known[key]; found {
+		return ok, nil
+	}
+
+	_, err := s.storage.GenreByName(ctx, name)
+	if err != nil && !errors.As(err, &bm.NotFoundError{}) {
+		return false, fmt.Errorf("get genre: %w", err)
+	}
+
+	known[key] = err == nil
+
+	return err == nil, nil
+}
+
+// dedupeImport marks rows which repeat earlier rows of the export and rows of books which already exist.
+// Rows and existing books are matched by ISBN or by title and authors normalized by importKey.
+func (s *Service) dedupeImport(ctx context.Context, rows []ImportedBook, books []bm.Book) error {
+	seen := make(map[string]bool)
+	for i := range rows {
+		r := &rows[i]
+		if r.Action != ImportCreate {
+			continue
+		}
+
+		key := importKey(r.Title, bookAuthors(books[i]))
+		keys := []string{"title:" + key}
+		if r.ISBN != "" {
+			keys = append(keys, "isbn:"+r.ISBN)
+		}
+
+		for _, k := range keys {
+			if seen[k] {
+				r.Action = ImportDuplicate
+			}
+
+			seen[k] = true
+		}
+
+		if r.Action == ImportDuplicate {
+			continue
+		}
+
+		id, err := s.existingImport(ctx, r, key)
+		if err != nil {
+			return err
+		}
+
+		if id != 0 {
+			r.Action, r.BookID = ImportExists, id
+		}
+	}
+
+	return nil
+}
+
+// existingImport finds an existing book of the row by ISBN or by the key of its title and authors.
+// Books with the title are looked up case-insensitively and compared by their keys.
+func (s *Service) existingImport(ctx context.Context, r *ImportedBook, key string) (int64, error) {
+	if r.ISBN != "" {
+		existing, err := s.storage.Books(ctx, bm.BookFilter{ISBN: r.ISBN, OrderBy: "id", Page: 1, PageSize: 1})
+		if err != nil {
+			return 0, fmt.Errorf("get books: %w", err)
+		}
+
+		if len(existing) != 0 {
+			return existing[0].ID, nil
+		}
+	}
+
+	f := bm.BookFilter{Query: strings.TrimSpace(r.Title), OrderBy: "id", PageSize: maxPageSize}
+	for f.Page = 1; ; f.Page++ {
+		candidates, err := s.storage.Books(ctx, f)
+		if err != nil {
+			return 0, fmt.Errorf("get books: %w", err)
+		}
+
+		for _, b := range candidates {
+			if importKey(b.Title, bookAuthors(b)) == key {
+				return b.ID, nil
+			}
+		}
+
+		if int64(len(candidates)) < f.PageSize {
+			return 0, nil
+		}
+	}
+}
+
+// importKey normalizes the title and the authors of a book for dedupe of imports: case, repeated spaces
+// and the order of co-authors don't matter.
+func importKey(title string, authors []string) string {
+	names := make([]string, 0, len(authors))
+	for _, a := range authors {
+		if name := normalizeImportText(a); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	return normalizeImportText(title) + "\x00" + strings.Join(names, "\x00")
+}
+
+func normalizeImportText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// bookAuthors returns names of contributors with author role, or the author line of books without contributors.
+func bookAuthors(book bm.Book) []string {
+	var authors []string
+	for _, c := range book.Contributors {
+		if c.Role == bm.RoleAuthor {
+			authors = append(authors, c.Name)
+		}
+	}
+
+	if len(authors) == 0 && book.Author != "" {
+		authors = append(authors, book.Author)
+	}
+
+	return authors
+}
+
+// collectionIDs maps names of all collections of the caller to their ids.
+func (s *Service) collectionIDs(ctx context.Context) (map[string]int64, error) {
+	ids := make(map[string]int64)
+	for page := int64(1); ; page++ {
+		collections, err := s.storage.Collections(ctx, bm.CollectionsFilter{OrderBy: "id", Page: page, PageSize: maxPageSize})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range collections {
+			ids[c.Name] = c.ID
+		}
+
+		if len(collections) < maxPageSize {
+			return ids, nil
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package bookservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+func TestImportKey(t *testing.T) {
+	coAuthored := bm.Book{
+		Title:  "Good Omens",
+		Author: "Terry Pratchett, Neil Gaiman",
+		Contributors: []bm.Contributor{
+			{Name: "Terry Pratchett", Role: bm.RoleAuthor},
+			{Name: "Neil Gaiman", Role: bm.RoleAuthor},
+			{Name: "Somebody Else", Role: bm.RoleEditor},
+		},
+	}
+
+	key := importKey(coAuthored.Title, bookAuthors(coAuthored))
+
+	// Case, spaces and the order of co-authors don't matter, editors aren't authors.
+	assert.Equal(t, key, importKey("good  omens ", []string{"NEIL GAIMAN", " Terry Pratchett"}))
+	assert.NotEqual(t, key, importKey("Good Omens", []string{"Terry Pratchett"}))
+	assert.NotEqual(t, key, importKey("Good Omens", []string{"Terry Pratchett", "Neil Gaiman", "Somebody Else"}))
+
+	// Books without contributors are matched by the author line.
+	assert.Equal(t, importKey("Solaris", []string{"stanislaw lem"}), importKey("Solaris", bookAuthors(bm.Book{Author: "Stanislaw Lem"})))
+}
@@ -0,0 +1,112 @@
+package bookservice
+
+import (
+	"strings"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+const (
+	isbn10Len = 10
+	isbn13Len = 13
+
+	isbn10Prefix = "978"
+)
+
+// NormalizeISBN validates ISBN-10 or ISBN-13 and converts it to ISBN-13 without separators.
+func NormalizeISBN(isbn string) (string, error) {
+	isbn = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+
+	switch len(isbn) {
+	case isbn10Len:
+		if !validISBN10(isbn) {
+			return "", bm.NewValidationError("incorrect isbn-10 %s", isbn)
+		}
+
+		isbn13 := isbn10Prefix + isbn[:9]
+
+		return isbn13 + string(isbn13CheckDigit(isbn13)), nil
+
+	case isbn13Len:
+		if !validISBN13(isbn) {
+			return "", bm.NewValidationError("incorrect isbn-13 %s", isbn)
+		}
+
+		return isbn, nil
+
+	default:
+		return "", bm.NewValidationError("isbn %s must contain 10 or 13 characters", isbn)
+	}
+}
+
+// ISBN10 converts normalized ISBN-13 to ISBN-10.
+// It returns an empty string if the ISBN-13 has no ISBN-10 form.
+func ISBN10(isbn13 string) string {
+	if len(isbn13) != isbn13Len || !strings.HasPrefix(isbn13, isbn10Prefix) {
+		return ""
+	}
+
+	isbn10 := isbn13[3:12]
+
+	return isbn10 + string(isbn10CheckDigit(isbn10))
+}
+
+func validISBN10(isbn string) bool {
+	if !digits(isbn[:9]) {
+		return false
+	}
+
+	return isbn[9] == isbn10CheckDigit(isbn[:9])
+}
+
+func validISBN13(isbn string) bool {
+	if !digits(isbn) {
+		return false
+	}
+
+	if !strings.HasPrefix(isbn, "978") && !strings.HasPrefix(isbn, "979") {
+		return false
+	}
+
+	return isbn[12] == isbn13CheckDigit(isbn[:12])
+}
+
+// isbn10CheckDigit calculates check digit of ISBN-10 by its first 9 digits.
+func isbn10CheckDigit(isbn string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += (10 - i) * int(isbn[i]-'0')
+	}
+
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+
+	return byte('0' + check)
+}
+
+// isbn13CheckDigit calculates check digit of ISBN-13 by its first 12 digits.
+func isbn13CheckDigit(isbn string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+
+		sum += weight * int(isbn[i]-'0')
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
+
+func digits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
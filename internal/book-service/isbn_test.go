@@ -0,0 +1,45 @@
+package bookservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		give    string
+		want    string
+		wantErr bool
+	}{
+		{give: "0-306-40615-2", want: "9780306406157"},
+		{give: "080442957X", want: "9780804429573"},
+		{give: "080442957x", want: "9780804429573"},
+		{give: "978-0-306-40615-7", want: "9780306406157"},
+		{give: "979 10 90636 07 1", want: "9791090636071"},
+		{give: "0-306-40615-3", wantErr: true},
+		{give: "978-0-306-40615-8", wantErr: true},
+		{give: "977-0-306-40615-1", wantErr: true},
+		{give: "03064061X2", wantErr: true},
+		{give: "12345", wantErr: true},
+		{give: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeISBN(tt.give)
+		if tt.wantErr {
+			assert.Error(t, err, tt.give)
+
+			continue
+		}
+
+		assert.NoError(t, err, tt.give)
+		assert.Equal(t, tt.want, got, tt.give)
+	}
+}
+
+func TestISBN10(t *testing.T) {
+	assert.Equal(t, "0306406152", ISBN10("9780306406157"))
+	assert.Equal(t, "080442957X", ISBN10("9780804429573"))
+	assert.Equal(t, "", ISBN10("9791090636071"))
+	assert.Equal(t, "", ISBN10(""))
+}
@@ -0,0 +1,119 @@
+package bookservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+const maxBorrowerLen = 100
+
+// today returns the current date in UTC.
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// LendBook lends a book to a borrower. The book is lent today if the lent date is zero.
+func (s *Service) LendBook(ctx context.Context, l bm.Loan) (int64, error) {
+	if l.BookID <= 0 {
+		return 0, bm.NewValidationError("incorrect book_id")
+	}
+
+	l.Borrower = strings.TrimSpace(l.Borrower)
+	if l.Borrower == "" {
+		return 0, bm.NewValidationError("borrower is empty")
+	}
+
+	if utf8.RuneCountInString(l.Borrower) > maxBorrowerLen {
+		return 0, bm.NewValidationError("borrower is longer than %d characters", maxBorrowerLen)
+	}
+
+	if l.LentAt.IsZero() {
+		l.LentAt = today()
+	}
+
+	if l.DueAt.IsZero() {
+		return 0, bm.NewValidationError("due date is empty")
+	}
+
+	l.LentAt = l.LentAt.Truncate(24 * time.Hour)
+	l.DueAt = l.DueAt.Truncate(24 * time.Hour)
+	if l.DueAt.Before(l.LentAt) {
+		return 0, bm.NewValidationError("due date is before lent date")
+	}
+
+	id, err := s.storage.LendBook(ctx, l)
+	if err != nil {
+		return 0, fmt.Errorf("lend book: %w", err)
+	}
+
+	return id, nil
+}
+
+// ReturnBook closes the active loan of a book. The book is returned today if the date is zero.
+func (s *Service) ReturnBook(ctx context.Context, bookID int64, returnedAt time.Time) error {
+	if bookID <= 0 {
+		return bm.NewValidationError("incorrect book_id")
+	}
+
+	if returnedAt.IsZero() {
+		returnedAt = today()
+	}
+
+	if err := s.storage.ReturnBook(ctx, bookID, returnedAt.Truncate(24*time.Hour)); err != nil {
+		return fmt.Errorf("return book: %w", err)
+	}
+
+	return nil
+}
+
+// Loans retrieves a list of active loans.
+func (s *Service) Loans(ctx context.Context, f bm.LoansFilter) ([]bm.Loan, error) {
+	f.DueBefore = time.Time{}
+
+	return s.loans(ctx, f)
+}
+
+// OverdueLoans retrieves a list of active loans due before today.
+func (s *Service) OverdueLoans(ctx context.Context, f bm.LoansFilter) ([]bm.Loan, error) {
+	f.DueBefore = today()
+
+	return s.loans(ctx, f)
+}
+
+func (s *Service) loans(ctx context.Context, f bm.LoansFilter) ([]bm.Loan, error) {
+	switch f.OrderBy {
+	case "id", "book_id", "borrower", "lent_at", "due_at":
+	case "":
+		f.OrderBy = "due_at"
+	default:
+		return nil, bm.NewValidationError("incorrect order_by")
+	}
+
+	if f.Page < 0 {
+		return nil, bm.NewValidationError("incorrect page")
+	}
+
+	if f.Page == 0 {
+		f.Page = 1
+	}
+
+	if f.PageSize < 0 {
+		return nil, bm.NewValidationError("page_size is negative")
+	}
+
+	if f.PageSize == 0 || f.PageSize > maxPageSize {
+		f.PageSize = maxPageSize
+	}
+
+	loans, err := s.storage.Loans(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("get loans: %w", err)
+	}
+
+	return loans, nil
+}
@@ -0,0 +1,86 @@
+package bookservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+	"github.com/Tsapen/bm/internal/marc"
+)
+
+// ImportMARC imports books from MARC 21 records in transmission format or in MARCXML.
+// Books which already exist by ISBN or by title and author are skipped, so records can be imported again.
+// Fields of records which have no place in a book are reported for every record. A dry run reports changes without making them.
+func (s *Service) ImportMARC(ctx context.Context, data []byte, genre string, dryRun bool) (*ImportReport, error) {
+	if len(data) > MaxImportSize {
+		return nil, bm.NewValidationError("export is larger than %d bytes", MaxImportSize)
+	}
+
+	format, records, err := marc.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode marc: %w", err)
+	}
+
+	rows := make([]ImportedBook, 0, len(records))
+	books := make([]bm.Book, 0, len(records))
+	for i, r := range records {
+		book, unmapped := marc.ToBook(r)
+		rows = append(rows, marcRow(i+1, &book, unmapped))
+		books = append(books, book)
+	}
+
+	return s.importBooks(ctx, format, rows, books, genre, dryRun)
+}
+
+// marcRow describes a book of a record for an import report. ISBN of the book is normalized.
+func marcRow(n int, book *bm.Book, unmapped []string) ImportedBook {
+	authors := make([]string, 0, len(book.Contributors))
+	for _, c := range book.Contributors {
+		if c.Role == bm.RoleAuthor {
+			authors = append(authors, c.Name)
+		}
+	}
+
+	r := ImportedBook{
+		Line:     n,
+		Title:    book.Title,
+		Author:   strings.Join(authors, ", "),
+		Year:     book.PublishedDate.Year(),
+		Unmapped: unmapped,
+		Action:   ImportCreate,
+	}
+
+	if book.PublishedDate.IsZero() {
+		r.Year = 0
+	}
+
+	switch {
+	case r.Title == "":
+		r.Action, r.Error = ImportInvalid, "title is empty"
+
+	case len(book.Contributors) == 0:
+		r.Action, r.Error = ImportInvalid, "author is empty"
+	}
+
+	if _, err := NormalizeTags(book.Tags); err != nil && r.Action == ImportCreate {
+		r.Action, r.Error = ImportInvalid, err.Error()
+	}
+
+	if r.ISBN = book.ISBN; r.ISBN == "" {
+		return r
+	}
+
+	isbn, err := NormalizeISBN(book.ISBN)
+	if err != nil {
+		if r.Action == ImportCreate {
+			r.Action, r.Error = ImportInvalid, err.Error()
+		}
+
+		return r
+	}
+
+	book.ISBN, r.ISBN = isbn, isbn
+
+	return r
+}
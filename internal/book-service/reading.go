@@ -0,0 +1,161 @@
+package bookservice
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// readingTransitions lists statuses a book can be moved to from each status.
+// The empty status belongs to books the reader hasn't set a status for yet.
+var readingTransitions = map[string][]string{
+	"":                  {bm.StatusWantToRead, bm.StatusReading, bm.StatusFinished},
+	bm.StatusWantToRead: {bm.StatusReading, bm.StatusFinished},
+	bm.StatusReading:    {bm.StatusWantToRead, bm.StatusFinished, bm.StatusAbandoned},
+	bm.StatusFinished:   {bm.StatusReading},
+	bm.StatusAbandoned:  {bm.StatusWantToRead, bm.StatusReading},
+}
+
+func validateStatus(status string) error {
+	switch status {
+	case bm.StatusWantToRead, bm.StatusReading, bm.StatusFinished, bm.StatusAbandoned:
+		return nil
+	default:
+		return bm.NewValidationError("incorrect status")
+	}
+}
+
+// Reading retrieves the reading state of a book.
+func (s *Service) Reading(ctx context.Context, bookID int64) (*bm.Reading, error) {
+	if bookID <= 0 {
+		return nil, bm.NewValidationError("incorrect book_id")
+	}
+
+	r, err := s.storage.Reading(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("get reading state: %w", err)
+	}
+
+	return r, nil
+}
+
+// UpdateReading moves a book to the status of r on the given date, today if the date is zero.
+// Zero current page and empty notes of r keep the current ones.
+func (s *Service) UpdateReading(ctx context.Context, r bm.Reading, date time.Time) (*bm.Reading, error) {
+	if r.BookID <= 0 {
+		return nil, bm.NewValidationError("incorrect book_id")
+	}
+
+	if err := validateStatus(r.Status); err != nil {
+		return nil, err
+	}
+
+	if r.CurrentPage < 0 {
+		return nil, bm.NewValidationError("current_page is negative")
+	}
+
+	now := time.Now()
+	next, err := s.storage.UpdateReading(ctx, r.BookID, func(current bm.Reading) (bm.Reading, error) {
+		next, err := transitReading(current, r, date, now)
+		if err != nil {
+			return bm.Reading{}, fmt.Errorf("transit reading state: %w", err)
+		}
+
+		return next, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update reading state: %w", err)
+	}
+
+	return next, nil
+}
+
+// transitReading builds the reading state after moving a book from the current state to the status of r.
+// Staying in the same status updates progress and notes, and dates if the date is set.
+// Moving to another status sets its date to the given date or to the day of now.
+func transitReading(current, r bm.Reading, date, now time.Time) (bm.Reading, error) {
+	changed := r.Status != current.Status
+	if changed && !slices.Contains(readingTransitions[current.Status], r.Status) {
+		from := current.Status
+		if from == "" {
+			from = "no status"
+		}
+
+		return bm.Reading{}, bm.NewValidationError("can't move book from %s to %s", from, r.Status)
+	}
+
+	dateSet := !date.IsZero()
+	if !dateSet {
+		date = now.UTC()
+	}
+
+	date = date.Truncate(24 * time.Hour)
+
+	next := current
+	next.Status = r.Status
+	if r.CurrentPage != 0 {
+		next.CurrentPage = r.CurrentPage
+	}
+
+	if r.Notes != "" {
+		next.Notes = r.Notes
+	}
+
+	switch r.Status {
+	case bm.StatusWantToRead:
+		if r.CurrentPage != 0 {
+			return bm.Reading{}, bm.NewValidationError("current_page must be empty for books to read")
+		}
+
+		next.StartedAt, next.FinishedAt, next.CurrentPage = time.Time{}, time.Time{}, 0
+
+	case bm.StatusReading:
+		if changed || dateSet {
+			next.StartedAt = date
+		}
+
+		if changed {
+			next.FinishedAt = time.Time{}
+			if current.Status == bm.StatusFinished && r.CurrentPage == 0 {
+				next.CurrentPage = 0
+			}
+		}
+
+	case bm.StatusFinished, bm.StatusAbandoned:
+		if changed || dateSet {
+			next.FinishedAt = date
+		}
+
+		if next.StartedAt.IsZero() {
+			next.StartedAt = next.FinishedAt
+		}
+
+		if next.FinishedAt.Before(next.StartedAt) {
+			return bm.Reading{}, bm.NewValidationError("finish date is before start date")
+		}
+	}
+
+	return next, nil
+}
+
+// ReadingReport retrieves books finished within the year, the current year if it is zero.
+func (s *Service) ReadingReport(ctx context.Context, year int) (*bm.ReadingReport, error) {
+	if year == 0 {
+		year = time.Now().UTC().Year()
+	}
+
+	if year < 1 || year > 9999 {
+		return nil, bm.NewValidationError("incorrect year")
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	books, err := s.storage.FinishedBooks(ctx, from, from.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, fmt.Errorf("get finished books: %w", err)
+	}
+
+	return &bm.ReadingReport{Year: year, Books: books}, nil
+}
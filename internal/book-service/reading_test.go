@@ -0,0 +1,52 @@
+package bookservice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+func TestTransitReading(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 15, 30, 0, 0, time.UTC)
+	today := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+	started := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	got, err := transitReading(bm.Reading{BookID: 1}, bm.Reading{BookID: 1, Status: bm.StatusReading, CurrentPage: 10}, time.Time{}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, bm.Reading{BookID: 1, Status: bm.StatusReading, StartedAt: today, CurrentPage: 10}, got)
+
+	reading := bm.Reading{BookID: 1, Status: bm.StatusReading, StartedAt: started, CurrentPage: 10, Notes: "slow start"}
+	got, err = transitReading(reading, bm.Reading{Status: bm.StatusReading, CurrentPage: 50}, time.Time{}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, bm.Reading{BookID: 1, Status: bm.StatusReading, StartedAt: started, CurrentPage: 50, Notes: "slow start"}, got)
+
+	got, err = transitReading(reading, bm.Reading{Status: bm.StatusFinished}, time.Time{}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, bm.Reading{BookID: 1, Status: bm.StatusFinished, StartedAt: started, FinishedAt: today, CurrentPage: 10, Notes: "slow start"}, got)
+
+	finished := got
+	got, err = transitReading(finished, bm.Reading{Status: bm.StatusReading}, time.Time{}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, bm.Reading{BookID: 1, Status: bm.StatusReading, StartedAt: today, Notes: "slow start"}, got)
+
+	got, err = transitReading(reading, bm.Reading{Status: bm.StatusWantToRead}, time.Time{}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, bm.Reading{BookID: 1, Status: bm.StatusWantToRead, Notes: "slow start"}, got)
+
+	_, err = transitReading(reading, bm.Reading{Status: bm.StatusFinished}, started.AddDate(0, 0, -1), now)
+	assert.Error(t, err)
+
+	for _, tc := range []struct{ from, to string }{
+		{"", bm.StatusAbandoned},
+		{bm.StatusWantToRead, bm.StatusAbandoned},
+		{bm.StatusFinished, bm.StatusAbandoned},
+		{bm.StatusFinished, bm.StatusWantToRead},
+		{bm.StatusAbandoned, bm.StatusFinished},
+	} {
+		_, err := transitReading(bm.Reading{Status: tc.from}, bm.Reading{Status: tc.to}, time.Time{}, now)
+		assert.Error(t, err, "%q -> %q", tc.from, tc.to)
+	}
+}
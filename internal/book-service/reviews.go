@@ -0,0 +1,125 @@
+package bookservice
+
+import (
+	"context"
+	"fmt"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+const (
+	minRating = 1
+	maxRating = 5
+)
+
+// Review retrieves a review of a book by its id.
+func (s *Service) Review(ctx context.Context, bookID, id int64) (*bm.Review, error) {
+	if bookID <= 0 {
+		return nil, bm.NewValidationError("incorrect book_id")
+	}
+
+	if id <= 0 {
+		return nil, bm.NewValidationError("incorrect id")
+	}
+
+	review, err := s.storage.Review(ctx, bookID, id)
+	if err != nil {
+		return nil, fmt.Errorf("get review: %w", err)
+	}
+
+	return review, nil
+}
+
+// Reviews retrieves a list of reviews of a book.
+func (s *Service) Reviews(ctx context.Context, f bm.ReviewsFilter) ([]bm.Review, error) {
+	if f.BookID <= 0 {
+		return nil, bm.NewValidationError("incorrect book_id")
+	}
+
+	switch f.OrderBy {
+	case "id", "rating", "created_at", "updated_at":
+	case "":
+		f.OrderBy = "id"
+	default:
+		return nil, bm.NewValidationError("incorrect order_by")
+	}
+
+	if f.Page < 0 {
+		return nil, bm.NewValidationError("incorrect page")
+	}
+
+	if f.Page == 0 {
+		f.Page = 1
+	}
+
+	if f.PageSize < 0 {
+		return nil, bm.NewValidationError("page_size is negative")
+	}
+
+	if f.PageSize == 0 || f.PageSize > maxPageSize {
+		f.PageSize = maxPageSize
+	}
+
+	reviews, err := s.storage.Reviews(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("get reviews: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// CreateReview creates a review of a book.
+func (s *Service) CreateReview(ctx context.Context, r bm.Review) (int64, error) {
+	if r.BookID <= 0 {
+		return 0, bm.NewValidationError("incorrect book_id")
+	}
+
+	if r.Rating < minRating || r.Rating > maxRating {
+		return 0, bm.NewValidationError("rating must be from %d to %d", minRating, maxRating)
+	}
+
+	id, err := s.storage.CreateReview(ctx, r)
+	if err != nil {
+		return 0, fmt.Errorf("create review: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateReview updates rating and text of a review.
+func (s *Service) UpdateReview(ctx context.Context, r bm.Review) error {
+	if r.BookID <= 0 {
+		return bm.NewValidationError("incorrect book_id")
+	}
+
+	if r.ID <= 0 {
+		return bm.NewValidationError("incorrect id")
+	}
+
+	if r.Rating < minRating || r.Rating > maxRating {
+		return bm.NewValidationError("rating must be from %d to %d", minRating, maxRating)
+	}
+
+	if err := s.storage.UpdateReview(ctx, r); err != nil {
+		return fmt.Errorf("update review: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteReview deletes a review of a book.
+func (s *Service) DeleteReview(ctx context.Context, bookID, id int64) error {
+	if bookID <= 0 {
+		return bm.NewValidationError("incorrect book_id")
+	}
+
+	if id <= 0 {
+		return bm.NewValidationError("incorrect id")
+	}
+
+	if err := s.storage.DeleteReview(ctx, bookID, id); err != nil {
+		return fmt.Errorf("delete review: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package bookservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+const maxTagLen = 50
+
+// Tags retrieves a list of tags with their usage counts.
+func (s *Service) Tags(ctx context.Context, f bm.TagsFilter) ([]bm.Tag, error) {
+	switch f.OrderBy {
+	case "id", "name", "books":
+	case "":
+		f.OrderBy = "id"
+	default:
+		return nil, bm.NewValidationError("incorrect order_by")
+	}
+
+	if f.Page < 0 {
+		return nil, bm.NewValidationError("incorrect page")
+	}
+
+	if f.Page == 0 {
+		f.Page = 1
+	}
+
+	if f.PageSize < 0 {
+		return nil, bm.NewValidationError("page_size is negative")
+	}
+
+	if f.PageSize == 0 || f.PageSize > maxPageSize {
+		f.PageSize = maxPageSize
+	}
+
+	tags, err := s.storage.Tags(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("get tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// AddBooksTags adds tags to a list of books.
+func (s *Service) AddBooksTags(ctx context.Context, bookIDs []int64, tags []string) error {
+	bookIDs, tags, err := validateBooksTags(bookIDs, tags)
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.AddBooksTags(ctx, bookIDs, tags); err != nil {
+		return fmt.Errorf("add tags to books: %w", err)
+	}
+
+	s.publish(ctx, bm.EventBookUpdated, bookEvents(bookIDs)...)
+
+	return nil
+}
+
+// DeleteBooksTags removes tags from a list of books.
+func (s *Service) DeleteBooksTags(ctx context.Context, bookIDs []int64, tags []string) error {
+	bookIDs, tags, err := validateBooksTags(bookIDs, tags)
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.DeleteBooksTags(ctx, bookIDs, tags); err != nil {
+		return fmt.Errorf("remove tags from books: %w", err)
+	}
+
+	s.publish(ctx, bm.EventBookUpdated, bookEvents(bookIDs)...)
+
+	return nil
+}
+
+func validateBooksTags(bookIDs []int64, tags []string) ([]int64, []string, error) {
+	if len(bookIDs) == 0 {
+		return nil, nil, bm.NewValidationError("empty book ids list")
+	}
+
+	if len(tags) == 0 {
+		return nil, nil, bm.NewValidationError("empty tags list")
+	}
+
+	uniqueIDs := make([]int64, 0, len(bookIDs))
+	seen := make(map[int64]bool, len(bookIDs))
+	for _, id := range bookIDs {
+		if id <= 0 {
+			return nil, nil, bm.NewValidationError("incorrect book id %d", id)
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			uniqueIDs = append(uniqueIDs, id)
+		}
+	}
+
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return nil, nil, fmt.Errorf("normalize tags: %w", err)
+	}
+
+	return uniqueIDs, tags, nil
+}
+
+// NormalizeTags trims and lowercases tags and removes duplicates.
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, bm.NewValidationError("tag is empty")
+		}
+
+		if utf8.RuneCountInString(tag) > maxTagLen {
+			return nil, bm.NewValidationError("tag %s is longer than %d characters", tag, maxTagLen)
+		}
+
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+
+	return normalized, nil
+}
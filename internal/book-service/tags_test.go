@@ -0,0 +1,23 @@
+package bookservice
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := NormalizeTags([]string{" To-Read ", "classics", "to-read", "CLASSICS"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"to-read", "classics"}, got)
+
+	got, err = NormalizeTags(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	for _, tags := range [][]string{{" "}, {strings.Repeat("a", maxTagLen+1)}} {
+		_, err := NormalizeTags(tags)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,351 @@
+package bookservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+const (
+	maxWebhookURLLen = 2048
+	minSecretLen     = 16
+	maxSecretLen     = 100
+
+	// secretSize is the number of random bytes of generated secrets.
+	secretSize = 32
+)
+
+// Data of events sent to webhooks. Deleted books and collections are described by their ids only,
+// receivers fetch other details from the API.
+type (
+	bookEvent struct {
+		ID    int64  `json:"id"`
+		Title string `json:"title,omitempty"`
+		ISBN  string `json:"isbn,omitempty"`
+	}
+
+	collectionEvent struct {
+		ID          int64  `json:"id"`
+		Name        string `json:"name,omitempty"`
+		Description string `json:"description,omitempty"`
+	}
+
+	collectionBooksEvent struct {
+		CollectionID int64   `json:"collection_id"`
+		BookIDs      []int64 `json:"book_ids"`
+	}
+)
+
+// Webhook retrieves a webhook by its id.
+func (s *Service) Webhook(ctx context.Context, id int64) (*bm.Webhook, error) {
+	if err := checkWebhookAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if id <= 0 {
+		return nil, bm.NewValidationError("incorrect id")
+	}
+
+	w, err := s.storage.Webhook(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get webhook: %w", err)
+	}
+
+	return w, nil
+}
+
+// Webhooks retrieves webhooks of the tenant.
+func (s *Service) Webhooks(ctx context.Context, f bm.WebhooksFilter) ([]bm.Webhook, error) {
+	if err := checkWebhookAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if f.Page < 0 {
+		return nil, bm.NewValidationError("incorrect page")
+	}
+
+	if f.Page == 0 {
+		f.Page = 1
+	}
+
+	if f.PageSize < 0 {
+		return nil, bm.NewValidationError("page_size is negative")
+	}
+
+	if f.PageSize == 0 || f.PageSize > maxPageSize {
+		f.PageSize = maxPageSize
+	}
+
+	webhooks, err := s.storage.Webhooks(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("get webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// CreateWebhook subscribes a URL to events and returns the secret signing its deliveries.
+// A secret is generated if it isn't set, the webhook is subscribed to all events if events aren't set.
+func (s *Service) CreateWebhook(ctx context.Context, w bm.Webhook) (int64, string, error) {
+	if err := checkWebhookAdmin(ctx); err != nil {
+		return 0, "", err
+	}
+
+	w, err := validateWebhook(w)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if w.Secret == "" {
+		if w.Secret, err = generateSecret(); err != nil {
+			return 0, "", fmt.Errorf("generate secret: %w", err)
+		}
+	}
+
+	id, err := s.storage.CreateWebhook(ctx, w)
+	if err != nil {
+		return 0, "", fmt.Errorf("create webhook: %w", err)
+	}
+
+	return id, w.Secret, nil
+}
+
+// UpdateWebhook updates an existing webhook. The secret is kept if it isn't set.
+func (s *Service) UpdateWebhook(ctx context.Context, w bm.Webhook) error {
+	if err := checkWebhookAdmin(ctx); err != nil {
+		return err
+	}
+
+	if w.ID <= 0 {
+		return bm.NewValidationError("incorrect id")
+	}
+
+	w, err := validateWebhook(w)
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.UpdateWebhook(ctx, w); err != nil {
+		return fmt.Errorf("update webhook: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteWebhook deletes a webhook together with its deliveries.
+func (s *Service) DeleteWebhook(ctx context.Context, id int64) error {
+	if err := checkWebhookAdmin(ctx); err != nil {
+		return err
+	}
+
+	if id <= 0 {
+		return bm.NewValidationError("incorrect id")
+	}
+
+	if err := s.storage.DeleteWebhook(ctx, id); err != nil {
+		return fmt.Errorf("delete webhook: %w", err)
+	}
+
+	return nil
+}
+
+// WebhookDelivery retrieves a delivery of a webhook with its attempts.
+func (s *Service) WebhookDelivery(ctx context.Context, webhookID, id int64) (*bm.WebhookDelivery, error) {
+	if err := checkWebhookAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if webhookID <= 0 {
+		return nil, bm.NewValidationError("incorrect webhook_id")
+	}
+
+	if id <= 0 {
+		return nil, bm.NewValidationError("incorrect id")
+	}
+
+	d, err := s.storage.WebhookDelivery(ctx, webhookID, id)
+	if err != nil {
+		return nil, fmt.Errorf("get webhook delivery: %w", err)
+	}
+
+	return d, nil
+}
+
+// WebhookDeliveries retrieves deliveries of a webhook from the newest.
+func (s *Service) WebhookDeliveries(ctx context.Context, f bm.WebhookDeliveriesFilter) ([]bm.WebhookDelivery, error) {
+	if err := checkWebhookAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if f.WebhookID <= 0 {
+		return nil, bm.NewValidationError("incorrect webhook_id")
+	}
+
+	switch f.Status {
+	case "", bm.DeliveryPending, bm.DeliverySucceeded, bm.DeliveryDead:
+	default:
+		return nil, bm.NewValidationError("incorrect status")
+	}
+
+	if f.Page < 0 {
+		return nil, bm.NewValidationError("incorrect page")
+	}
+
+	if f.Page == 0 {
+		f.Page = 1
+	}
+
+	if f.PageSize < 0 {
+		return nil, bm.NewValidationError("page_size is negative")
+	}
+
+	if f.PageSize == 0 || f.PageSize > maxPageSize {
+		f.PageSize = maxPageSize
+	}
+
+	deliveries, err := s.storage.WebhookDeliveries(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("get webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RedeliverWebhookEvent sends the event of a delivery to the webhook once again, usually after the delivery
+// is dead. It returns the id of the new delivery.
+func (s *Service) RedeliverWebhookEvent(ctx context.Context, webhookID, deliveryID int64) (int64, error) {
+	if err := checkWebhookAdmin(ctx); err != nil {
+		return 0, err
+	}
+
+	if webhookID <= 0 {
+		return 0, bm.NewValidationError("incorrect webhook_id")
+	}
+
+	if deliveryID <= 0 {
+		return 0, bm.NewValidationError("incorrect delivery_id")
+	}
+
+	id, err := s.storage.RedeliverWebhookEvent(ctx, webhookID, deliveryID)
+	if err != nil {
+		return 0, fmt.Errorf("redeliver webhook event: %w", err)
+	}
+
+	return id, nil
+}
+
+// publish queues events of the type with the data for webhooks subscribed to them.
+// The change is already made, so a failure is only logged.
+func (s *Service) publish(ctx context.Context, eventType string, data ...any) {
+	events := make([]bm.WebhookEvent, 0, len(data))
+	for _, d := range data {
+		raw, err := json.Marshal(d)
+		if err != nil {
+			log.Error().Err(err).Str("event", eventType).Msg("marshal webhook event")
+
+			return
+		}
+
+		events = append(events, bm.WebhookEvent{Type: eventType, Data: raw})
+	}
+
+	if err := s.storage.CreateWebhookEvents(ctx, events...); err != nil {
+		log.Error().Err(err).Str("event", eventType).Msg("publish webhook events")
+	}
+}
+
+// bookEvents describes each book by a separate event with its id.
+func bookEvents(ids []int64) []any {
+	events := make([]any, 0, len(ids))
+	for _, id := range ids {
+		events = append(events, bookEvent{ID: id})
+	}
+
+	return events
+}
+
+// withTenant sets the tenant of the caller to publish events to webhooks of another tenant.
+func withTenant(ctx context.Context, tenant string) context.Context {
+	id := bm.IdentityFromCtx(ctx)
+	id.Tenant = tenant
+
+	return bm.WithIdentity(ctx, id)
+}
+
+// checkWebhookAdmin allows only admins to manage webhooks: deliveries are requests of the server to URLs
+// set by the callers, and their results are recorded.
+func checkWebhookAdmin(ctx context.Context) error {
+	if !bm.IdentityFromCtx(ctx).Admin {
+		return bm.NewForbiddenError("only admins can manage webhooks")
+	}
+
+	return nil
+}
+
+// validateWebhook checks the URL, the secret and the events of a webhook.
+func validateWebhook(w bm.Webhook) (bm.Webhook, error) {
+	w.URL = strings.TrimSpace(w.URL)
+	if w.URL == "" {
+		return w, bm.NewValidationError("url is empty")
+	}
+
+	if len(w.URL) > maxWebhookURLLen {
+		return w, bm.NewValidationError("url is longer than %d characters", maxWebhookURLLen)
+	}
+
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return w, bm.NewValidationError("url is not an absolute http or https url")
+	}
+
+	if w.Secret != "" && (len(w.Secret) < minSecretLen || len(w.Secret) > maxSecretLen) {
+		return w, bm.NewValidationError("secret must be from %d to %d characters long", minSecretLen, maxSecretLen)
+	}
+
+	if len(w.Events) == 0 {
+		w.Events = []string{"*"}
+	}
+
+	for _, e := range w.Events {
+		if !validEventFilter(e) {
+			return w, bm.NewValidationError("incorrect event %q", e)
+		}
+	}
+
+	w.Events = slices.Clone(w.Events)
+	slices.Sort(w.Events)
+	w.Events = slices.Compact(w.Events)
+
+	return w, nil
+}
+
+// validEventFilter checks that the filter is an event type, a category of events like "book.*" or "*".
+func validEventFilter(filter string) bool {
+	if filter == "*" || slices.Contains(bm.EventTypes, filter) {
+		return true
+	}
+
+	category, ok := strings.CutSuffix(filter, ".*")
+
+	return ok && slices.ContainsFunc(bm.EventTypes, func(t string) bool {
+		return strings.HasPrefix(t, category+".")
+	})
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,40 @@
+package bookservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+func TestValidateWebhook(t *testing.T) {
+	got, err := validateWebhook(bm.Webhook{
+		URL:    " https://example.com/hook ",
+		Events: []string{"collection.*", "book.created", "collection.*"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/hook", got.URL)
+	assert.Equal(t, []string{"book.created", "collection.*"}, got.Events)
+
+	got, err = validateWebhook(bm.Webhook{URL: "http://localhost:8091"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"*"}, got.Events)
+
+	for _, w := range []bm.Webhook{
+		{URL: ""},
+		{URL: "/hook"},
+		{URL: "ftp://example.com/hook"},
+		{URL: "https://example.com/hook", Events: []string{"book.lent"}},
+		{URL: "https://example.com/hook", Events: []string{"loan.*"}},
+		{URL: "https://example.com/hook", Events: []string{"book"}},
+		{URL: "https://example.com/hook", Secret: "short"},
+	} {
+		_, err := validateWebhook(w)
+		assert.Error(t, err, "url %q, events %v", w.URL, w.Events)
+	}
+}
+
+func TestEventPatterns(t *testing.T) {
+	assert.Equal(t, []string{"collection.books_added", "collection.*", "*"}, bm.EventPatterns(bm.EventCollectionBooksAdded))
+}
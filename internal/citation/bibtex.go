@@ -0,0 +1,83 @@
+package citation
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// latexEscaper escapes characters which are special in LaTeX. Other characters, including non-ASCII letters,
+// are kept as is, since BibTeX files are read as UTF-8 by biber and modern BibTeX.
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`$`, `\$`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// EscapeLaTeX escapes special characters of LaTeX in a value of a BibTeX field.
+func EscapeLaTeX(s string) string {
+	return latexEscaper.Replace(s)
+}
+
+// BibTeX formats books as @book entries. Translators and illustrators are written in biblatex fields,
+// which classic BibTeX styles ignore.
+func BibTeX(books []bm.Book) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	for i, key := range Keys(books) {
+		if i != 0 {
+			buf.WriteByte('\n')
+		}
+
+		b := books[i]
+		fmt.Fprintf(buf, "@book{%s,\n", key)
+
+		writeField := func(field, value string) {
+			if value != "" {
+				fmt.Fprintf(buf, "  %s = {%s},\n", field, EscapeLaTeX(value))
+			}
+		}
+
+		writeField("author", bibtexNames(contributors(b, bm.RoleAuthor)))
+		writeField("editor", bibtexNames(contributors(b, bm.RoleEditor)))
+		writeField("translator", bibtexNames(contributors(b, bm.RoleTranslator)))
+		writeField("illustrator", bibtexNames(contributors(b, bm.RoleIllustrator)))
+		writeField("title", b.Title)
+		writeField("edition", b.Edition)
+		if !b.PublishedDate.IsZero() {
+			writeField("year", strconv.Itoa(b.PublishedDate.Year()))
+		}
+
+		writeField("isbn", b.ISBN)
+		writeField("abstract", b.Description)
+		writeField("keywords", strings.Join(b.Tags, ", "))
+		buf.WriteString("}\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// bibtexNames joins names in the "Family, Given" form with "and".
+func bibtexNames(names []name) string {
+	parts := make([]string, 0, len(names))
+	for _, n := range names {
+		if n.given == "" {
+			parts = append(parts, n.family)
+
+			continue
+		}
+
+		parts = append(parts, n.family+", "+n.given)
+	}
+
+	return strings.Join(parts, " and ")
+}
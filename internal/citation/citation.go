@@ -0,0 +1,167 @@
+// Package citation formats books as citations for reference managers: BibTeX, RIS and CSL-JSON.
+package citation
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// Formats of citations.
+const (
+	FormatBibTeX  = "bibtex"
+	FormatRIS     = "ris"
+	FormatCSLJSON = "csljson"
+)
+
+// keyStopWords are skipped when the first word of a title is taken for a citation key.
+var keyStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "of": true, "on": true, "in": true, "and": true,
+	"der": true, "die": true, "das": true, "le": true, "la": true, "les": true, "el": true,
+}
+
+// foldedLetters spells letters with diacritics and ligatures in ASCII for citation keys.
+var foldedLetters = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ą': "a", 'æ': "ae",
+	'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ł': "l", 'ľ': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ř': "r", 'ś': "s", 'š': "s", 'ß': "ss", 'ť': "t",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// name is a personal name split into the family name and given names.
+type name struct {
+	family string
+	given  string
+}
+
+// splitName splits "Fyodor Dostoyevsky" and "Dostoyevsky, Fyodor" into family and given names.
+// Lowercase particles belong to the family name, like "van Beethoven". A single word is a family name.
+func splitName(full string) name {
+	full = strings.TrimSpace(full)
+	if family, given, ok := strings.Cut(full, ","); ok {
+		return name{family: strings.TrimSpace(family), given: strings.TrimSpace(given)}
+	}
+
+	words := strings.Fields(full)
+	if len(words) < 2 {
+		return name{family: full}
+	}
+
+	familyStart := len(words) - 1
+	for i := 1; i < len(words)-1; i++ {
+		if r := []rune(words[i])[0]; unicode.IsLower(r) {
+			familyStart = i
+
+			break
+		}
+	}
+
+	return name{
+		family: strings.Join(words[familyStart:], " "),
+		given:  strings.Join(words[:familyStart], " "),
+	}
+}
+
+// contributors returns names of contributors of a book with the role.
+// Books without contributors have the author line, which is taken as a single author.
+func contributors(b bm.Book, role string) []name {
+	if len(b.Contributors) == 0 && role == bm.RoleAuthor && b.Author != "" {
+		return []name{splitName(b.Author)}
+	}
+
+	var names []name
+	for _, c := range b.Contributors {
+		if c.Role == role {
+			names = append(names, splitName(c.Name))
+		}
+	}
+
+	return names
+}
+
+// Keys generates citation keys of books from the family name of the first author, the year and the first significant
+// word of the title, like dostoyevsky1993crime. Books without authors are keyed by editors, books without both
+// by the title alone. Keys which repeat within the books get suffixes in order of the books: a, b, c.
+func Keys(books []bm.Book) []string {
+	keys := make([]string, 0, len(books))
+	counts := make(map[string]int, len(books))
+	for _, b := range books {
+		key := baseKey(b)
+		keys = append(keys, key)
+		counts[key]++
+	}
+
+	seen := make(map[string]int, len(books))
+	for i, key := range keys {
+		if counts[key] == 1 {
+			continue
+		}
+
+		keys[i] = key + keySuffix(seen[key])
+		seen[key]++
+	}
+
+	return keys
+}
+
+func baseKey(b bm.Book) string {
+	var key string
+	for _, role := range []string{bm.RoleAuthor, bm.RoleEditor} {
+		if names := contributors(b, role); len(names) != 0 {
+			key = keyWord(names[0].family)
+
+			break
+		}
+	}
+
+	if !b.PublishedDate.IsZero() {
+		key += strconv.Itoa(b.PublishedDate.Year())
+	}
+
+	for _, word := range strings.Fields(b.Title) {
+		if w := keyWord(word); w != "" && !keyStopWords[w] {
+			key += w
+
+			break
+		}
+	}
+
+	if key == "" {
+		return "book" + strconv.FormatInt(b.ID, 10)
+	}
+
+	return key
+}
+
+// keyWord folds a word into lowercase ASCII letters and digits, other characters are dropped.
+func keyWord(word string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(word) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			sb.WriteRune(r)
+
+		case foldedLetters[r] != "":
+			sb.WriteString(foldedLetters[r])
+		}
+	}
+
+	return sb.String()
+}
+
+// keySuffix returns a, b, ..., z, aa, ab for repeated keys.
+func keySuffix(n int) string {
+	suffix := string(rune('a' + n%26))
+	if n >= 26 {
+		return keySuffix(n/26-1) + suffix
+	}
+
+	return suffix
+}
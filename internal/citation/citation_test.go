@@ -0,0 +1,145 @@
+package citation
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+var testBooks = []bm.Book{
+	{
+		ID:            1,
+		Title:         "Crime and Punishment",
+		PublishedDate: time.Date(1993, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Edition:       "1st Vintage classics ed.",
+		Description:   "A murder\nand its consequences.",
+		ISBN:          "9780679734505",
+		Contributors: []bm.Contributor{
+			{Name: "Fyodor Dostoyevsky", Role: bm.RoleAuthor},
+			{Name: "Richard Pevear", Role: bm.RoleTranslator},
+			{Name: "Larissa Volokhonsky", Role: bm.RoleTranslator},
+		},
+		Tags: []string{"classics", "russian"},
+	},
+	{
+		ID:            2,
+		Title:         "The Brothers Karamazov",
+		PublishedDate: time.Date(1993, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Contributors:  []bm.Contributor{{Name: "Fyodor Dostoyevsky", Role: bm.RoleAuthor}},
+	},
+	{
+		ID:            3,
+		Title:         "Crime & Punishment: 100% of the $ #1_{draft}~^\\",
+		PublishedDate: time.Date(1993, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Author:        "Fyodor Dostoyevsky",
+	},
+	{
+		ID:           4,
+		Title:        "Symphonies",
+		Contributors: []bm.Contributor{{Name: "Ludwig van Beethoven", Role: bm.RoleEditor}, {Name: "Gödel Ærø", Role: bm.RoleIllustrator}},
+	},
+}
+
+func TestKeys(t *testing.T) {
+	assert.Equal(t, []string{"dostoyevsky1993crimea", "dostoyevsky1993brothers", "dostoyevsky1993crimeb", "vanbeethovensymphonies"}, Keys(testBooks))
+
+	// Keys don't depend on anything but the book and repeated keys.
+	assert.Equal(t, []string{"dostoyevsky1993brothers"}, Keys(testBooks[1:2]))
+	assert.Equal(t, []string{"godel"}, Keys([]bm.Book{{Title: "Gödel", Author: ""}}))
+	assert.Equal(t, []string{"book5"}, Keys([]bm.Book{{ID: 5, Title: "Трава"}}))
+	assert.Equal(t, []string{"z", "aa"}, []string{keySuffix(25), keySuffix(26)})
+}
+
+func TestSplitName(t *testing.T) {
+	tests := []struct {
+		give string
+		want name
+	}{
+		{give: "Fyodor Dostoyevsky", want: name{family: "Dostoyevsky", given: "Fyodor"}},
+		{give: "Dostoyevsky, Fyodor", want: name{family: "Dostoyevsky", given: "Fyodor"}},
+		{give: "J. D. Salinger", want: name{family: "Salinger", given: "J. D."}},
+		{give: "Ludwig van Beethoven", want: name{family: "van Beethoven", given: "Ludwig"}},
+		{give: "Homer", want: name{family: "Homer"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, splitName(tt.give), tt.give)
+	}
+}
+
+func TestBibTeX(t *testing.T) {
+	data, err := BibTeX(testBooks[2:4])
+	require.NoError(t, err)
+	assert.Equal(t, `@book{dostoyevsky1993crime,
+  author = {Dostoyevsky, Fyodor},
+  title = {Crime \& Punishment: 100\% of the \$ \#1\_\{draft\}\textasciitilde{}\textasciicircum{}\textbackslash{}},
+  year = {1993},
+}
+
+@book{vanbeethovensymphonies,
+  editor = {van Beethoven, Ludwig},
+  illustrator = {Ærø, Gödel},
+  title = {Symphonies},
+}
+`, string(data))
+
+	data, err = BibTeX(testBooks[:1])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "  translator = {Pevear, Richard and Volokhonsky, Larissa},\n")
+	assert.Contains(t, string(data), "  keywords = {classics, russian},\n")
+}
+
+func TestRIS(t *testing.T) {
+	data, err := RIS(testBooks[:1])
+	require.NoError(t, err)
+	assert.Equal(t, "TY  - BOOK\r\n"+
+		"ID  - dostoyevsky1993crime\r\n"+
+		"AU  - Dostoyevsky, Fyodor\r\n"+
+		"A4  - Pevear, Richard\r\n"+
+		"A4  - Volokhonsky, Larissa\r\n"+
+		"TI  - Crime and Punishment\r\n"+
+		"ET  - 1st Vintage classics ed.\r\n"+
+		"PY  - 1993\r\n"+
+		"SN  - 9780679734505\r\n"+
+		"AB  - A murder and its consequences.\r\n"+
+		"KW  - classics\r\n"+
+		"KW  - russian\r\n"+
+		"ER  - \r\n", string(data))
+}
+
+func TestCSLJSON(t *testing.T) {
+	data, err := CSLJSON(testBooks[:2])
+	require.NoError(t, err)
+
+	var items []map[string]any
+	require.NoError(t, json.Unmarshal(data, &items))
+	assert.Equal(t, []map[string]any{
+		{
+			"id":         "dostoyevsky1993crime",
+			"type":       "book",
+			"title":      "Crime and Punishment",
+			"author":     []any{map[string]any{"family": "Dostoyevsky", "given": "Fyodor"}},
+			"translator": []any{map[string]any{"family": "Pevear", "given": "Richard"}, map[string]any{"family": "Volokhonsky", "given": "Larissa"}},
+			"issued":     map[string]any{"date-parts": []any{[]any{1993.0}}},
+			"edition":    "1st Vintage classics ed.",
+			"ISBN":       "9780679734505",
+			"abstract":   "A murder\nand its consequences.",
+			"keyword":    "classics, russian",
+		},
+		{
+			"id":     "dostoyevsky1993brothers",
+			"type":   "book",
+			"title":  "The Brothers Karamazov",
+			"author": []any{map[string]any{"family": "Dostoyevsky", "given": "Fyodor"}},
+			"issued": map[string]any{"date-parts": []any{[]any{1993.0}}},
+		},
+	}, items)
+
+	data, err = CSLJSON(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "[]\n", string(data))
+}
@@ -0,0 +1,76 @@
+package citation
+
+import (
+	"encoding/json"
+	"strings"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// cslItem is a bibliographic item of CSL-JSON, https://citeproc-js.readthedocs.io/en/latest/csl-json/markup.html.
+type cslItem struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	Title       string    `json:"title"`
+	Author      []cslName `json:"author,omitempty"`
+	Editor      []cslName `json:"editor,omitempty"`
+	Translator  []cslName `json:"translator,omitempty"`
+	Illustrator []cslName `json:"illustrator,omitempty"`
+	Issued      *cslDate  `json:"issued,omitempty"`
+	Edition     string    `json:"edition,omitempty"`
+	ISBN        string    `json:"ISBN,omitempty"`
+	Abstract    string    `json:"abstract,omitempty"`
+	Keyword     string    `json:"keyword,omitempty"`
+}
+
+type cslName struct {
+	Family string `json:"family"`
+	Given  string `json:"given,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// CSLJSON formats books as an array of CSL-JSON items, which citeproc processors, Zotero and pandoc read.
+func CSLJSON(books []bm.Book) ([]byte, error) {
+	items := make([]cslItem, 0, len(books))
+	for i, key := range Keys(books) {
+		b := books[i]
+		item := cslItem{
+			ID:          key,
+			Type:        "book",
+			Title:       b.Title,
+			Author:      cslNames(contributors(b, bm.RoleAuthor)),
+			Editor:      cslNames(contributors(b, bm.RoleEditor)),
+			Translator:  cslNames(contributors(b, bm.RoleTranslator)),
+			Illustrator: cslNames(contributors(b, bm.RoleIllustrator)),
+			Edition:     b.Edition,
+			ISBN:        b.ISBN,
+			Abstract:    b.Description,
+			Keyword:     strings.Join(b.Tags, ", "),
+		}
+
+		if !b.PublishedDate.IsZero() {
+			item.Issued = &cslDate{DateParts: [][]int{{b.PublishedDate.Year()}}}
+		}
+
+		items = append(items, item)
+	}
+
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return nil, bm.NewInternalError("marshal csl-json: %w", err)
+	}
+
+	return append(data, '\n'), nil
+}
+
+func cslNames(names []name) []cslName {
+	var result []cslName
+	for _, n := range names {
+		result = append(result, cslName{Family: n.family, Given: n.given})
+	}
+
+	return result
+}
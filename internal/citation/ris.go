@@ -0,0 +1,67 @@
+package citation
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// risRoles maps contributor roles to RIS tags of a book. Illustrators have no tag.
+var risRoles = []struct {
+	role string
+	tag  string
+}{
+	{role: bm.RoleAuthor, tag: "AU"},
+	{role: bm.RoleEditor, tag: "ED"},
+	{role: bm.RoleTranslator, tag: "A4"},
+}
+
+// RIS formats books as BOOK references. Lines end with CRLF as the format requires.
+func RIS(books []bm.Book) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	for i, key := range Keys(books) {
+		b := books[i]
+
+		writeTag := func(tag, value string) {
+			// Values are single lines, line breaks of descriptions are replaced with spaces.
+			if value = strings.Join(strings.Fields(value), " "); value != "" {
+				fmt.Fprintf(buf, "%s  - %s\r\n", tag, value)
+			}
+		}
+
+		buf.WriteString("TY  - BOOK\r\n")
+		writeTag("ID", key)
+		for _, r := range risRoles {
+			for _, n := range contributors(b, r.role) {
+				writeTag(r.tag, risName(n))
+			}
+		}
+
+		writeTag("TI", b.Title)
+		writeTag("ET", b.Edition)
+		if !b.PublishedDate.IsZero() {
+			writeTag("PY", strconv.Itoa(b.PublishedDate.Year()))
+		}
+
+		writeTag("SN", b.ISBN)
+		writeTag("AB", b.Description)
+		for _, tag := range b.Tags {
+			writeTag("KW", tag)
+		}
+
+		buf.WriteString("ER  - \r\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func risName(n name) string {
+	if n.given == "" {
+		return n.family
+	}
+
+	return n.family + ", " + n.given
+}
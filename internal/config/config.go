@@ -3,8 +3,10 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/netip"
 	"os"
 	"path"
+	"strconv"
 	"time"
 
 	"github.com/caarlos0/env/v9"
@@ -12,6 +14,15 @@ import (
 	"github.com/Tsapen/bm/internal/bm"
 )
 
+// defaultTenant owns the data created before tenants were introduced.
+const defaultTenant = "default"
+
+// Default limits of GraphQL queries, a query with them still fetches a collection, its books and their collections.
+const (
+	defaultGraphQLMaxDepth      = 10
+	defaultGraphQLMaxComplexity = 5000
+)
+
 type serverEnvs struct {
 	RootDir        string `env:"BM_ROOT_DIR"`
 	Config         string `env:"BM_SERVER_CONFIG"`
@@ -29,20 +40,82 @@ type cliClientEnvs struct {
 }
 
 type ServerConfig struct {
-	HTTPCfg *HTTPCfg `json:"http"`
-	DB      *DBCfg   `json:"db"`
+	HTTPCfg   *HTTPCfg      `json:"http"`
+	GRPC      *GRPCCfg      `json:"grpc"`
+	GraphQL   *GraphQLCfg   `json:"graphql"`
+	DB        *DBCfg        `json:"db"`
+	Auth      *AuthCfg      `json:"auth"`
+	RateLimit *RateLimitCfg `json:"rate_limit"`
+	Blobs     *BlobsCfg     `json:"blobs"`
+	Files     *BlobsCfg     `json:"files"`
+	Webhooks  *WebhooksCfg  `json:"webhooks"`
+	Cache     *CacheCfg     `json:"cache"`
 
 	MigrationsPath string `json:"-"`
 }
 
 type HTTPCfg struct {
-	Addr         string `json:"address"`
-	SocketPath   string `json:"socket_path"`
-	ConnMaxCount int    `json:"connections_max_count"`
+	Addr         string     `json:"address"`
+	SocketPath   string     `json:"socket_path"`
+	Socket       *SocketCfg `json:"socket"`
+	ConnMaxCount int        `json:"connections_max_count"`
+	TLS          *TLSCfg    `json:"tls"`
 
 	Timeout time.Duration `json:"-"`
 }
 
+// SocketCfg restricts access to the unix socket: mode is an octal mode of the socket file and group is
+// a name or an id of its group. Peers allow processes by uid with read-only or read-write access;
+// any local user who can open the socket is allowed without them.
+type SocketCfg struct {
+	Group string          `json:"group"`
+	Peers []SocketPeerCfg `json:"peers"`
+
+	Mode os.FileMode `json:"-"`
+}
+
+type SocketPeerCfg struct {
+	UID    uint32 `json:"uid"`
+	Access string `json:"access"`
+}
+
+func (c *SocketCfg) UnmarshalJSON(data []byte) error {
+	type Alias SocketCfg
+	aux := &struct {
+		Mode string `json:"mode"`
+		*Alias
+	}{
+		Alias: (*Alias)(c),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+
+	if aux.Mode == "" {
+		return nil
+	}
+
+	mode, err := strconv.ParseUint(aux.Mode, 8, 32)
+	if err != nil || os.FileMode(mode)&^os.ModePerm != 0 {
+		return fmt.Errorf("parse mode %q: permission bits are expected", aux.Mode)
+	}
+
+	c.Mode = os.FileMode(mode)
+
+	return nil
+}
+
+// TLSCfg enables TLS on the tcp address of the server. Client certificates signed by the client CA are verified
+// and mapped to identities by the auth section; require_client_cert rejects connections without them.
+// The files are reloaded after they change.
+type TLSCfg struct {
+	CertFile          string `json:"cert_file"`
+	KeyFile           string `json:"key_file"`
+	ClientCAFile      string `json:"client_ca_file"`
+	RequireClientCert bool   `json:"require_client_cert"`
+}
+
 func (c *HTTPCfg) UnmarshalJSON(data []byte) error {
 	type Alias HTTPCfg
 	aux := &struct {
@@ -66,6 +139,169 @@ func (c *HTTPCfg) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// GRPCCfg configures the gRPC server. It listens on the address, on the unix socket or on both;
+// the server is disabled if both are empty. Socket and TLS configure the server like the HTTP one.
+type GRPCCfg struct {
+	Addr       string     `json:"address"`
+	SocketPath string     `json:"socket_path"`
+	Socket     *SocketCfg `json:"socket"`
+	TLS        *TLSCfg    `json:"tls"`
+}
+
+// GraphQLCfg limits depth and complexity of GraphQL queries. Zero disables a limit.
+type GraphQLCfg struct {
+	MaxDepth      int `json:"max_depth"`
+	MaxComplexity int `json:"max_complexity"`
+}
+
+// AuthCfg contains api keys and client certificates of the server users.
+// Requests without an api key or a client certificate belong to the default tenant; they are rejected if it is empty
+// and read-only if api keys or client certificates are configured, except requests over the unix socket.
+type AuthCfg struct {
+	DefaultTenant string          `json:"default_tenant"`
+	APIKeys       []APIKeyCfg     `json:"api_keys"`
+	ClientCerts   []ClientCertCfg `json:"client_certs"`
+}
+
+type APIKeyCfg struct {
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	Tenant string `json:"tenant"`
+	Admin  bool   `json:"admin"`
+}
+
+// ClientCertCfg binds the common name of a client certificate subject to identity of its owner.
+type ClientCertCfg struct {
+	CommonName string `json:"common_name"`
+	Name       string `json:"name"`
+	Tenant     string `json:"tenant"`
+	Admin      bool   `json:"admin"`
+}
+
+// RateLimitCfg contains per client limits for reading, writing and bulk routes.
+// A missing limit disables limiting of the routes.
+type RateLimitCfg struct {
+	Reads  *LimitCfg `json:"reads"`
+	Writes *LimitCfg `json:"writes"`
+	Bulk   *LimitCfg `json:"bulk"`
+}
+
+// LimitCfg configures a token bucket: rate is the number of requests per second, burst is the bucket size.
+type LimitCfg struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// BlobsCfg configures a local directory for blobs: cover images or digital files of books.
+// Directories default to blobs and files in the root directory.
+type BlobsCfg struct {
+	Dir string `json:"dir"`
+}
+
+// WebhooksCfg configures delivery of events to webhooks. Missing values are replaced by defaults
+// of the dispatcher. Webhooks on non-public addresses get deliveries only if the addresses are
+// in allowed_networks, a list of CIDRs.
+type WebhooksCfg struct {
+	Workers     int   `json:"workers"`
+	MaxAttempts int64 `json:"max_attempts"`
+	BatchSize   int64 `json:"batch_size"`
+
+	AllowedNetworks []netip.Prefix `json:"-"`
+	PollInterval    time.Duration  `json:"-"`
+	Timeout         time.Duration  `json:"-"`
+	BaseBackoff     time.Duration  `json:"-"`
+	MaxBackoff      time.Duration  `json:"-"`
+}
+
+func (c *WebhooksCfg) UnmarshalJSON(data []byte) error {
+	type Alias WebhooksCfg
+	aux := &struct {
+		PollInterval string `json:"poll_interval"`
+		Timeout      string `json:"timeout"`
+		BaseBackoff  string `json:"base_backoff"`
+		MaxBackoff   string `json:"max_backoff"`
+
+		AllowedNetworks []string `json:"allowed_networks"`
+		*Alias
+	}{
+		Alias: (*Alias)(c),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+
+	for _, network := range aux.AllowedNetworks {
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return fmt.Errorf("parse allowed network: %w", err)
+		}
+
+		c.AllowedNetworks = append(c.AllowedNetworks, prefix)
+	}
+
+	durations := []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"poll_interval", aux.PollInterval, &c.PollInterval},
+		{"timeout", aux.Timeout, &c.Timeout},
+		{"base_backoff", aux.BaseBackoff, &c.BaseBackoff},
+		{"max_backoff", aux.MaxBackoff, &c.MaxBackoff},
+	}
+
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+
+		duration, err := time.ParseDuration(d.value)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", d.name, err)
+		}
+
+		*d.dst = duration
+	}
+
+	return nil
+}
+
+// CacheCfg enables the storage cache, a missing section disables it. Missing values are replaced
+// by defaults of the cache.
+type CacheCfg struct {
+	Size int `json:"size"`
+
+	TTL time.Duration `json:"-"`
+}
+
+func (c *CacheCfg) UnmarshalJSON(data []byte) error {
+	type Alias CacheCfg
+	aux := &struct {
+		TTL string `json:"ttl"`
+		*Alias
+	}{
+		Alias: (*Alias)(c),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+
+	if aux.TTL == "" {
+		return nil
+	}
+
+	duration, err := time.ParseDuration(aux.TTL)
+	if err != nil {
+		return fmt.Errorf("parse ttl: %w", err)
+	}
+
+	c.TTL = duration
+
+	return nil
+}
+
 type DBCfg struct {
 	UserName    string `json:"username"`
 	Password    string `json:"password"`
@@ -73,10 +309,23 @@ type DBCfg struct {
 	VirtualHost string `json:"virtual_host"`
 
 	HostName string `json:"host"`
+
+	// SSLMode is a libpq sslmode, it defaults to disable. SSLRootCert is the CA verifying the server
+	// in the verify-ca and verify-full modes.
+	SSLMode     string `json:"sslmode"`
+	SSLRootCert string `json:"sslrootcert"`
 }
 
 type HTTPClientConfig struct {
 	Address string `json:"address"`
+	APIKey  string `json:"api_key"`
+
+	// GRPCAddress is the address of the gRPC server, the integration tests call it besides the HTTP API.
+	GRPCAddress string `json:"grpc_address"`
+
+	// WebhookAddress is the address the integration tests receive deliveries of webhooks on.
+	// It must be reachable from the server.
+	WebhookAddress string `json:"webhook_address"`
 
 	Timeout time.Duration `json:"-"`
 }
@@ -106,6 +355,7 @@ func (c *HTTPClientConfig) UnmarshalJSON(data []byte) error {
 
 type CLIClientConfig struct {
 	SocketPath string        `json:"socket_path"`
+	APIKey     string        `json:"api_key"`
 	Timeout    time.Duration `json:"-"`
 }
 
@@ -143,6 +393,26 @@ func GetForServer() (*ServerConfig, error) {
 		return nil, fmt.Errorf("read config: %w", err)
 	}
 
+	if cfg.Auth == nil {
+		cfg.Auth = &AuthCfg{DefaultTenant: defaultTenant}
+	}
+
+	if cfg.GraphQL == nil {
+		cfg.GraphQL = &GraphQLCfg{MaxDepth: defaultGraphQLMaxDepth, MaxComplexity: defaultGraphQLMaxComplexity}
+	}
+
+	if cfg.Blobs == nil || cfg.Blobs.Dir == "" {
+		cfg.Blobs = &BlobsCfg{Dir: path.Join(envs.RootDir, "blobs")}
+	}
+
+	if cfg.Files == nil || cfg.Files.Dir == "" {
+		cfg.Files = &BlobsCfg{Dir: path.Join(envs.RootDir, "files")}
+	}
+
+	if cfg.Webhooks == nil {
+		cfg.Webhooks = new(WebhooksCfg)
+	}
+
 	cfg.MigrationsPath = path.Join(envs.RootDir, envs.MigrationsPath)
 	// cfg.MigrationsPath = envs.MigrationsPath
 
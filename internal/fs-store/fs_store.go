@@ -0,0 +1,120 @@
+package fsstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// Config contains data for constructing the store.
+type Config struct {
+	Dir string
+}
+
+// Store keeps blobs as files in a local directory.
+type Store struct {
+	dir string
+}
+
+// New constructs a new store and creates its directory.
+func New(cfg Config) (*Store, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("directory is empty")
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create directory: %w", err)
+	}
+
+	return &Store{dir: cfg.Dir}, nil
+}
+
+// Put writes the blob into a temporary file and renames it, so readers never see a partial blob.
+func (s *Store) Put(_ context.Context, key string, r io.Reader) (err error) {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return bm.NewInternalError("create directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return bm.NewInternalError("create file: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = bm.HandleErrPair(os.Remove(file.Name()), err)
+		}
+	}()
+
+	if _, err = io.Copy(file, r); err != nil {
+		return bm.HandleErrPair(file.Close(), bm.NewInternalError("write file: %w", err))
+	}
+
+	if err = file.Close(); err != nil {
+		return bm.NewInternalError("close file: %w", err)
+	}
+
+	if err = os.Rename(file.Name(), name); err != nil {
+		return bm.NewInternalError("rename file: %w", err)
+	}
+
+	return nil
+}
+
+// Get opens the blob file.
+func (s *Store) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, bm.NewNotFoundError("blob %s not found", key)
+
+	case err != nil:
+		return nil, bm.NewInternalError("open file: %w", err)
+
+	default:
+		return file, nil
+	}
+}
+
+// Delete removes blob files.
+func (s *Store) Delete(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		name, err := s.path(key)
+		if err != nil {
+			return err
+		}
+
+		if err = os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return bm.NewInternalError("remove file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// path maps a key to a file inside the store directory.
+func (s *Store) path(key string) (string, error) {
+	if key == "" || path.IsAbs(key) || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return "", bm.NewValidationError("incorrect blob key %q", key)
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,39 @@
+package fsstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(Config{Dir: t.TempDir()})
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Put(ctx, "covers/a", strings.NewReader("first")))
+	assert.NoError(t, s.Put(ctx, "covers/a", strings.NewReader("second")))
+
+	r, err := s.Get(ctx, "covers/a")
+	assert.NoError(t, err)
+
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, "second", string(data))
+
+	assert.NoError(t, s.Delete(ctx, "covers/a", "covers/missing"))
+
+	_, err = s.Get(ctx, "covers/a")
+	assert.True(t, errors.As(err, &bm.NotFoundError{}))
+
+	for _, key := range []string{"", "/etc/passwd", "../a", "covers/../../a", "covers//a"} {
+		assert.Error(t, s.Put(ctx, key, strings.NewReader("x")))
+	}
+}
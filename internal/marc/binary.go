@@ -0,0 +1,197 @@
+package marc
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// Delimiters of ISO 2709 records.
+const (
+	subfieldDelimiter = 0x1f
+	fieldTerminator   = 0x1e
+	recordTerminator  = 0x1d
+)
+
+const (
+	leaderLen         = 24
+	directoryEntryLen = 12
+	maxFieldLen       = 9999
+	maxRecordLen      = 99999
+)
+
+// DecodeBinary decodes records in MARC 21 transmission format, ISO 2709. Line breaks between records are skipped.
+// Data is expected in UTF-8, MARC-8 records are read correctly only if they are ASCII.
+func DecodeBinary(data []byte) ([]Record, error) {
+	var records []Record
+	for n := 1; ; n++ {
+		data = bytes.TrimLeft(data, "\r\n")
+		if len(data) == 0 {
+			break
+		}
+
+		if len(data) < leaderLen {
+			return nil, bm.NewValidationError("incorrect marc record %d: leader is too short", n)
+		}
+
+		length, err := strconv.Atoi(string(data[:5]))
+		if err != nil || length <= leaderLen || length > len(data) {
+			return nil, bm.NewValidationError("incorrect marc record %d: incorrect record length %q", n, data[:5])
+		}
+
+		r, err := decodeBinaryRecord(data[:length])
+		if err != nil {
+			return nil, bm.NewValidationError("incorrect marc record %d: %w", n, err)
+		}
+
+		records = append(records, r)
+		data = data[length:]
+	}
+
+	if len(records) == 0 {
+		return nil, bm.NewValidationError("marc file has no records")
+	}
+
+	return records, nil
+}
+
+func decodeBinaryRecord(data []byte) (Record, error) {
+	if data[len(data)-1] != recordTerminator {
+		return Record{}, fmt.Errorf("record terminator is missing")
+	}
+
+	r := Record{Leader: string(data[:leaderLen])}
+	base, err := strconv.Atoi(r.Leader[12:17])
+	if err != nil || base <= leaderLen || base > len(data) || data[base-1] != fieldTerminator {
+		return Record{}, fmt.Errorf("incorrect base address of data %q", r.Leader[12:17])
+	}
+
+	directory := data[leaderLen : base-1]
+	if len(directory)%directoryEntryLen != 0 {
+		return Record{}, fmt.Errorf("incorrect directory length %d", len(directory))
+	}
+
+	for i := 0; i < len(directory); i += directoryEntryLen {
+		entry := directory[i : i+directoryEntryLen]
+		tag := string(entry[:3])
+
+		length, lengthErr := strconv.Atoi(string(entry[3:7]))
+		start, startErr := strconv.Atoi(string(entry[7:12]))
+		// Atoi accepts signs, so a negative start or length must be rejected explicitly.
+		if lengthErr != nil || startErr != nil || length < 1 || length > maxFieldLen || start < 0 ||
+			base+start+length > len(data)-1 {
+			return Record{}, fmt.Errorf("incorrect directory entry %q", entry)
+		}
+
+		fieldData := data[base+start : base+start+length]
+		if fieldData[length-1] != fieldTerminator {
+			return Record{}, fmt.Errorf("field %s: field terminator is missing", tag)
+		}
+
+		f, err := decodeBinaryField(tag, fieldData[:length-1])
+		if err != nil {
+			return Record{}, fmt.Errorf("field %s: %w", tag, err)
+		}
+
+		r.Fields = append(r.Fields, f)
+	}
+
+	return r, nil
+}
+
+func decodeBinaryField(tag string, data []byte) (Field, error) {
+	f := Field{Tag: tag}
+	if f.IsControl() {
+		f.Value = string(data)
+
+		return f, nil
+	}
+
+	if len(data) < 2 {
+		return Field{}, fmt.Errorf("indicators are missing")
+	}
+
+	f.Ind1, f.Ind2 = data[0], data[1]
+
+	// Anything between indicators and the first subfield is not a subfield, so it is skipped.
+	parts := bytes.Split(data[2:], []byte{subfieldDelimiter})
+	for _, p := range parts[1:] {
+		if len(p) == 0 {
+			continue
+		}
+
+		f.Subfields = append(f.Subfields, Subfield{Code: p[0], Value: string(p[1:])})
+	}
+
+	return f, nil
+}
+
+// EncodeBinary encodes records in MARC 21 transmission format with UTF-8 data.
+func EncodeBinary(records []Record) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	for i, r := range records {
+		if err := encodeBinaryRecord(buf, r); err != nil {
+			return nil, bm.NewValidationError("encode marc record %d: %w", i+1, err)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func encodeBinaryRecord(buf *bytes.Buffer, r Record) error {
+	directory, data := new(bytes.Buffer), new(bytes.Buffer)
+	for _, f := range r.Fields {
+		if len(f.Tag) != 3 {
+			return fmt.Errorf("incorrect tag %q", f.Tag)
+		}
+
+		start := data.Len()
+		if f.IsControl() {
+			data.WriteString(f.Value)
+		} else {
+			data.WriteByte(indicator(f.Ind1))
+			data.WriteByte(indicator(f.Ind2))
+			for _, sf := range f.Subfields {
+				data.WriteByte(subfieldDelimiter)
+				data.WriteByte(sf.Code)
+				data.WriteString(sf.Value)
+			}
+		}
+
+		data.WriteByte(fieldTerminator)
+
+		length := data.Len() - start
+		if length > maxFieldLen {
+			return fmt.Errorf("field %s is longer than %d bytes", f.Tag, maxFieldLen)
+		}
+
+		fmt.Fprintf(directory, "%s%04d%05d", f.Tag, length, start)
+	}
+
+	directory.WriteByte(fieldTerminator)
+
+	base := leaderLen + directory.Len()
+	length := base + data.Len() + 1
+	if length > maxRecordLen {
+		return fmt.Errorf("record is longer than %d bytes", maxRecordLen)
+	}
+
+	leader := []byte(defaultLeader)
+	if len(r.Leader) == leaderLen {
+		leader = []byte(r.Leader)
+	}
+
+	copy(leader[0:5], fmt.Sprintf("%05d", length))
+	copy(leader[9:12], "a22")
+	copy(leader[12:17], fmt.Sprintf("%05d", base))
+	copy(leader[20:24], "4500")
+
+	buf.Write(leader)
+	buf.Write(directory.Bytes())
+	buf.Write(data.Bytes())
+	buf.WriteByte(recordTerminator)
+
+	return nil
+}
@@ -0,0 +1,274 @@
+package marc
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// relatorRoles maps relator terms and codes of name fields to contributor roles.
+var relatorRoles = map[string]string{
+	"author":      bm.RoleAuthor,
+	"aut":         bm.RoleAuthor,
+	"editor":      bm.RoleEditor,
+	"edt":         bm.RoleEditor,
+	"translator":  bm.RoleTranslator,
+	"trl":         bm.RoleTranslator,
+	"illustrator": bm.RoleIllustrator,
+	"ill":         bm.RoleIllustrator,
+}
+
+var yearPattern = regexp.MustCompile(`\d{4}`)
+
+// ToBook maps a record to a book: 020 ISBN, 100 and 700 contributors, 245 title, 250 edition,
+// 264 or 260 publication year, 520 summary, the first 650 subject becomes the genre and other subjects
+// together with 653 index terms become tags. ISBN and tags are taken as is.
+// Fields and subfields which have no place in a book are returned as tags, like 001, and as tag$code, like 245$c.
+func ToBook(r Record) (bm.Book, []string) {
+	var b bm.Book
+	u := new(unmapped)
+	for _, f := range r.Fields {
+		switch f.Tag {
+		case "020":
+			u.subfields(f, 'a')
+			if b.ISBN == "" {
+				// Qualifiers may follow the ISBN, like 0140449132 (pbk.).
+				b.ISBN, _, _ = strings.Cut(strings.TrimSpace(f.First('a')), " ")
+			}
+
+		case "100", "700":
+			if c, ok := contributor(f, u); ok {
+				b.Contributors = append(b.Contributors, c)
+			}
+
+		case "245":
+			u.subfields(f, 'a', 'b')
+			b.Title = trimPunctuation(f.First('a'))
+			if subtitle := trimPunctuation(f.First('b')); subtitle != "" {
+				b.Title += ": " + subtitle
+			}
+
+		case "250":
+			u.subfields(f, 'a')
+			b.Edition = trimISBD(f.First('a'))
+
+		case "260", "264":
+			// Only the publication statement of 264 has the date of publication, not copyright or manufacture.
+			if f.Tag == "264" && f.Ind2 != '1' {
+				u.field(f.Tag)
+
+				continue
+			}
+
+			u.subfields(f, 'c')
+			if year := yearPattern.FindString(f.First('c')); year != "" && b.PublishedDate.IsZero() {
+				y, _ := strconv.Atoi(year)
+				b.PublishedDate = time.Date(y, time.January, 1, 0, 0, 0, 0, time.UTC)
+			}
+
+		case "520":
+			u.subfields(f, 'a')
+			if summary := strings.TrimSpace(f.First('a')); b.Description == "" {
+				b.Description = summary
+			} else if summary != "" {
+				b.Description += "\n\n" + summary
+			}
+
+		case "650":
+			u.subfields(f, 'a')
+			if subject := trimPunctuation(f.First('a')); b.Genre == "" {
+				b.Genre = subject
+			} else if subject != "" {
+				b.Tags = append(b.Tags, subject)
+			}
+
+		case "653":
+			u.subfields(f, 'a')
+			for _, term := range f.Values('a') {
+				if term = trimISBD(term); term != "" {
+					b.Tags = append(b.Tags, term)
+				}
+			}
+
+		default:
+			u.field(f.Tag)
+		}
+	}
+
+	return b, u.list
+}
+
+// contributor maps a name field to a contributor. Fields without a relator are authors,
+// fields with an unknown relator are reported as unmapped.
+func contributor(f Field, u *unmapped) (bm.Contributor, bool) {
+	u.subfields(f, 'a', 'e', '4')
+
+	name := trimPunctuation(f.First('a'))
+	if f.Ind1 == '1' {
+		name = forenameFirst(name)
+	}
+
+	role := bm.RoleAuthor
+	if relator := f.First('e') + f.First('4'); relator != "" {
+		var ok bool
+		for _, code := range []byte{'e', '4'} {
+			if role, ok = relatorRoles[strings.ToLower(trimPunctuation(f.First(code)))]; ok {
+				break
+			}
+		}
+
+		if !ok {
+			u.field(f.Tag)
+
+			return bm.Contributor{}, false
+		}
+	}
+
+	if name == "" {
+		return bm.Contributor{}, false
+	}
+
+	return bm.Contributor{Name: name, Role: role}, true
+}
+
+// FromBook maps a book to a record. The id of the book becomes the control number.
+func FromBook(b bm.Book) Record {
+	r := Record{Leader: defaultLeader}
+	if b.ID != 0 {
+		r.Fields = append(r.Fields, Field{Tag: "001", Value: strconv.FormatInt(b.ID, 10)})
+	}
+
+	if b.ISBN != "" {
+		r.Fields = append(r.Fields, dataField("020", ' ', ' ', Subfield{'a', b.ISBN}))
+	}
+
+	contributors := b.Contributors
+	if len(contributors) == 0 && b.Author != "" {
+		contributors = []bm.Contributor{{Name: b.Author, Role: bm.RoleAuthor}}
+	}
+
+	// The first author is the main entry, other contributors are added entries.
+	mainEntry := false
+	var added []Field
+	for _, c := range contributors {
+		f := dataField("700", '0', ' ', Subfield{'a', c.Name}, Subfield{'e', c.Role})
+		if !mainEntry && c.Role == bm.RoleAuthor {
+			f.Tag, mainEntry = "100", true
+			r.Fields = append(r.Fields, f)
+
+			continue
+		}
+
+		added = append(added, f)
+	}
+
+	title, subtitle, _ := strings.Cut(b.Title, ": ")
+	titleInd1 := byte('0')
+	if mainEntry {
+		titleInd1 = '1'
+	}
+
+	f := dataField("245", titleInd1, '0', Subfield{'a', title})
+	if subtitle != "" {
+		f = dataField("245", titleInd1, '0', Subfield{'a', title + " :"}, Subfield{'b', subtitle})
+	}
+
+	r.Fields = append(r.Fields, f)
+
+	if b.Edition != "" {
+		r.Fields = append(r.Fields, dataField("250", ' ', ' ', Subfield{'a', b.Edition}))
+	}
+
+	if !b.PublishedDate.IsZero() {
+		r.Fields = append(r.Fields, dataField("264", ' ', '1', Subfield{'c', strconv.Itoa(b.PublishedDate.Year())}))
+	}
+
+	if b.Description != "" {
+		r.Fields = append(r.Fields, dataField("520", ' ', ' ', Subfield{'a', b.Description}))
+	}
+
+	if b.Genre != "" {
+		r.Fields = append(r.Fields, dataField("650", ' ', '4', Subfield{'a', b.Genre}))
+	}
+
+	for _, tag := range b.Tags {
+		r.Fields = append(r.Fields, dataField("653", ' ', ' ', Subfield{'a', tag}))
+	}
+
+	r.Fields = append(r.Fields, added...)
+
+	return r
+}
+
+// dataField builds a data field. Subfields with empty values are skipped.
+func dataField(tag string, ind1, ind2 byte, subfields ...Subfield) Field {
+	f := Field{Tag: tag, Ind1: ind1, Ind2: ind2}
+	for _, sf := range subfields {
+		if sf.Value != "" {
+			f.Subfields = append(f.Subfields, sf)
+		}
+	}
+
+	return f
+}
+
+// unmapped collects fields and subfields which have no place in a book, without duplicates.
+type unmapped struct {
+	list []string
+	seen map[string]bool
+}
+
+func (u *unmapped) field(name string) {
+	if u.seen == nil {
+		u.seen = make(map[string]bool)
+	}
+
+	if !u.seen[name] {
+		u.seen[name] = true
+		u.list = append(u.list, name)
+	}
+}
+
+// subfields reports subfields of a field except the mapped ones.
+func (u *unmapped) subfields(f Field, mapped ...byte) {
+	for _, sf := range f.Subfields {
+		if !strings.ContainsRune(string(mapped), rune(sf.Code)) {
+			u.field(f.Tag + "$" + string(sf.Code))
+		}
+	}
+}
+
+// trimISBD removes ISBD punctuation which ends subfields, like "Crime and punishment /".
+func trimISBD(s string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(s), " /:;,="))
+}
+
+// trimPunctuation removes ISBD punctuation and the final period of titles, names and subjects.
+// Periods of initials and ellipses are kept.
+func trimPunctuation(s string) string {
+	s = trimISBD(s)
+	if !strings.HasSuffix(s, ".") || strings.HasSuffix(s, "..") {
+		return s
+	}
+
+	// The last word of "Salinger, J. D." is an initial.
+	if lastWord := s[strings.LastIndexAny(s[:len(s)-1], " ,.")+1:]; utf8.RuneCountInString(lastWord) <= 2 {
+		return s
+	}
+
+	return strings.TrimSuffix(s, ".")
+}
+
+// forenameFirst turns an inverted name, "Dostoyevsky, Fyodor", into "Fyodor Dostoyevsky".
+func forenameFirst(name string) string {
+	surname, forename, ok := strings.Cut(name, ",")
+	if !ok || strings.Contains(forename, ",") {
+		return name
+	}
+
+	return strings.TrimSpace(strings.TrimSpace(forename) + " " + strings.TrimSpace(surname))
+}
@@ -0,0 +1,88 @@
+package marc
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Formats of MARC files.
+const (
+	FormatMARC21  = "marc21"
+	FormatMARCXML = "marcxml"
+)
+
+// defaultLeader is the leader of new records: a new record of a monograph in Unicode with ISBD punctuation.
+// Record length and base address of data are filled in by encoders.
+const defaultLeader = "00000nam a2200000 i 4500"
+
+// Record is a MARC 21 bibliographic record. Control fields are kept before data fields.
+type Record struct {
+	Leader string
+	Fields []Field
+}
+
+// Field is a control field with a value or a data field with indicators and subfields.
+type Field struct {
+	Tag       string
+	Value     string
+	Ind1      byte
+	Ind2      byte
+	Subfields []Subfield
+}
+
+// Subfield is a subfield of a data field.
+type Subfield struct {
+	Code  byte
+	Value string
+}
+
+// IsControl reports whether the field is a control field, 001-009.
+func (f Field) IsControl() bool {
+	return strings.HasPrefix(f.Tag, "00")
+}
+
+// Values returns values of subfields with the code.
+func (f Field) Values(code byte) []string {
+	var values []string
+	for _, sf := range f.Subfields {
+		if sf.Code == code {
+			values = append(values, sf.Value)
+		}
+	}
+
+	return values
+}
+
+// First returns the value of the first subfield with the code.
+func (f Field) First(code byte) string {
+	for _, sf := range f.Subfields {
+		if sf.Code == code {
+			return sf.Value
+		}
+	}
+
+	return ""
+}
+
+// Decode detects the format of MARC data and decodes its records.
+func Decode(data []byte) (string, []Record, error) {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\ufeff")), " \t\r\n")
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		records, err := DecodeXML(trimmed)
+
+		return FormatMARCXML, records, err
+	}
+
+	records, err := DecodeBinary(data)
+
+	return FormatMARC21, records, err
+}
+
+// indicator replaces an unset indicator with a blank.
+func indicator(b byte) byte {
+	if b == 0 {
+		return ' '
+	}
+
+	return b
+}
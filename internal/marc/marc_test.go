@@ -0,0 +1,189 @@
+package marc
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+var fixtureBooks = []struct {
+	book     bm.Book
+	unmapped []string
+}{
+	{
+		book: bm.Book{
+			Title:         "Crime and punishment",
+			PublishedDate: time.Date(1993, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Edition:       "1st Vintage classics ed.",
+			Description:   "Raskolnikov, a destitute student in Saint Petersburg, commits a murder and struggles with guilt.",
+			Genre:         "Murder",
+			ISBN:          "0679734503",
+			Contributors: []bm.Contributor{
+				{Name: "Fyodor Dostoyevsky", Role: bm.RoleAuthor},
+				{Name: "Richard Pevear", Role: bm.RoleTranslator},
+			},
+			Tags: []string{"Psychological fiction"},
+		},
+		unmapped: []string{"001", "005", "008", "020$q", "100$d", "240", "245$c", "264$a", "264$b", "264", "650$z", "650$v", "700$d"},
+	},
+	{
+		book: bm.Book{
+			Title:         "The catcher in the rye: a novel",
+			PublishedDate: time.Date(1951, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Contributors:  []bm.Contributor{{Name: "J. D. Salinger", Role: bm.RoleAuthor}},
+			Tags:          []string{"Coming of age", "Classics"},
+		},
+		unmapped: []string{"001", "260$a", "260$b"},
+	},
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestDecode(t *testing.T) {
+	binary := readFixture(t, "books.mrc")
+	format, binaryRecords, err := Decode(binary)
+	require.NoError(t, err)
+	assert.Equal(t, FormatMARC21, format)
+
+	format, xmlRecords, err := Decode(readFixture(t, "books.xml"))
+	require.NoError(t, err)
+	assert.Equal(t, FormatMARCXML, format)
+
+	require.Len(t, binaryRecords, len(fixtureBooks))
+	require.Len(t, xmlRecords, len(fixtureBooks))
+	for i, want := range fixtureBooks {
+		// Leaders of the XML fixture don't have record lengths.
+		assert.Equal(t, binaryRecords[i].Fields, xmlRecords[i].Fields)
+
+		book, unmapped := ToBook(binaryRecords[i])
+		assert.Equal(t, want.book, book)
+		assert.Equal(t, want.unmapped, unmapped)
+	}
+
+	// Records are encoded back into the same bytes.
+	encoded, err := EncodeBinary(binaryRecords)
+	require.NoError(t, err)
+	assert.Equal(t, binary, encoded)
+
+	encoded, err = EncodeXML(xmlRecords)
+	require.NoError(t, err)
+
+	decoded, err := DecodeXML(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, xmlRecords, decoded)
+}
+
+func TestBookRoundTrip(t *testing.T) {
+	books := []bm.Book{
+		{
+			ID:            7,
+			Title:         "The Master and Margarita: a novel",
+			PublishedDate: time.Date(1967, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Edition:       "2nd ed.",
+			Description:   "The devil visits Moscow.\n\nA satire of Soviet life.",
+			Genre:         "Fiction",
+			ISBN:          "9780141180144",
+			Contributors: []bm.Contributor{
+				{Name: "Michael Glenny", Role: bm.RoleTranslator},
+				{Name: "Mikhail Bulgakov", Role: bm.RoleAuthor},
+				{Name: "Jane Doe", Role: bm.RoleIllustrator},
+				{Name: "John Smith", Role: bm.RoleEditor},
+			},
+			Tags: []string{"satire", "classics"},
+		},
+		{
+			ID:           8,
+			Title:        "An anthology",
+			Contributors: []bm.Contributor{{Name: "John Smith", Role: bm.RoleEditor}},
+			Genre:        "Poetry",
+		},
+	}
+
+	records := make([]Record, 0, len(books))
+	for _, b := range books {
+		records = append(records, FromBook(b))
+	}
+
+	binary, err := EncodeBinary(records)
+	require.NoError(t, err)
+
+	xml, err := EncodeXML(records)
+	require.NoError(t, err)
+
+	for _, data := range [][]byte{binary, xml} {
+		_, decoded, err := Decode(data)
+		require.NoError(t, err)
+		require.Len(t, decoded, len(books))
+
+		for i, want := range books {
+			got, unmapped := ToBook(decoded[i])
+			assert.Equal(t, []string{"001"}, unmapped)
+			assert.Equal(t, want.Title, got.Title)
+			assert.Equal(t, want.PublishedDate, got.PublishedDate)
+			assert.Equal(t, want.Edition, got.Edition)
+			assert.Equal(t, want.Description, got.Description)
+			assert.Equal(t, want.Genre, got.Genre)
+			assert.Equal(t, want.ISBN, got.ISBN)
+			assert.ElementsMatch(t, want.Contributors, got.Contributors)
+			assert.Equal(t, want.Tags, got.Tags)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	binary := readFixture(t, "books.mrc")
+	corrupted := append([]byte(nil), binary...)
+	corrupted[30] = 'x'
+
+	// A record of 45 bytes with a single field starting at a negative offset.
+	negativeStart := []byte(fmt.Sprintf("%05dnam a22%05d   4500", 45, 37) + "2450007-0050\x1e" + "10\x1fabc\x1e\x1d")
+
+	tests := []struct {
+		name string
+		give []byte
+	}{
+		{name: "empty", give: nil},
+		{name: "negative field start", give: negativeStart},
+		{name: "short leader", give: binary[:10]},
+		{name: "truncated record", give: binary[:len(binary)-1]},
+		{name: "incorrect directory", give: corrupted},
+		{name: "not marc", give: []byte("title,author\n")},
+		{name: "xml without records", give: []byte(`<collection xmlns="http://www.loc.gov/MARC21/slim"/>`)},
+		{name: "incorrect xml", give: []byte(`<collection><record><leader>`)},
+	}
+	for _, tt := range tests {
+		_, _, err := Decode(tt.give)
+		assert.Error(t, err, tt.name)
+	}
+
+	_, err := DecodeBinary(negativeStart)
+	assert.Error(t, err)
+}
+
+func TestTrimPunctuation(t *testing.T) {
+	tests := []struct {
+		give string
+		want string
+	}{
+		{give: "Crime and punishment /", want: "Crime and punishment"},
+		{give: "Dostoyevsky, Fyodor,", want: "Dostoyevsky, Fyodor"},
+		{give: "Psychological fiction.", want: "Psychological fiction"},
+		{give: "Salinger, J. D.", want: "Salinger, J. D."},
+		{give: "And then...", want: "And then..."},
+		{give: " Boston : ", want: "Boston"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, trimPunctuation(tt.give), tt.give)
+	}
+}
@@ -0,0 +1,140 @@
+package marc
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+const xmlNamespace = "http://www.loc.gov/MARC21/slim"
+
+type xmlCollection struct {
+	XMLName xml.Name    `xml:"collection"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Records []xmlRecord `xml:"record"`
+}
+
+type xmlRecord struct {
+	Leader        string            `xml:"leader"`
+	ControlFields []xmlControlField `xml:"controlfield"`
+	DataFields    []xmlDataField    `xml:"datafield"`
+}
+
+type xmlControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlDataField struct {
+	Tag       string        `xml:"tag,attr"`
+	Ind1      string        `xml:"ind1,attr"`
+	Ind2      string        `xml:"ind2,attr"`
+	Subfields []xmlSubfield `xml:"subfield"`
+}
+
+type xmlSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// DecodeXML decodes MARCXML records. Both a collection of records and a single record are accepted,
+// with or without the MARCXML namespace.
+func DecodeXML(data []byte) ([]Record, error) {
+	var records []Record
+
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, bm.NewValidationError("incorrect marcxml: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+
+		var xr xmlRecord
+		if err = d.DecodeElement(&xr, &start); err != nil {
+			return nil, bm.NewValidationError("incorrect marcxml record %d: %w", len(records)+1, err)
+		}
+
+		records = append(records, xr.record())
+	}
+
+	if len(records) == 0 {
+		return nil, bm.NewValidationError("marcxml has no records")
+	}
+
+	return records, nil
+}
+
+func (xr xmlRecord) record() Record {
+	r := Record{Leader: xr.Leader}
+	for _, cf := range xr.ControlFields {
+		r.Fields = append(r.Fields, Field{Tag: cf.Tag, Value: cf.Value})
+	}
+
+	for _, df := range xr.DataFields {
+		f := Field{Tag: df.Tag, Ind1: xmlIndicator(df.Ind1), Ind2: xmlIndicator(df.Ind2)}
+		for _, sf := range df.Subfields {
+			if sf.Code != "" {
+				f.Subfields = append(f.Subfields, Subfield{Code: sf.Code[0], Value: sf.Value})
+			}
+		}
+
+		r.Fields = append(r.Fields, f)
+	}
+
+	return r
+}
+
+func xmlIndicator(s string) byte {
+	if s == "" {
+		return ' '
+	}
+
+	return s[0]
+}
+
+// EncodeXML encodes records as a MARCXML collection.
+func EncodeXML(records []Record) ([]byte, error) {
+	c := xmlCollection{Xmlns: xmlNamespace}
+	for _, r := range records {
+		xr := xmlRecord{Leader: r.Leader}
+		if len(xr.Leader) != leaderLen {
+			xr.Leader = defaultLeader
+		}
+
+		for _, f := range r.Fields {
+			if f.IsControl() {
+				xr.ControlFields = append(xr.ControlFields, xmlControlField{Tag: f.Tag, Value: f.Value})
+
+				continue
+			}
+
+			df := xmlDataField{Tag: f.Tag, Ind1: string(indicator(f.Ind1)), Ind2: string(indicator(f.Ind2))}
+			for _, sf := range f.Subfields {
+				df.Subfields = append(df.Subfields, xmlSubfield{Code: string(sf.Code), Value: sf.Value})
+			}
+
+			xr.DataFields = append(xr.DataFields, df)
+		}
+
+		c.Records = append(c.Records, xr)
+	}
+
+	data, err := xml.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, bm.NewInternalError("marshal marcxml: %w", err)
+	}
+
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
@@ -0,0 +1,237 @@
+// Package openapi contains the OpenAPI document of the HTTP API and validates traffic against it.
+package openapi
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// Version is a version of the API.
+type Version int
+
+const (
+	V1 Version = 1
+	V2 Version = 2
+)
+
+// EventStreamContentType is the content type of streams of server-sent events.
+const EventStreamContentType = "text/event-stream"
+
+// Versions are all served versions of the API.
+var Versions = []Version{V1, V2}
+
+//go:embed openapi.yaml
+var spec []byte
+
+// v1Properties are properties of v2 schemas which had other names in v1.
+var v1Properties = map[string]map[string]string{
+	"Collection":               {"description": "decription"},
+	"CreateCollectionReq":      {"description": "decription"},
+	"UpdateCollectionReq":      {"description": "decription"},
+	"DeleteBooksCollectionReq": {"book_ids": "books_ids"},
+}
+
+// v2Properties are properties of v2 schemas which v1 lacks.
+var v2Properties = map[string][]string{
+	"Collection": {"updated_at"},
+}
+
+// Prefix is the path prefix of the version.
+func (v Version) Prefix() string {
+	return "/api/v" + strconv.Itoa(int(v))
+}
+
+// Load returns the document of the version. The document is written for v2, v1 differs from it by names of
+// a few properties and lacks a few others.
+func Load(v Version) (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("load document: %w", err)
+	}
+
+	switch v {
+	case V1:
+		for name, properties := range v1Properties {
+			schemaRef, ok := doc.Components.Schemas[name]
+			if !ok {
+				return nil, fmt.Errorf("schema %s is not found", name)
+			}
+
+			renameProperties(schemaRef.Value, properties)
+		}
+
+		for name, properties := range v2Properties {
+			schemaRef, ok := doc.Components.Schemas[name]
+			if !ok {
+				return nil, fmt.Errorf("schema %s is not found", name)
+			}
+
+			for _, property := range properties {
+				delete(schemaRef.Value.Properties, property)
+			}
+		}
+
+	case V2:
+
+	default:
+		return nil, fmt.Errorf("unknown version %d", v)
+	}
+
+	doc.Info.Version = strconv.Itoa(int(v))
+	doc.Servers = openapi3.Servers{{URL: v.Prefix()}}
+
+	if err = doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("validate document: %w", err)
+	}
+
+	return doc, nil
+}
+
+func renameProperties(schema *openapi3.Schema, names map[string]string) {
+	for name, v1Name := range names {
+		if property, ok := schema.Properties[name]; ok {
+			delete(schema.Properties, name)
+			schema.Properties[v1Name] = property
+		}
+
+		for i, required := range schema.Required {
+			if required == name {
+				schema.Required[i] = v1Name
+			}
+		}
+	}
+}
+
+// Validator checks requests and responses against the document of a version.
+type Validator struct {
+	router  routers.Router
+	options *openapi3filter.Options
+}
+
+func NewValidator(v Version) (*Validator, error) {
+	doc, err := Load(v)
+	if err != nil {
+		return nil, err
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("create router: %w", err)
+	}
+
+	return &Validator{
+		router: router,
+		options: &openapi3filter.Options{
+			MultiError:         true,
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	}, nil
+}
+
+// ValidateRequest checks the request. The body of the request is kept for its handler.
+func (v *Validator) ValidateRequest(r *http.Request) (*openapi3filter.RequestValidationInput, error) {
+	route, pathParams, err := v.router.FindRoute(r)
+	if err != nil {
+		return nil, fmt.Errorf("find route of %s %s: %w", r.Method, r.URL.Path, err)
+	}
+
+	input := &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    v.options,
+	}
+
+	if err = openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+		return nil, fmt.Errorf("%s %s: %w", r.Method, r.URL.Path, err)
+	}
+
+	return input, nil
+}
+
+// ValidateResponse checks the response to the validated request. The body of the response is kept for its reader.
+// Event streams don't end, so only their status and headers are checked.
+func (v *Validator) ValidateResponse(ctx context.Context, input *openapi3filter.RequestValidationInput, resp *http.Response) error {
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == EventStreamContentType {
+		options := *v.options
+		options.ExcludeResponseBody = true
+
+		err := openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 resp.StatusCode,
+			Header:                 resp.Header,
+			Options:                &options,
+		})
+		if err != nil {
+			return fmt.Errorf("response %d to %s %s: %w", resp.StatusCode, input.Request.Method, input.Request.URL.Path, err)
+		}
+
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("read response body: %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	err = openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 resp.StatusCode,
+		Header:                 resp.Header,
+		Body:                   io.NopCloser(bytes.NewReader(body)),
+		Options:                v.options,
+	})
+	if err != nil {
+		return fmt.Errorf("response %d to %s %s: %w", resp.StatusCode, input.Request.Method, input.Request.URL.Path, err)
+	}
+
+	return nil
+}
+
+// Transport validates requests before sending them and responses after receiving them.
+// Any violation of the document fails the round trip, it makes tests of clients enforce the contract.
+type Transport struct {
+	Validator *Validator
+	Base      http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	// Validation replaces the body of the request, round trippers must not modify requests of callers.
+	r = r.Clone(r.Context())
+
+	input, err := t.Validator.ValidateRequest(r)
+	if err != nil {
+		return nil, fmt.Errorf("validate request: %w", err)
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = t.Validator.ValidateResponse(r.Context(), input, resp); err != nil {
+		return nil, fmt.Errorf("validate response: %w", err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,39 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	properties := func(doc *openapi3.T, schema string) []string {
+		var names []string
+		for name := range doc.Components.Schemas[schema].Value.Properties {
+			names = append(names, name)
+		}
+
+		return names
+	}
+
+	v1, err := Load(V1)
+	require.NoError(t, err)
+
+	v2, err := Load(V2)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/api/v1", v1.Servers[0].URL)
+	assert.ElementsMatch(t, []string{"id", "name", "decription"}, properties(v1, "Collection"))
+	assert.ElementsMatch(t, []string{"name", "decription"}, properties(v1, "UpdateCollectionReq"))
+	assert.ElementsMatch(t, []string{"books_ids"}, properties(v1, "DeleteBooksCollectionReq"))
+	assert.Equal(t, []string{"id", "name", "decription"}, v1.Components.Schemas["Collection"].Value.Required)
+
+	assert.Equal(t, "/api/v2", v2.Servers[0].URL)
+	assert.ElementsMatch(t, []string{"id", "name", "description", "updated_at"}, properties(v2, "Collection"))
+	assert.ElementsMatch(t, []string{"book_ids"}, properties(v2, "DeleteBooksCollectionReq"))
+
+	_, err = Load(Version(3))
+	assert.EqualError(t, err, "unknown version 3")
+}
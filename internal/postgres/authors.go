@@ -0,0 +1,330 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// Author gets author by id.
+func (s *DB) Author(ctx context.Context, id int64) (*bm.Author, error) {
+	q := `SELECT id, name FROM authors WHERE id=$1 AND tenant=$2`
+
+	author := new(bm.Author)
+	err := s.GetContext(ctx, author, q, id, bm.TenantFromCtx(ctx))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, bm.NewNotFoundError("author not found: %w", err)
+
+	case err != nil:
+		return nil, bm.NewInternalError("select author: %w", err)
+
+	default:
+		return author, nil
+	}
+}
+
+// Authors gets authors by filter.
+func (s *DB) Authors(ctx context.Context, f bm.AuthorsFilter) ([]bm.Author, error) {
+	q := "SELECT a.id, a.name FROM authors a WHERE a.tenant = :tenant "
+	params := map[string]any{"tenant": bm.TenantFromCtx(ctx)}
+	if f.Name != "" {
+		q += `AND a.name ILIKE :name ESCAPE '\' `
+		params["name"] = containsPattern(f.Name)
+	}
+
+	q += orderBy("a", f.OrderBy, f.Desc)
+	q += pagination(f.Page, f.PageSize)
+
+	rows, err := s.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		return nil, bm.NewInternalError("select authors: %w", err)
+	}
+
+	defer func() {
+		err = bm.HandleErrPair(rows.Close(), err)
+	}()
+
+	var authors []bm.Author
+	if err = sqlx.StructScan(rows, &authors); err != nil {
+		return nil, bm.NewInternalError("copy data into struct: %w", err)
+	}
+
+	return authors, nil
+}
+
+func (s *DB) CreateAuthor(ctx context.Context, a bm.Author) (int64, error) {
+	q := `INSERT INTO authors (tenant, name) VALUES ($1, $2) RETURNING id`
+
+	tenant := bm.TenantFromCtx(ctx)
+
+	var id int64
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, q, tenant, a.Name).Scan(&id)
+		if isConflict(err) {
+			return bm.NewConflictError("insert author: %w", err)
+		}
+
+		if err != nil {
+			return bm.NewInternalError("insert author: %w", err)
+		}
+
+		return recordChanges(ctx, tx, tenant, bm.EntityAuthor, bm.OpCreated, []int64{id}, nil)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("execute tx: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateAuthor renames an author and refreshes author names of its books.
+func (s *DB) UpdateAuthor(ctx context.Context, a bm.Author) error {
+	tenant := bm.TenantFromCtx(ctx)
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		q := `UPDATE authors SET name = $1 WHERE id = $2 AND tenant = $3`
+		result, err := tx.ExecContext(ctx, q, a.Name, a.ID, tenant)
+		if isConflict(err) {
+			return bm.NewConflictError("update author: %w", err)
+		}
+
+		if err != nil {
+			return bm.NewInternalError("update author: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return bm.NewInternalError("get the number of affected rows: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return bm.NewNotFoundError("author with ID %d not found", a.ID)
+		}
+
+		q = `WITH u AS (
+				UPDATE books b SET author = COALESCE((
+					SELECT string_agg(a.name, ', ' ORDER BY ba.position) FROM book_authors ba
+					JOIN authors a ON a.id = ba.author_id
+					WHERE ba.book_id = b.id AND ba.role = 'author'
+				), '')
+				WHERE b.id IN (SELECT book_id FROM book_authors WHERE author_id = $1)
+				RETURNING b.id
+			)
+			SELECT COALESCE(array_agg(id), '{}') FROM u`
+
+		var bookIDs pq.Int64Array
+		err = tx.QueryRowContext(ctx, q, a.ID).Scan(&bookIDs)
+		if isConflict(err) {
+			return bm.NewConflictError("update books author: %w", err)
+		}
+
+		if err != nil {
+			return bm.NewInternalError("update books author: %w", err)
+		}
+
+		if err = recordChanges(ctx, tx, tenant, bm.EntityAuthor, bm.OpUpdated, []int64{a.ID}, nil); err != nil {
+			return err
+		}
+
+		return recordChanges(ctx, tx, tenant, bm.EntityBook, bm.OpUpdated, bookIDs, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("execute tx: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAuthor deletes an author. Authors of existing books can't be deleted.
+func (s *DB) DeleteAuthor(ctx context.Context, id int64) error {
+	q := `DELETE FROM authors WHERE id = $1 AND tenant = $2`
+
+	tenant := bm.TenantFromCtx(ctx)
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, q, id, tenant)
+		if isConflict(err) {
+			return bm.NewConflictError("author with ID %d has books: %w", id, err)
+		}
+
+		if err != nil {
+			return bm.NewInternalError("delete author: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return bm.NewInternalError("get the number of affected rows: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return bm.NewNotFoundError("author with ID %d not found", id)
+		}
+
+		return recordChanges(ctx, tx, tenant, bm.EntityAuthor, bm.OpDeleted, []int64{id}, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("execute tx: %w", err)
+	}
+
+	return nil
+}
+
+// resolveContributors fills ids and names of contributors. Authors missing in the tenant are created by name.
+// An author may have a role only once, also when it is referred by both its id and its name.
+func resolveContributors(ctx context.Context, tx *sql.Tx, tenant string, contributors []bm.Contributor) ([]bm.Contributor, error) {
+	type authorRole struct {
+		id   int64
+		role string
+	}
+
+	seen := make(map[authorRole]bool, len(contributors))
+	resolved := make([]bm.Contributor, 0, len(contributors))
+	for _, c := range contributors {
+		if c.AuthorID != 0 {
+			q := `SELECT name FROM authors WHERE id = $1 AND tenant = $2`
+			err := tx.QueryRowContext(ctx, q, c.AuthorID, tenant).Scan(&c.Name)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, bm.NewNotFoundError("author with ID %d not found", c.AuthorID)
+			}
+
+			if err != nil {
+				return nil, bm.NewInternalError("select author: %w", err)
+			}
+		} else {
+			// xmax of a row is zero only if the row is inserted rather than updated on conflict.
+			q := `INSERT INTO authors (tenant, name) VALUES ($1, $2)
+				ON CONFLICT (tenant, name) DO UPDATE SET name = EXCLUDED.name
+				RETURNING id, xmax = 0`
+
+			var created bool
+			if err := tx.QueryRowContext(ctx, q, tenant, c.Name).Scan(&c.AuthorID, &created); err != nil {
+				return nil, bm.NewInternalError("upsert author: %w", err)
+			}
+
+			if created {
+				if err := recordChanges(ctx, tx, tenant, bm.EntityAuthor, bm.OpCreated, []int64{c.AuthorID}, nil); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		key := authorRole{id: c.AuthorID, role: c.Role}
+		if seen[key] {
+			return nil, bm.NewValidationError("author %q is repeated with role %s", c.Name, c.Role)
+		}
+
+		seen[key] = true
+		resolved = append(resolved, c)
+	}
+
+	return resolved, nil
+}
+
+// authorLine joins names of contributors with author role.
+func authorLine(contributors []bm.Contributor) string {
+	names := make([]string, 0, len(contributors))
+	for _, c := range contributors {
+		if c.Role == bm.RoleAuthor {
+			names = append(names, c.Name)
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// linkContributors replaces contributors of a book.
+func linkContributors(ctx context.Context, tx *sql.Tx, bookID int64, contributors []bm.Contributor) error {
+	q := `DELETE FROM book_authors WHERE book_id = $1`
+	if _, err := tx.ExecContext(ctx, q, bookID); err != nil {
+		return bm.NewInternalError("delete book authors: %w", err)
+	}
+
+	q = `INSERT INTO book_authors (book_id, author_id, role, position) VALUES ($1, $2, $3, $4)`
+	for i, c := range contributors {
+		_, err := tx.ExecContext(ctx, q, bookID, c.AuthorID, c.Role, i)
+		if isConflict(err) {
+			return bm.NewConflictError("insert book author: %w", err)
+		}
+
+		if err != nil {
+			return bm.NewInternalError("insert book author: %w", err)
+		}
+	}
+
+	return nil
+}
+
+type bookContributor struct {
+	BookID int64 `db:"book_id"`
+	bm.Contributor
+}
+
+// loadContributors fills contributors of books.
+func (s *DB) loadContributors(ctx context.Context, books []bm.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(books))
+	positions := make(map[int64]int, len(books))
+	for i, b := range books {
+		ids = append(ids, b.ID)
+		positions[b.ID] = i
+	}
+
+	q := `SELECT ba.book_id, ba.author_id, a.name, ba.role FROM book_authors ba
+		JOIN authors a ON a.id = ba.author_id
+		WHERE ba.book_id = ANY($1)
+		ORDER BY ba.position`
+
+	var contributors []bookContributor
+	if err := s.SelectContext(ctx, &contributors, q, pq.Array(ids)); err != nil {
+		return bm.NewInternalError("select book authors: %w", err)
+	}
+
+	for _, c := range contributors {
+		b := &books[positions[c.BookID]]
+		b.Contributors = append(b.Contributors, c.Contributor)
+	}
+
+	return nil
+}
+
+// relinkAuthors points books of the tenant to authors of the same tenant after books were moved.
+func relinkAuthors(ctx context.Context, tx *sql.Tx, tenant string) error {
+	q := `WITH i AS (
+			INSERT INTO authors (tenant, name)
+			SELECT DISTINCT b.tenant, a.name FROM book_authors ba
+			JOIN books b ON b.id = ba.book_id
+			JOIN authors a ON a.id = ba.author_id
+			WHERE b.tenant = $1 AND a.tenant <> b.tenant
+			ON CONFLICT DO NOTHING
+			RETURNING id
+		)
+		SELECT COALESCE(array_agg(id), '{}') FROM i`
+
+	var created pq.Int64Array
+	if err := tx.QueryRowContext(ctx, q, tenant).Scan(&created); err != nil {
+		return bm.NewInternalError("copy authors: %w", err)
+	}
+
+	if err := recordChanges(ctx, tx, tenant, bm.EntityAuthor, bm.OpCreated, created, nil); err != nil {
+		return err
+	}
+
+	q = `UPDATE book_authors ba SET author_id = t.id
+		FROM books b, authors a, authors t
+		WHERE ba.book_id = b.id AND ba.author_id = a.id AND b.tenant = $1
+			AND a.tenant <> b.tenant AND t.tenant = b.tenant AND t.name = a.name`
+	if _, err := tx.ExecContext(ctx, q, tenant); err != nil {
+		return bm.NewInternalError("relink authors: %w", err)
+	}
+
+	return nil
+}
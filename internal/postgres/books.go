@@ -15,33 +15,76 @@ import (
 
 const (
 	constraintViolationCode = "23503"
+	uniqueViolationCode     = "23505"
 )
 
+// likeEscaper escapes wildcards and the escape character of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// booksSelect selects books with their average rating, review count, copy count, cover and modification time.
+const booksSelect = `SELECT b.id, b.title, b.author, b.published_date, b.edition, b.description, b.genre,
+		COALESCE(b.genre_id, 0) AS genre_id, COALESCE(b.isbn, '') AS isbn, rv.average_rating, rv.review_count,
+		(SELECT COUNT(*) FROM copies c WHERE c.book_id = b.id) AS copy_count, b.cover, b.updated_at
+	FROM books b
+	LEFT JOIN LATERAL (
+		SELECT COALESCE(AVG(rating), 0)::FLOAT8 AS average_rating, COUNT(*) AS review_count FROM reviews WHERE book_id = b.id
+	) rv ON true `
+
+// CreateBook creates a book with its contributors. Author of the book is built from contributors with author role.
 func (s *DB) CreateBook(ctx context.Context, b bm.Book) (int64, error) {
 	query := `
-		INSERT INTO books (title, author, published_date, edition, description, genre)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO books (title, author, published_date, edition, description, genre, genre_id, isbn, tenant)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9)
 		RETURNING id
 	`
 
+	tenant := bm.TenantFromCtx(ctx)
+
 	var bookID int64
-	err := s.QueryRowContext(
-		ctx,
-		query,
-		b.Title,
-		b.Author,
-		b.PublishedDate,
-		b.Edition,
-		b.Description,
-		b.Genre,
-	).
-		Scan(&bookID)
-	pqErr := new(pq.Error)
-	if ok := errors.As(err, &pqErr); ok && pqErr.Code == constraintViolationCode {
-		return 0, bm.NewConflictError("insert book: %w", err)
-	}
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		contributors, err := resolveContributors(ctx, tx, tenant, b.Contributors)
+		if err != nil {
+			return err
+		}
+
+		genreID, genre, err := resolveGenre(ctx, tx, tenant, b.GenreID, b.Genre)
+		if err != nil {
+			return err
+		}
+
+		err = tx.QueryRowContext(
+			ctx,
+			query,
+			b.Title,
+			authorLine(contributors),
+			b.PublishedDate,
+			b.Edition,
+			b.Description,
+			genre,
+			genreID,
+			b.ISBN,
+			tenant,
+		).
+			Scan(&bookID)
+		if isConflict(err) {
+			return bm.NewConflictError("insert book: %w", err)
+		}
+		if err != nil {
+			return bm.NewInternalError("insert book: %w", err)
+		}
+
+		if err = linkContributors(ctx, tx, bookID, contributors); err != nil {
+			return err
+		}
+
+		if err = addTags(ctx, tx, tenant, []int64{bookID}, b.Tags); err != nil {
+			return err
+		}
+
+		return recordChanges(ctx, tx, tenant, bm.EntityBook, bm.OpCreated, []int64{bookID}, nil)
+	})
 	if err != nil {
-		return 0, bm.NewInternalError("insert book: %w", err)
+		return 0, fmt.Errorf("execute tx: %w", err)
 	}
 
 	return bookID, nil
@@ -49,22 +92,37 @@ func (s *DB) CreateBook(ctx context.Context, b bm.Book) (int64, error) {
 
 // Book gets book by id.
 func (s *DB) Book(ctx context.Context, id int64) (*bm.Book, error) {
-	q := `SELECT id, title, author, published_date, edition, description, genre FROM books b 
-			WHERE id=$1
-	`
+	q := booksSelect + "WHERE b.id=$1 AND b.tenant=$2"
 
 	book := new(bm.Book)
-	err := s.GetContext(ctx, book, q, id)
+	err := s.GetContext(ctx, book, q, id, bm.TenantFromCtx(ctx))
 	switch {
 	case errors.Is(err, sql.ErrNoRows):
 		return nil, bm.NewNotFoundError("book not found: %w", err)
 
 	case err != nil:
 		return nil, bm.NewInternalError("select book: %w", err)
+	}
 
-	default:
-		return book, nil
+	books := []bm.Book{*book}
+	if err = s.loadBookDetails(ctx, books); err != nil {
+		return nil, err
+	}
+
+	return &books[0], nil
+}
+
+// loadBookDetails fills contributors, tags and reading states of books.
+func (s *DB) loadBookDetails(ctx context.Context, books []bm.Book) error {
+	if err := s.loadContributors(ctx, books); err != nil {
+		return err
+	}
+
+	if err := s.loadTags(ctx, books); err != nil {
+		return err
 	}
+
+	return s.loadReadings(ctx, books)
 }
 
 func joinCollection(f bm.BookFilter) string {
@@ -75,20 +133,88 @@ func joinCollection(f bm.BookFilter) string {
 	return `JOIN books_collection bc ON b.id=bc.book_id `
 }
 
-func booksWhereClause(f bm.BookFilter) (string, map[string]any) {
-	whereClauses := make([]string, 0)
-	params := make(map[string]any, 0)
+func booksWhereClause(tenant string, f bm.BookFilter) (string, map[string]any) {
+	whereClauses := []string{"b.tenant = :tenant "}
+	params := map[string]any{"tenant": tenant}
+
+	if f.Query != "" {
+		whereClauses = append(whereClauses, `(b.title ILIKE :query ESCAPE '\' OR b.author ILIKE :query ESCAPE '\') `)
+		params["query"] = containsPattern(f.Query)
+	}
+
+	if f.Title != "" {
+		whereClauses = append(whereClauses, "b.title=:title ")
+		params["title"] = f.Title
+	}
 
+	// The author matches any author of the book or the joined names of all authors.
 	if f.Author != "" {
-		whereClauses = append(whereClauses, "b.author=:author ")
+		whereClauses = append(whereClauses, `(b.author = :author OR EXISTS (
+				SELECT 1 FROM book_authors ba JOIN authors a ON a.id = ba.author_id
+				WHERE ba.book_id = b.id AND ba.role = 'author' AND a.name = :author
+			)) `)
 		params["author"] = f.Author
 	}
 
+	if f.AuthorID != 0 {
+		whereClauses = append(whereClauses, "EXISTS (SELECT 1 FROM book_authors ba WHERE ba.book_id = b.id AND ba.author_id = :author_id) ")
+		params["author_id"] = f.AuthorID
+	}
+
 	if f.Genre != "" {
-		whereClauses = append(whereClauses, "b.genre=:genre ")
+		// The genre is found like the genre of a new book: by name or alias.
+		whereClauses = append(whereClauses, `b.genre_id IN (WITH RECURSIVE sub AS (
+				SELECT id FROM (`+genreByNameNamedQuery+`) root
+				UNION
+				SELECT g.id FROM genres g JOIN sub ON g.parent_id = sub.id
+			) SELECT id FROM sub) `)
 		params["genre"] = f.Genre
 	}
 
+	if f.GenreID != 0 {
+		whereClauses = append(whereClauses, `b.genre_id IN (WITH RECURSIVE sub AS (
+				SELECT id FROM genres WHERE id = :genre_id
+				UNION
+				SELECT g.id FROM genres g JOIN sub ON g.parent_id = sub.id
+			) SELECT id FROM sub) `)
+		params["genre_id"] = f.GenreID
+	}
+
+	if len(f.Tags) != 0 {
+		tagsClause := "EXISTS (SELECT 1 FROM book_tags bt JOIN tags t ON t.id = bt.tag_id WHERE bt.book_id = b.id AND t.name = ANY(:tags)) "
+		if f.TagsMatch == bm.TagsMatchAll {
+			tagsClause = "(SELECT COUNT(*) FROM book_tags bt JOIN tags t ON t.id = bt.tag_id WHERE bt.book_id = b.id AND t.name = ANY(:tags)) = :tags_count "
+			params["tags_count"] = len(f.Tags)
+		}
+
+		whereClauses = append(whereClauses, tagsClause)
+		params["tags"] = pq.Array(f.Tags)
+	}
+
+	if f.Status != "" {
+		whereClauses = append(whereClauses, "EXISTS (SELECT 1 FROM reading_states rs WHERE rs.book_id = b.id AND rs.status = :status) ")
+		params["status"] = f.Status
+	}
+
+	if f.MinRating != 0 {
+		whereClauses = append(whereClauses, "rv.average_rating >= :min_rating ")
+		params["min_rating"] = f.MinRating
+	}
+
+	if f.OnLoan != nil {
+		loanClause := "EXISTS (SELECT 1 FROM loans l WHERE l.book_id = b.id AND l.returned_at IS NULL) "
+		if !*f.OnLoan {
+			loanClause = "NOT " + loanClause
+		}
+
+		whereClauses = append(whereClauses, loanClause)
+	}
+
+	if f.ISBN != "" {
+		whereClauses = append(whereClauses, "b.isbn=:isbn ")
+		params["isbn"] = f.ISBN
+	}
+
 	if !f.StartDate.IsZero() {
 		whereClauses = append(whereClauses, "b.published_date >= :start_date ")
 		params["start_date"] = f.StartDate
@@ -104,10 +230,6 @@ func booksWhereClause(f bm.BookFilter) (string, map[string]any) {
 		params["collection_id"] = f.CollectionID
 	}
 
-	if len(whereClauses) == 0 {
-		return "", nil
-	}
-
 	return fmt.Sprintf("WHERE %s ", strings.Join(whereClauses, " AND ")), params
 }
 
@@ -124,17 +246,38 @@ func orderBy(table, column string, desc bool) string {
 	return fmt.Sprintf("ORDER BY %s.%s %s", table, column, dir)
 }
 
+// booksOrderBy orders books by a column of books or by average rating.
+// Books with the same value are ordered by id, so pages neither overlap nor skip books.
+func booksOrderBy(f bm.BookFilter) string {
+	switch f.OrderBy {
+	case "", "id":
+		return orderBy("b", "id", f.Desc)
+
+	case "rating":
+		return orderBy("rv", "average_rating", f.Desc) + ", b.id "
+
+	default:
+		return orderBy("b", f.OrderBy, f.Desc) + ", b.id "
+	}
+}
+
 func pagination(page, pageSize int64) string {
 	return fmt.Sprintf("LIMIT %d OFFSET %d ", pageSize, (page-1)*pageSize)
 }
 
+// containsPattern builds an ILIKE pattern which matches strings containing the text. Wildcards of the text
+// are escaped, the pattern must be used with ESCAPE '\'.
+func containsPattern(text string) string {
+	return "%" + likeEscaper.Replace(text) + "%"
+}
+
 // Books gets books by filter.
 func (s *DB) Books(ctx context.Context, f bm.BookFilter) ([]bm.Book, error) {
-	q := "SELECT b.id, b.title, b.author, b.published_date, b.edition, b.description, b.genre FROM books b "
+	q := booksSelect
 	q += joinCollection(f)
-	whereClause, params := booksWhereClause(f)
+	whereClause, params := booksWhereClause(bm.TenantFromCtx(ctx), f)
 	q += whereClause
-	q += orderBy("b", f.OrderBy, f.Desc)
+	q += booksOrderBy(f)
 	q += pagination(f.Page, f.PageSize)
 
 	rows, err := s.NamedQueryContext(ctx, q, params)
@@ -151,49 +294,61 @@ func (s *DB) Books(ctx context.Context, f bm.BookFilter) ([]bm.Book, error) {
 		return nil, bm.NewInternalError("copy data into struct: %w", err)
 	}
 
+	if err = s.loadBookDetails(ctx, books); err != nil {
+		return nil, err
+	}
+
 	return books, nil
 }
 
-func (s *DB) UpdateBook(ctx context.Context, b bm.Book) error {
-	params := []any{b.Author, b.Title, b.Edition, b.Description, b.PublishedDate, b.Genre, b.ID}
-	q := `UPDATE books SET
-			author = $1,
-			title = $2,
-			edition = $3,
-			description = $4,
-			published_date = $5,
-			genre = $6
-		WHERE id = $7`
-
-	result, err := s.DB.ExecContext(ctx, q, params...)
-	if err != nil {
-		return bm.NewInternalError("update book: %w", err)
-	}
+// BooksByIDs gets books of the tenant by their ids.
+func (s *DB) BooksByIDs(ctx context.Context, ids []int64) ([]bm.Book, error) {
+	q := booksSelect + "WHERE b.id = ANY($1) AND b.tenant = $2 ORDER BY b.id"
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return bm.NewInternalError("get the number of affected rows: %w", err)
+	var books []bm.Book
+	if err := s.SelectContext(ctx, &books, q, pq.Array(ids), bm.TenantFromCtx(ctx)); err != nil {
+		return nil, bm.NewInternalError("select books: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return bm.NewNotFoundError("book with ID %d not found", b.ID)
+	if err := s.loadBookDetails(ctx, books); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return books, nil
 }
 
-func (s *DB) DeleteBooks(ctx context.Context, ids []int64) error {
+// UpdateBook updates a book and replaces its contributors.
+func (s *DB) UpdateBook(ctx context.Context, b bm.Book) error {
+	tenant := bm.TenantFromCtx(ctx)
 	err := s.withTX(ctx, func(tx *sql.Tx) error {
-		q := `DELETE FROM books_collection bc WHERE bc.book_id = ANY($1)`
-		_, err := tx.ExecContext(ctx, q, pq.Array(ids))
+		contributors, err := resolveContributors(ctx, tx, tenant, b.Contributors)
 		if err != nil {
-			return bm.NewInternalError("delete collection books: %w", err)
+			return err
 		}
 
-		q = `DELETE FROM books b WHERE id = ANY($1)`
-		result, err := tx.ExecContext(ctx, q, pq.Array(ids))
+		genreID, genre, err := resolveGenre(ctx, tx, tenant, b.GenreID, b.Genre)
 		if err != nil {
-			return bm.NewInternalError("delete books: %w", err)
+			return err
+		}
+
+		params := []any{authorLine(contributors), b.Title, b.Edition, b.Description, b.PublishedDate, genre, genreID, b.ISBN, b.ID, tenant}
+		q := `UPDATE books SET
+				author = $1,
+				title = $2,
+				edition = $3,
+				description = $4,
+				published_date = $5,
+				genre = $6,
+				genre_id = $7,
+				isbn = NULLIF($8, '')
+			WHERE id = $9 AND tenant = $10`
+
+		result, err := tx.ExecContext(ctx, q, params...)
+		if isConflict(err) {
+			return bm.NewConflictError("update book: %w", err)
+		}
+		if err != nil {
+			return bm.NewInternalError("update book: %w", err)
 		}
 
 		rowsAffected, err := result.RowsAffected()
@@ -202,10 +357,14 @@ func (s *DB) DeleteBooks(ctx context.Context, ids []int64) error {
 		}
 
 		if rowsAffected == 0 {
-			return bm.NewNotFoundError("book with ID %v not found", ids)
+			return bm.NewNotFoundError("book with ID %d not found", b.ID)
+		}
+
+		if err = linkContributors(ctx, tx, b.ID, contributors); err != nil {
+			return err
 		}
 
-		return nil
+		return recordChanges(ctx, tx, tenant, bm.EntityBook, bm.OpUpdated, []int64{b.ID}, nil)
 	})
 	if err != nil {
 		return fmt.Errorf("execute tx: %w", err)
@@ -213,3 +372,188 @@ func (s *DB) DeleteBooks(ctx context.Context, ids []int64) error {
 
 	return nil
 }
+
+// DeleteBooks deletes books with their links and loans and returns their blobs.
+// Books on loan are deleted only if force is set.
+func (s *DB) DeleteBooks(ctx context.Context, ids []int64, force bool) (*bm.DeletedBooks, error) {
+	tenant := bm.TenantFromCtx(ctx)
+
+	var (
+		deleted       pq.Int64Array
+		covers, files pq.StringArray
+	)
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		// Locked books can't be lent meanwhile, so the check holds until the books are deleted.
+		q := `SELECT id FROM books WHERE id = ANY($1) AND tenant = $2 ORDER BY id FOR UPDATE`
+		if _, err := tx.ExecContext(ctx, q, pq.Array(ids), tenant); err != nil {
+			return bm.NewInternalError("lock books: %w", err)
+		}
+
+		if !force {
+			if err := checkBooksNotOnLoan(ctx, tx, tenant, ids); err != nil {
+				return err
+			}
+		}
+
+		q = `DELETE FROM books_collection bc USING books b
+			WHERE bc.book_id = b.id AND b.id = ANY($1) AND b.tenant = $2`
+		_, err := tx.ExecContext(ctx, q, pq.Array(ids), tenant)
+		if err != nil {
+			return bm.NewInternalError("delete collection books: %w", err)
+		}
+
+		q = `DELETE FROM copies c USING books b
+			WHERE c.book_id = b.id AND b.id = ANY($1) AND b.tenant = $2`
+		if _, err = tx.ExecContext(ctx, q, pq.Array(ids), tenant); err != nil {
+			return bm.NewInternalError("delete copies: %w", err)
+		}
+
+		q = `WITH d AS (
+				DELETE FROM book_files f USING books b
+				WHERE f.book_id = b.id AND b.id = ANY($1) AND b.tenant = $2
+				RETURNING f.blob
+			)
+			SELECT COALESCE(array_agg(blob), '{}') FROM d`
+		if err = tx.QueryRowContext(ctx, q, pq.Array(ids), tenant).Scan(&files); err != nil {
+			return bm.NewInternalError("delete book files: %w", err)
+		}
+
+		q = `DELETE FROM loans l USING books b
+			WHERE l.book_id = b.id AND b.id = ANY($1) AND b.tenant = $2`
+		if _, err = tx.ExecContext(ctx, q, pq.Array(ids), tenant); err != nil {
+			return bm.NewInternalError("delete loans: %w", err)
+		}
+
+		q = `DELETE FROM book_authors ba USING books b
+			WHERE ba.book_id = b.id AND b.id = ANY($1) AND b.tenant = $2`
+		if _, err = tx.ExecContext(ctx, q, pq.Array(ids), tenant); err != nil {
+			return bm.NewInternalError("delete book authors: %w", err)
+		}
+
+		q = `DELETE FROM book_tags bt USING books b
+			WHERE bt.book_id = b.id AND b.id = ANY($1) AND b.tenant = $2`
+		if _, err = tx.ExecContext(ctx, q, pq.Array(ids), tenant); err != nil {
+			return bm.NewInternalError("delete book tags: %w", err)
+		}
+
+		if err = deleteUnusedTags(ctx, tx, tenant); err != nil {
+			return err
+		}
+
+		q = `DELETE FROM reviews r USING books b
+			WHERE r.book_id = b.id AND b.id = ANY($1) AND b.tenant = $2`
+		if _, err = tx.ExecContext(ctx, q, pq.Array(ids), tenant); err != nil {
+			return bm.NewInternalError("delete reviews: %w", err)
+		}
+
+		q = `DELETE FROM reading_states r USING books b
+			WHERE r.book_id = b.id AND b.id = ANY($1) AND b.tenant = $2`
+		if _, err = tx.ExecContext(ctx, q, pq.Array(ids), tenant); err != nil {
+			return bm.NewInternalError("delete reading states: %w", err)
+		}
+
+		q = `WITH d AS (DELETE FROM books WHERE id = ANY($1) AND tenant = $2 RETURNING id, cover)
+			SELECT COALESCE(array_agg(id), '{}'), COALESCE(array_remove(array_agg(cover), ''), '{}') FROM d`
+		if err = tx.QueryRowContext(ctx, q, pq.Array(ids), tenant).Scan(&deleted, &covers); err != nil {
+			return bm.NewInternalError("delete books: %w", err)
+		}
+
+		if len(deleted) == 0 {
+			return bm.NewNotFoundError("book with ID %v not found", ids)
+		}
+
+		return recordChanges(ctx, tx, tenant, bm.EntityBook, bm.OpDeleted, deleted, nil)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("execute tx: %w", err)
+	}
+
+	return &bm.DeletedBooks{IDs: deleted, Covers: covers, Files: files}, nil
+}
+
+// SetBookCover replaces the cover of a book and returns the previous one.
+func (s *DB) SetBookCover(ctx context.Context, bookID int64, cover string) (string, error) {
+	q := `UPDATE books b SET cover = $1
+		FROM (SELECT id, cover FROM books WHERE id = $2 AND tenant = $3 FOR UPDATE) p
+		WHERE b.id = p.id
+		RETURNING p.cover`
+
+	tenant := bm.TenantFromCtx(ctx)
+
+	var previous string
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, q, cover, bookID, tenant).Scan(&previous)
+		if errors.Is(err, sql.ErrNoRows) {
+			return bm.NewNotFoundError("book with ID %d not found", bookID)
+		}
+
+		if err != nil {
+			return bm.NewInternalError("update book cover: %w", err)
+		}
+
+		return recordChanges(ctx, tx, tenant, bm.EntityBook, bm.OpUpdated, []int64{bookID}, nil)
+	})
+	if err != nil {
+		return "", fmt.Errorf("execute tx: %w", err)
+	}
+
+	return previous, nil
+}
+
+// MoveBooks transfers books to another tenant and drops their links to collections of other tenants.
+// Moved books are deleted from the change feeds of their previous tenants and created in the feed of the tenant.
+func (s *DB) MoveBooks(ctx context.Context, ids []int64, tenant string) (map[string][]int64, error) {
+	var moved map[string][]int64
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		q := `UPDATE books b SET tenant = $2 FROM books p
+			WHERE p.id = b.id AND b.id = ANY($1)
+			RETURNING b.id, p.tenant`
+		rows, err := tx.QueryContext(ctx, q, pq.Array(ids), tenant)
+		if isConflict(err) {
+			return bm.NewConflictError("move books: %w", err)
+		}
+
+		if err != nil {
+			return bm.NewInternalError("move books: %w", err)
+		}
+
+		var found bool
+		moved, found, err = scanMoved(rows, tenant)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return bm.NewNotFoundError("book with ID %v not found", ids)
+		}
+
+		q = `DELETE FROM books_collection bc USING collections c
+			WHERE bc.collection_id = c.id AND bc.book_id = ANY($1) AND c.tenant <> $2`
+		if _, err = tx.ExecContext(ctx, q, pq.Array(ids), tenant); err != nil {
+			return bm.NewInternalError("delete foreign collection books: %w", err)
+		}
+
+		if err = relinkAuthors(ctx, tx, tenant); err != nil {
+			return err
+		}
+
+		if err = relinkGenres(ctx, tx, tenant); err != nil {
+			return err
+		}
+
+		if err = relinkCopies(ctx, tx, tenant); err != nil {
+			return err
+		}
+
+		if err = relinkTags(ctx, tx, tenant); err != nil {
+			return err
+		}
+
+		return recordMoves(ctx, tx, tenant, bm.EntityBook, moved)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("execute tx: %w", err)
+	}
+
+	return moved, nil
+}
@@ -0,0 +1,256 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// changesLock is the first key of advisory locks taken by writers of changes, the second one is a hash
+// of the tenant. The lock is held until the end of the transaction, so changes of a tenant are committed
+// in the order of their seq and a reader resuming after a seq never misses a change committed later with
+// a smaller one. Readers read changes of one tenant, so writers of different tenants don't wait for each other;
+// tenants with the same hash share a lock.
+const changesLock = 0x626d63 // "bmc"
+
+// Notifications about committed changes, see migrations/015_add_changes_notify.up.sql.
+const (
+	changesChannel = "bm_changes"
+
+	listenerMinReconnect = time.Second
+	listenerMaxReconnect = time.Minute
+
+	// listenerPingInterval is the period of checks of an idle connection of the listener.
+	listenerPingInterval = time.Minute
+)
+
+// changeNotice is the payload of a notification about a change. Data lacks ids of books
+// added to or removed from a collection.
+type changeNotice struct {
+	Tenant   string          `json:"tenant"`
+	Seq      int64           `json:"seq"`
+	Entity   string          `json:"entity"`
+	EntityID int64           `json:"entity_id"`
+	Op       string          `json:"op"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// Data of changes of entities which belong to a book and of books added to or removed from a collection.
+type (
+	bookRef struct {
+		BookID int64 `json:"book_id"`
+	}
+
+	bookIDsRef struct {
+		BookIDs []int64 `json:"book_ids"`
+	}
+)
+
+// Changes gets changes of the tenant after f.Since ordered by seq.
+func (s *DB) Changes(ctx context.Context, f bm.ChangesFilter) ([]bm.Change, error) {
+	q := `SELECT seq, entity, entity_id, op, data, created_at FROM changes
+		WHERE tenant = $1 AND seq > $2
+		ORDER BY seq
+		LIMIT $3`
+
+	var changes []bm.Change
+	if err := s.SelectContext(ctx, &changes, q, bm.TenantFromCtx(ctx), f.Since, f.Limit); err != nil {
+		return nil, bm.NewInternalError("select changes: %w", err)
+	}
+
+	return changes, nil
+}
+
+// ListenChanges calls handle for every change committed by any server sharing the database until the context
+// is done. Notifications sent while the connection is lost are missed, so reset is called after every reconnection.
+func (s *DB) ListenChanges(ctx context.Context, handle func(tenant string, c bm.Change), reset func()) error {
+	listener := pq.NewListener(s.addr, listenerMinReconnect, listenerMaxReconnect, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Error().Err(err).Int("event", int(event)).Msg("listen changes")
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(changesChannel); err != nil {
+		return fmt.Errorf("listen %s: %w", changesChannel, err)
+	}
+
+	// Changes committed before the listener has connected are unknown too.
+	reset()
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			// A failed ping makes the listener reconnect.
+			_ = listener.Ping()
+
+		case n := <-listener.Notify:
+			if n == nil {
+				reset()
+
+				continue
+			}
+
+			var notice changeNotice
+			if err := json.Unmarshal([]byte(n.Extra), &notice); err != nil {
+				log.Error().Err(err).Str("payload", n.Extra).Msg("parse change notification")
+				reset()
+
+				continue
+			}
+
+			handle(notice.Tenant, bm.Change{
+				Seq:      notice.Seq,
+				Entity:   notice.Entity,
+				EntityID: notice.EntityID,
+				Op:       notice.Op,
+				Data:     notice.Data,
+			})
+		}
+	}
+}
+
+// recordChanges writes the same change of every entity of the tenant to the outbox within the transaction
+// of the mutation. Data is optional.
+func recordChanges(ctx context.Context, tx *sql.Tx, tenant, entity, op string, ids []int64, data any) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	raw := []byte("{}")
+	if data != nil {
+		var err error
+		if raw, err = json.Marshal(data); err != nil {
+			return bm.NewInternalError("marshal change data: %w", err)
+		}
+	}
+
+	if err := lockChanges(ctx, tx, tenant); err != nil {
+		return err
+	}
+
+	q := `INSERT INTO changes (tenant, entity, entity_id, op, data) SELECT $1, $2, unnest($3::INT[]), $4, $5`
+	if _, err := tx.ExecContext(ctx, q, tenant, entity, pq.Array(ids), op, string(raw)); err != nil {
+		return bm.NewInternalError("insert changes: %w", err)
+	}
+
+	return touch(ctx, tx, tenant, entity, op, ids, data)
+}
+
+// lockChanges takes locks of changes of the tenants. Locks are taken in the order of tenants, so transactions
+// writing changes of several tenants don't deadlock. A lock taken again by the transaction doesn't wait.
+func lockChanges(ctx context.Context, tx *sql.Tx, tenants ...string) error {
+	slices.Sort(tenants)
+	for _, tenant := range tenants {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, changesLock, tenant); err != nil {
+			return bm.NewInternalError("lock changes: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// touch sets the modification time of books and collections whose representation is changed: updated books,
+// books with changed reviews, numbers of copies or reading states, and updated collections or their books.
+// Loans aren't a part of books, so they don't touch them.
+func touch(ctx context.Context, tx *sql.Tx, tenant, entity, op string, ids []int64, data any) error {
+	table := "books"
+	switch {
+	case entity == bm.EntityBook && op == bm.OpUpdated, entity == bm.EntityReading:
+
+	case entity == bm.EntityReview, entity == bm.EntityCopy && op != bm.OpUpdated:
+		ref, ok := data.(bookRef)
+		if !ok {
+			return nil
+		}
+
+		ids = []int64{ref.BookID}
+
+	case entity == bm.EntityCollection && (op == bm.OpUpdated || op == bm.OpBooksAdded || op == bm.OpBooksRemoved):
+		table = "collections"
+
+	default:
+		return nil
+	}
+
+	q := `UPDATE ` + table + ` SET updated_at = now() WHERE id = ANY($1) AND tenant = $2`
+	if _, err := tx.ExecContext(ctx, q, pq.Array(ids), tenant); err != nil {
+		return bm.NewInternalError("touch %s: %w", table, err)
+	}
+
+	return nil
+}
+
+// recordMoves records entities moved to the tenant as deleted in their previous tenants and created in the tenant.
+// Moved is a result of scanMoved.
+func recordMoves(ctx context.Context, tx *sql.Tx, tenant, entity string, moved map[string][]int64) error {
+	tenants := []string{tenant}
+	for previous := range moved {
+		tenants = append(tenants, previous)
+	}
+
+	if err := lockChanges(ctx, tx, tenants...); err != nil {
+		return err
+	}
+
+	for previous, ids := range moved {
+		if err := recordChanges(ctx, tx, previous, entity, bm.OpDeleted, ids, nil); err != nil {
+			return err
+		}
+
+		if err := recordChanges(ctx, tx, tenant, entity, bm.OpCreated, ids, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanMoved groups ids of moved entities by their previous tenants. Rows contain an id and a previous tenant,
+// entities which already belonged to the tenant are skipped.
+func scanMoved(rows *sql.Rows, tenant string) (moved map[string][]int64, found bool, err error) {
+	defer func() {
+		err = bm.HandleErrPair(rows.Close(), err)
+	}()
+
+	moved = make(map[string][]int64)
+	for rows.Next() {
+		var (
+			id       int64
+			previous string
+		)
+		if err = rows.Scan(&id, &previous); err != nil {
+			return nil, false, bm.NewInternalError("scan moved entity: %w", err)
+		}
+
+		found = true
+		if previous != tenant {
+			moved[previous] = append(moved[previous], id)
+		}
+	}
+
+	err = rows.Err()
+	if isConflict(err) {
+		return nil, false, bm.NewConflictError("move: %w", err)
+	}
+
+	if err != nil {
+		return nil, false, bm.NewInternalError("iterate moved entities: %w", err)
+	}
+
+	return moved, found, nil
+}
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/lib/pq"
 
@@ -14,12 +13,12 @@ import (
 
 // Collection gets collection by its id.
 func (s *DB) Collection(ctx context.Context, id int64) (*bm.Collection, error) {
-	q := `SELECT id, name, description FROM collections c 
-			WHERE id=$1
+	q := `SELECT id, name, description, updated_at FROM collections c 
+			WHERE id=$1 AND tenant=$2
 	`
 
 	collection := new(bm.Collection)
-	err := s.GetContext(ctx, collection, q, id)
+	err := s.GetContext(ctx, collection, q, id, bm.TenantFromCtx(ctx))
 	switch {
 	case errors.Is(err, sql.ErrNoRows):
 		return nil, bm.NewNotFoundError("collection not found: %w", err)
@@ -34,28 +33,132 @@ func (s *DB) Collection(ctx context.Context, id int64) (*bm.Collection, error) {
 
 // Collections gets collections.
 func (s *DB) Collections(ctx context.Context, f bm.CollectionsFilter) ([]bm.Collection, error) {
-	q := "SELECT c.id, c.name, c.description FROM collections c "
+	q := "SELECT c.id, c.name, c.description, c.updated_at FROM collections c WHERE c.tenant = $1 "
 	q += pagination(f.Page, f.PageSize)
 
 	var collections []bm.Collection
-	if err := s.SelectContext(ctx, &collections, q); err != nil {
+	if err := s.SelectContext(ctx, &collections, q, bm.TenantFromCtx(ctx)); err != nil {
 		return nil, bm.NewInternalError("select collections: %w", err)
 	}
 
 	return collections, nil
 }
 
+// CollectionsByIDs gets collections of the tenant by their ids.
+func (s *DB) CollectionsByIDs(ctx context.Context, ids []int64) ([]bm.Collection, error) {
+	q := `SELECT id, name, description, updated_at FROM collections WHERE id = ANY($1) AND tenant = $2 ORDER BY id`
+
+	var collections []bm.Collection
+	if err := s.SelectContext(ctx, &collections, q, pq.Array(ids), bm.TenantFromCtx(ctx)); err != nil {
+		return nil, bm.NewInternalError("select collections: %w", err)
+	}
+
+	return collections, nil
+}
+
+type collectionBook struct {
+	CollectionID int64 `db:"collection_id"`
+	BookID       int64 `db:"book_id"`
+}
+
+// BooksOfCollections gets a page of books of each collection of the tenant, books are selected once for all collections.
+func (s *DB) BooksOfCollections(ctx context.Context, collectionIDs []int64, page, pageSize int64) (map[int64][]bm.Book, error) {
+	q := `SELECT collection_id, book_id FROM (
+			SELECT bc.collection_id, bc.book_id,
+				row_number() OVER (PARTITION BY bc.collection_id ORDER BY bc.book_id) AS n
+			FROM books_collection bc
+			JOIN collections c ON c.id = bc.collection_id
+			WHERE bc.collection_id = ANY($1) AND c.tenant = $2
+		) l
+		WHERE n > $3 AND n <= $4`
+
+	offset := (page - 1) * pageSize
+
+	var links []collectionBook
+	if err := s.SelectContext(ctx, &links, q, pq.Array(collectionIDs), bm.TenantFromCtx(ctx), offset, offset+pageSize); err != nil {
+		return nil, bm.NewInternalError("select collection books: %w", err)
+	}
+
+	bookIDs := make([]int64, 0, len(links))
+	collectionsOfBook := make(map[int64][]int64, len(links))
+	for _, l := range links {
+		if _, ok := collectionsOfBook[l.BookID]; !ok {
+			bookIDs = append(bookIDs, l.BookID)
+		}
+
+		collectionsOfBook[l.BookID] = append(collectionsOfBook[l.BookID], l.CollectionID)
+	}
+
+	books, err := s.BooksByIDs(ctx, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	booksOfCollection := make(map[int64][]bm.Book, len(collectionIDs))
+	for _, b := range books {
+		for _, cID := range collectionsOfBook[b.ID] {
+			booksOfCollection[cID] = append(booksOfCollection[cID], b)
+		}
+	}
+
+	return booksOfCollection, nil
+}
+
+type bookCollection struct {
+	BookID int64 `db:"book_id"`
+	bm.Collection
+}
+
+// CollectionsOfBooks gets a page of collections of the tenant which contain each of the books.
+func (s *DB) CollectionsOfBooks(ctx context.Context, bookIDs []int64, page, pageSize int64) (map[int64][]bm.Collection, error) {
+	q := `SELECT book_id, id, name, description, updated_at FROM (
+			SELECT bc.book_id, c.id, c.name, c.description, c.updated_at,
+				row_number() OVER (PARTITION BY bc.book_id ORDER BY c.id) AS n
+			FROM books_collection bc
+			JOIN collections c ON c.id = bc.collection_id
+			WHERE bc.book_id = ANY($1) AND c.tenant = $2
+		) l
+		WHERE n > $3 AND n <= $4
+		ORDER BY id`
+
+	offset := (page - 1) * pageSize
+
+	var rows []bookCollection
+	if err := s.SelectContext(ctx, &rows, q, pq.Array(bookIDs), bm.TenantFromCtx(ctx), offset, offset+pageSize); err != nil {
+		return nil, bm.NewInternalError("select book collections: %w", err)
+	}
+
+	collections := make(map[int64][]bm.Collection, len(bookIDs))
+	for _, r := range rows {
+		collections[r.BookID] = append(collections[r.BookID], r.Collection)
+	}
+
+	return collections, nil
+}
+
 func (s *DB) CreateCollection(ctx context.Context, c bm.Collection) (int64, error) {
 	query := `
-		INSERT INTO collections (name, description)
-		VALUES ($1, $2)
+		INSERT INTO collections (name, description, tenant)
+		VALUES ($1, $2, $3)
 		RETURNING id
 	`
 
+	tenant := bm.TenantFromCtx(ctx)
+
 	var id int64
-	err := s.QueryRowContext(ctx, query, c.Name, c.Description).Scan(&id)
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, query, c.Name, c.Description, tenant).Scan(&id)
+		if isConflict(err) {
+			return bm.NewConflictError("insert collection: %w", err)
+		}
+		if err != nil {
+			return bm.NewInternalError("insert collection: %w", err)
+		}
+
+		return recordChanges(ctx, tx, tenant, bm.EntityCollection, bm.OpCreated, []int64{id}, nil)
+	})
 	if err != nil {
-		return 0, bm.NewInternalError("insert collection: %w", err)
+		return 0, fmt.Errorf("execute tx: %w", err)
 	}
 
 	return id, nil
@@ -63,24 +166,35 @@ func (s *DB) CreateCollection(ctx context.Context, c bm.Collection) (int64, erro
 
 // UpdateCollection updates a collection and its books.
 func (s *DB) UpdateCollection(ctx context.Context, c bm.Collection) (err error) {
-	params := []any{c.Name, c.Description, c.ID}
+	tenant := bm.TenantFromCtx(ctx)
+	params := []any{c.Name, c.Description, c.ID, tenant}
 	q := `UPDATE collections c SET
 			name = $1,
 			description = $2
-		WHERE id = $3`
+		WHERE id = $3 AND tenant = $4`
 
-	result, err := s.DB.ExecContext(ctx, q, params...)
-	if err != nil {
-		return bm.NewInternalError("update collection: %w", err)
-	}
+	err = s.withTX(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, q, params...)
+		if isConflict(err) {
+			return bm.NewConflictError("update collection: %w", err)
+		}
+		if err != nil {
+			return bm.NewInternalError("update collection: %w", err)
+		}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return bm.NewInternalError("get the number of affected rows: %w", err)
-	}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return bm.NewInternalError("get the number of affected rows: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return bm.NewNotFoundError("collection with ID %d not found", c.ID)
+		}
 
-	if rowsAffected == 0 {
-		return bm.NewNotFoundError("collection with ID %d not found", c.ID)
+		return recordChanges(ctx, tx, tenant, bm.EntityCollection, bm.OpUpdated, []int64{c.ID}, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("execute tx: %w", err)
 	}
 
 	return nil
@@ -88,14 +202,16 @@ func (s *DB) UpdateCollection(ctx context.Context, c bm.Collection) (err error)
 
 // DeleteCollections deletes collection and its associations.
 func (s *DB) DeleteCollection(ctx context.Context, id int64) error {
+	tenant := bm.TenantFromCtx(ctx)
 	err := s.withTX(ctx, func(tx *sql.Tx) error {
-		q := `DELETE FROM books_collection bc WHERE bc.collection_id = $1`
-		if _, err := tx.ExecContext(ctx, q, id); err != nil {
+		q := `DELETE FROM books_collection bc USING collections c
+			WHERE bc.collection_id = c.id AND c.id = $1 AND c.tenant = $2`
+		if _, err := tx.ExecContext(ctx, q, id, tenant); err != nil {
 			return bm.NewInternalError("delete collection books: %w", err)
 		}
 
-		q = `DELETE FROM collections WHERE id = $1`
-		result, err := tx.ExecContext(ctx, q, id)
+		q = `DELETE FROM collections WHERE id = $1 AND tenant = $2`
+		result, err := tx.ExecContext(ctx, q, id, tenant)
 		if err != nil {
 			return bm.NewInternalError("delete collection: %w", err)
 		}
@@ -109,7 +225,7 @@ func (s *DB) DeleteCollection(ctx context.Context, id int64) error {
 			return bm.NewNotFoundError("collection with ID %d not found", id)
 		}
 
-		return nil
+		return recordChanges(ctx, tx, tenant, bm.EntityCollection, bm.OpDeleted, []int64{id}, nil)
 	})
 	if err != nil {
 		return fmt.Errorf("execute tx: %w", err)
@@ -118,30 +234,18 @@ func (s *DB) DeleteCollection(ctx context.Context, id int64) error {
 	return nil
 }
 
-func insertBooksCollectionValues(numValues int) string {
-	values := make([]string, 0, numValues)
-	for i := 0; i < numValues; i++ {
-		values = append(values, fmt.Sprintf("($%d, $%d)", i*2+1, i*2+2))
-	}
-
-	return strings.Join(values, ", ")
-}
-
 // CreateBooksCollection adds books to a collection.
+// Books and the collection must belong to the tenant of the caller.
 func (s *DB) CreateBooksCollection(ctx context.Context, cID int64, bookIDs []int64) error {
+	tenant := bm.TenantFromCtx(ctx)
 	err := s.withTX(ctx, func(tx *sql.Tx) error {
-		q := fmt.Sprintf(
-			`INSERT INTO books_collection (collection_id, book_id) VALUES %s`,
-			insertBooksCollectionValues(len(bookIDs)))
+		q := `INSERT INTO books_collection (collection_id, book_id)
+			SELECT c.id, b.id FROM collections c
+			JOIN books b ON b.tenant = c.tenant
+			WHERE c.id = $1 AND c.tenant = $2 AND b.id = ANY($3)`
 
-		args := make([]interface{}, 0, len(bookIDs)*2)
-		for _, bookID := range bookIDs {
-			args = append(args, cID, bookID)
-		}
-
-		_, err := tx.ExecContext(ctx, q, args...)
-		pqErr := new(pq.Error)
-		if ok := errors.As(err, &pqErr); ok && pqErr.Code == constraintViolationCode {
+		result, err := tx.ExecContext(ctx, q, cID, tenant, pq.Array(bookIDs))
+		if isConflict(err) {
 			return bm.NewConflictError("add books to collection: %w", err)
 		}
 
@@ -149,7 +253,16 @@ func (s *DB) CreateBooksCollection(ctx context.Context, cID int64, bookIDs []int
 			return bm.NewInternalError("add books to collection: %w", err)
 		}
 
-		return nil
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return bm.NewInternalError("get the number of affected rows: %w", err)
+		}
+
+		if rowsAffected != int64(len(bookIDs)) {
+			return bm.NewNotFoundError("books %v or collection %d not found", bookIDs, cID)
+		}
+
+		return recordChanges(ctx, tx, tenant, bm.EntityCollection, bm.OpBooksAdded, []int64{cID}, bookIDsRef{BookIDs: bookIDs})
 	})
 	if err != nil {
 		return fmt.Errorf("execute tx: %w", err)
@@ -160,19 +273,106 @@ func (s *DB) CreateBooksCollection(ctx context.Context, cID int64, bookIDs []int
 
 // DeleteBooksCollection deletes books from a collection.
 func (s *DB) DeleteBooksCollection(ctx context.Context, cID int64, bookIDs []int64) error {
-	q := `DELETE FROM books_collection bc WHERE bc.collection_id = $1 AND bc.book_id = ANY ($2)`
-	result, err := s.ExecContext(ctx, q, cID, pq.Array(bookIDs))
-	if err != nil {
-		return bm.NewInternalError("remove books from collection: %w", err)
-	}
+	tenant := bm.TenantFromCtx(ctx)
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		q := `WITH d AS (
+				DELETE FROM books_collection bc USING collections c
+				WHERE bc.collection_id = c.id AND c.id = $1 AND c.tenant = $2 AND bc.book_id = ANY ($3)
+				RETURNING bc.book_id
+			)
+			SELECT COALESCE(array_agg(book_id ORDER BY book_id), '{}') FROM d`
+
+		var removed pq.Int64Array
+		if err := tx.QueryRowContext(ctx, q, cID, tenant, pq.Array(bookIDs)).Scan(&removed); err != nil {
+			return bm.NewInternalError("remove books from collection: %w", err)
+		}
+
+		if len(removed) == 0 {
+			return bm.NewNotFoundError("book with ID %v not found in collection %d", bookIDs, cID)
+		}
 
-	rowsAffected, err := result.RowsAffected()
+		return recordChanges(ctx, tx, tenant, bm.EntityCollection, bm.OpBooksRemoved, []int64{cID}, bookIDsRef{BookIDs: removed})
+	})
 	if err != nil {
-		return bm.NewInternalError("get the number of affected rows: %w", err)
+		return fmt.Errorf("execute tx: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return bm.NewNotFoundError("book with ID %v not found in collection %d", bookIDs, cID)
+	return nil
+}
+
+// MoveCollection transfers a collection with its books to another tenant.
+// Links between the moved books and collections of other tenants are dropped.
+// The collection and its books are deleted from the change feed of their previous tenant and created in the feed of the tenant.
+func (s *DB) MoveCollection(ctx context.Context, id int64, tenant string) error {
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		q := `UPDATE collections c SET tenant = $2 FROM collections p
+			WHERE p.id = c.id AND c.id = $1
+			RETURNING p.tenant`
+
+		var previous string
+		err := tx.QueryRowContext(ctx, q, id, tenant).Scan(&previous)
+		if errors.Is(err, sql.ErrNoRows) {
+			return bm.NewNotFoundError("collection with ID %d not found", id)
+		}
+
+		if isConflict(err) {
+			return bm.NewConflictError("move collection: %w", err)
+		}
+
+		if err != nil {
+			return bm.NewInternalError("move collection: %w", err)
+		}
+
+		q = `UPDATE books b SET tenant = $2 FROM books_collection bc, books p
+			WHERE bc.book_id = b.id AND p.id = b.id AND bc.collection_id = $1
+			RETURNING b.id, p.tenant`
+		rows, err := tx.QueryContext(ctx, q, id, tenant)
+		if isConflict(err) {
+			return bm.NewConflictError("move collection books: %w", err)
+		}
+
+		if err != nil {
+			return bm.NewInternalError("move collection books: %w", err)
+		}
+
+		movedBooks, _, err := scanMoved(rows, tenant)
+		if err != nil {
+			return err
+		}
+
+		q = `DELETE FROM books_collection bc USING books b, collections c
+			WHERE bc.book_id = b.id AND bc.collection_id = c.id AND b.tenant <> c.tenant AND b.tenant = $1`
+		if _, err = tx.ExecContext(ctx, q, tenant); err != nil {
+			return bm.NewInternalError("delete foreign collection books: %w", err)
+		}
+
+		if err = relinkAuthors(ctx, tx, tenant); err != nil {
+			return err
+		}
+
+		if err = relinkGenres(ctx, tx, tenant); err != nil {
+			return err
+		}
+
+		if err = relinkCopies(ctx, tx, tenant); err != nil {
+			return err
+		}
+
+		if err = relinkTags(ctx, tx, tenant); err != nil {
+			return err
+		}
+
+		if previous != tenant {
+			movedCollection := map[string][]int64{previous: {id}}
+			if err = recordMoves(ctx, tx, tenant, bm.EntityCollection, movedCollection); err != nil {
+				return err
+			}
+		}
+
+		return recordMoves(ctx, tx, tenant, bm.EntityBook, movedBooks)
+	})
+	if err != nil {
+		return fmt.Errorf("execute tx: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,210 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+const copiesSelect = `SELECT c.id, c.book_id, b.title, c.barcode, c.condition, c.acquired_at, c.price, c.location FROM copies c
+	JOIN books b ON b.id = c.book_id `
+
+type copyRow struct {
+	ID         int64        `db:"id"`
+	BookID     int64        `db:"book_id"`
+	BookTitle  string       `db:"title"`
+	Barcode    string       `db:"barcode"`
+	Condition  string       `db:"condition"`
+	AcquiredAt sql.NullTime `db:"acquired_at"`
+	Price      int64        `db:"price"`
+	Location   string       `db:"location"`
+}
+
+func (r copyRow) copy() bm.Copy {
+	return bm.Copy{
+		ID:         r.ID,
+		BookID:     r.BookID,
+		BookTitle:  r.BookTitle,
+		Barcode:    r.Barcode,
+		Condition:  r.Condition,
+		AcquiredAt: r.AcquiredAt.Time,
+		Price:      r.Price,
+		Location:   r.Location,
+	}
+}
+
+// Copy gets copy by id.
+func (s *DB) Copy(ctx context.Context, id int64) (*bm.Copy, error) {
+	return s.getCopy(ctx, copiesSelect+"WHERE c.id = $1 AND c.tenant = $2", id)
+}
+
+// CopyByBarcode gets copy by barcode.
+func (s *DB) CopyByBarcode(ctx context.Context, barcode string) (*bm.Copy, error) {
+	return s.getCopy(ctx, copiesSelect+"WHERE c.barcode = $1 AND c.tenant = $2", barcode)
+}
+
+func (s *DB) getCopy(ctx context.Context, q string, arg any) (*bm.Copy, error) {
+	var row copyRow
+	err := s.GetContext(ctx, &row, q, arg, bm.TenantFromCtx(ctx))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, bm.NewNotFoundError("copy not found: %w", err)
+
+	case err != nil:
+		return nil, bm.NewInternalError("select copy: %w", err)
+
+	default:
+		c := row.copy()
+
+		return &c, nil
+	}
+}
+
+// Copies gets copies by filter.
+func (s *DB) Copies(ctx context.Context, f bm.CopiesFilter) ([]bm.Copy, error) {
+	q := copiesSelect + "WHERE c.tenant = :tenant "
+	params := map[string]any{"tenant": bm.TenantFromCtx(ctx)}
+	if f.BookID != 0 {
+		q += "AND c.book_id = :book_id "
+		params["book_id"] = f.BookID
+	}
+
+	if f.Location != "" {
+		q += "AND c.location = :location "
+		params["location"] = f.Location
+	}
+
+	q += orderBy("c", f.OrderBy, f.Desc)
+	q += pagination(f.Page, f.PageSize)
+
+	rows, err := s.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		return nil, bm.NewInternalError("select copies: %w", err)
+	}
+
+	defer func() {
+		err = bm.HandleErrPair(rows.Close(), err)
+	}()
+
+	var copyRows []copyRow
+	if err = sqlx.StructScan(rows, &copyRows); err != nil {
+		return nil, bm.NewInternalError("copy data into struct: %w", err)
+	}
+
+	copies := make([]bm.Copy, 0, len(copyRows))
+	for _, r := range copyRows {
+		copies = append(copies, r.copy())
+	}
+
+	return copies, nil
+}
+
+// CreateCopy creates copy of a book of the tenant of the caller.
+func (s *DB) CreateCopy(ctx context.Context, c bm.Copy) (int64, error) {
+	q := `INSERT INTO copies (book_id, tenant, barcode, condition, acquired_at, price, location)
+		SELECT id, tenant, $2, $3, $4, $5, $6 FROM books WHERE id = $1 AND tenant = $7
+		RETURNING id`
+
+	tenant := bm.TenantFromCtx(ctx)
+
+	var id int64
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, q, c.BookID, c.Barcode, c.Condition, nullDate(c.AcquiredAt), c.Price, c.Location, tenant).Scan(&id)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return bm.NewNotFoundError("book with ID %d not found", c.BookID)
+
+		case isConflict(err):
+			return bm.NewConflictError("insert copy: %w", err)
+
+		case err != nil:
+			return bm.NewInternalError("insert copy: %w", err)
+
+		default:
+			return recordChanges(ctx, tx, tenant, bm.EntityCopy, bm.OpCreated, []int64{id}, bookRef{BookID: c.BookID})
+		}
+	})
+	if err != nil {
+		return 0, fmt.Errorf("execute tx: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateCopy updates a copy.
+func (s *DB) UpdateCopy(ctx context.Context, c bm.Copy) error {
+	q := `UPDATE copies SET barcode = $1, condition = $2, acquired_at = $3, price = $4, location = $5
+		WHERE id = $6 AND tenant = $7
+		RETURNING book_id`
+
+	tenant := bm.TenantFromCtx(ctx)
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		var bookID int64
+		err := tx.QueryRowContext(ctx, q, c.Barcode, c.Condition, nullDate(c.AcquiredAt), c.Price, c.Location, c.ID, tenant).Scan(&bookID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return bm.NewNotFoundError("copy with ID %d not found", c.ID)
+
+		case isConflict(err):
+			return bm.NewConflictError("update copy: %w", err)
+
+		case err != nil:
+			return bm.NewInternalError("update copy: %w", err)
+
+		default:
+			return recordChanges(ctx, tx, tenant, bm.EntityCopy, bm.OpUpdated, []int64{c.ID}, bookRef{BookID: bookID})
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("execute tx: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCopy deletes a copy.
+func (s *DB) DeleteCopy(ctx context.Context, id int64) error {
+	q := `DELETE FROM copies WHERE id = $1 AND tenant = $2 RETURNING book_id`
+
+	tenant := bm.TenantFromCtx(ctx)
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		var bookID int64
+		err := tx.QueryRowContext(ctx, q, id, tenant).Scan(&bookID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return bm.NewNotFoundError("copy with ID %d not found", id)
+
+		case err != nil:
+			return bm.NewInternalError("delete copy: %w", err)
+
+		default:
+			return recordChanges(ctx, tx, tenant, bm.EntityCopy, bm.OpDeleted, []int64{id}, bookRef{BookID: bookID})
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("execute tx: %w", err)
+	}
+
+	return nil
+}
+
+// relinkCopies moves copies of books of the tenant to the tenant after books were moved.
+func relinkCopies(ctx context.Context, tx *sql.Tx, tenant string) error {
+	q := `UPDATE copies c SET tenant = b.tenant FROM books b
+		WHERE c.book_id = b.id AND b.tenant = $1 AND c.tenant <> b.tenant`
+	_, err := tx.ExecContext(ctx, q, tenant)
+	if isConflict(err) {
+		return bm.NewConflictError("move copies: %w", err)
+	}
+
+	if err != nil {
+		return bm.NewInternalError("move copies: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// BookFile gets a digital file of a book by id.
+func (s *DB) BookFile(ctx context.Context, bookID, id int64) (*bm.BookFile, error) {
+	q := `SELECT f.id, f.book_id, f.name, f.format, f.size, f.blob, f.created_at FROM book_files f
+		JOIN books b ON b.id = f.book_id
+		WHERE f.id = $1 AND f.book_id = $2 AND b.tenant = $3`
+
+	file := new(bm.BookFile)
+	err := s.GetContext(ctx, file, q, id, bookID, bm.TenantFromCtx(ctx))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, bm.NewNotFoundError("book file not found: %w", err)
+
+	case err != nil:
+		return nil, bm.NewInternalError("select book file: %w", err)
+
+	default:
+		return file, nil
+	}
+}
+
+// BookFiles gets digital files of a book ordered by id.
+func (s *DB) BookFiles(ctx context.Context, bookID int64) ([]bm.BookFile, error) {
+	q := `SELECT f.id, f.book_id, f.name, f.format, f.size, f.blob, f.created_at FROM book_files f
+		JOIN books b ON b.id = f.book_id
+		WHERE f.book_id = $1 AND b.tenant = $2
+		ORDER BY f.id`
+
+	var files []bm.BookFile
+	if err := s.SelectContext(ctx, &files, q, bookID, bm.TenantFromCtx(ctx)); err != nil {
+		return nil, bm.NewInternalError("select book files: %w", err)
+	}
+
+	return files, nil
+}
+
+// CreateBookFile attaches a digital file to a book of the tenant of the caller.
+func (s *DB) CreateBookFile(ctx context.Context, f bm.BookFile) (int64, error) {
+	q := `INSERT INTO book_files (book_id, name, format, size, blob)
+		SELECT id, $2, $3, $4, $5 FROM books WHERE id = $1 AND tenant = $6
+		RETURNING id`
+
+	tenant := bm.TenantFromCtx(ctx)
+
+	var id int64
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, q, f.BookID, f.Name, f.Format, f.Size, f.Blob, tenant).Scan(&id)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return bm.NewNotFoundError("book with ID %d not found", f.BookID)
+
+		case err != nil:
+			return bm.NewInternalError("insert book file: %w", err)
+
+		default:
+			return recordChanges(ctx, tx, tenant, bm.EntityBookFile, bm.OpCreated, []int64{id}, bookRef{BookID: f.BookID})
+		}
+	})
+	if err != nil {
+		return 0, fmt.Errorf("execute tx: %w", err)
+	}
+
+	return id, nil
+}
+
+// DeleteBookFile detaches a digital file from a book and returns its blob.
+func (s *DB) DeleteBookFile(ctx context.Context, bookID, id int64) (string, error) {
+	q := `DELETE FROM book_files f USING books b
+		WHERE f.book_id = b.id AND f.id = $1 AND f.book_id = $2 AND b.tenant = $3
+		RETURNING f.blob`
+
+	tenant := bm.TenantFromCtx(ctx)
+
+	var blob string
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, q, id, bookID, tenant).Scan(&blob)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return bm.NewNotFoundError("book file with ID %d not found", id)
+
+		case err != nil:
+			return bm.NewInternalError("delete book file: %w", err)
+
+		default:
+			return recordChanges(ctx, tx, tenant, bm.EntityBookFile, bm.OpDeleted, []int64{id}, bookRef{BookID: bookID})
+		}
+	})
+	if err != nil {
+		return "", fmt.Errorf("execute tx: %w", err)
+	}
+
+	return blob, nil
+}
@@ -0,0 +1,304 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// subgenresQuery selects ids of the genre with $1 id and all its subgenres.
+const subgenresQuery = `WITH RECURSIVE sub AS (
+		SELECT id FROM genres WHERE id = $1
+		UNION
+		SELECT g.id FROM genres g JOIN sub ON g.parent_id = sub.id
+	)`
+
+// Genre gets genre by id.
+func (s *DB) Genre(ctx context.Context, id int64) (*bm.Genre, error) {
+	q := `SELECT id, name, COALESCE(parent_id, 0) AS parent_id FROM genres WHERE id=$1 AND tenant=$2`
+
+	genre := new(bm.Genre)
+	err := s.GetContext(ctx, genre, q, id, bm.TenantFromCtx(ctx))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, bm.NewNotFoundError("genre not found: %w", err)
+
+	case err != nil:
+		return nil, bm.NewInternalError("select genre: %w", err)
+
+	default:
+		return genre, nil
+	}
+}
+
+// Genres gets genres by filter.
+func (s *DB) Genres(ctx context.Context, f bm.GenresFilter) ([]bm.Genre, error) {
+	q := "SELECT g.id, g.name, COALESCE(g.parent_id, 0) AS parent_id FROM genres g WHERE g.tenant = :tenant "
+	params := map[string]any{"tenant": bm.TenantFromCtx(ctx)}
+	if f.Name != "" {
+		q += `AND g.name ILIKE :name ESCAPE '\' `
+		params["name"] = containsPattern(f.Name)
+	}
+
+	if f.ParentID != 0 {
+		q += "AND g.parent_id = :parent_id "
+		params["parent_id"] = f.ParentID
+	}
+
+	q += orderBy("g", f.OrderBy, f.Desc)
+	q += pagination(f.Page, f.PageSize)
+
+	rows, err := s.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		return nil, bm.NewInternalError("select genres: %w", err)
+	}
+
+	defer func() {
+		err = bm.HandleErrPair(rows.Close(), err)
+	}()
+
+	var genres []bm.Genre
+	if err = sqlx.StructScan(rows, &genres); err != nil {
+		return nil, bm.NewInternalError("copy data into struct: %w", err)
+	}
+
+	return genres, nil
+}
+
+func (s *DB) CreateGenre(ctx context.Context, g bm.Genre) (int64, error) {
+	tenant := bm.TenantFromCtx(ctx)
+
+	var id int64
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		if err := checkParentGenre(ctx, tx, tenant, g.ParentID); err != nil {
+			return err
+		}
+
+		q := `INSERT INTO genres (tenant, name, parent_id) VALUES ($1, $2, NULLIF($3, 0)) RETURNING id`
+		err := tx.QueryRowContext(ctx, q, tenant, g.Name, g.ParentID).Scan(&id)
+		if isConflict(err) {
+			return bm.NewConflictError("insert genre: %w", err)
+		}
+
+		if err != nil {
+			return bm.NewInternalError("insert genre: %w", err)
+		}
+
+		return recordChanges(ctx, tx, tenant, bm.EntityGenre, bm.OpCreated, []int64{id}, nil)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("execute tx: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateGenre renames a genre or changes its parent and refreshes genre names of its books.
+func (s *DB) UpdateGenre(ctx context.Context, g bm.Genre) error {
+	tenant := bm.TenantFromCtx(ctx)
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		if err := checkParentGenre(ctx, tx, tenant, g.ParentID); err != nil {
+			return err
+		}
+
+		if g.ParentID != 0 {
+			var cycle bool
+			q := subgenresQuery + ` SELECT EXISTS (SELECT 1 FROM sub WHERE id = $2)`
+			if err := tx.QueryRowContext(ctx, q, g.ID, g.ParentID).Scan(&cycle); err != nil {
+				return bm.NewInternalError("select subgenres: %w", err)
+			}
+
+			if cycle {
+				return bm.NewValidationError("genre %d can't be a subgenre of its own subgenre %d", g.ID, g.ParentID)
+			}
+		}
+
+		q := `UPDATE genres SET name = $1, parent_id = NULLIF($2, 0) WHERE id = $3 AND tenant = $4`
+		result, err := tx.ExecContext(ctx, q, g.Name, g.ParentID, g.ID, tenant)
+		if isConflict(err) {
+			return bm.NewConflictError("update genre: %w", err)
+		}
+
+		if err != nil {
+			return bm.NewInternalError("update genre: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return bm.NewInternalError("get the number of affected rows: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return bm.NewNotFoundError("genre with ID %d not found", g.ID)
+		}
+
+		q = `WITH u AS (UPDATE books SET genre = $1 WHERE genre_id = $2 RETURNING id)
+			SELECT COALESCE(array_agg(id), '{}') FROM u`
+
+		var bookIDs pq.Int64Array
+		if err = tx.QueryRowContext(ctx, q, g.Name, g.ID).Scan(&bookIDs); err != nil {
+			return bm.NewInternalError("update books genre: %w", err)
+		}
+
+		if err = recordChanges(ctx, tx, tenant, bm.EntityGenre, bm.OpUpdated, []int64{g.ID}, nil); err != nil {
+			return err
+		}
+
+		return recordChanges(ctx, tx, tenant, bm.EntityBook, bm.OpUpdated, bookIDs, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("execute tx: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteGenre deletes a genre. Genres of existing books and genres with subgenres can't be deleted.
+func (s *DB) DeleteGenre(ctx context.Context, id int64) error {
+	q := `DELETE FROM genres WHERE id = $1 AND tenant = $2`
+
+	tenant := bm.TenantFromCtx(ctx)
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, q, id, tenant)
+		if isConflict(err) {
+			return bm.NewConflictError("genre with ID %d has books or subgenres: %w", id, err)
+		}
+
+		if err != nil {
+			return bm.NewInternalError("delete genre: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return bm.NewInternalError("get the number of affected rows: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return bm.NewNotFoundError("genre with ID %d not found", id)
+		}
+
+		return recordChanges(ctx, tx, tenant, bm.EntityGenre, bm.OpDeleted, []int64{id}, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("execute tx: %w", err)
+	}
+
+	return nil
+}
+
+func checkParentGenre(ctx context.Context, tx *sql.Tx, tenant string, parentID int64) error {
+	if parentID == 0 {
+		return nil
+	}
+
+	var exists bool
+	q := `SELECT EXISTS (SELECT 1 FROM genres WHERE id = $1 AND tenant = $2)`
+	if err := tx.QueryRowContext(ctx, q, parentID, tenant).Scan(&exists); err != nil {
+		return bm.NewInternalError("select parent genre: %w", err)
+	}
+
+	if !exists {
+		return bm.NewNotFoundError("parent genre with ID %d not found", parentID)
+	}
+
+	return nil
+}
+
+// genreByNameQuery selects a genre of the $1 tenant by case-insensitive $2 name or alias, names take precedence.
+const genreByNameQuery = `SELECT id, name, parent_id FROM (
+		SELECT g.id, g.name, COALESCE(g.parent_id, 0) AS parent_id, 0 AS alias FROM genres g
+		WHERE g.tenant = $1 AND lower(g.name) = lower(trim($2))
+		UNION ALL
+		SELECT g.id, g.name, COALESCE(g.parent_id, 0) AS parent_id, 1 AS alias FROM genre_aliases a
+		JOIN genres g ON g.id = a.genre_id
+		WHERE a.tenant = $1 AND lower(a.alias) = lower(trim($2))
+	) g ORDER BY alias LIMIT 1`
+
+// genreByNameNamedQuery is genreByNameQuery with :tenant and :genre named parameters.
+var genreByNameNamedQuery = strings.NewReplacer("$1", ":tenant", "$2", ":genre").Replace(genreByNameQuery)
+
+// GenreByName gets genre by case-insensitive name or alias.
+func (s *DB) GenreByName(ctx context.Context, name string) (*bm.Genre, error) {
+	genre := new(bm.Genre)
+	err := s.GetContext(ctx, genre, genreByNameQuery, bm.TenantFromCtx(ctx), name)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, bm.NewNotFoundError("genre %q not found: %w", name, err)
+
+	case err != nil:
+		return nil, bm.NewInternalError("select genre: %w", err)
+
+	default:
+		return genre, nil
+	}
+}
+
+// resolveGenre finds a genre of a book by id or by case-insensitive name or alias. Unknown names are rejected,
+// genres are created explicitly.
+func resolveGenre(ctx context.Context, tx *sql.Tx, tenant string, id int64, name string) (int64, string, error) {
+	if id != 0 {
+		q := `SELECT name FROM genres WHERE id = $1 AND tenant = $2`
+		err := tx.QueryRowContext(ctx, q, id, tenant).Scan(&name)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, "", bm.NewNotFoundError("genre with ID %d not found", id)
+		}
+
+		if err != nil {
+			return 0, "", bm.NewInternalError("select genre: %w", err)
+		}
+
+		return id, name, nil
+	}
+
+	var parentID int64
+	err := tx.QueryRowContext(ctx, genreByNameQuery, tenant, name).Scan(&id, &name, &parentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, "", bm.NewValidationError("unknown genre %q: create the genre or set genre_id", name)
+	}
+
+	if err != nil {
+		return 0, "", bm.NewInternalError("select genre: %w", err)
+	}
+
+	return id, name, nil
+}
+
+// relinkGenres points books of the tenant to genres of the same tenant after books were moved.
+// Genres missing in the tenant are created at the top level.
+func relinkGenres(ctx context.Context, tx *sql.Tx, tenant string) error {
+	q := `WITH i AS (
+			INSERT INTO genres (tenant, name)
+			SELECT DISTINCT b.tenant, g.name FROM books b
+			JOIN genres g ON g.id = b.genre_id
+			WHERE b.tenant = $1 AND g.tenant <> b.tenant
+			ON CONFLICT DO NOTHING
+			RETURNING id
+		)
+		SELECT COALESCE(array_agg(id), '{}') FROM i`
+
+	var created pq.Int64Array
+	if err := tx.QueryRowContext(ctx, q, tenant).Scan(&created); err != nil {
+		return bm.NewInternalError("copy genres: %w", err)
+	}
+
+	if err := recordChanges(ctx, tx, tenant, bm.EntityGenre, bm.OpCreated, created, nil); err != nil {
+		return err
+	}
+
+	q = `UPDATE books b SET genre_id = t.id, genre = t.name
+		FROM genres g, genres t
+		WHERE b.genre_id = g.id AND b.tenant = $1
+			AND g.tenant <> b.tenant AND t.tenant = b.tenant AND lower(t.name) = lower(g.name)`
+	if _, err := tx.ExecContext(ctx, q, tenant); err != nil {
+		return bm.NewInternalError("relink genres: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// LendBook creates an active loan of a book of the tenant of the caller.
+func (s *DB) LendBook(ctx context.Context, l bm.Loan) (int64, error) {
+	q := `INSERT INTO loans (book_id, borrower, lent_at, due_at)
+		SELECT id, $2, $3, $4 FROM books WHERE id = $1 AND tenant = $5
+		RETURNING id`
+
+	tenant := bm.TenantFromCtx(ctx)
+
+	var id int64
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, q, l.BookID, l.Borrower, l.LentAt, l.DueAt, tenant).Scan(&id)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return bm.NewNotFoundError("book with ID %d not found", l.BookID)
+
+		case isConflict(err):
+			return bm.NewConflictError("book with ID %d is already on loan: %w", l.BookID, err)
+
+		case err != nil:
+			return bm.NewInternalError("insert loan: %w", err)
+
+		default:
+			return recordChanges(ctx, tx, tenant, bm.EntityLoan, bm.OpCreated, []int64{id}, bookRef{BookID: l.BookID})
+		}
+	})
+	if err != nil {
+		return 0, fmt.Errorf("execute tx: %w", err)
+	}
+
+	return id, nil
+}
+
+// ReturnBook closes the active loan of a book of the tenant of the caller.
+func (s *DB) ReturnBook(ctx context.Context, bookID int64, returnedAt time.Time) error {
+	tenant := bm.TenantFromCtx(ctx)
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		q := `SELECT l.id, l.lent_at FROM loans l
+			JOIN books b ON b.id = l.book_id
+			WHERE l.book_id = $1 AND b.tenant = $2 AND l.returned_at IS NULL
+			FOR UPDATE OF l`
+
+		var (
+			id     int64
+			lentAt time.Time
+		)
+		err := tx.QueryRowContext(ctx, q, bookID, tenant).Scan(&id, &lentAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return bm.NewNotFoundError("book with ID %d is not on loan", bookID)
+		}
+
+		if err != nil {
+			return bm.NewInternalError("select loan: %w", err)
+		}
+
+		if returnedAt.Before(lentAt) {
+			return bm.NewValidationError("book with ID %d can't be returned before it was lent on %s", bookID, lentAt.Format(time.DateOnly))
+		}
+
+		q = `UPDATE loans SET returned_at = $1 WHERE id = $2`
+		if _, err = tx.ExecContext(ctx, q, returnedAt, id); err != nil {
+			return bm.NewInternalError("update loan: %w", err)
+		}
+
+		return recordChanges(ctx, tx, tenant, bm.EntityLoan, bm.OpUpdated, []int64{id}, bookRef{BookID: bookID})
+	})
+	if err != nil {
+		return fmt.Errorf("execute tx: %w", err)
+	}
+
+	return nil
+}
+
+// Loans gets active loans by filter.
+func (s *DB) Loans(ctx context.Context, f bm.LoansFilter) ([]bm.Loan, error) {
+	q := `SELECT l.id, l.book_id, b.title, l.borrower, l.lent_at, l.due_at FROM loans l
+		JOIN books b ON b.id = l.book_id
+		WHERE b.tenant = :tenant AND l.returned_at IS NULL `
+	params := map[string]any{"tenant": bm.TenantFromCtx(ctx)}
+	if !f.DueBefore.IsZero() {
+		q += "AND l.due_at < :due_before "
+		params["due_before"] = f.DueBefore
+	}
+
+	q += orderBy("l", f.OrderBy, f.Desc)
+	q += pagination(f.Page, f.PageSize)
+
+	rows, err := s.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		return nil, bm.NewInternalError("select loans: %w", err)
+	}
+
+	defer func() {
+		err = bm.HandleErrPair(rows.Close(), err)
+	}()
+
+	var loans []bm.Loan
+	if err = sqlx.StructScan(rows, &loans); err != nil {
+		return nil, bm.NewInternalError("copy data into struct: %w", err)
+	}
+
+	return loans, nil
+}
+
+// checkBooksNotOnLoan checks that books of the tenant have no active loans.
+func checkBooksNotOnLoan(ctx context.Context, tx *sql.Tx, tenant string, bookIDs []int64) error {
+	q := `SELECT COALESCE(array_agg(l.book_id ORDER BY l.book_id), '{}') FROM loans l
+		JOIN books b ON b.id = l.book_id
+		WHERE l.book_id = ANY($1) AND b.tenant = $2 AND l.returned_at IS NULL`
+
+	var onLoan pq.Int64Array
+	if err := tx.QueryRowContext(ctx, q, pq.Array(bookIDs), tenant).Scan(&onLoan); err != nil {
+		return bm.NewInternalError("select active loans: %w", err)
+	}
+
+	if len(onLoan) != 0 {
+		return bm.NewConflictError("books %v are on loan", []int64(onLoan))
+	}
+
+	return nil
+}
@@ -3,11 +3,13 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
 	bm "github.com/Tsapen/bm/internal/bm"
 )
@@ -19,22 +21,55 @@ type Config struct {
 	Port        string
 	VirtualHost string
 	HostName    string
+
+	// SSLMode is a libpq sslmode, it defaults to disable. SSLRootCert is the CA verifying the server
+	// in the verify-ca and verify-full modes.
+	SSLMode     string
+	SSLRootCert string
 }
 
 // DB contains db connection.
 type DB struct {
 	*sqlx.DB
+
+	addr string
 }
 
+// connValueEscaper escapes values of libpq connection strings within single quotes.
+var connValueEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// dbAddr builds a libpq connection string. Values are quoted, so passwords and paths may contain spaces,
+// quotes and backslashes.
 func (c *Config) dbAddr() string {
-	return fmt.Sprintf(
-		"user=%s password=%s host=%s port=%s dbname=%s sslmode=disable",
-		c.UserName,
-		c.Password,
-		c.HostName,
-		c.Port,
-		c.VirtualHost,
-	)
+	sslMode := c.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	params := [][2]string{
+		{"user", c.UserName},
+		{"password", c.Password},
+		{"host", c.HostName},
+		{"port", c.Port},
+		{"dbname", c.VirtualHost},
+		{"sslmode", sslMode},
+	}
+
+	if c.SSLRootCert != "" {
+		params = append(params, [2]string{"sslrootcert", c.SSLRootCert})
+	}
+
+	pairs := make([]string, 0, len(params))
+	for _, p := range params {
+		pairs = append(pairs, p[0]+"='"+connValueEscaper.Replace(p[1])+"'")
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+// String describes the database without credentials for logs and errors.
+func (c *Config) String() string {
+	return fmt.Sprintf("%s:%s/%s", c.HostName, c.Port, c.VirtualHost)
 }
 
 // New create new storage.
@@ -42,7 +77,7 @@ func New(c Config) (*DB, error) {
 	dbAddr := c.dbAddr()
 	db, err := sqlx.Open("postgres", dbAddr)
 	if err != nil {
-		return nil, fmt.Errorf("open connection %s: %w", dbAddr, err)
+		return nil, fmt.Errorf("open connection %s: %w", c.String(), err)
 	}
 
 	for i := 0; i < 10; i++ {
@@ -54,15 +89,16 @@ func New(c Config) (*DB, error) {
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("ping with connection %s: %w", dbAddr, err)
+		return nil, fmt.Errorf("ping with connection %s: %w", c.String(), err)
 	}
 
 	return &DB{
-		db,
+		DB:   db,
+		addr: dbAddr,
 	}, nil
 }
 
-func (s *DB) withTX(ctx context.Context, fnc func(tx *sql.Tx) error) error {
+func (s *DB) withTX(ctx context.Context, fnc func(tx *sql.Tx) error) (err error) {
 	tx, err := s.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("start tx: %w", err)
@@ -78,3 +114,13 @@ func (s *DB) withTX(ctx context.Context, fnc func(tx *sql.Tx) error) error {
 
 	return fnc(tx)
 }
+
+// isConflict checks whether err is a foreign key or unique constraint violation.
+func isConflict(err error) bool {
+	pqErr := new(pq.Error)
+	if ok := errors.As(err, &pqErr); !ok {
+		return false
+	}
+
+	return pqErr.Code == constraintViolationCode || pqErr.Code == uniqueViolationCode
+}
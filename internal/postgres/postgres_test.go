@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBAddr(t *testing.T) {
+	c := Config{
+		UserName:    "bm",
+		Password:    `it's a \secret`,
+		Port:        "5432",
+		VirtualHost: "bm",
+		HostName:    "db",
+		SSLMode:     "verify-full",
+		SSLRootCert: "/etc/ssl/my certs/ca.pem",
+	}
+
+	addr := c.dbAddr()
+	assert.Equal(t, `user='bm' password='it\'s a \\secret' host='db' port='5432' dbname='bm' sslmode='verify-full' sslrootcert='/etc/ssl/my certs/ca.pem'`, addr)
+
+	// The connection string is parsed by the driver without connecting.
+	_, err := pq.NewConnector(addr)
+	assert.NoError(t, err)
+
+	assert.NotContains(t, c.String(), "secret")
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+type readingRow struct {
+	BookID      int64        `db:"book_id"`
+	Status      string       `db:"status"`
+	StartedAt   sql.NullTime `db:"started_at"`
+	FinishedAt  sql.NullTime `db:"finished_at"`
+	CurrentPage int64        `db:"current_page"`
+	Notes       string       `db:"notes"`
+}
+
+func (r readingRow) reading() *bm.Reading {
+	return &bm.Reading{
+		BookID:      r.BookID,
+		Status:      r.Status,
+		StartedAt:   r.StartedAt.Time,
+		FinishedAt:  r.FinishedAt.Time,
+		CurrentPage: r.CurrentPage,
+		Notes:       r.Notes,
+	}
+}
+
+func nullDate(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// Reading gets reading state of a book.
+func (s *DB) Reading(ctx context.Context, bookID int64) (*bm.Reading, error) {
+	q := `SELECT b.id AS book_id, COALESCE(r.status, '') AS status, r.started_at, r.finished_at,
+			COALESCE(r.current_page, 0) AS current_page, COALESCE(r.notes, '') AS notes
+		FROM books b
+		LEFT JOIN reading_states r ON r.book_id = b.id
+		WHERE b.id = $1 AND b.tenant = $2`
+
+	var row readingRow
+	err := s.GetContext(ctx, &row, q, bookID, bm.TenantFromCtx(ctx))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, bm.NewNotFoundError("book with ID %d not found", bookID)
+
+	case err != nil:
+		return nil, bm.NewInternalError("select reading state: %w", err)
+
+	default:
+		return row.reading(), nil
+	}
+}
+
+// UpdateReading replaces reading state of a book of the tenant of the caller with the result of update.
+// The book is locked while update checks the transition, so concurrent updates see each other.
+func (s *DB) UpdateReading(ctx context.Context, bookID int64, update func(current bm.Reading) (bm.Reading, error)) (*bm.Reading, error) {
+	tenant := bm.TenantFromCtx(ctx)
+
+	var next bm.Reading
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		q := `SELECT b.id, COALESCE(r.status, ''), r.started_at, r.finished_at,
+				COALESCE(r.current_page, 0), COALESCE(r.notes, '')
+			FROM books b
+			LEFT JOIN reading_states r ON r.book_id = b.id
+			WHERE b.id = $1 AND b.tenant = $2
+			FOR UPDATE OF b`
+
+		var row readingRow
+		err := tx.QueryRowContext(ctx, q, bookID, tenant).
+			Scan(&row.BookID, &row.Status, &row.StartedAt, &row.FinishedAt, &row.CurrentPage, &row.Notes)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return bm.NewNotFoundError("book with ID %d not found", bookID)
+
+		case err != nil:
+			return bm.NewInternalError("select reading state: %w", err)
+		}
+
+		if next, err = update(*row.reading()); err != nil {
+			return err
+		}
+
+		q = `INSERT INTO reading_states (book_id, status, started_at, finished_at, current_page, notes)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (book_id) DO UPDATE SET
+				status = EXCLUDED.status,
+				started_at = EXCLUDED.started_at,
+				finished_at = EXCLUDED.finished_at,
+				current_page = EXCLUDED.current_page,
+				notes = EXCLUDED.notes`
+
+		_, err = tx.ExecContext(ctx, q, bookID, next.Status, nullDate(next.StartedAt), nullDate(next.FinishedAt), next.CurrentPage, next.Notes)
+		if err != nil {
+			return bm.NewInternalError("upsert reading state: %w", err)
+		}
+
+		// The reading state is identified by its book.
+		return recordChanges(ctx, tx, tenant, bm.EntityReading, bm.OpUpdated, []int64{bookID}, nil)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("execute tx: %w", err)
+	}
+
+	return &next, nil
+}
+
+// FinishedBooks gets books of the tenant finished within [from, to).
+func (s *DB) FinishedBooks(ctx context.Context, from, to time.Time) ([]bm.Book, error) {
+	q := booksSelect + `JOIN reading_states r ON r.book_id = b.id
+		WHERE b.tenant = $1 AND r.status = $2 AND r.finished_at >= $3 AND r.finished_at < $4
+		ORDER BY r.finished_at, b.id`
+
+	var books []bm.Book
+	if err := s.SelectContext(ctx, &books, q, bm.TenantFromCtx(ctx), bm.StatusFinished, from, to); err != nil {
+		return nil, bm.NewInternalError("select finished books: %w", err)
+	}
+
+	if err := s.loadBookDetails(ctx, books); err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+// loadReadings fills reading states of books.
+func (s *DB) loadReadings(ctx context.Context, books []bm.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(books))
+	positions := make(map[int64]int, len(books))
+	for i, b := range books {
+		ids = append(ids, b.ID)
+		positions[b.ID] = i
+	}
+
+	q := `SELECT book_id, status, started_at, finished_at, current_page, notes FROM reading_states
+		WHERE book_id = ANY($1)`
+
+	var rows []readingRow
+	if err := s.SelectContext(ctx, &rows, q, pq.Array(ids)); err != nil {
+		return bm.NewInternalError("select reading states: %w", err)
+	}
+
+	for _, r := range rows {
+		books[positions[r.BookID]].Reading = r.reading()
+	}
+
+	return nil
+}
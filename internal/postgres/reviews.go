@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// Review gets review of a book by id.
+func (s *DB) Review(ctx context.Context, bookID, id int64) (*bm.Review, error) {
+	q := `SELECT r.id, r.book_id, r.rating, r.text, r.created_at, r.updated_at FROM reviews r
+		JOIN books b ON b.id = r.book_id
+		WHERE r.id = $1 AND r.book_id = $2 AND b.tenant = $3`
+
+	review := new(bm.Review)
+	err := s.GetContext(ctx, review, q, id, bookID, bm.TenantFromCtx(ctx))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, bm.NewNotFoundError("review not found: %w", err)
+
+	case err != nil:
+		return nil, bm.NewInternalError("select review: %w", err)
+
+	default:
+		return review, nil
+	}
+}
+
+// Reviews gets reviews of a book by filter.
+func (s *DB) Reviews(ctx context.Context, f bm.ReviewsFilter) ([]bm.Review, error) {
+	q := `SELECT r.id, r.book_id, r.rating, r.text, r.created_at, r.updated_at FROM reviews r
+		JOIN books b ON b.id = r.book_id
+		WHERE r.book_id = :book_id AND b.tenant = :tenant `
+	params := map[string]any{"book_id": f.BookID, "tenant": bm.TenantFromCtx(ctx)}
+
+	q += orderBy("r", f.OrderBy, f.Desc)
+	q += pagination(f.Page, f.PageSize)
+
+	rows, err := s.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		return nil, bm.NewInternalError("select reviews: %w", err)
+	}
+
+	defer func() {
+		err = bm.HandleErrPair(rows.Close(), err)
+	}()
+
+	var reviews []bm.Review
+	if err = sqlx.StructScan(rows, &reviews); err != nil {
+		return nil, bm.NewInternalError("copy data into struct: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// CreateReview creates review of a book of the tenant of the caller.
+func (s *DB) CreateReview(ctx context.Context, r bm.Review) (int64, error) {
+	q := `INSERT INTO reviews (book_id, rating, text)
+		SELECT id, $2, $3 FROM books WHERE id = $1 AND tenant = $4
+		RETURNING id`
+
+	tenant := bm.TenantFromCtx(ctx)
+
+	var id int64
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, q, r.BookID, r.Rating, r.Text, tenant).Scan(&id)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return bm.NewNotFoundError("book with ID %d not found", r.BookID)
+
+		case err != nil:
+			return bm.NewInternalError("insert review: %w", err)
+
+		default:
+			return recordChanges(ctx, tx, tenant, bm.EntityReview, bm.OpCreated, []int64{id}, bookRef{BookID: r.BookID})
+		}
+	})
+	if err != nil {
+		return 0, fmt.Errorf("execute tx: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateReview updates rating and text of a review.
+func (s *DB) UpdateReview(ctx context.Context, r bm.Review) error {
+	q := `UPDATE reviews r SET rating = $1, text = $2, updated_at = now()
+		FROM books b
+		WHERE b.id = r.book_id AND r.id = $3 AND r.book_id = $4 AND b.tenant = $5`
+
+	tenant := bm.TenantFromCtx(ctx)
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, q, r.Rating, r.Text, r.ID, r.BookID, tenant)
+		if err != nil {
+			return bm.NewInternalError("update review: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return bm.NewInternalError("get the number of affected rows: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return bm.NewNotFoundError("review with ID %d not found", r.ID)
+		}
+
+		return recordChanges(ctx, tx, tenant, bm.EntityReview, bm.OpUpdated, []int64{r.ID}, bookRef{BookID: r.BookID})
+	})
+	if err != nil {
+		return fmt.Errorf("execute tx: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteReview deletes a review of a book.
+func (s *DB) DeleteReview(ctx context.Context, bookID, id int64) error {
+	q := `DELETE FROM reviews r USING books b
+		WHERE b.id = r.book_id AND r.id = $1 AND r.book_id = $2 AND b.tenant = $3`
+
+	tenant := bm.TenantFromCtx(ctx)
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, q, id, bookID, tenant)
+		if err != nil {
+			return bm.NewInternalError("delete review: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return bm.NewInternalError("get the number of affected rows: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return bm.NewNotFoundError("review with ID %d not found", id)
+		}
+
+		return recordChanges(ctx, tx, tenant, bm.EntityReview, bm.OpDeleted, []int64{id}, bookRef{BookID: bookID})
+	})
+	if err != nil {
+		return fmt.Errorf("execute tx: %w", err)
+	}
+
+	return nil
+}
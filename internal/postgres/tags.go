@@ -0,0 +1,211 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// Tags gets tags of the tenant with the number of their books.
+func (s *DB) Tags(ctx context.Context, f bm.TagsFilter) ([]bm.Tag, error) {
+	q := `SELECT * FROM (
+			SELECT t.id, t.name, COUNT(bt.book_id) AS books FROM tags t
+			LEFT JOIN book_tags bt ON bt.tag_id = t.id
+			WHERE t.tenant = :tenant
+			GROUP BY t.id, t.name
+		) t `
+	params := map[string]any{"tenant": bm.TenantFromCtx(ctx)}
+
+	q += orderBy("t", f.OrderBy, f.Desc)
+	q += pagination(f.Page, f.PageSize)
+
+	rows, err := s.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		return nil, bm.NewInternalError("select tags: %w", err)
+	}
+
+	defer func() {
+		err = bm.HandleErrPair(rows.Close(), err)
+	}()
+
+	var tags []bm.Tag
+	if err = sqlx.StructScan(rows, &tags); err != nil {
+		return nil, bm.NewInternalError("copy data into struct: %w", err)
+	}
+
+	return tags, nil
+}
+
+// AddBooksTags adds tags to books of the tenant of the caller.
+func (s *DB) AddBooksTags(ctx context.Context, bookIDs []int64, tags []string) error {
+	tenant := bm.TenantFromCtx(ctx)
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		if err := checkBooks(ctx, tx, tenant, bookIDs); err != nil {
+			return err
+		}
+
+		if err := addTags(ctx, tx, tenant, bookIDs, tags); err != nil {
+			return err
+		}
+
+		// Tags are details of books.
+		return recordChanges(ctx, tx, tenant, bm.EntityBook, bm.OpUpdated, bookIDs, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("execute tx: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteBooksTags removes tags from books of the tenant of the caller.
+func (s *DB) DeleteBooksTags(ctx context.Context, bookIDs []int64, tags []string) error {
+	tenant := bm.TenantFromCtx(ctx)
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		if err := checkBooks(ctx, tx, tenant, bookIDs); err != nil {
+			return err
+		}
+
+		q := `DELETE FROM book_tags bt USING books b, tags t
+			WHERE bt.book_id = b.id AND bt.tag_id = t.id
+				AND b.id = ANY($1) AND b.tenant = $2 AND t.name = ANY($3)`
+		if _, err := tx.ExecContext(ctx, q, pq.Array(bookIDs), tenant, pq.Array(tags)); err != nil {
+			return bm.NewInternalError("delete book tags: %w", err)
+		}
+
+		if err := deleteUnusedTags(ctx, tx, tenant); err != nil {
+			return err
+		}
+
+		return recordChanges(ctx, tx, tenant, bm.EntityBook, bm.OpUpdated, bookIDs, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("execute tx: %w", err)
+	}
+
+	return nil
+}
+
+// checkBooks checks that all books exist in the tenant.
+func checkBooks(ctx context.Context, tx *sql.Tx, tenant string, bookIDs []int64) error {
+	var count int
+	q := `SELECT COUNT(*) FROM books WHERE id = ANY($1) AND tenant = $2`
+	if err := tx.QueryRowContext(ctx, q, pq.Array(bookIDs), tenant).Scan(&count); err != nil {
+		return bm.NewInternalError("count books: %w", err)
+	}
+
+	if count != len(bookIDs) {
+		return bm.NewNotFoundError("books %v not found", bookIDs)
+	}
+
+	return nil
+}
+
+// addTags creates missing tags of the tenant and links them to books.
+func addTags(ctx context.Context, tx *sql.Tx, tenant string, bookIDs []int64, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	q := `INSERT INTO tags (tenant, name) SELECT $1, unnest($2::VARCHAR[]) ON CONFLICT DO NOTHING`
+	if _, err := tx.ExecContext(ctx, q, tenant, pq.Array(tags)); err != nil {
+		return bm.NewInternalError("insert tags: %w", err)
+	}
+
+	q = `INSERT INTO book_tags (book_id, tag_id)
+		SELECT b.id, t.id FROM books b
+		JOIN tags t ON t.tenant = b.tenant
+		WHERE b.id = ANY($1) AND b.tenant = $2 AND t.name = ANY($3)
+		ON CONFLICT DO NOTHING`
+	if _, err := tx.ExecContext(ctx, q, pq.Array(bookIDs), tenant, pq.Array(tags)); err != nil {
+		return bm.NewInternalError("insert book tags: %w", err)
+	}
+
+	return nil
+}
+
+// deleteUnusedTags deletes tags of the tenants without books.
+func deleteUnusedTags(ctx context.Context, tx *sql.Tx, tenants ...string) error {
+	if len(tenants) == 0 {
+		return nil
+	}
+
+	q := `DELETE FROM tags t
+		WHERE t.tenant = ANY($1) AND NOT EXISTS (SELECT 1 FROM book_tags bt WHERE bt.tag_id = t.id)`
+	if _, err := tx.ExecContext(ctx, q, pq.Array(tenants)); err != nil {
+		return bm.NewInternalError("delete unused tags: %w", err)
+	}
+
+	return nil
+}
+
+type bookTag struct {
+	BookID int64  `db:"book_id"`
+	Name   string `db:"name"`
+}
+
+// loadTags fills tags of books.
+func (s *DB) loadTags(ctx context.Context, books []bm.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(books))
+	positions := make(map[int64]int, len(books))
+	for i, b := range books {
+		ids = append(ids, b.ID)
+		positions[b.ID] = i
+	}
+
+	q := `SELECT bt.book_id, t.name FROM book_tags bt
+		JOIN tags t ON t.id = bt.tag_id
+		WHERE bt.book_id = ANY($1)
+		ORDER BY t.name`
+
+	var tags []bookTag
+	if err := s.SelectContext(ctx, &tags, q, pq.Array(ids)); err != nil {
+		return bm.NewInternalError("select book tags: %w", err)
+	}
+
+	for _, t := range tags {
+		b := &books[positions[t.BookID]]
+		b.Tags = append(b.Tags, t.Name)
+	}
+
+	return nil
+}
+
+// relinkTags points books of the tenant to tags of the same tenant after books were moved.
+func relinkTags(ctx context.Context, tx *sql.Tx, tenant string) error {
+	q := `INSERT INTO tags (tenant, name)
+		SELECT DISTINCT b.tenant, t.name FROM book_tags bt
+		JOIN books b ON b.id = bt.book_id
+		JOIN tags t ON t.id = bt.tag_id
+		WHERE b.tenant = $1 AND t.tenant <> b.tenant
+		ON CONFLICT DO NOTHING`
+	if _, err := tx.ExecContext(ctx, q, tenant); err != nil {
+		return bm.NewInternalError("copy tags: %w", err)
+	}
+
+	q = `WITH u AS (
+			UPDATE book_tags bt SET tag_id = n.id
+			FROM books b, tags t, tags n
+			WHERE bt.book_id = b.id AND bt.tag_id = t.id AND b.tenant = $1
+				AND t.tenant <> b.tenant AND n.tenant = b.tenant AND n.name = t.name
+			RETURNING t.tenant
+		)
+		SELECT COALESCE(array_agg(DISTINCT tenant), '{}') FROM u`
+
+	// Tags of the previous tenants may be left without books.
+	var previous pq.StringArray
+	if err := tx.QueryRowContext(ctx, q, tenant).Scan(&previous); err != nil {
+		return bm.NewInternalError("relink tags: %w", err)
+	}
+
+	return deleteUnusedTags(ctx, tx, previous...)
+}
@@ -0,0 +1,331 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// webhookRow is a webhook as it is stored, events are a postgres array.
+type webhookRow struct {
+	ID        int64          `db:"id"`
+	URL       string         `db:"url"`
+	Secret    string         `db:"secret"`
+	Events    pq.StringArray `db:"events"`
+	Active    bool           `db:"active"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+func (r webhookRow) webhook() bm.Webhook {
+	return bm.Webhook{
+		ID:        r.ID,
+		URL:       r.URL,
+		Secret:    r.Secret,
+		Events:    r.Events,
+		Active:    r.Active,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+const deliveriesSelect = `SELECT d.id, d.webhook_id, d.event_id, e.type AS event_type, d.status, d.attempts,
+	d.next_attempt_at, d.last_error, d.created_at, d.updated_at FROM webhook_deliveries d
+	JOIN webhooks w ON w.id = d.webhook_id
+	JOIN webhook_events e ON e.id = d.event_id `
+
+// Webhook gets a webhook by id.
+func (s *DB) Webhook(ctx context.Context, id int64) (*bm.Webhook, error) {
+	q := `SELECT id, url, secret, events, active, created_at FROM webhooks WHERE id = $1 AND tenant = $2`
+
+	row := new(webhookRow)
+	err := s.GetContext(ctx, row, q, id, bm.TenantFromCtx(ctx))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, bm.NewNotFoundError("webhook with ID %d not found", id)
+
+	case err != nil:
+		return nil, bm.NewInternalError("select webhook: %w", err)
+
+	default:
+		w := row.webhook()
+
+		return &w, nil
+	}
+}
+
+// Webhooks gets webhooks ordered by id.
+func (s *DB) Webhooks(ctx context.Context, f bm.WebhooksFilter) ([]bm.Webhook, error) {
+	q := `SELECT id, url, secret, events, active, created_at FROM webhooks WHERE tenant = $1 ORDER BY id `
+	q += pagination(f.Page, f.PageSize)
+
+	var rows []webhookRow
+	if err := s.SelectContext(ctx, &rows, q, bm.TenantFromCtx(ctx)); err != nil {
+		return nil, bm.NewInternalError("select webhooks: %w", err)
+	}
+
+	webhooks := make([]bm.Webhook, 0, len(rows))
+	for _, r := range rows {
+		webhooks = append(webhooks, r.webhook())
+	}
+
+	return webhooks, nil
+}
+
+// CreateWebhook creates a webhook of the tenant of the caller.
+func (s *DB) CreateWebhook(ctx context.Context, w bm.Webhook) (int64, error) {
+	q := `INSERT INTO webhooks (tenant, url, secret, events, active) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+
+	var id int64
+	err := s.QueryRowContext(ctx, q, bm.TenantFromCtx(ctx), w.URL, w.Secret, pq.Array(w.Events), w.Active).Scan(&id)
+	if err != nil {
+		return 0, bm.NewInternalError("insert webhook: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateWebhook updates a webhook, an empty secret keeps the current one.
+func (s *DB) UpdateWebhook(ctx context.Context, w bm.Webhook) error {
+	q := `UPDATE webhooks SET url = $1, secret = COALESCE(NULLIF($2, ''), secret), events = $3, active = $4
+		WHERE id = $5 AND tenant = $6`
+
+	result, err := s.ExecContext(ctx, q, w.URL, w.Secret, pq.Array(w.Events), w.Active, w.ID, bm.TenantFromCtx(ctx))
+	if err != nil {
+		return bm.NewInternalError("update webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return bm.NewInternalError("get the number of affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return bm.NewNotFoundError("webhook with ID %d not found", w.ID)
+	}
+
+	return nil
+}
+
+// DeleteWebhook deletes a webhook, its deliveries are deleted by the cascade.
+func (s *DB) DeleteWebhook(ctx context.Context, id int64) error {
+	q := `DELETE FROM webhooks WHERE id = $1 AND tenant = $2`
+
+	result, err := s.ExecContext(ctx, q, id, bm.TenantFromCtx(ctx))
+	if err != nil {
+		return bm.NewInternalError("delete webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return bm.NewInternalError("get the number of affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return bm.NewNotFoundError("webhook with ID %d not found", id)
+	}
+
+	return nil
+}
+
+// CreateWebhookEvents stores events of the tenant of the caller and queues their deliveries in one transaction.
+func (s *DB) CreateWebhookEvents(ctx context.Context, events ...bm.WebhookEvent) error {
+	tenant := bm.TenantFromCtx(ctx)
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		for _, e := range events {
+			q := `INSERT INTO webhook_events (tenant, type, data) VALUES ($1, $2, $3) RETURNING id`
+
+			var id int64
+			if err := tx.QueryRowContext(ctx, q, tenant, e.Type, string(e.Data)).Scan(&id); err != nil {
+				return bm.NewInternalError("insert webhook event: %w", err)
+			}
+
+			q = `INSERT INTO webhook_deliveries (webhook_id, event_id)
+				SELECT id, $1 FROM webhooks WHERE tenant = $2 AND active AND events && $3`
+			if _, err := tx.ExecContext(ctx, q, id, tenant, pq.Array(bm.EventPatterns(e.Type))); err != nil {
+				return bm.NewInternalError("insert webhook deliveries: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("execute tx: %w", err)
+	}
+
+	return nil
+}
+
+// WebhookDelivery gets a delivery of a webhook with its attempts ordered by attempt number.
+func (s *DB) WebhookDelivery(ctx context.Context, webhookID, id int64) (*bm.WebhookDelivery, error) {
+	q := deliveriesSelect + `WHERE d.id = $1 AND d.webhook_id = $2 AND w.tenant = $3`
+
+	d := new(bm.WebhookDelivery)
+	err := s.GetContext(ctx, d, q, id, webhookID, bm.TenantFromCtx(ctx))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, bm.NewNotFoundError("webhook delivery with ID %d not found", id)
+
+	case err != nil:
+		return nil, bm.NewInternalError("select webhook delivery: %w", err)
+	}
+
+	q = `SELECT delivery_id, attempt, status_code, error, duration_ms, created_at FROM webhook_attempts
+		WHERE delivery_id = $1
+		ORDER BY attempt`
+
+	rows, err := s.QueryContext(ctx, q, id)
+	if err != nil {
+		return nil, bm.NewInternalError("select webhook attempts: %w", err)
+	}
+
+	defer func() {
+		err = bm.HandleErrPair(rows.Close(), err)
+	}()
+
+	for rows.Next() {
+		var (
+			a        bm.WebhookAttempt
+			duration int64
+		)
+		if err = rows.Scan(&a.DeliveryID, &a.Attempt, &a.StatusCode, &a.Error, &duration, &a.CreatedAt); err != nil {
+			return nil, bm.NewInternalError("scan webhook attempt: %w", err)
+		}
+
+		a.Duration = time.Duration(duration) * time.Millisecond
+		d.AttemptLog = append(d.AttemptLog, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, bm.NewInternalError("read webhook attempts: %w", err)
+	}
+
+	return d, nil
+}
+
+// WebhookDeliveries gets deliveries of a webhook from the newest.
+func (s *DB) WebhookDeliveries(ctx context.Context, f bm.WebhookDeliveriesFilter) ([]bm.WebhookDelivery, error) {
+	q := deliveriesSelect + `WHERE d.webhook_id = $1 AND w.tenant = $2 AND ($3::text = '' OR d.status = $3)
+		ORDER BY d.id DESC `
+	q += pagination(f.Page, f.PageSize)
+
+	var deliveries []bm.WebhookDelivery
+	if err := s.SelectContext(ctx, &deliveries, q, f.WebhookID, bm.TenantFromCtx(ctx), f.Status); err != nil {
+		return nil, bm.NewInternalError("select webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RedeliverWebhookEvent queues the event of a delivery to the webhook once again.
+func (s *DB) RedeliverWebhookEvent(ctx context.Context, webhookID, deliveryID int64) (int64, error) {
+	q := `INSERT INTO webhook_deliveries (webhook_id, event_id)
+		SELECT d.webhook_id, d.event_id FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.id = $1 AND d.webhook_id = $2 AND w.tenant = $3
+		RETURNING id`
+
+	var id int64
+	err := s.QueryRowContext(ctx, q, deliveryID, webhookID, bm.TenantFromCtx(ctx)).Scan(&id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, bm.NewNotFoundError("webhook delivery with ID %d not found", deliveryID)
+
+	case err != nil:
+		return 0, bm.NewInternalError("insert webhook delivery: %w", err)
+
+	default:
+		return id, nil
+	}
+}
+
+// ClaimWebhookDeliveries leases due deliveries. Locked rows are skipped, so concurrent dispatchers
+// never claim the same delivery.
+func (s *DB) ClaimWebhookDeliveries(ctx context.Context, limit int64, leaseUntil time.Time) ([]bm.WebhookTask, error) {
+	q := `WITH claimed AS (
+			UPDATE webhook_deliveries SET next_attempt_at = $2, updated_at = now()
+			WHERE id IN (
+				SELECT d.id FROM webhook_deliveries d
+				JOIN webhooks w ON w.id = d.webhook_id
+				WHERE d.status = 'pending' AND d.next_attempt_at <= now() AND w.active
+				ORDER BY d.next_attempt_at
+				LIMIT $1
+				FOR UPDATE OF d SKIP LOCKED
+			)
+			RETURNING id, webhook_id, event_id, attempts
+		)
+		SELECT c.id, c.webhook_id, c.event_id, c.attempts, w.url, w.secret, e.type, e.data, e.created_at
+		FROM claimed c
+		JOIN webhooks w ON w.id = c.webhook_id
+		JOIN webhook_events e ON e.id = c.event_id
+		ORDER BY c.id`
+
+	rows, err := s.QueryContext(ctx, q, limit, leaseUntil)
+	if err != nil {
+		return nil, bm.NewInternalError("claim webhook deliveries: %w", err)
+	}
+
+	defer func() {
+		err = bm.HandleErrPair(rows.Close(), err)
+	}()
+
+	var tasks []bm.WebhookTask
+	for rows.Next() {
+		var t bm.WebhookTask
+		err = rows.Scan(
+			&t.Delivery.ID,
+			&t.Delivery.WebhookID,
+			&t.Delivery.EventID,
+			&t.Delivery.Attempts,
+			&t.URL,
+			&t.Secret,
+			&t.Event.Type,
+			&t.Event.Data,
+			&t.Event.CreatedAt,
+		)
+		if err != nil {
+			return nil, bm.NewInternalError("scan webhook delivery: %w", err)
+		}
+
+		t.Delivery.Status = bm.DeliveryPending
+		t.Delivery.EventType = t.Event.Type
+		t.Event.ID = t.Delivery.EventID
+		tasks = append(tasks, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, bm.NewInternalError("read webhook deliveries: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// RecordWebhookAttempt updates a delivery and appends the attempt to its log in one transaction.
+func (s *DB) RecordWebhookAttempt(ctx context.Context, d bm.WebhookDelivery, a bm.WebhookAttempt) error {
+	err := s.withTX(ctx, func(tx *sql.Tx) error {
+		q := `UPDATE webhook_deliveries SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4,
+			updated_at = now()
+			WHERE id = $5`
+		if _, err := tx.ExecContext(ctx, q, d.Status, d.Attempts, d.NextAttemptAt, d.LastError, d.ID); err != nil {
+			return bm.NewInternalError("update webhook delivery: %w", err)
+		}
+
+		q = `INSERT INTO webhook_attempts (delivery_id, attempt, status_code, error, duration_ms)
+			VALUES ($1, $2, $3, $4, $5)`
+		_, err := tx.ExecContext(ctx, q, d.ID, a.Attempt, a.StatusCode, a.Error, a.Duration.Milliseconds())
+		if err != nil {
+			return bm.NewInternalError("insert webhook attempt: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("execute tx: %w", err)
+	}
+
+	return nil
+}
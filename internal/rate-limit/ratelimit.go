@@ -0,0 +1,140 @@
+// Package ratelimit limits requests of clients by token buckets, separately for reading, writing and bulk calls.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+// Class is a class of calls limited together.
+type Class int
+
+const (
+	Read Class = iota
+	Write
+	Bulk
+)
+
+// Config contains limits for every class of calls. Zero limit disables limiting.
+type Config struct {
+	Reads  Limit
+	Writes Limit
+	Bulk   Limit
+}
+
+// Limit configures a token bucket: it holds Burst tokens and gains Rate tokens per second.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Quota describes the state of a bucket after taking a token.
+type Quota struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Limiter keeps buckets of clients for every limited class.
+type Limiter struct {
+	limiters map[Class]*limiter
+}
+
+func New(cfg Config) *Limiter {
+	limiters := make(map[Class]*limiter)
+	for class, limit := range map[Class]Limit{
+		Read:  cfg.Reads,
+		Write: cfg.Writes,
+		Bulk:  cfg.Bulk,
+	} {
+		if limit.Rate > 0 && limit.Burst > 0 {
+			limiters[class] = newLimiter(limit)
+		}
+	}
+
+	return &Limiter{limiters: limiters}
+}
+
+// Take takes a token from the bucket of the client. False is returned if calls of the class aren't limited.
+func (l *Limiter) Take(class Class, key string, now time.Time) (Quota, bool) {
+	cl, ok := l.limiters[class]
+	if !ok {
+		return Quota{}, false
+	}
+
+	return cl.take(key, now), true
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limiter is a set of token buckets sharing the same limit.
+type limiter struct {
+	limit Limit
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func newLimiter(limit Limit) *limiter {
+	return &limiter{
+		limit:   limit,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (l *limiter) take(key string, now time.Time) Quota {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	burst := float64(l.limit.Burst)
+
+	if now.Sub(l.lastSweep) > sweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*l.limit.Rate)
+	b.last = now
+
+	q := Quota{Limit: l.limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		q.Allowed = true
+	} else {
+		q.RetryAfter = l.refillTime(1 - b.tokens)
+	}
+
+	q.Remaining = int(b.tokens)
+	q.Reset = l.refillTime(burst - b.tokens)
+
+	return q
+}
+
+func (l *limiter) refillTime(tokens float64) time.Duration {
+	return time.Duration(tokens / l.limit.Rate * float64(time.Second))
+}
+
+// sweep removes buckets which are full again.
+func (l *limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.limit.Rate >= float64(l.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+
+	l.lastSweep = now
+}
@@ -0,0 +1,201 @@
+package storagecache
+
+import (
+	"context"
+	"time"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// Mutations drop the cached queries they may change after they are done, even if they fail:
+// the failure may come after the commit. Files are not parts of cached books, so their mutations pass through.
+
+// CreateBook creates a book and drops queries of books by filters.
+func (c *Cache) CreateBook(ctx context.Context, b bm.Book) (int64, error) {
+	defer c.invalidateBooks(bm.TenantFromCtx(ctx))
+
+	return c.Storage.CreateBook(ctx, b)
+}
+
+// UpdateBook updates a book and drops it.
+func (c *Cache) UpdateBook(ctx context.Context, b bm.Book) error {
+	defer c.invalidateBooks(bm.TenantFromCtx(ctx), b.ID)
+
+	return c.Storage.UpdateBook(ctx, b)
+}
+
+// DeleteBooks deletes books and drops them.
+func (c *Cache) DeleteBooks(ctx context.Context, ids []int64, force bool) (*bm.DeletedBooks, error) {
+	defer c.invalidateBooks(bm.TenantFromCtx(ctx), ids...)
+
+	return c.Storage.DeleteBooks(ctx, ids, force)
+}
+
+// SetBookCover sets the cover of a book and drops the book.
+func (c *Cache) SetBookCover(ctx context.Context, bookID int64, cover string) (string, error) {
+	defer c.invalidateBooks(bm.TenantFromCtx(ctx), bookID)
+
+	return c.Storage.SetBookCover(ctx, bookID, cover)
+}
+
+// UpdateCollection updates a collection and drops it.
+func (c *Cache) UpdateCollection(ctx context.Context, collection bm.Collection) error {
+	defer c.invalidateCollection(bm.TenantFromCtx(ctx), collection.ID)
+
+	return c.Storage.UpdateCollection(ctx, collection)
+}
+
+// DeleteCollection deletes a collection and drops it together with queries of books by filters.
+func (c *Cache) DeleteCollection(ctx context.Context, id int64) error {
+	tenant := bm.TenantFromCtx(ctx)
+	defer c.invalidateBooks(tenant)
+	defer c.invalidateCollection(tenant, id)
+
+	return c.Storage.DeleteCollection(ctx, id)
+}
+
+// CreateBooksCollection adds books to a collection and drops queries of books by filters.
+func (c *Cache) CreateBooksCollection(ctx context.Context, collectionID int64, bookIDs []int64) error {
+	defer c.invalidateBooks(bm.TenantFromCtx(ctx))
+
+	return c.Storage.CreateBooksCollection(ctx, collectionID, bookIDs)
+}
+
+// DeleteBooksCollection removes books from a collection and drops queries of books by filters.
+func (c *Cache) DeleteBooksCollection(ctx context.Context, collectionID int64, bookIDs []int64) error {
+	defer c.invalidateBooks(bm.TenantFromCtx(ctx))
+
+	return c.Storage.DeleteBooksCollection(ctx, collectionID, bookIDs)
+}
+
+// UpdateAuthor updates an author and drops all books, since any of them may have the author.
+func (c *Cache) UpdateAuthor(ctx context.Context, a bm.Author) error {
+	defer c.invalidateAllBooks(bm.TenantFromCtx(ctx))
+
+	return c.Storage.UpdateAuthor(ctx, a)
+}
+
+// UpdateGenre updates a genre and drops all books, since any of them may have the genre.
+func (c *Cache) UpdateGenre(ctx context.Context, g bm.Genre) error {
+	defer c.invalidateAllBooks(bm.TenantFromCtx(ctx))
+
+	return c.Storage.UpdateGenre(ctx, g)
+}
+
+// DeleteAuthor deletes an author and drops all books, since queries of books may filter by the author.
+func (c *Cache) DeleteAuthor(ctx context.Context, id int64) error {
+	defer c.invalidateAllBooks(bm.TenantFromCtx(ctx))
+
+	return c.Storage.DeleteAuthor(ctx, id)
+}
+
+// DeleteGenre deletes a genre and drops all books, since queries of books may filter by the genre.
+func (c *Cache) DeleteGenre(ctx context.Context, id int64) error {
+	defer c.invalidateAllBooks(bm.TenantFromCtx(ctx))
+
+	return c.Storage.DeleteGenre(ctx, id)
+}
+
+// AddBooksTags tags books and drops them.
+func (c *Cache) AddBooksTags(ctx context.Context, bookIDs []int64, tags []string) error {
+	defer c.invalidateBooks(bm.TenantFromCtx(ctx), bookIDs...)
+
+	return c.Storage.AddBooksTags(ctx, bookIDs, tags)
+}
+
+// DeleteBooksTags untags books and drops them.
+func (c *Cache) DeleteBooksTags(ctx context.Context, bookIDs []int64, tags []string) error {
+	defer c.invalidateBooks(bm.TenantFromCtx(ctx), bookIDs...)
+
+	return c.Storage.DeleteBooksTags(ctx, bookIDs, tags)
+}
+
+// UpdateReading updates the reading state of a book and drops the book.
+func (c *Cache) UpdateReading(ctx context.Context, bookID int64, update func(current bm.Reading) (bm.Reading, error)) (*bm.Reading, error) {
+	defer c.invalidateBooks(bm.TenantFromCtx(ctx), bookID)
+
+	return c.Storage.UpdateReading(ctx, bookID, update)
+}
+
+// CreateReview reviews a book and drops the book with its rating.
+func (c *Cache) CreateReview(ctx context.Context, r bm.Review) (int64, error) {
+	defer c.invalidateBooks(bm.TenantFromCtx(ctx), r.BookID)
+
+	return c.Storage.CreateReview(ctx, r)
+}
+
+// UpdateReview updates a review and drops the book with its rating.
+func (c *Cache) UpdateReview(ctx context.Context, r bm.Review) error {
+	defer c.invalidateBooks(bm.TenantFromCtx(ctx), r.BookID)
+
+	return c.Storage.UpdateReview(ctx, r)
+}
+
+// DeleteReview deletes a review and drops the book with its rating.
+func (c *Cache) DeleteReview(ctx context.Context, bookID, id int64) error {
+	defer c.invalidateBooks(bm.TenantFromCtx(ctx), bookID)
+
+	return c.Storage.DeleteReview(ctx, bookID, id)
+}
+
+// CreateCopy adds a copy of a book and drops the book with its number of copies.
+func (c *Cache) CreateCopy(ctx context.Context, cp bm.Copy) (int64, error) {
+	defer c.invalidateBooks(bm.TenantFromCtx(ctx), cp.BookID)
+
+	return c.Storage.CreateCopy(ctx, cp)
+}
+
+// UpdateCopy updates a copy and drops its book with queries of books by filters. A copy never changes its book,
+// so the book is found before the update; all books are dropped if it isn't found.
+func (c *Cache) UpdateCopy(ctx context.Context, cp bm.Copy) error {
+	tenant := bm.TenantFromCtx(ctx)
+	if old, err := c.Storage.Copy(ctx, cp.ID); err == nil {
+		defer c.invalidateBooks(tenant, old.BookID)
+	} else {
+		defer c.invalidateAllBooks(tenant)
+	}
+
+	return c.Storage.UpdateCopy(ctx, cp)
+}
+
+// DeleteCopy deletes a copy and drops its book with the number of copies. A copy never changes its book,
+// so the book is found before the deletion; all books are dropped if it isn't found.
+func (c *Cache) DeleteCopy(ctx context.Context, id int64) error {
+	tenant := bm.TenantFromCtx(ctx)
+	if cp, err := c.Storage.Copy(ctx, id); err == nil {
+		defer c.invalidateBooks(tenant, cp.BookID)
+	} else {
+		defer c.invalidateAllBooks(tenant)
+	}
+
+	return c.Storage.DeleteCopy(ctx, id)
+}
+
+// LendBook lends a book and drops queries of books by filters, they may select books on loan.
+func (c *Cache) LendBook(ctx context.Context, l bm.Loan) (int64, error) {
+	defer c.invalidateBooks(bm.TenantFromCtx(ctx))
+
+	return c.Storage.LendBook(ctx, l)
+}
+
+// ReturnBook returns a book and drops queries of books by filters, they may select books on loan.
+func (c *Cache) ReturnBook(ctx context.Context, bookID int64, returnedAt time.Time) error {
+	defer c.invalidateBooks(bm.TenantFromCtx(ctx))
+
+	return c.Storage.ReturnBook(ctx, bookID, returnedAt)
+}
+
+// MoveBooks moves books to the tenant and drops all queries: the books may come from any tenant.
+func (c *Cache) MoveBooks(ctx context.Context, ids []int64, tenant string) (map[string][]int64, error) {
+	defer c.Reset()
+
+	return c.Storage.MoveBooks(ctx, ids, tenant)
+}
+
+// MoveCollection moves a collection with its books to the tenant and drops all queries:
+// the collection may come from any tenant.
+func (c *Cache) MoveCollection(ctx context.Context, id int64, tenant string) error {
+	defer c.Reset()
+
+	return c.Storage.MoveCollection(ctx, id, tenant)
+}
@@ -0,0 +1,394 @@
+// Package storagecache caches books and collections read from a storage.
+package storagecache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+const (
+	defaultSize = 10000
+	defaultTTL  = time.Minute
+)
+
+// Kinds of cached queries.
+const (
+	kindBook       = "book"
+	kindBooks      = "books"
+	kindCollection = "collection"
+)
+
+// Config contains settings of the cache. Zero values are replaced by defaults.
+// Size is the maximum number of cached queries, the least recently used ones are evicted.
+type Config struct {
+	Size int
+	TTL  time.Duration
+}
+
+// key identifies a cached query: a book or a collection by id or books by a normalized filter.
+type key struct {
+	tenant string
+	kind   string
+	id     int64
+	filter string
+}
+
+// group contains keys of queries of a kind of the tenant.
+type group struct {
+	tenant string
+	kind   string
+}
+
+type entry struct {
+	key       key
+	value     any
+	expiresAt time.Time
+}
+
+// Cache is a read-through cache of a storage. It keeps books and collections by id and books by filter,
+// mutations through the cache drop the queries they change. Changes made by other servers sharing the database
+// are passed to Invalidate.
+type Cache struct {
+	bm.Storage
+
+	cfg Config
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[key]*list.Element
+	groups  map[group]map[key]struct{}
+
+	// order keeps entries from the most to the least recently used.
+	order *list.List
+
+	// Versions of groups grow when their queries are dropped and the version of the cache grows on resets;
+	// a query loaded before either of them isn't cached.
+	version  uint64
+	versions map[group]uint64
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// New constructs a new cache of the storage.
+func New(db bm.Storage, cfg Config) *Cache {
+	if cfg.Size <= 0 {
+		cfg.Size = defaultSize
+	}
+
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+
+	return &Cache{
+		Storage:  db,
+		cfg:      cfg,
+		now:      time.Now,
+		entries:  make(map[key]*list.Element),
+		groups:   make(map[group]map[key]struct{}),
+		versions: make(map[group]uint64),
+		order:    list.New(),
+	}
+}
+
+// Stats returns counters of the cache.
+func (c *Cache) Stats() bm.CacheStats {
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+
+	return bm.CacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: int64(entries),
+	}
+}
+
+// Invalidate drops queries changed by the change of the tenant.
+func (c *Cache) Invalidate(tenant string, change bm.Change) {
+	switch change.Entity {
+	case bm.EntityBook:
+		if change.Op == bm.OpCreated {
+			c.invalidateBooks(tenant)
+		} else {
+			c.invalidateBooks(tenant, change.EntityID)
+		}
+
+	case bm.EntityCollection:
+		if change.Op == bm.OpUpdated || change.Op == bm.OpDeleted {
+			c.invalidateCollection(tenant, change.EntityID)
+		}
+
+		if change.Op != bm.OpCreated && change.Op != bm.OpUpdated {
+			c.invalidateBooks(tenant)
+		}
+
+	case bm.EntityReview, bm.EntityCopy:
+		var ref struct {
+			BookID int64 `json:"book_id"`
+		}
+		if err := json.Unmarshal(change.Data, &ref); err != nil || ref.BookID == 0 {
+			c.invalidateAllBooks(tenant)
+
+			return
+		}
+
+		c.invalidateBooks(tenant, ref.BookID)
+
+	case bm.EntityReading:
+		c.invalidateBooks(tenant, change.EntityID)
+
+	case bm.EntityLoan:
+		c.invalidateBooks(tenant)
+	}
+
+	// Authors and genres are cached only as parts of books; their updates come with changes of the books.
+}
+
+// Reset drops all queries.
+func (c *Cache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	clear(c.entries)
+	clear(c.groups)
+	c.order.Init()
+}
+
+// Book gets a book by its id from the cache or from the storage.
+func (c *Cache) Book(ctx context.Context, id int64) (*bm.Book, error) {
+	k := key{tenant: bm.TenantFromCtx(ctx), kind: kindBook, id: id}
+
+	return load(c, k, cloneBook, func() (*bm.Book, error) {
+		return c.Storage.Book(ctx, id)
+	})
+}
+
+// Books gets books by the filter from the cache or from the storage.
+func (c *Cache) Books(ctx context.Context, f bm.BookFilter) ([]bm.Book, error) {
+	k := key{tenant: bm.TenantFromCtx(ctx), kind: kindBooks, filter: filterKey(f)}
+
+	return load(c, k, cloneBooks, func() ([]bm.Book, error) {
+		return c.Storage.Books(ctx, f)
+	})
+}
+
+// Collection gets a collection by its id from the cache or from the storage.
+func (c *Cache) Collection(ctx context.Context, id int64) (*bm.Collection, error) {
+	k := key{tenant: bm.TenantFromCtx(ctx), kind: kindCollection, id: id}
+
+	return load(c, k, cloneCollection, func() (*bm.Collection, error) {
+		return c.Storage.Collection(ctx, id)
+	})
+}
+
+// load returns a copy of the cached result of the query or runs the query and caches a copy of its result.
+// Errors aren't cached.
+func load[T any](c *Cache, k key, clone func(T) T, query func() (T, error)) (T, error) {
+	if value, ok := c.get(k); ok {
+		return clone(value.(T)), nil
+	}
+
+	version, groupVersion := c.currentVersion(k)
+	value, err := query()
+	if err != nil {
+		return value, err
+	}
+
+	c.put(k, clone(value), version, groupVersion)
+
+	return value, nil
+}
+
+// currentVersion returns versions of the cache and of the group of the key.
+func (c *Cache) currentVersion(k key) (uint64, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.version, c.versions[group{tenant: k.tenant, kind: k.kind}]
+}
+
+func (c *Cache) get(k key) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[k]; ok {
+		e := el.Value.(*entry)
+		if c.now().Before(e.expiresAt) {
+			c.order.MoveToFront(el)
+			c.hits.Add(1)
+
+			return e.value, true
+		}
+
+		c.remove(el)
+	}
+
+	c.misses.Add(1)
+
+	return nil, false
+}
+
+// put caches the result of the query unless the cache or the group of the key was invalidated
+// after the query had started.
+func (c *Cache) put(k key, value any, version, groupVersion uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g := group{tenant: k.tenant, kind: k.kind}
+	if c.version != version || c.versions[g] != groupVersion {
+		return
+	}
+
+	expiresAt := c.now().Add(c.cfg.TTL)
+	if el, ok := c.entries[k]; ok {
+		e := el.Value.(*entry)
+		e.value = value
+		e.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+
+		return
+	}
+
+	c.entries[k] = c.order.PushFront(&entry{key: k, value: value, expiresAt: expiresAt})
+
+	if c.groups[g] == nil {
+		c.groups[g] = make(map[key]struct{})
+	}
+
+	c.groups[g][k] = struct{}{}
+
+	for len(c.entries) > c.cfg.Size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *Cache) remove(el *list.Element) {
+	k := el.Value.(*entry).key
+	c.order.Remove(el)
+	delete(c.entries, k)
+
+	g := group{tenant: k.tenant, kind: k.kind}
+	delete(c.groups[g], k)
+	if len(c.groups[g]) == 0 {
+		delete(c.groups, g)
+	}
+}
+
+// drop removes the queries of the kind of the tenant: the ones with the ids or all of them if no id is given.
+// The caller holds the lock.
+func (c *Cache) drop(tenant, kind string, ids ...int64) {
+	g := group{tenant: tenant, kind: kind}
+	c.versions[g]++
+
+	if len(ids) == 0 {
+		for k := range c.groups[g] {
+			c.remove(c.entries[k])
+		}
+
+		return
+	}
+
+	for _, id := range ids {
+		if el, ok := c.entries[key{tenant: tenant, kind: kind, id: id}]; ok {
+			c.remove(el)
+		}
+	}
+}
+
+// invalidateBooks drops the books with the ids and all queries of books by filters of the tenant,
+// since any change of a book may change their results.
+func (c *Cache) invalidateBooks(tenant string, ids ...int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(ids) != 0 {
+		c.drop(tenant, kindBook, ids...)
+	}
+
+	c.drop(tenant, kindBooks)
+}
+
+// invalidateAllBooks drops all books and queries of books of the tenant.
+func (c *Cache) invalidateAllBooks(tenant string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.drop(tenant, kindBook)
+	c.drop(tenant, kindBooks)
+}
+
+func (c *Cache) invalidateCollection(tenant string, id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.drop(tenant, kindCollection, id)
+}
+
+// filterKey normalizes the filter, so filters selecting the same books share a key.
+func filterKey(f bm.BookFilter) string {
+	f.Tags = slices.Clone(f.Tags)
+	slices.Sort(f.Tags)
+	f.Tags = slices.Compact(f.Tags)
+	switch len(f.Tags) {
+	case 0:
+		f.TagsMatch = ""
+	case 1:
+		f.TagsMatch = bm.TagsMatchAny
+	}
+
+	f.StartDate = f.StartDate.UTC()
+	f.FinishDate = f.FinishDate.UTC()
+
+	// The filter consists of strings, numbers, times and a bool, it is always marshaled.
+	data, _ := json.Marshal(f)
+
+	return string(data)
+}
+
+func cloneBook(b *bm.Book) *bm.Book {
+	if b == nil {
+		return nil
+	}
+
+	clone := *b
+	clone.Contributors = slices.Clone(b.Contributors)
+	clone.Tags = slices.Clone(b.Tags)
+	if b.Reading != nil {
+		reading := *b.Reading
+		clone.Reading = &reading
+	}
+
+	return &clone
+}
+
+func cloneBooks(books []bm.Book) []bm.Book {
+	if books == nil {
+		return nil
+	}
+
+	clone := make([]bm.Book, len(books))
+	for i := range books {
+		clone[i] = *cloneBook(&books[i])
+	}
+
+	return clone
+}
+
+func cloneCollection(c *bm.Collection) *bm.Collection {
+	if c == nil {
+		return nil
+	}
+
+	clone := *c
+
+	return &clone
+}
@@ -0,0 +1,180 @@
+package storagecache
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// fakeStorage counts queries and runs the hook inside of them.
+type fakeStorage struct {
+	bm.Storage
+
+	queries int
+	hook    func()
+}
+
+func (s *fakeStorage) Book(_ context.Context, id int64) (*bm.Book, error) {
+	s.queries++
+	if s.hook != nil {
+		s.hook()
+	}
+
+	if id == 0 {
+		return nil, bm.NewNotFoundError("book with ID %d not found", id)
+	}
+
+	return &bm.Book{ID: id, Tags: []string{"classic"}}, nil
+}
+
+func (s *fakeStorage) Books(context.Context, bm.BookFilter) ([]bm.Book, error) {
+	s.queries++
+
+	return []bm.Book{{ID: 1}}, nil
+}
+
+func (s *fakeStorage) UpdateBook(context.Context, bm.Book) error {
+	return nil
+}
+
+func (s *fakeStorage) Copy(_ context.Context, id int64) (*bm.Copy, error) {
+	return &bm.Copy{ID: id, BookID: 2}, nil
+}
+
+func (s *fakeStorage) UpdateCopy(context.Context, bm.Copy) error {
+	return nil
+}
+
+func (s *fakeStorage) DeleteGenre(context.Context, int64) error {
+	return nil
+}
+
+func tenantCtx(tenant string) context.Context {
+	return bm.WithIdentity(context.Background(), bm.Identity{Tenant: tenant})
+}
+
+func TestCacheBook(t *testing.T) {
+	db := new(fakeStorage)
+	c := New(db, Config{})
+	ctx := tenantCtx("default")
+
+	book, err := c.Book(ctx, 1)
+	require.NoError(t, err)
+	book.Tags[0] = "changed"
+
+	book, err = c.Book(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"classic"}, book.Tags)
+	assert.Equal(t, 1, db.queries)
+
+	// Errors aren't cached, tenants don't share books.
+	_, err = c.Book(ctx, 0)
+	assert.Error(t, err)
+	_, err = c.Book(ctx, 0)
+	assert.Error(t, err)
+
+	_, err = c.Book(tenantCtx("reader"), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 4, db.queries)
+	assert.Equal(t, bm.CacheStats{Hits: 1, Misses: 4, Entries: 2}, c.Stats())
+}
+
+func TestCacheExpiration(t *testing.T) {
+	db := new(fakeStorage)
+	c := New(db, Config{Size: 2, TTL: time.Minute})
+	now := time.Date(2024, time.March, 10, 15, 30, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	ctx := tenantCtx("default")
+
+	for _, id := range []int64{1, 2, 1, 3, 2} {
+		_, err := c.Book(ctx, id)
+		require.NoError(t, err)
+	}
+
+	// The least recently used book 2 was evicted by book 3.
+	assert.Equal(t, 4, db.queries)
+
+	now = now.Add(time.Minute)
+	_, err := c.Book(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5, db.queries)
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	db := new(fakeStorage)
+	c := New(db, Config{})
+	ctx := tenantCtx("default")
+
+	load := func() {
+		for _, id := range []int64{1, 2} {
+			_, err := c.Book(ctx, id)
+			require.NoError(t, err)
+		}
+
+		_, err := c.Books(ctx, bm.BookFilter{Tags: []string{"b", "a"}, TagsMatch: bm.TagsMatchAll})
+		require.NoError(t, err)
+	}
+
+	load()
+	require.Equal(t, 3, db.queries)
+
+	// The same books are selected by a filter with tags in another order.
+	_, err := c.Books(ctx, bm.BookFilter{Tags: []string{"a", "b"}, TagsMatch: bm.TagsMatchAll})
+	require.NoError(t, err)
+	assert.Equal(t, 3, db.queries)
+
+	// The updated book and filters are dropped.
+	require.NoError(t, c.UpdateBook(ctx, bm.Book{ID: 1}))
+	load()
+	assert.Equal(t, 5, db.queries)
+
+	// A review changes the rating of its book.
+	c.Invalidate("default", bm.Change{Entity: bm.EntityReview, EntityID: 7, Op: bm.OpCreated, Data: json.RawMessage(`{"book_id":2}`)})
+	load()
+	assert.Equal(t, 7, db.queries)
+
+	// An updated copy drops its book and filters.
+	require.NoError(t, c.UpdateCopy(ctx, bm.Copy{ID: 5}))
+	load()
+	assert.Equal(t, 9, db.queries)
+
+	// A deleted genre drops all books and filters.
+	require.NoError(t, c.DeleteGenre(ctx, 3))
+	load()
+	assert.Equal(t, 12, db.queries)
+
+	// Changes of other tenants are ignored.
+	c.Invalidate("reader", bm.Change{Entity: bm.EntityBook, EntityID: 1, Op: bm.OpUpdated})
+	load()
+	assert.Equal(t, 12, db.queries)
+
+	c.Reset()
+	load()
+	assert.Equal(t, 15, db.queries)
+	assert.Equal(t, int64(3), c.Stats().Entries)
+}
+
+func TestCacheSkipsStaleQueries(t *testing.T) {
+	db := new(fakeStorage)
+	c := New(db, Config{})
+	ctx := tenantCtx("default")
+
+	// The book is changed while it is read, the read result may be stale.
+	db.hook = func() {
+		c.Invalidate("default", bm.Change{Entity: bm.EntityBook, EntityID: 1, Op: bm.OpUpdated})
+	}
+
+	_, err := c.Book(ctx, 1)
+	require.NoError(t, err)
+
+	db.hook = nil
+	_, err = c.Book(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, db.queries)
+}
@@ -0,0 +1,34 @@
+//go:build linux
+
+package unixsocket
+
+import (
+	"net"
+	"syscall"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// PeerCred reads credentials of the process on the other side of a unix socket connection.
+func PeerCred(conn net.Conn) (bm.PeerCred, bool) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return bm.PeerCred{}, false
+	}
+
+	rawConn, err := unixConn.SyscallConn()
+	if err != nil {
+		return bm.PeerCred{}, false
+	}
+
+	var ucred *syscall.Ucred
+	var credErr error
+	err = rawConn.Control(func(fd uintptr) {
+		ucred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil || credErr != nil {
+		return bm.PeerCred{}, false
+	}
+
+	return bm.PeerCred{UID: ucred.Uid, GID: ucred.Gid}, true
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package unixsocket
+
+import (
+	"net"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// PeerCred is not supported on this platform.
+func PeerCred(net.Conn) (bm.PeerCred, bool) {
+	return bm.PeerCred{}, false
+}
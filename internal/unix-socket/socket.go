@@ -0,0 +1,227 @@
+// Package unixsocket listens on unix sockets of the servers and authorizes their peers.
+package unixsocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// staleSocketTimeout limits the check of a socket file left on the socket path.
+const staleSocketTimeout = time.Second
+
+// Config restricts access to the unix socket. Mode and Group set permissions and the group of
+// the socket file, zero values keep the defaults. Peers allow processes by uid of their owners;
+// any local user who can open the socket is allowed if Peers is empty.
+type Config struct {
+	Mode  os.FileMode
+	Group string
+	Peers []Peer
+}
+
+// Peer grants access to processes of the user.
+type Peer struct {
+	UID    uint32
+	Access Access
+}
+
+// Access is a level of access to the unix socket: read-only peers can't change the library.
+type Access string
+
+const (
+	ReadOnlyAccess  Access = "read-only"
+	ReadWriteAccess Access = "read-write"
+)
+
+// Listen listens on the socket path with the configured permissions. A socket file left by a server
+// which isn't running is removed first.
+//
+// The socket is created in a private directory and linked to the path after its permissions are set,
+// so nobody connects to it while it has the default ones. Closing the listener removes the socket file.
+func Listen(path string, cfg Config) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	gid := -1
+	if cfg.Group != "" {
+		var err error
+		if gid, err = lookupGroup(cfg.Group); err != nil {
+			return nil, err
+		}
+	}
+
+	// Names are short: paths of unix sockets are limited to about a hundred bytes.
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".bm")
+	if err != nil {
+		return nil, fmt.Errorf("create socket directory: %w", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	tmpPath := filepath.Join(dir, "s")
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: tmpPath, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+
+	// The listener knows only the temporary path, the socket file is removed by unixListener.
+	listener.SetUnlinkOnClose(false)
+
+	if err = chmodSocket(tmpPath, cfg.Mode, gid); err != nil {
+		return nil, bm.HandleErrPair(listener.Close(), err)
+	}
+
+	// Unlike rename, link doesn't replace a file created on the path after the check of stale sockets.
+	if err = os.Link(tmpPath, path); err != nil {
+		return nil, bm.HandleErrPair(listener.Close(), fmt.Errorf("link socket: %w", err))
+	}
+
+	return &unixListener{UnixListener: listener, path: path}, nil
+}
+
+// unixListener removes the socket file after the listener is closed.
+type unixListener struct {
+	*net.UnixListener
+
+	path string
+}
+
+func (l *unixListener) Close() error {
+	err := l.UnixListener.Close()
+	if removeErr := os.Remove(l.path); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+		err = bm.HandleErrPair(err, fmt.Errorf("remove socket: %w", removeErr))
+	}
+
+	return err
+}
+
+func chmodSocket(path string, mode os.FileMode, gid int) error {
+	if gid >= 0 {
+		if err := os.Chown(path, -1, gid); err != nil {
+			return fmt.Errorf("change group of socket: %w", err)
+		}
+	}
+
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			return fmt.Errorf("change mode of socket: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// removeStaleSocket removes the socket file if nobody accepts connections on it. Other files and sockets
+// of running servers are kept, a misconfigured path must not break them.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("stat socket: %w", err)
+	}
+
+	if info.Mode().Type() != os.ModeSocket {
+		return fmt.Errorf("%s exists and it is not a socket", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, staleSocketTimeout)
+	if err == nil {
+		conn.Close()
+
+		return fmt.Errorf("socket %s is in use", path)
+	}
+
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("check socket: %w", err)
+	}
+
+	log.Info().Msgf("remove stale socket %s", path)
+
+	if err = os.Remove(path); err != nil {
+		return fmt.Errorf("remove stale socket: %w", err)
+	}
+
+	return nil
+}
+
+// lookupGroup resolves a group by name or id.
+func lookupGroup(group string) (int, error) {
+	if gid, err := strconv.Atoi(group); err == nil {
+		return gid, nil
+	}
+
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return 0, fmt.Errorf("lookup group: %w", err)
+	}
+
+	gid, err := strconv.Atoi(g.Gid)
+	if err != nil {
+		return 0, fmt.Errorf("parse gid of group %s: %w", group, err)
+	}
+
+	return gid, nil
+}
+
+// Authorizer checks access of unix socket peers by their uids.
+type Authorizer struct {
+	access map[uint32]Access
+}
+
+// NewAuthorizer builds an authorizer of the peers, everybody is allowed without peers.
+func NewAuthorizer(peers []Peer) (*Authorizer, error) {
+	if len(peers) == 0 {
+		return new(Authorizer), nil
+	}
+
+	access := make(map[uint32]Access, len(peers))
+	for _, p := range peers {
+		if p.Access != ReadOnlyAccess && p.Access != ReadWriteAccess {
+			return nil, fmt.Errorf("unknown access %q of uid %d", p.Access, p.UID)
+		}
+
+		access[p.UID] = p.Access
+	}
+
+	return &Authorizer{access: access}, nil
+}
+
+// Authorize checks access of the peer with credentials in the context. Write is set for calls
+// which change the library.
+func (a *Authorizer) Authorize(ctx context.Context, write bool) error {
+	if a.access == nil {
+		return nil
+	}
+
+	// Credentials are unknown on platforms without SO_PEERCRED, nobody is allowed then.
+	cred, ok := bm.PeerCredFromCtx(ctx)
+	if !ok {
+		return bm.NewForbiddenError("credentials of the socket peer are unknown")
+	}
+
+	access, ok := a.access[cred.UID]
+	if !ok {
+		return bm.NewForbiddenError("uid %d is not allowed to use the socket", cred.UID)
+	}
+
+	if access == ReadOnlyAccess && write {
+		return bm.NewForbiddenError("uid %d has read-only access", cred.UID)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package unixsocket
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+func TestRemoveStaleSocket(t *testing.T) {
+	dir := t.TempDir()
+
+	// 1. Nothing to remove.
+	require.NoError(t, removeStaleSocket(filepath.Join(dir, "missing.sock")))
+
+	// 2. Other files are kept.
+	file := filepath.Join(dir, "file.sock")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	assert.Error(t, removeStaleSocket(file))
+	assert.FileExists(t, file)
+
+	// 3. Sockets of running servers are kept.
+	live := filepath.Join(dir, "live.sock")
+	listener, err := net.Listen("unix", live)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	assert.Error(t, removeStaleSocket(live))
+	assert.FileExists(t, live)
+
+	// 4. Sockets left by stopped servers are removed.
+	stale := filepath.Join(dir, "stale.sock")
+	staleListener, err := net.Listen("unix", stale)
+	require.NoError(t, err)
+	staleListener.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, staleListener.Close())
+
+	require.NoError(t, removeStaleSocket(stale))
+	assert.NoFileExists(t, stale)
+}
+
+func TestListen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bm.sock")
+	listener, err := Listen(path, Config{Mode: 0o660, Group: strconv.Itoa(os.Getgid())})
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o660), info.Mode().Perm())
+
+	// The socket is linked to the path, the private directory is removed.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	conn.Close()
+
+	// The socket is in use until the listener is closed.
+	_, err = Listen(path, Config{})
+	assert.Error(t, err)
+
+	require.NoError(t, listener.Close())
+	assert.NoFileExists(t, path)
+
+	_, err = Listen(filepath.Join(t.TempDir(), "bm.sock"), Config{Group: "no-such-group-of-bm"})
+	assert.Error(t, err)
+}
+
+func TestAuthorizer(t *testing.T) {
+	_, err := NewAuthorizer([]Peer{{UID: 1000, Access: "admin"}})
+	assert.Error(t, err)
+
+	a, err := NewAuthorizer([]Peer{
+		{UID: 1000, Access: ReadWriteAccess},
+		{UID: 1001, Access: ReadOnlyAccess},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		write   bool
+		cred    *bm.PeerCred
+		allowed bool
+	}{
+		{name: "read-write peer writes", write: true, cred: &bm.PeerCred{UID: 1000}, allowed: true},
+		{name: "read-only peer reads", cred: &bm.PeerCred{UID: 1001}, allowed: true},
+		{name: "read-only peer writes", write: true, cred: &bm.PeerCred{UID: 1001}, allowed: false},
+		{name: "unknown peer", cred: &bm.PeerCred{UID: 1002}, allowed: false},
+		{name: "unknown credentials", allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.cred != nil {
+				ctx = bm.WithPeerCred(ctx, *tt.cred)
+			}
+
+			err := a.Authorize(ctx, tt.write)
+			assert.Equal(t, tt.allowed, err == nil)
+			if err != nil {
+				assert.True(t, errors.As(err, new(bm.ForbiddenError)))
+			}
+		})
+	}
+
+	// Everybody is allowed without peers.
+	open, err := NewAuthorizer(nil)
+	require.NoError(t, err)
+	assert.NoError(t, open.Authorize(context.Background(), true))
+}
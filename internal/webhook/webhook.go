@@ -0,0 +1,337 @@
+// Package webhook delivers events of the library to webhooks.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	bm "github.com/Tsapen/bm/internal/bm"
+)
+
+// Headers of deliveries. The signature is "sha256=" followed by the hex encoded HMAC-SHA256 of
+// the timestamp, a dot and the body keyed by the secret of the webhook.
+const (
+	HeaderEvent     = "X-BM-Event"
+	HeaderDelivery  = "X-BM-Delivery"
+	HeaderTimestamp = "X-BM-Timestamp"
+	HeaderSignature = "X-BM-Signature"
+)
+
+const (
+	defaultWorkers      = 4
+	defaultPollInterval = time.Second
+	defaultTimeout      = 10 * time.Second
+	defaultMaxAttempts  = 8
+	defaultBaseBackoff  = 10 * time.Second
+	defaultMaxBackoff   = time.Hour
+	defaultBatchSize    = 20
+
+	// maxResponseSize limits the part of a response body read to keep the connection reusable.
+	maxResponseSize = 64 << 10
+)
+
+// Config contains settings of the dispatcher. Zero values are replaced by defaults.
+// A failed delivery is retried after BaseBackoff doubled after every attempt up to MaxBackoff,
+// it becomes dead after MaxAttempts attempts.
+// Deliveries to loopback, private, link-local and other non-public addresses are refused unless
+// the address is in AllowedNetworks.
+type Config struct {
+	Workers      int
+	PollInterval time.Duration
+	Timeout      time.Duration
+	MaxAttempts  int64
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	BatchSize    int64
+
+	AllowedNetworks []netip.Prefix
+}
+
+// Payload is the body of a delivery. ID is the id of the event, it is the same for redeliveries.
+type Payload struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Dispatcher sends pending deliveries in the background.
+type Dispatcher struct {
+	storage bm.Storage
+	client  *http.Client
+	cfg     Config
+}
+
+// New constructs a new dispatcher.
+func New(db bm.Storage, cfg Config) *Dispatcher {
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultWorkers
+	}
+
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = defaultBaseBackoff
+	}
+
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+
+	return &Dispatcher{
+		storage: db,
+		client:  newClient(cfg),
+		cfg:     cfg,
+	}
+}
+
+// newClient constructs a client which connects only to allowed addresses. Addresses are checked after
+// resolution, so a host resolving to an internal address later is refused too. Redirects aren't followed:
+// they could lead to internal addresses through an allowed one, and receivers must answer with 2xx anyway.
+func newClient(cfg Config) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: cfg.Timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			return checkAddress(address, cfg.AllowedNetworks)
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+
+	// A proxy would connect on behalf of the dispatcher, bypassing the check.
+	transport.Proxy = nil
+
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkAddress refuses non-public addresses which aren't in the allowed networks.
+func checkAddress(address string, allowed []netip.Prefix) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("parse address %s: %w", address, err)
+	}
+
+	addr := addrPort.Addr().Unmap()
+	for _, network := range allowed {
+		if network.Contains(addr) {
+			return nil
+		}
+	}
+
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return fmt.Errorf("address %s is not allowed", addr)
+	}
+
+	for _, network := range reservedNetworks {
+		if network.Contains(addr) {
+			return fmt.Errorf("address %s is not allowed", addr)
+		}
+	}
+
+	return nil
+}
+
+// reservedNetworks aren't reachable from the internet, but they aren't excluded by the checks of netip.Addr:
+// "this network" and the shared address space of carrier-grade NAT.
+var reservedNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// Run sends due deliveries every poll interval until the context is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.dispatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch claims due deliveries in batches and sends them with the workers until no full batch is left.
+func (d *Dispatcher) dispatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		// Workers send a batch in turns, the lease covers the slowest case; an expired lease only makes
+		// the delivery sent once more.
+		turns := (d.cfg.BatchSize + int64(d.cfg.Workers) - 1) / int64(d.cfg.Workers)
+		leaseUntil := time.Now().Add(time.Duration(turns+1) * d.cfg.Timeout)
+
+		tasks, err := d.storage.ClaimWebhookDeliveries(ctx, d.cfg.BatchSize, leaseUntil)
+		if err != nil {
+			log.Error().Err(err).Msg("claim webhook deliveries")
+
+			return
+		}
+
+		queue := make(chan bm.WebhookTask)
+		wg := new(sync.WaitGroup)
+		for i := 0; i < min(d.cfg.Workers, len(tasks)); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for t := range queue {
+					d.deliver(ctx, t)
+				}
+			}()
+		}
+
+		for _, t := range tasks {
+			queue <- t
+		}
+
+		close(queue)
+		wg.Wait()
+
+		if int64(len(tasks)) < d.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// deliver sends a delivery once and records the attempt. A failed delivery is scheduled for a retry
+// or becomes dead if it is out of attempts.
+func (d *Dispatcher) deliver(ctx context.Context, t bm.WebhookTask) {
+	start := time.Now()
+	statusCode, err := d.send(ctx, t)
+
+	delivery := t.Delivery
+	delivery.Attempts++
+	delivery.NextAttemptAt = time.Now()
+	attempt := bm.WebhookAttempt{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts,
+		StatusCode: int64(statusCode),
+		Duration:   time.Since(start),
+	}
+
+	switch {
+	case err == nil:
+		delivery.Status = bm.DeliverySucceeded
+		delivery.LastError = ""
+
+	case delivery.Attempts >= d.cfg.MaxAttempts:
+		delivery.Status = bm.DeliveryDead
+		delivery.LastError = err.Error()
+		attempt.Error = err.Error()
+
+	default:
+		delivery.Status = bm.DeliveryPending
+		delivery.NextAttemptAt = delivery.NextAttemptAt.Add(Backoff(delivery.Attempts, d.cfg.BaseBackoff, d.cfg.MaxBackoff))
+		delivery.LastError = err.Error()
+		attempt.Error = err.Error()
+	}
+
+	if err = d.storage.RecordWebhookAttempt(ctx, delivery, attempt); err != nil {
+		log.Error().Err(err).Int64("delivery_id", delivery.ID).Msg("record webhook attempt")
+	}
+}
+
+// send posts the event of a delivery to the webhook and returns the status code of the response.
+// Any status other than 2xx fails the delivery.
+func (d *Dispatcher) send(ctx context.Context, t bm.WebhookTask) (int, error) {
+	body, err := json.Marshal(Payload{
+		ID:        t.Event.ID,
+		Type:      t.Event.Type,
+		CreatedAt: t.Event.CreatedAt,
+		Data:      t.Event.Data,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, t.Event.Type)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(t.Delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(t.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("send request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign returns the value of the signature header of a delivery body sent at the unix timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns the delay before the next attempt after the given number of failed attempts:
+// base doubled after every attempt but the first one and capped by maxDelay.
+func Backoff(attempts int64, base, maxDelay time.Duration) time.Duration {
+	delay := base
+	for i := int64(1); i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxDelay {
+		return maxDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	base, maxDelay := 10*time.Second, time.Minute
+	for attempts, want := range map[int64]time.Duration{
+		1:  10 * time.Second,
+		2:  20 * time.Second,
+		3:  40 * time.Second,
+		4:  time.Minute,
+		64: time.Minute,
+	} {
+		assert.Equal(t, want, Backoff(attempts, base, maxDelay), "attempts %d", attempts)
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":1,"type":"book.created"}`)
+	signature := Sign("secret", 1700000000, body)
+
+	assert.Equal(t, "sha256=3fff6885bab2946491e507c105f1da59e48c7974c885bf64f79cd804c66caa70", signature)
+	assert.NotEqual(t, signature, Sign("another secret", 1700000000, body))
+	assert.NotEqual(t, signature, Sign("secret", 1700000001, body))
+}
+
+func TestCheckAddress(t *testing.T) {
+	allowed := []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}
+	for address, ok := range map[string]bool{
+		"93.184.216.34:443":        true,
+		"[2606:2800:220:1::1]:443": true,
+		"10.1.2.3:8080":            true,
+		"127.0.0.1:5432":           false,
+		"[::1]:80":                 false,
+		"[::ffff:127.0.0.1]:80":    false,
+		"169.254.169.254:80":       false,
+		"10.2.0.1:80":              false,
+		"192.168.1.1:80":           false,
+		"100.64.0.1:80":            false,
+		"0.0.0.0:80":               false,
+		"[fd00::1]:80":             false,
+		"[fe80::1]:80":             false,
+		"224.0.0.1:80":             false,
+	} {
+		err := checkAddress(address, allowed)
+		assert.Equal(t, ok, err == nil, "address %s", address)
+	}
+}
+
+func TestClientRefusesInternalAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/target", http.StatusFound)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	// The test server listens on loopback.
+	_, err := newClient(Config{Timeout: time.Second}).Post(srv.URL, "application/json", nil)
+	assert.Error(t, err)
+
+	client := newClient(Config{Timeout: time.Second, AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}})
+	resp, err := client.Post(srv.URL, "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// Redirects aren't followed.
+	resp, err = client.Post(srv.URL+"/redirect", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+}
@@ -0,0 +1,3 @@
+ALTER TABLE books ADD COLUMN IF NOT EXISTS isbn VARCHAR(13);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_book_tenant_isbn ON books (tenant, isbn) WHERE isbn IS NOT NULL;
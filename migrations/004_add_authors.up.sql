@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS authors (
+    id SERIAL NOT NULL PRIMARY KEY,
+    tenant VARCHAR(100) NOT NULL DEFAULT 'default',
+    name VARCHAR(100) NOT NULL,
+
+    CONSTRAINT unique_author_tenant_name UNIQUE (tenant, name)
+);
+
+CREATE TABLE IF NOT EXISTS book_authors (
+    book_id INT NOT NULL REFERENCES books(id),
+    author_id INT NOT NULL REFERENCES authors(id),
+    role VARCHAR(20) NOT NULL DEFAULT 'author',
+    position INT NOT NULL DEFAULT 0,
+    PRIMARY KEY(book_id, author_id, role),
+
+    CONSTRAINT book_author_role CHECK (role IN ('author', 'editor', 'translator', 'illustrator'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_book_authors_author ON book_authors (author_id);
+
+INSERT INTO authors (tenant, name)
+SELECT DISTINCT tenant, author FROM books
+ON CONFLICT DO NOTHING;
+
+INSERT INTO book_authors (book_id, author_id, role)
+SELECT b.id, a.id, 'author' FROM books b
+JOIN authors a ON a.tenant = b.tenant AND a.name = b.author
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL NOT NULL PRIMARY KEY,
+    tenant VARCHAR(100) NOT NULL DEFAULT 'default',
+    name VARCHAR(50) NOT NULL,
+
+    CONSTRAINT unique_tag_tenant_name UNIQUE (tenant, name)
+);
+
+CREATE TABLE IF NOT EXISTS book_tags (
+    book_id INT NOT NULL REFERENCES books(id),
+    tag_id INT NOT NULL REFERENCES tags(id),
+    PRIMARY KEY(book_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_book_tags_tag ON book_tags (tag_id);
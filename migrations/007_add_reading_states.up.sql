@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS reading_states (
+    book_id INT NOT NULL PRIMARY KEY REFERENCES books(id),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('want_to_read', 'reading', 'finished', 'abandoned')),
+    started_at DATE,
+    finished_at DATE,
+    current_page INT NOT NULL DEFAULT 0 CHECK (current_page >= 0),
+    notes TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_reading_states_status ON reading_states (status);
+CREATE INDEX IF NOT EXISTS idx_reading_states_finished_at ON reading_states (finished_at);
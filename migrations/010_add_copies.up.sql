@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS copies (
+    id SERIAL NOT NULL PRIMARY KEY,
+    book_id INT NOT NULL REFERENCES books(id),
+    tenant VARCHAR(100) NOT NULL DEFAULT 'default',
+    barcode VARCHAR(50) NOT NULL,
+    condition VARCHAR(20) NOT NULL CHECK (condition IN ('new', 'fine', 'good', 'fair', 'poor')),
+    acquired_at DATE,
+    price BIGINT NOT NULL DEFAULT 0 CHECK (price >= 0),
+    location VARCHAR(100) NOT NULL DEFAULT '',
+
+    CONSTRAINT unique_copy_tenant_barcode UNIQUE (tenant, barcode)
+);
+
+CREATE INDEX IF NOT EXISTS idx_copies_book ON copies (book_id);
+
+CREATE INDEX IF NOT EXISTS idx_copies_tenant_location ON copies (tenant, location);
@@ -0,0 +1 @@
+ALTER TABLE books ADD COLUMN IF NOT EXISTS cover VARCHAR(36) NOT NULL DEFAULT '';
@@ -0,0 +1,13 @@
+-- Changes is the outbox of the library: every mutation writes its changes in its own transaction.
+-- Writers serialize on an advisory lock, so changes are committed in the order of their seq.
+CREATE TABLE IF NOT EXISTS changes (
+    seq BIGSERIAL NOT NULL PRIMARY KEY,
+    tenant VARCHAR(100) NOT NULL,
+    entity VARCHAR(20) NOT NULL,
+    entity_id INT NOT NULL,
+    op VARCHAR(20) NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_changes_tenant_seq ON changes (tenant, seq);
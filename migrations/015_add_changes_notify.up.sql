@@ -0,0 +1,21 @@
+-- Servers sharing the database invalidate their caches on notifications about committed changes.
+-- Ids of books added to or removed from a collection are left out to keep the payload small.
+CREATE OR REPLACE FUNCTION notify_change() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('bm_changes', json_build_object(
+        'tenant', NEW.tenant,
+        'seq', NEW.seq,
+        'entity', NEW.entity,
+        'entity_id', NEW.entity_id,
+        'op', NEW.op,
+        'data', NEW.data - 'book_ids'
+    )::text);
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS changes_notify ON changes;
+
+CREATE TRIGGER changes_notify AFTER INSERT ON changes
+    FOR EACH ROW EXECUTE FUNCTION notify_change();
@@ -0,0 +1,4 @@
+-- Modification times of books and collections back Last-Modified headers of their responses.
+ALTER TABLE books ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT now();
+
+ALTER TABLE collections ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT now();
@@ -0,0 +1,36 @@
+-- Aliases map alternative names of genres, so free-text genres of books and imports resolve to existing genres
+-- instead of creating new ones.
+CREATE TABLE IF NOT EXISTS genre_aliases (
+    id SERIAL NOT NULL PRIMARY KEY,
+    tenant VARCHAR(100) NOT NULL DEFAULT 'default',
+    alias VARCHAR(100) NOT NULL,
+    genre_id INT NOT NULL REFERENCES genres(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_genre_alias_tenant_alias ON genre_aliases (tenant, lower(alias));
+
+CREATE INDEX IF NOT EXISTS idx_genre_aliases_genre ON genre_aliases (genre_id);
+
+-- Common alternative names are added for genres which exist in a tenant.
+INSERT INTO genre_aliases (tenant, alias, genre_id)
+SELECT g.tenant, a.alias, g.id FROM genres g
+JOIN (VALUES
+    ('Sci-Fi', 'Science Fiction'),
+    ('SciFi', 'Science Fiction'),
+    ('SF', 'Science Fiction'),
+    ('Fantasy Fiction', 'Fantasy'),
+    ('Crime Fiction', 'Crime'),
+    ('Detective and mystery stories', 'Mystery'),
+    ('Mystery Fiction', 'Mystery'),
+    ('Horror Fiction', 'Horror'),
+    ('Love stories', 'Romance'),
+    ('Historical Novel', 'Historical Fiction'),
+    ('YA', 'Young Adult'),
+    ('Non-fiction', 'Nonfiction'),
+    ('Biographies', 'Biography'),
+    ('Autobiography', 'Biography'),
+    ('Poems', 'Poetry'),
+    ('Comics', 'Graphic Novels')
+) AS a (alias, name) ON lower(g.name) = lower(a.name)
+WHERE NOT EXISTS (SELECT 1 FROM genres n WHERE n.tenant = g.tenant AND lower(n.name) = lower(a.alias))
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,10 @@
+-- The author of a book joins names of all its authors, so it doesn't fit into the length of a single name.
+-- Long values don't fit into btree index entries either: uniqueness is checked by a hash of the author,
+-- books are filtered by names of their authors through book_authors.
+ALTER TABLE books DROP CONSTRAINT IF EXISTS unique_book_tenant_author_title_edition;
+
+DROP INDEX IF EXISTS book_author;
+
+ALTER TABLE books ALTER COLUMN author TYPE TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_book_tenant_author_title_edition ON books (tenant, md5(author), title, edition);
@@ -1,9 +1,41 @@
+DROP TABLE IF EXISTS changes;
+
+DROP TABLE IF EXISTS webhook_attempts;
+
+DROP TABLE IF EXISTS webhook_deliveries;
+
+DROP TABLE IF EXISTS webhook_events;
+
+DROP TABLE IF EXISTS webhooks;
+
+DROP TABLE IF EXISTS book_files;
+
+DROP TABLE IF EXISTS copies;
+
+DROP TABLE IF EXISTS loans;
+
+DROP TABLE IF EXISTS reviews;
+
+DROP TABLE IF EXISTS reading_states;
+
+DROP TABLE IF EXISTS book_tags;
+
+DROP TABLE IF EXISTS tags;
+
+DROP TABLE IF EXISTS book_authors;
+
+DROP TABLE IF EXISTS authors;
+
 DROP TABLE IF EXISTS books_collection;
 
 DROP TABLE IF EXISTS collections;
 
 DROP TABLE IF EXISTS books;
 
+DROP TABLE IF EXISTS genre_aliases;
+
+DROP TABLE IF EXISTS genres;
+
 CREATE TABLE IF NOT EXISTS books (
     id SERIAL NOT NULL PRIMARY KEY,
     title VARCHAR(100) NOT NULL,
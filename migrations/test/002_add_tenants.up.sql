@@ -0,0 +1,13 @@
+ALTER TABLE books ADD COLUMN IF NOT EXISTS tenant VARCHAR(100) NOT NULL DEFAULT 'default';
+
+ALTER TABLE books DROP CONSTRAINT IF EXISTS unique_book_author_title_edition;
+
+ALTER TABLE books ADD CONSTRAINT unique_book_tenant_author_title_edition UNIQUE (tenant, author, title, edition);
+
+CREATE INDEX IF NOT EXISTS book_tenant ON books (tenant);
+
+ALTER TABLE collections ADD COLUMN IF NOT EXISTS tenant VARCHAR(100) NOT NULL DEFAULT 'default';
+
+ALTER TABLE collections DROP CONSTRAINT IF EXISTS collections_name_key;
+
+ALTER TABLE collections ADD CONSTRAINT unique_collection_tenant_name UNIQUE (tenant, name);
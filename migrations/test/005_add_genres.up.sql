@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS genres (
+    id SERIAL NOT NULL PRIMARY KEY,
+    tenant VARCHAR(100) NOT NULL DEFAULT 'default',
+    name VARCHAR(100) NOT NULL,
+    parent_id INT REFERENCES genres(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_genre_tenant_name ON genres (tenant, lower(name));
+
+CREATE INDEX IF NOT EXISTS idx_genres_parent ON genres (parent_id);
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS genre_id INT REFERENCES genres(id);
+
+CREATE INDEX IF NOT EXISTS book_genre_id ON books (genre_id);
+
+-- Genres differing only by case or surrounding spaces are mapped to one genre.
+INSERT INTO genres (tenant, name)
+SELECT DISTINCT ON (tenant, lower(trim(genre))) tenant, trim(genre) FROM books
+WHERE trim(genre) <> ''
+ORDER BY tenant, lower(trim(genre)), trim(genre)
+ON CONFLICT DO NOTHING;
+
+UPDATE books b SET genre_id = g.id, genre = g.name
+FROM genres g
+WHERE g.tenant = b.tenant AND lower(g.name) = lower(trim(b.genre));
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS reviews (
+    id SERIAL NOT NULL PRIMARY KEY,
+    book_id INT NOT NULL REFERENCES books(id),
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    text TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_book ON reviews (book_id);
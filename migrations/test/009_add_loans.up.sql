@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS loans (
+    id SERIAL NOT NULL PRIMARY KEY,
+    book_id INT NOT NULL REFERENCES books(id),
+    borrower VARCHAR(100) NOT NULL,
+    lent_at DATE NOT NULL,
+    due_at DATE NOT NULL,
+    returned_at DATE,
+
+    CHECK (due_at >= lent_at),
+    CHECK (returned_at >= lent_at)
+);
+
+-- A book can have only one active loan.
+CREATE UNIQUE INDEX IF NOT EXISTS unique_loans_active_book ON loans (book_id) WHERE returned_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_loans_active_due_at ON loans (due_at) WHERE returned_at IS NULL;
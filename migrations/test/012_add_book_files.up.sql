@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS book_files (
+    id SERIAL NOT NULL PRIMARY KEY,
+    book_id INT NOT NULL REFERENCES books(id),
+    name VARCHAR(255) NOT NULL,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('epub', 'pdf')),
+    size BIGINT NOT NULL CHECK (size > 0),
+    blob VARCHAR(36) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_book_files_book ON book_files (book_id);
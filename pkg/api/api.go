@@ -15,15 +15,28 @@ type (
 		Book Book `json:"book"`
 	}
 
+	GetBookByISBNReq struct {
+		ISBN string `json:"-"`
+	}
+
 	GetBooksReq struct {
+		Query        string    `url:"q,omitempty" json:"q"`
 		Author       string    `url:"author,omitempty" json:"author"`
+		AuthorID     int64     `url:"author_id,omitempty" json:"author_id"`
 		Genre        string    `url:"genre,omitempty" json:"genre"`
+		GenreID      int64     `url:"genre_id,omitempty" json:"genre_id"`
+		Tags         []string  `url:"tags,omitempty" json:"tags"`
+		TagsMatch    string    `url:"tags_match,omitempty" json:"tags_match"`
+		Status       string    `url:"status,omitempty" json:"status"`
+		MinRating    float64   `url:"min_rating,omitempty" json:"min_rating"`
+		OnLoan       *bool     `url:"on_loan,omitempty" json:"on_loan"`
+		ISBN         string    `url:"isbn,omitempty" json:"isbn"`
 		CollectionID int64     `url:"collection_id,omitempty" json:"collection_id"`
 		StartDate    time.Time `url:"start_date,omitempty" json:"start_date" layout:"2006-01-02"`
 		FinishDate   time.Time `url:"finish_date,omitempty" json:"finish_date" layout:"2006-01-02"`
 		OrderBy      string    `url:"order_by,omitempty" json:"order_by"`
 		Desc         bool      `url:"desc,omitempty" json:"desc"`
-		Page         int64     `url:"page,omitempty" json:"date"`
+		Page         int64     `url:"page,omitempty" json:"page"`
 		PageSize     int64     `url:"page_size,omitempty" json:"page_size"`
 	}
 
@@ -39,6 +52,45 @@ type (
 		Edition       string    `json:"edition"`
 		Description   string    `json:"description"`
 		Genre         string    `json:"genre"`
+		GenreID       int64     `json:"genre_id"`
+		ISBN13        string    `json:"isbn_13,omitempty"`
+		ISBN10        string    `json:"isbn_10,omitempty"`
+
+		// Contributors of the book. Author contains names of contributors with author role.
+		Contributors []Contributor `json:"contributors"`
+		Tags         []string      `json:"tags"`
+
+		// AverageRating is 0 for books without reviews.
+		AverageRating float64 `json:"average_rating"`
+		ReviewCount   int64   `json:"review_count"`
+		CopyCount     int64   `json:"copy_count"`
+
+		// CoverURL and ThumbnailURL are omitted for books without a cover.
+		CoverURL     string `json:"cover_url,omitempty"`
+		ThumbnailURL string `json:"thumbnail_url,omitempty"`
+
+		// Reading is omitted for books without a reading status.
+		Reading *Reading `json:"reading,omitempty"`
+
+		// UpdatedAt is the last time the book, its reviews, number of copies or reading state were changed.
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
+	// Reading is the reading state of a book. Dates have 2006-01-02 format.
+	// FinishedAt is the date the book was finished or abandoned.
+	Reading struct {
+		Status      string `json:"status"`
+		StartedAt   string `json:"started_at,omitempty"`
+		FinishedAt  string `json:"finished_at,omitempty"`
+		CurrentPage int64  `json:"current_page"`
+		Notes       string `json:"notes"`
+	}
+
+	// Contributor refers to an existing author by id or to an author by name.
+	Contributor struct {
+		AuthorID int64  `json:"author_id,omitempty"`
+		Name     string `json:"name,omitempty"`
+		Role     string `json:"role,omitempty"`
 	}
 
 	CreateBookReq struct {
@@ -48,6 +100,12 @@ type (
 		Edition       string    `json:"edition"`
 		Description   string    `json:"description"`
 		Genre         string    `json:"genre"`
+		GenreID       int64     `json:"genre_id"`
+		ISBN          string    `json:"isbn"`
+
+		// Contributors replace Author when set.
+		Contributors []Contributor `json:"contributors"`
+		Tags         []string      `json:"tags"`
 	}
 
 	CreateBookResp struct {
@@ -62,10 +120,17 @@ type (
 		Edition       string    `json:"edition"`
 		Description   string    `json:"description"`
 		Genre         string    `json:"genre"`
+		GenreID       int64     `json:"genre_id"`
+		ISBN          string    `json:"isbn"`
+
+		// Contributors replace Author when set.
+		Contributors []Contributor `json:"contributors"`
 	}
 
+	// DeleteBooksReq deletes books. Books on loan are deleted only if Force is set.
 	DeleteBooksReq struct {
-		IDs []int64 `json:"ids"`
+		IDs   []int64 `json:"ids"`
+		Force bool    `json:"force,omitempty"`
 	}
 
 	GetCollectionReq struct {
@@ -84,9 +149,10 @@ type (
 	}
 
 	Collection struct {
-		ID          int64  `json:"id"`
-		Name        string `json:"name"`
-		Description string `json:"decription"`
+		ID          int64     `json:"id"`
+		Name        string    `json:"name"`
+		Description string    `json:"description"`
+		UpdatedAt   time.Time `json:"updated_at"`
 	}
 
 	GetCollectionsResp struct {
@@ -95,7 +161,7 @@ type (
 
 	CreateCollectionReq struct {
 		Name        string `json:"name"`
-		Description string `json:"decription"`
+		Description string `json:"description"`
 	}
 
 	CreateCollectionResp struct {
@@ -105,7 +171,7 @@ type (
 	UpdateCollectionReq struct {
 		ID          int64  `json:"-"`
 		Name        string `json:"name"`
-		Description string `json:"decription"`
+		Description string `json:"description"`
 	}
 
 	UpdateCollectionResp struct {
@@ -123,18 +189,672 @@ type (
 
 	DeleteBooksCollectionReq struct {
 		CID     int64   `json:"-"`
-		BookIDs []int64 `json:"books_ids"`
+		BookIDs []int64 `json:"book_ids"`
+	}
+
+	GetAuthorReq struct {
+		ID int64 `json:"-"`
+	}
+
+	GetAuthorResp struct {
+		Author Author `json:"author"`
+	}
+
+	GetAuthorsReq struct {
+		Name     string `url:"name,omitempty" json:"name"`
+		OrderBy  string `url:"order_by,omitempty" json:"order_by"`
+		Desc     bool   `url:"desc,omitempty" json:"desc"`
+		Page     int64  `url:"page,omitempty" json:"page"`
+		PageSize int64  `url:"page_size,omitempty" json:"page_size"`
+	}
+
+	GetAuthorsResp struct {
+		Authors []Author `json:"authors"`
+	}
+
+	Author struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+
+	CreateAuthorReq struct {
+		Name string `json:"name"`
+	}
+
+	CreateAuthorResp struct {
+		ID int64 `json:"id"`
+	}
+
+	UpdateAuthorReq struct {
+		ID   int64  `json:"-"`
+		Name string `json:"name"`
+	}
+
+	DeleteAuthorReq struct {
+		ID int64 `json:"-"`
+	}
+
+	GetTagsReq struct {
+		OrderBy  string `url:"order_by,omitempty" json:"order_by"`
+		Desc     bool   `url:"desc,omitempty" json:"desc"`
+		Page     int64  `url:"page,omitempty" json:"page"`
+		PageSize int64  `url:"page_size,omitempty" json:"page_size"`
+	}
+
+	GetTagsResp struct {
+		Tags []Tag `json:"tags"`
+	}
+
+	// Tag is a label of books with the number of books it is used by.
+	Tag struct {
+		ID    int64  `json:"id"`
+		Name  string `json:"name"`
+		Books int64  `json:"books"`
+	}
+
+	AddBooksTagsReq struct {
+		BookIDs []int64  `json:"book_ids"`
+		Tags    []string `json:"tags"`
+	}
+
+	DeleteBooksTagsReq struct {
+		BookIDs []int64  `json:"book_ids"`
+		Tags    []string `json:"tags"`
+	}
+
+	GetGenreReq struct {
+		ID int64 `json:"-"`
+	}
+
+	GetGenreResp struct {
+		Genre Genre `json:"genre"`
+	}
+
+	GetGenresReq struct {
+		Name     string `url:"name,omitempty" json:"name"`
+		ParentID int64  `url:"parent_id,omitempty" json:"parent_id"`
+		OrderBy  string `url:"order_by,omitempty" json:"order_by"`
+		Desc     bool   `url:"desc,omitempty" json:"desc"`
+		Page     int64  `url:"page,omitempty" json:"page"`
+		PageSize int64  `url:"page_size,omitempty" json:"page_size"`
+	}
+
+	GetGenresResp struct {
+		Genres []Genre `json:"genres"`
+	}
+
+	// Genre is a node of the genre taxonomy. ParentID is 0 for top level genres.
+	Genre struct {
+		ID       int64  `json:"id"`
+		Name     string `json:"name"`
+		ParentID int64  `json:"parent_id"`
+	}
+
+	CreateGenreReq struct {
+		Name     string `json:"name"`
+		ParentID int64  `json:"parent_id"`
+	}
+
+	CreateGenreResp struct {
+		ID int64 `json:"id"`
+	}
+
+	UpdateGenreReq struct {
+		ID       int64  `json:"-"`
+		Name     string `json:"name"`
+		ParentID int64  `json:"parent_id"`
+	}
+
+	DeleteGenreReq struct {
+		ID int64 `json:"-"`
+	}
+
+	GetReadingReq struct {
+		BookID int64 `json:"-"`
+	}
+
+	GetReadingResp struct {
+		Reading Reading `json:"reading"`
+	}
+
+	// UpdateReadingReq moves a book to the status on the date in 2006-01-02 format, today if it is empty.
+	// Zero current page and empty notes keep the current ones.
+	UpdateReadingReq struct {
+		BookID      int64  `json:"-"`
+		Status      string `json:"status"`
+		Date        string `json:"date,omitempty"`
+		CurrentPage int64  `json:"current_page,omitempty"`
+		Notes       string `json:"notes,omitempty"`
+	}
+
+	UpdateReadingResp struct {
+		Reading Reading `json:"reading"`
+	}
+
+	GetReadingReportReq struct {
+		Year int `url:"year,omitempty" json:"year"`
+	}
+
+	// GetReadingReportResp lists books finished within the year.
+	GetReadingReportResp struct {
+		Year     int    `json:"year"`
+		Finished int    `json:"finished"`
+		Books    []Book `json:"books"`
+	}
+
+	GetReviewReq struct {
+		BookID int64 `json:"-"`
+		ID     int64 `json:"-"`
+	}
+
+	GetReviewResp struct {
+		Review Review `json:"review"`
+	}
+
+	GetReviewsReq struct {
+		BookID   int64  `url:"-" json:"-"`
+		OrderBy  string `url:"order_by,omitempty" json:"order_by"`
+		Desc     bool   `url:"desc,omitempty" json:"desc"`
+		Page     int64  `url:"page,omitempty" json:"page"`
+		PageSize int64  `url:"page_size,omitempty" json:"page_size"`
+	}
+
+	GetReviewsResp struct {
+		Reviews []Review `json:"reviews"`
+	}
+
+	// Review is a rating of a book from 1 to 5 with an optional text.
+	Review struct {
+		ID        int64     `json:"id"`
+		BookID    int64     `json:"book_id"`
+		Rating    int64     `json:"rating"`
+		Text      string    `json:"text"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
+	CreateReviewReq struct {
+		BookID int64  `json:"-"`
+		Rating int64  `json:"rating"`
+		Text   string `json:"text"`
+	}
+
+	CreateReviewResp struct {
+		ID int64 `json:"id"`
+	}
+
+	UpdateReviewReq struct {
+		BookID int64  `json:"-"`
+		ID     int64  `json:"-"`
+		Rating int64  `json:"rating"`
+		Text   string `json:"text"`
+	}
+
+	DeleteReviewReq struct {
+		BookID int64 `json:"-"`
+		ID     int64 `json:"-"`
+	}
+
+	GetCopyReq struct {
+		ID int64 `json:"-"`
+	}
+
+	GetCopyByBarcodeReq struct {
+		Barcode string `json:"-"`
+	}
+
+	GetCopyResp struct {
+		Copy Copy `json:"copy"`
+	}
+
+	// GetCopiesReq selects copies of a book, copies kept in a location or both.
+	GetCopiesReq struct {
+		BookID   int64  `url:"book_id,omitempty" json:"book_id"`
+		Location string `url:"location,omitempty" json:"location"`
+		OrderBy  string `url:"order_by,omitempty" json:"order_by"`
+		Desc     bool   `url:"desc,omitempty" json:"desc"`
+		Page     int64  `url:"page,omitempty" json:"page"`
+		PageSize int64  `url:"page_size,omitempty" json:"page_size"`
+	}
+
+	GetCopiesResp struct {
+		Copies []Copy `json:"copies"`
+	}
+
+	// Copy is a physical copy of a book. Price is in minor currency units, AcquiredAt has 2006-01-02 format.
+	Copy struct {
+		ID         int64  `json:"id"`
+		BookID     int64  `json:"book_id"`
+		BookTitle  string `json:"book_title"`
+		Barcode    string `json:"barcode"`
+		Condition  string `json:"condition"`
+		AcquiredAt string `json:"acquired_at,omitempty"`
+		Price      int64  `json:"price"`
+		Location   string `json:"location"`
+	}
+
+	CreateCopyReq struct {
+		BookID     int64  `json:"-"`
+		Barcode    string `json:"barcode"`
+		Condition  string `json:"condition,omitempty"`
+		AcquiredAt string `json:"acquired_at,omitempty"`
+		Price      int64  `json:"price"`
+		Location   string `json:"location"`
+	}
+
+	CreateCopyResp struct {
+		ID int64 `json:"id"`
+	}
+
+	UpdateCopyReq struct {
+		ID         int64  `json:"-"`
+		Barcode    string `json:"barcode"`
+		Condition  string `json:"condition,omitempty"`
+		AcquiredAt string `json:"acquired_at,omitempty"`
+		Price      int64  `json:"price"`
+		Location   string `json:"location"`
+	}
+
+	DeleteCopyReq struct {
+		ID int64 `json:"-"`
+	}
+
+	// UploadCoverReq replaces the cover of a book. Data is a JPEG or PNG image sent as the request body.
+	UploadCoverReq struct {
+		BookID int64  `json:"-"`
+		Data   []byte `json:"-"`
+	}
+
+	UploadCoverResp struct {
+		CoverURL     string `json:"cover_url"`
+		ThumbnailURL string `json:"thumbnail_url"`
+	}
+
+	// GetCoverReq requests the cover of a book, or its thumbnail if Size is thumbnail.
+	// The cover isn't sent back if ETag matches the current one.
+	GetCoverReq struct {
+		BookID int64  `url:"-" json:"-"`
+		Size   string `url:"size,omitempty" json:"size"`
+		ETag   string `url:"-" json:"-"`
+	}
+
+	// GetCoverResp contains the image. Data is empty if the image is not modified.
+	GetCoverResp struct {
+		ContentType string
+		ETag        string
+		NotModified bool
+		Data        []byte
+	}
+
+	// AddBookFileReq attaches an EPUB or PDF file sent as the request body to a book.
+	AddBookFileReq struct {
+		BookID int64  `json:"-"`
+		Name   string `json:"-"`
+		Data   []byte `json:"-"`
+	}
+
+	AddBookFileResp struct {
+		ID  int64  `json:"id"`
+		URL string `json:"url"`
+	}
+
+	GetBookFilesReq struct {
+		BookID int64 `json:"-"`
+	}
+
+	GetBookFilesResp struct {
+		Files []BookFile `json:"files"`
+	}
+
+	// BookFile is a digital file of a book. URL is the path to download the file.
+	BookFile struct {
+		ID        int64     `json:"id"`
+		BookID    int64     `json:"book_id"`
+		Name      string    `json:"name"`
+		Format    string    `json:"format"`
+		Size      int64     `json:"size"`
+		URL       string    `json:"url"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	GetBookFileReq struct {
+		BookID int64 `json:"-"`
+		ID     int64 `json:"-"`
+	}
+
+	// GetBookFileResp contains the content of a file.
+	GetBookFileResp struct {
+		ContentType string
+		Data        []byte
+	}
+
+	DeleteBookFileReq struct {
+		BookID int64 `json:"-"`
+		ID     int64 `json:"-"`
+	}
+
+	// ImportEPUBReq creates a book from metadata of an EPUB sent as the request body and attaches the EPUB to it.
+	// Genre replaces the first subject of the EPUB if it is set.
+	ImportEPUBReq struct {
+		Name  string `url:"name,omitempty" json:"-"`
+		Genre string `url:"genre,omitempty" json:"-"`
+		Data  []byte `url:"-" json:"-"`
+	}
+
+	ImportEPUBResp struct {
+		ID     int64 `json:"id"`
+		FileID int64 `json:"file_id"`
+	}
+
+	// ImportBooksReq imports books from a Goodreads or LibraryThing CSV export sent as the request body.
+	// Genre is set to every imported book, "Unsorted" by default. DryRun reports changes without making them.
+	ImportBooksReq struct {
+		Genre  string `url:"genre,omitempty" json:"-"`
+		DryRun bool   `url:"dry_run,omitempty" json:"-"`
+		Data   []byte `url:"-" json:"-"`
+	}
+
+	// ImportBooksResp lists rows of the export with actions taken for them and collections created for shelves.
+	ImportBooksResp struct {
+		Layout         string         `json:"layout"`
+		DryRun         bool           `json:"dry_run"`
+		Books          []ImportedBook `json:"books"`
+		NewCollections []string       `json:"new_collections"`
+	}
+
+	// ImportedBook is a row of an export or a MARC record, Line is the number of the record.
+	// Action is create, exists, duplicate, invalid or failed.
+	// BookID is the existing book for rows which exist and the created book after an import.
+	// Unmapped lists MARC fields and subfields which have no place in a book, like 245$c.
+	ImportedBook struct {
+		Line     int      `json:"line"`
+		Title    string   `json:"title"`
+		Author   string   `json:"author"`
+		Year     int      `json:"year,omitempty"`
+		ISBN     string   `json:"isbn,omitempty"`
+		Shelves  []string `json:"shelves,omitempty"`
+		Unmapped []string `json:"unmapped,omitempty"`
+		Action   string   `json:"action"`
+		BookID   int64    `json:"book_id,omitempty"`
+		Error    string   `json:"error,omitempty"`
+	}
+
+	// ImportMARCReq imports books from MARC 21 records, in transmission format or MARCXML, sent as the request body.
+	// Genre replaces genres of the records, books without one get "Unsorted". DryRun reports changes without making them.
+	ImportMARCReq struct {
+		Genre  string `url:"genre,omitempty" json:"-"`
+		DryRun bool   `url:"dry_run,omitempty" json:"-"`
+		Data   []byte `url:"-" json:"-"`
+	}
+
+	// ExportBookReq exports a book. Format is marc21, marcxml, bibtex, ris or csljson.
+	ExportBookReq struct {
+		BookID int64  `url:"-" json:"-"`
+		Format string `url:"format" json:"-"`
+	}
+
+	// ExportCollectionReq exports all books of a collection. Format is marc21, marcxml, bibtex, ris or csljson.
+	ExportCollectionReq struct {
+		CollectionID int64  `url:"-" json:"-"`
+		Format       string `url:"format" json:"-"`
+	}
+
+	// ExportResp contains the exported file.
+	ExportResp struct {
+		ContentType string
+		FileName    string
+		Data        []byte
+	}
+
+	// GetOPDSFeedReq requests an OPDS feed: the root navigation feed, recent additions, a collection
+	// or search results for Query. Page numbers start from 1.
+	GetOPDSFeedReq struct {
+		Recent       bool   `url:"-" json:"-"`
+		CollectionID int64  `url:"-" json:"-"`
+		Query        string `url:"q,omitempty" json:"-"`
+		Page         int64  `url:"page,omitempty" json:"-"`
+	}
+
+	// GetOpenSearchReq requests the OpenSearch description of the catalog. BaseURL is the address the client used.
+	GetOpenSearchReq struct {
+		BaseURL string `url:"-" json:"-"`
+	}
+
+	// OPDSResp contains an Atom feed or an OpenSearch description.
+	OPDSResp struct {
+		ContentType string
+		Data        []byte
+	}
+
+	// LendBookReq lends a book. Dates have 2006-01-02 format, LentAt is today if it is empty.
+	LendBookReq struct {
+		BookID   int64  `json:"-"`
+		Borrower string `json:"borrower"`
+		LentAt   string `json:"lent_at,omitempty"`
+		DueAt    string `json:"due_at"`
+	}
+
+	LendBookResp struct {
+		ID int64 `json:"id"`
+	}
+
+	// ReturnBookReq returns a lent book. ReturnedAt has 2006-01-02 format and is today if it is empty.
+	ReturnBookReq struct {
+		BookID     int64  `json:"-"`
+		ReturnedAt string `json:"returned_at,omitempty"`
+	}
+
+	GetLoansReq struct {
+		OrderBy  string `url:"order_by,omitempty" json:"order_by"`
+		Desc     bool   `url:"desc,omitempty" json:"desc"`
+		Page     int64  `url:"page,omitempty" json:"page"`
+		PageSize int64  `url:"page_size,omitempty" json:"page_size"`
+	}
+
+	GetLoansResp struct {
+		Loans []Loan `json:"loans"`
+	}
+
+	// Loan is an active lending of a book. Dates have 2006-01-02 format.
+	Loan struct {
+		ID        int64  `json:"id"`
+		BookID    int64  `json:"book_id"`
+		BookTitle string `json:"book_title"`
+		Borrower  string `json:"borrower"`
+		LentAt    string `json:"lent_at"`
+		DueAt     string `json:"due_at"`
+	}
+
+	MoveBooksReq struct {
+		IDs    []int64 `json:"ids"`
+		Tenant string  `json:"tenant"`
+	}
+
+	MoveCollectionReq struct {
+		ID     int64  `json:"-"`
+		Tenant string `json:"tenant"`
+	}
+
+	// GraphQLReq executes a GraphQL query or mutation. Queries may be sent with GET, mutations are POST only.
+	GraphQLReq struct {
+		Query         string         `url:"query" json:"query"`
+		OperationName string         `url:"operationName,omitempty" json:"operationName,omitempty"`
+		Variables     map[string]any `url:"-" json:"variables,omitempty"`
+	}
+
+	// GraphQLResp contains the result of a GraphQL operation. Data is decoded by the caller.
+	GraphQLResp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []GraphQLError  `json:"errors,omitempty"`
+	}
+
+	// GraphQLError is an error of a GraphQL operation. Extensions contain the error code.
+	GraphQLError struct {
+		Message    string         `json:"message"`
+		Path       []any          `json:"path,omitempty"`
+		Extensions map[string]any `json:"extensions,omitempty"`
+	}
+
+	GetWebhookReq struct {
+		ID int64 `json:"-"`
+	}
+
+	GetWebhookResp struct {
+		Webhook Webhook `json:"webhook"`
+	}
+
+	GetWebhooksReq struct {
+		Page     int64 `url:"page,omitempty" json:"page"`
+		PageSize int64 `url:"page_size,omitempty" json:"page_size"`
+	}
+
+	GetWebhooksResp struct {
+		Webhooks []Webhook `json:"webhooks"`
+	}
+
+	// Webhook is a subscription to events of the library. Its secret is returned only when it is created.
+	Webhook struct {
+		ID        int64     `json:"id"`
+		URL       string    `json:"url"`
+		Events    []string  `json:"events"`
+		Active    bool      `json:"active"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	// CreateWebhookReq subscribes a URL to events: event types, categories like "collection.*" or "*".
+	// Missing events subscribe the webhook to all events, a missing secret is generated, Active defaults to true.
+	CreateWebhookReq struct {
+		URL    string   `json:"url"`
+		Secret string   `json:"secret,omitempty"`
+		Events []string `json:"events,omitempty"`
+		Active *bool    `json:"active,omitempty"`
+	}
+
+	CreateWebhookResp struct {
+		ID     int64  `json:"id"`
+		Secret string `json:"secret"`
+	}
+
+	// UpdateWebhookReq replaces a webhook. A missing secret keeps the current one.
+	UpdateWebhookReq struct {
+		ID     int64    `json:"-"`
+		URL    string   `json:"url"`
+		Secret string   `json:"secret,omitempty"`
+		Events []string `json:"events,omitempty"`
+		Active *bool    `json:"active,omitempty"`
+	}
+
+	DeleteWebhookReq struct {
+		ID int64 `json:"-"`
+	}
+
+	// GetWebhookDeliveriesReq selects deliveries of a webhook from the newest, optionally with the status:
+	// pending, succeeded or dead.
+	GetWebhookDeliveriesReq struct {
+		WebhookID int64  `url:"-" json:"-"`
+		Status    string `url:"status,omitempty" json:"status"`
+		Page      int64  `url:"page,omitempty" json:"page"`
+		PageSize  int64  `url:"page_size,omitempty" json:"page_size"`
+	}
+
+	GetWebhookDeliveriesResp struct {
+		Deliveries []WebhookDelivery `json:"deliveries"`
+	}
+
+	GetWebhookDeliveryReq struct {
+		WebhookID int64 `json:"-"`
+		ID        int64 `json:"-"`
+	}
+
+	GetWebhookDeliveryResp struct {
+		Delivery WebhookDelivery `json:"delivery"`
+	}
+
+	// WebhookDelivery is a delivery of an event to a webhook. NextAttemptAt is set for pending deliveries only,
+	// Attempts contains the delivery log and is returned for a single delivery.
+	WebhookDelivery struct {
+		ID            int64            `json:"id"`
+		WebhookID     int64            `json:"webhook_id"`
+		EventID       int64            `json:"event_id"`
+		EventType     string           `json:"event_type"`
+		Status        string           `json:"status"`
+		AttemptCount  int64            `json:"attempt_count"`
+		NextAttemptAt *time.Time       `json:"next_attempt_at,omitempty"`
+		LastError     string           `json:"last_error,omitempty"`
+		CreatedAt     time.Time        `json:"created_at"`
+		UpdatedAt     time.Time        `json:"updated_at"`
+		Attempts      []WebhookAttempt `json:"attempts,omitempty"`
+	}
+
+	// WebhookAttempt is an attempt of a delivery. StatusCode is missing if no response was received.
+	WebhookAttempt struct {
+		Attempt    int64     `json:"attempt"`
+		StatusCode int64     `json:"status_code,omitempty"`
+		Error      string    `json:"error,omitempty"`
+		DurationMS int64     `json:"duration_ms"`
+		CreatedAt  time.Time `json:"created_at"`
+	}
+
+	// RedeliverWebhookEventReq sends the event of a delivery to the webhook once again.
+	RedeliverWebhookEventReq struct {
+		WebhookID  int64 `json:"-"`
+		DeliveryID int64 `json:"-"`
+	}
+
+	RedeliverWebhookEventResp struct {
+		ID int64 `json:"id"`
+	}
+
+	// GetChangesReq selects changes following the change with seq Since, so a consumer resumes the feed
+	// with the seq of the last change it has seen. Zero Since starts the feed from the beginning.
+	GetChangesReq struct {
+		Since int64 `url:"since,omitempty" json:"since"`
+		Limit int64 `url:"limit,omitempty" json:"limit"`
+	}
+
+	// GetChangesResp contains changes ordered by seq and the seq to resume the feed with.
+	GetChangesResp struct {
+		Changes   []Change `json:"changes"`
+		NextSince int64    `json:"next_since"`
+	}
+
+	// StreamChangesReq subscribes to changes following the change with seq Since.
+	StreamChangesReq struct {
+		Since int64 `url:"since,omitempty" json:"since"`
+	}
+
+	// Change is a record of the change feed: an entity like a book or a review is created, updated or deleted,
+	// or books are added to or removed from a collection. Data refers to related entities, like the book
+	// of a review or the books added to a collection.
+	Change struct {
+		Seq       int64           `json:"seq"`
+		Entity    string          `json:"entity"`
+		EntityID  int64           `json:"entity_id"`
+		Op        string          `json:"op"`
+		Data      json.RawMessage `json:"data"`
+		CreatedAt time.Time       `json:"created_at"`
+	}
+
+	// GetCacheStatsResp contains counters of the storage cache. Entries is the number of cached queries.
+	GetCacheStatsResp struct {
+		Hits    int64 `json:"hits"`
+		Misses  int64 `json:"misses"`
+		Entries int64 `json:"entries"`
 	}
 )
 
 func (c *CreateBookReq) UnmarshalJSON(data []byte) error {
 	var aux struct {
-		Title         string `json:"title"`
-		Author        string `json:"author"`
-		PublishedDate string `json:"published_date"`
-		Edition       string `json:"edition"`
-		Description   string `json:"description"`
-		Genre         string `json:"genre"`
+		Title         string        `json:"title"`
+		Author        string        `json:"author"`
+		PublishedDate string        `json:"published_date"`
+		Edition       string        `json:"edition"`
+		Description   string        `json:"description"`
+		Genre         string        `json:"genre"`
+		GenreID       int64         `json:"genre_id,omitempty"`
+		ISBN          string        `json:"isbn"`
+		Contributors  []Contributor `json:"contributors,omitempty"`
+		Tags          []string      `json:"tags,omitempty"`
 	}
 
 	if err := json.Unmarshal(data, &aux); err != nil {
@@ -146,6 +866,10 @@ func (c *CreateBookReq) UnmarshalJSON(data []byte) error {
 	c.Edition = aux.Edition
 	c.Description = aux.Description
 	c.Genre = aux.Genre
+	c.GenreID = aux.GenreID
+	c.ISBN = aux.ISBN
+	c.Contributors = aux.Contributors
+	c.Tags = aux.Tags
 
 	if aux.PublishedDate != "" {
 		parsedDate, err := time.Parse("2006-01-02", aux.PublishedDate)
@@ -161,18 +885,26 @@ func (c *CreateBookReq) UnmarshalJSON(data []byte) error {
 
 func (c *CreateBookReq) MarshalJSON() ([]byte, error) {
 	aux := struct {
-		Title         string `json:"title"`
-		Author        string `json:"author"`
-		PublishedDate string `json:"published_date,omitempty"`
-		Edition       string `json:"edition"`
-		Description   string `json:"description"`
-		Genre         string `json:"genre"`
+		Title         string        `json:"title"`
+		Author        string        `json:"author"`
+		PublishedDate string        `json:"published_date,omitempty"`
+		Edition       string        `json:"edition"`
+		Description   string        `json:"description"`
+		Genre         string        `json:"genre"`
+		GenreID       int64         `json:"genre_id,omitempty"`
+		ISBN          string        `json:"isbn"`
+		Contributors  []Contributor `json:"contributors,omitempty"`
+		Tags          []string      `json:"tags,omitempty"`
 	}{
-		Title:       c.Title,
-		Author:      c.Author,
-		Edition:     c.Edition,
-		Description: c.Description,
-		Genre:       c.Genre,
+		Title:        c.Title,
+		Author:       c.Author,
+		Edition:      c.Edition,
+		Description:  c.Description,
+		Genre:        c.Genre,
+		GenreID:      c.GenreID,
+		ISBN:         c.ISBN,
+		Contributors: c.Contributors,
+		Tags:         c.Tags,
 	}
 
 	if !c.PublishedDate.IsZero() {
@@ -184,12 +916,15 @@ func (c *CreateBookReq) MarshalJSON() ([]byte, error) {
 
 func (u *UpdateBookReq) UnmarshalJSON(data []byte) error {
 	var aux struct {
-		Title         string `json:"title"`
-		Author        string `json:"author"`
-		PublishedDate string `json:"published_date"`
-		Edition       string `json:"edition"`
-		Description   string `json:"description"`
-		Genre         string `json:"genre"`
+		Title         string        `json:"title"`
+		Author        string        `json:"author"`
+		PublishedDate string        `json:"published_date"`
+		Edition       string        `json:"edition"`
+		Description   string        `json:"description"`
+		Genre         string        `json:"genre"`
+		GenreID       int64         `json:"genre_id,omitempty"`
+		ISBN          string        `json:"isbn"`
+		Contributors  []Contributor `json:"contributors,omitempty"`
 	}
 
 	if err := json.Unmarshal(data, &aux); err != nil {
@@ -201,6 +936,9 @@ func (u *UpdateBookReq) UnmarshalJSON(data []byte) error {
 	u.Edition = aux.Edition
 	u.Description = aux.Description
 	u.Genre = aux.Genre
+	u.GenreID = aux.GenreID
+	u.ISBN = aux.ISBN
+	u.Contributors = aux.Contributors
 
 	if aux.PublishedDate != "" {
 		parsedDate, err := time.Parse("2006-01-02", aux.PublishedDate)
@@ -216,18 +954,24 @@ func (u *UpdateBookReq) UnmarshalJSON(data []byte) error {
 
 func (u *UpdateBookReq) MarshalJSON() ([]byte, error) {
 	aux := struct {
-		Title         string `json:"title"`
-		Author        string `json:"author"`
-		PublishedDate string `json:"published_date"`
-		Edition       string `json:"edition"`
-		Description   string `json:"description"`
-		Genre         string `json:"genre"`
+		Title         string        `json:"title"`
+		Author        string        `json:"author"`
+		PublishedDate string        `json:"published_date"`
+		Edition       string        `json:"edition"`
+		Description   string        `json:"description"`
+		Genre         string        `json:"genre"`
+		GenreID       int64         `json:"genre_id,omitempty"`
+		ISBN          string        `json:"isbn"`
+		Contributors  []Contributor `json:"contributors,omitempty"`
 	}{
-		Title:       u.Title,
-		Author:      u.Author,
-		Edition:     u.Edition,
-		Description: u.Description,
-		Genre:       u.Genre,
+		Title:        u.Title,
+		Author:       u.Author,
+		Edition:      u.Edition,
+		Description:  u.Description,
+		Genre:        u.Genre,
+		GenreID:      u.GenreID,
+		ISBN:         u.ISBN,
+		Contributors: u.Contributors,
 	}
 
 	if !u.PublishedDate.IsZero() {
@@ -0,0 +1,154 @@
+package httpclient
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+)
+
+// cacheTransport keeps JSON responses of GET requests with validators and revalidates them:
+// a cached response is reused when the server answers 304 Not Modified to the conditional request.
+// Requests with their own conditions pass through.
+type cacheTransport struct {
+	next http.RoundTripper
+	size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+
+	// order keeps entries from the most to the least recently used.
+	order *list.List
+}
+
+type cachedResp struct {
+	key    string
+	header http.Header
+	body   []byte
+}
+
+func newCacheTransport(next http.RoundTripper, size int) *cacheTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &cacheTransport{
+		next:    next,
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return t.next.RoundTrip(req)
+	}
+
+	// Responses depend on the tenant and the role of the api key.
+	key := req.Header.Get("Authorization") + " " + req.URL.String()
+	cached := t.get(key)
+	if cached != nil {
+		req = req.Clone(req.Context())
+		if etag := cached.header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+
+		if modifiedAt := cached.header.Get("Last-Modified"); modifiedAt != "" {
+			req.Header.Set("If-Modified-Since", modifiedAt)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		resp.Body.Close()
+
+		resp.Status = "200 OK"
+		resp.StatusCode = http.StatusOK
+		resp.Header = cached.header.Clone()
+		resp.Body = io.NopCloser(bytes.NewReader(cached.body))
+		resp.ContentLength = int64(len(cached.body))
+
+		return resp, nil
+
+	case resp.StatusCode == http.StatusOK && cacheable(resp):
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		t.put(&cachedResp{key: key, header: resp.Header.Clone(), body: body})
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		return resp, nil
+	}
+
+	if cached != nil {
+		t.remove(key)
+	}
+
+	return resp, nil
+}
+
+// cacheable tells whether the response is JSON with validators; blobs such as covers aren't kept.
+func cacheable(resp *http.Response) bool {
+	if resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	return err == nil && mediaType == "application/json"
+}
+
+func (t *cacheTransport) get(key string) *cachedResp {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	el, ok := t.entries[key]
+	if !ok {
+		return nil
+	}
+
+	t.order.MoveToFront(el)
+
+	return el.Value.(*cachedResp)
+}
+
+func (t *cacheTransport) put(resp *cachedResp) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if el, ok := t.entries[resp.key]; ok {
+		el.Value = resp
+		t.order.MoveToFront(el)
+
+		return
+	}
+
+	t.entries[resp.key] = t.order.PushFront(resp)
+	for len(t.entries) > t.size {
+		el := t.order.Back()
+		t.order.Remove(el)
+		delete(t.entries, el.Value.(*cachedResp).key)
+	}
+}
+
+func (t *cacheTransport) remove(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if el, ok := t.entries[key]; ok {
+		t.order.Remove(el)
+		delete(t.entries, key)
+	}
+}
@@ -0,0 +1,45 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitError is returned when the server rejects a request because the client exceeded its rate limit.
+type RateLimitError struct {
+	// RetryAfter is the time to wait before the next attempt.
+	RetryAfter time.Duration
+	// Limit is the maximum number of requests in a burst.
+	Limit int
+	// Remaining is the number of requests left in the current burst.
+	Remaining int
+	// Reset is the time left until the quota is fully restored.
+	Reset time.Duration
+}
+
+func (err *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded, retry after %s", err.RetryAfter)
+}
+
+func newRateLimitError(h http.Header) *RateLimitError {
+	seconds := func(key string) time.Duration {
+		n, _ := strconv.ParseInt(h.Get(key), 10, 64)
+
+		return time.Duration(n) * time.Second
+	}
+
+	number := func(key string) int {
+		n, _ := strconv.Atoi(h.Get(key))
+
+		return n
+	}
+
+	return &RateLimitError{
+		RetryAfter: seconds("Retry-After"),
+		Limit:      number("RateLimit-Limit"),
+		Remaining:  number("RateLimit-Remaining"),
+		Reset:      seconds("RateLimit-Reset"),
+	}
+}
@@ -2,8 +2,12 @@ package httpclient
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"net"
 	"net/http"
+	"os"
 	"time"
 )
 
@@ -11,7 +15,21 @@ import (
 type Config struct {
 	Address    string
 	SocketPath string
+	APIKey     string
 	Timeout    time.Duration
+
+	// CAFile replaces system roots verifying the certificate of the server. CertFile and KeyFile
+	// authenticate the client by a certificate. The files are read by New, requests fail if they can't be loaded.
+	CAFile   string
+	CertFile string
+	KeyFile  string
+
+	// Transport replaces the default transport, SocketPath and TLS files are ignored if it is set.
+	Transport http.RoundTripper
+
+	// CacheSize enables the cache of up to CacheSize responses with validators, such as books and collections.
+	// Cached responses are revalidated by conditional requests.
+	CacheSize int
 }
 
 // Clients communicates with BM http-server.
@@ -24,15 +42,36 @@ type Client struct {
 // New constructs a new BM http-client.
 func New(cfg Config) *Client {
 	c := &http.Client{
-		Timeout: cfg.Timeout,
+		Timeout:   cfg.Timeout,
+		Transport: cfg.Transport,
 	}
 
-	if cfg.SocketPath != "" {
+	switch {
+	case cfg.Transport != nil:
+		// The transport is used as is.
+
+	case cfg.SocketPath != "":
 		c.Transport = &http.Transport{
 			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
 				return net.Dial("unix", cfg.SocketPath)
 			},
 		}
+
+	case cfg.CAFile != "" || cfg.CertFile != "" || cfg.KeyFile != "":
+		tlsCfg, err := tlsConfig(cfg)
+		if err != nil {
+			c.Transport = failingTransport{err: fmt.Errorf("configure tls: %w", err)}
+
+			break
+		}
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsCfg
+		c.Transport = transport
+	}
+
+	if cfg.CacheSize > 0 {
+		c.Transport = newCacheTransport(c.Transport, cfg.CacheSize)
 	}
 
 	return &Client{
@@ -40,3 +79,50 @@ func New(cfg Config) *Client {
 		httpClient: c,
 	}
 }
+
+// WithAPIKey returns a copy of the client that authorizes with the given api key.
+func (c *Client) WithAPIKey(key string) *Client {
+	cfg := c.cfg
+	cfg.APIKey = key
+
+	return &Client{
+		cfg:        cfg,
+		httpClient: c.httpClient,
+	}
+}
+
+func tlsConfig(cfg Config) (*tls.Config, error) {
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CAFile != "" {
+		data, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ca: %w", err)
+		}
+
+		tlsCfg.RootCAs = x509.NewCertPool()
+		if !tlsCfg.RootCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates in ca %s", cfg.CAFile)
+		}
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load key pair: %w", err)
+		}
+
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
+
+// failingTransport fails every request with the error of the client configuration.
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
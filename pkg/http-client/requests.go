@@ -1,14 +1,19 @@
 package httpclient
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"path"
 	"strconv"
+	"strings"
 
 	"github.com/google/go-querystring/query"
 
@@ -18,27 +23,535 @@ import (
 
 func booksPath(id int64) string {
 	if id > 0 {
-		return path.Join("/api/v1/books", strconv.FormatInt(id, 10))
+		return path.Join("/api/v2/books", strconv.FormatInt(id, 10))
 	}
 
-	return "/api/v1/books"
+	return "/api/v2/books"
+}
+
+func bookByISBNPath(isbn string) string {
+	return path.Join("/api/v2/books/isbn", url.PathEscape(isbn))
 }
 
 func collectionsPath(id int64) string {
 	if id > 0 {
-		return path.Join("/api/v1/collections", strconv.FormatInt(id, 10))
+		return path.Join("/api/v2/collections", strconv.FormatInt(id, 10))
+	}
+
+	return "/api/v2/collections"
+}
+
+func booksCollectionPath(id int64) string {
+	return path.Join("/api/v2/collections", strconv.FormatInt(id, 10), "books")
+}
+
+func authorsPath(id int64) string {
+	if id > 0 {
+		return path.Join("/api/v2/authors", strconv.FormatInt(id, 10))
+	}
+
+	return "/api/v2/authors"
+}
+
+func genresPath(id int64) string {
+	if id > 0 {
+		return path.Join("/api/v2/genres", strconv.FormatInt(id, 10))
+	}
+
+	return "/api/v2/genres"
+}
+
+func reviewsPath(bookID, id int64) string {
+	p := path.Join("/api/v2/books", strconv.FormatInt(bookID, 10), "reviews")
+	if id > 0 {
+		return path.Join(p, strconv.FormatInt(id, 10))
+	}
+
+	return p
+}
+
+func copiesPath(id int64) string {
+	if id > 0 {
+		return path.Join("/api/v2/copies", strconv.FormatInt(id, 10))
+	}
+
+	return "/api/v2/copies"
+}
+
+func copyByBarcodePath(barcode string) string {
+	return path.Join("/api/v2/copies/barcode", url.PathEscape(barcode))
+}
+
+func bookFilesPath(bookID, id int64) string {
+	p := path.Join("/api/v2/books", strconv.FormatInt(bookID, 10), "files")
+	if id > 0 {
+		return path.Join(p, strconv.FormatInt(id, 10))
+	}
+
+	return p
+}
+
+func bookActionPath(bookID int64, action string) string {
+	return path.Join("/api/v2/books", strconv.FormatInt(bookID, 10), action)
+}
+
+func collectionActionPath(id int64, action string) string {
+	return path.Join("/api/v2/collections", strconv.FormatInt(id, 10), action)
+}
+
+func moveCollectionPath(id int64) string {
+	return path.Join("/api/v2/admin/collections", strconv.FormatInt(id, 10), "move")
+}
+
+const changesPath = "/api/v2/changes"
+
+func webhooksPath(id int64) string {
+	if id > 0 {
+		return path.Join("/api/v2/webhooks", strconv.FormatInt(id, 10))
+	}
+
+	return "/api/v2/webhooks"
+}
+
+func webhookDeliveriesPath(webhookID, id int64) string {
+	p := path.Join("/api/v2/webhooks", strconv.FormatInt(webhookID, 10), "deliveries")
+	if id > 0 {
+		return path.Join(p, strconv.FormatInt(id, 10))
+	}
+
+	return p
+}
+
+func (c *Client) GetBook(ctx context.Context, req *api.GetBookReq) (*api.GetBookResp, error) {
+	resp := new(api.GetBookResp)
+	err := c.doRequestWithURLParams(ctx, booksPath(req.ID), nil, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetBookByISBN(ctx context.Context, req *api.GetBookByISBNReq) (*api.GetBookResp, error) {
+	resp := new(api.GetBookResp)
+	err := c.doRequestWithURLParams(ctx, bookByISBNPath(req.ISBN), nil, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetBooks(ctx context.Context, req *api.GetBooksReq) (*api.GetBooksResp, error) {
+	resp := new(api.GetBooksResp)
+	err := c.doRequestWithURLParams(ctx, booksPath(0), req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) CreateBook(ctx context.Context, req *api.CreateBookReq) (*api.CreateBookResp, error) {
+	resp := new(api.CreateBookResp)
+	err := c.doRequestWithJSON(ctx, booksPath(0), http.MethodPost, req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) UpdateBook(ctx context.Context, req *api.UpdateBookReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, booksPath(req.ID), http.MethodPut, req, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) DeleteBooks(ctx context.Context, req *api.DeleteBooksReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, booksPath(0), http.MethodDelete, req, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) GetCollection(ctx context.Context, req *api.GetCollectionReq) (*api.GetCollectionResp, error) {
+	resp := new(api.GetCollectionResp)
+	err := c.doRequestWithURLParams(ctx, collectionsPath(req.ID), nil, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetCollections(ctx context.Context, req *api.GetCollectionsReq) (*api.GetCollectionsResp, error) {
+	resp := new(api.GetCollectionsResp)
+	err := c.doRequestWithURLParams(ctx, collectionsPath(0), req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) CreateCollection(ctx context.Context, req *api.CreateCollectionReq) (*api.CreateCollectionResp, error) {
+	resp := new(api.CreateCollectionResp)
+	err := c.doRequestWithJSON(ctx, collectionsPath(0), http.MethodPost, req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) UpdateCollection(ctx context.Context, req *api.UpdateCollectionReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, collectionsPath(req.ID), http.MethodPut, req, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) DeleteCollection(ctx context.Context, req *api.DeleteCollectionReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, collectionsPath(req.ID), http.MethodDelete, nil, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) CreateBooksCollection(ctx context.Context, req *api.CreateBooksCollectionReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, booksCollectionPath(req.CID), http.MethodPost, req, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) DeleteBooksCollection(ctx context.Context, req *api.DeleteBooksCollectionReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, booksCollectionPath(req.CID), http.MethodDelete, req, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) GetAuthor(ctx context.Context, req *api.GetAuthorReq) (*api.GetAuthorResp, error) {
+	resp := new(api.GetAuthorResp)
+	err := c.doRequestWithURLParams(ctx, authorsPath(req.ID), nil, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetAuthors(ctx context.Context, req *api.GetAuthorsReq) (*api.GetAuthorsResp, error) {
+	resp := new(api.GetAuthorsResp)
+	err := c.doRequestWithURLParams(ctx, authorsPath(0), req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) CreateAuthor(ctx context.Context, req *api.CreateAuthorReq) (*api.CreateAuthorResp, error) {
+	resp := new(api.CreateAuthorResp)
+	err := c.doRequestWithJSON(ctx, authorsPath(0), http.MethodPost, req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) UpdateAuthor(ctx context.Context, req *api.UpdateAuthorReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, authorsPath(req.ID), http.MethodPut, req, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) DeleteAuthor(ctx context.Context, req *api.DeleteAuthorReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, authorsPath(req.ID), http.MethodDelete, nil, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) GetTags(ctx context.Context, req *api.GetTagsReq) (*api.GetTagsResp, error) {
+	resp := new(api.GetTagsResp)
+	err := c.doRequestWithURLParams(ctx, "/api/v2/tags", req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) AddBooksTags(ctx context.Context, req *api.AddBooksTagsReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, "/api/v2/books/tags", http.MethodPost, req, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) DeleteBooksTags(ctx context.Context, req *api.DeleteBooksTagsReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, "/api/v2/books/tags", http.MethodDelete, req, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) GetGenre(ctx context.Context, req *api.GetGenreReq) (*api.GetGenreResp, error) {
+	resp := new(api.GetGenreResp)
+	err := c.doRequestWithURLParams(ctx, genresPath(req.ID), nil, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetGenres(ctx context.Context, req *api.GetGenresReq) (*api.GetGenresResp, error) {
+	resp := new(api.GetGenresResp)
+	err := c.doRequestWithURLParams(ctx, genresPath(0), req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) CreateGenre(ctx context.Context, req *api.CreateGenreReq) (*api.CreateGenreResp, error) {
+	resp := new(api.CreateGenreResp)
+	err := c.doRequestWithJSON(ctx, genresPath(0), http.MethodPost, req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) UpdateGenre(ctx context.Context, req *api.UpdateGenreReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, genresPath(req.ID), http.MethodPut, req, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) DeleteGenre(ctx context.Context, req *api.DeleteGenreReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, genresPath(req.ID), http.MethodDelete, nil, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) GetReading(ctx context.Context, req *api.GetReadingReq) (*api.GetReadingResp, error) {
+	resp := new(api.GetReadingResp)
+	err := c.doRequestWithURLParams(ctx, bookActionPath(req.BookID, "reading"), nil, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) UpdateReading(ctx context.Context, req *api.UpdateReadingReq) (*api.UpdateReadingResp, error) {
+	resp := new(api.UpdateReadingResp)
+	err := c.doRequestWithJSON(ctx, bookActionPath(req.BookID, "reading"), http.MethodPut, req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetReadingReport(ctx context.Context, req *api.GetReadingReportReq) (*api.GetReadingReportResp, error) {
+	resp := new(api.GetReadingReportResp)
+	err := c.doRequestWithURLParams(ctx, "/api/v2/reading/report", req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetReview(ctx context.Context, req *api.GetReviewReq) (*api.GetReviewResp, error) {
+	resp := new(api.GetReviewResp)
+	err := c.doRequestWithURLParams(ctx, reviewsPath(req.BookID, req.ID), nil, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetReviews(ctx context.Context, req *api.GetReviewsReq) (*api.GetReviewsResp, error) {
+	resp := new(api.GetReviewsResp)
+	err := c.doRequestWithURLParams(ctx, reviewsPath(req.BookID, 0), req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) CreateReview(ctx context.Context, req *api.CreateReviewReq) (*api.CreateReviewResp, error) {
+	resp := new(api.CreateReviewResp)
+	err := c.doRequestWithJSON(ctx, reviewsPath(req.BookID, 0), http.MethodPost, req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) UpdateReview(ctx context.Context, req *api.UpdateReviewReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, reviewsPath(req.BookID, req.ID), http.MethodPut, req, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) DeleteReview(ctx context.Context, req *api.DeleteReviewReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, reviewsPath(req.BookID, req.ID), http.MethodDelete, nil, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) GetCopy(ctx context.Context, req *api.GetCopyReq) (*api.GetCopyResp, error) {
+	resp := new(api.GetCopyResp)
+	err := c.doRequestWithURLParams(ctx, copiesPath(req.ID), nil, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetCopyByBarcode(ctx context.Context, req *api.GetCopyByBarcodeReq) (*api.GetCopyResp, error) {
+	resp := new(api.GetCopyResp)
+	err := c.doRequestWithURLParams(ctx, copyByBarcodePath(req.Barcode), nil, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetCopies(ctx context.Context, req *api.GetCopiesReq) (*api.GetCopiesResp, error) {
+	resp := new(api.GetCopiesResp)
+	err := c.doRequestWithURLParams(ctx, copiesPath(0), req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) CreateCopy(ctx context.Context, req *api.CreateCopyReq) (*api.CreateCopyResp, error) {
+	resp := new(api.CreateCopyResp)
+	err := c.doRequestWithJSON(ctx, bookActionPath(req.BookID, "copies"), http.MethodPost, req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) UpdateCopy(ctx context.Context, req *api.UpdateCopyReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, copiesPath(req.ID), http.MethodPut, req, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) DeleteCopy(ctx context.Context, req *api.DeleteCopyReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, copiesPath(req.ID), http.MethodDelete, nil, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) LendBook(ctx context.Context, req *api.LendBookReq) (*api.LendBookResp, error) {
+	resp := new(api.LendBookResp)
+	err := c.doRequestWithJSON(ctx, bookActionPath(req.BookID, "loan"), http.MethodPost, req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) ReturnBook(ctx context.Context, req *api.ReturnBookReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, bookActionPath(req.BookID, "return"), http.MethodPost, req, nil)
+	if err != nil {
+		return false, fmt.Errorf("do request: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) UploadCover(ctx context.Context, req *api.UploadCoverReq) (*api.UploadCoverResp, error) {
+	resp := new(api.UploadCoverResp)
+	err := c.doRequestWithBody(ctx, bookActionPath(req.BookID, "cover"), nil, http.MethodPut, http.DetectContentType(req.Data), bytes.NewReader(req.Data), resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
 	}
 
-	return "/api/v1/collections"
+	return resp, nil
 }
 
-func booksCollectionPath(id int64) string {
-	return path.Join("/api/v1/collections", strconv.FormatInt(id, 10), "books")
+func (c *Client) GetCover(ctx context.Context, req *api.GetCoverReq) (*api.GetCoverResp, error) {
+	blob, err := c.doBlobRequest(ctx, bookActionPath(req.BookID, "cover"), req, req.ETag)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return &api.GetCoverResp{
+		ContentType: blob.contentType,
+		ETag:        blob.etag,
+		NotModified: blob.notModified,
+		Data:        blob.data,
+	}, nil
 }
 
-func (c *Client) GetBook(ctx context.Context, req *api.GetBookReq) (*api.GetBookResp, error) {
-	resp := new(api.GetBookResp)
-	err := c.doRequestWithURLParams(ctx, booksPath(req.ID), nil, resp)
+func (c *Client) GetBookFiles(ctx context.Context, req *api.GetBookFilesReq) (*api.GetBookFilesResp, error) {
+	resp := new(api.GetBookFilesResp)
+	err := c.doRequestWithURLParams(ctx, bookFilesPath(req.BookID, 0), nil, resp)
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
@@ -46,19 +559,22 @@ func (c *Client) GetBook(ctx context.Context, req *api.GetBookReq) (*api.GetBook
 	return resp, nil
 }
 
-func (c *Client) GetBooks(ctx context.Context, req *api.GetBooksReq) (*api.GetBooksResp, error) {
-	resp := new(api.GetBooksResp)
-	err := c.doRequestWithURLParams(ctx, booksPath(0), req, resp)
+func (c *Client) GetBookFile(ctx context.Context, req *api.GetBookFileReq) (*api.GetBookFileResp, error) {
+	blob, err := c.doBlobRequest(ctx, bookFilesPath(req.BookID, req.ID), nil, "")
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
 
-	return resp, nil
+	return &api.GetBookFileResp{
+		ContentType: blob.contentType,
+		Data:        blob.data,
+	}, nil
 }
 
-func (c *Client) CreateBook(ctx context.Context, req *api.CreateBookReq) (*api.CreateBookResp, error) {
-	resp := new(api.CreateBookResp)
-	err := c.doRequestWithJSON(ctx, booksPath(0), http.MethodPost, req, resp)
+func (c *Client) AddBookFile(ctx context.Context, req *api.AddBookFileReq) (*api.AddBookFileResp, error) {
+	resp := new(api.AddBookFileResp)
+	params := url.Values{"name": []string{req.Name}}
+	err := c.doRequestWithBody(ctx, bookFilesPath(req.BookID, 0), params, http.MethodPost, http.DetectContentType(req.Data), bytes.NewReader(req.Data), resp)
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
@@ -66,8 +582,8 @@ func (c *Client) CreateBook(ctx context.Context, req *api.CreateBookReq) (*api.C
 	return resp, nil
 }
 
-func (c *Client) UpdateBook(ctx context.Context, req *api.UpdateBookReq) (bool, error) {
-	err := c.doRequestWithJSON(ctx, booksPath(req.ID), http.MethodPut, req, nil)
+func (c *Client) DeleteBookFile(ctx context.Context, req *api.DeleteBookFileReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, bookFilesPath(req.BookID, req.ID), http.MethodDelete, req, nil)
 	if err != nil {
 		return false, fmt.Errorf("do request: %w", err)
 	}
@@ -75,18 +591,29 @@ func (c *Client) UpdateBook(ctx context.Context, req *api.UpdateBookReq) (bool,
 	return true, nil
 }
 
-func (c *Client) DeleteBooks(ctx context.Context, req *api.DeleteBooksReq) (bool, error) {
-	err := c.doRequestWithJSON(ctx, booksPath(0), http.MethodDelete, req, nil)
+func (c *Client) ImportEPUB(ctx context.Context, req *api.ImportEPUBReq) (*api.ImportEPUBResp, error) {
+	params, err := query.Values(req)
 	if err != nil {
-		return false, fmt.Errorf("do request: %w", err)
+		return nil, fmt.Errorf("construct request: %w", err)
 	}
 
-	return true, nil
+	resp := new(api.ImportEPUBResp)
+	err = c.doRequestWithBody(ctx, "/api/v2/books/import/epub", params, http.MethodPost, "application/epub+zip", bytes.NewReader(req.Data), resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
 }
 
-func (c *Client) GetCollection(ctx context.Context, req *api.GetCollectionReq) (*api.GetCollectionResp, error) {
-	resp := new(api.GetCollectionResp)
-	err := c.doRequestWithURLParams(ctx, collectionsPath(req.ID), nil, resp)
+func (c *Client) ImportBooks(ctx context.Context, req *api.ImportBooksReq) (*api.ImportBooksResp, error) {
+	params, err := query.Values(req)
+	if err != nil {
+		return nil, fmt.Errorf("construct request: %w", err)
+	}
+
+	resp := new(api.ImportBooksResp)
+	err = c.doRequestWithBody(ctx, "/api/v2/books/import/csv", params, http.MethodPost, "text/csv", bytes.NewReader(req.Data), resp)
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
@@ -94,9 +621,14 @@ func (c *Client) GetCollection(ctx context.Context, req *api.GetCollectionReq) (
 	return resp, nil
 }
 
-func (c *Client) GetCollections(ctx context.Context, req *api.GetCollectionsReq) (*api.GetCollectionsResp, error) {
-	resp := new(api.GetCollectionsResp)
-	err := c.doRequestWithURLParams(ctx, collectionsPath(0), req, resp)
+func (c *Client) ImportMARC(ctx context.Context, req *api.ImportMARCReq) (*api.ImportBooksResp, error) {
+	params, err := query.Values(req)
+	if err != nil {
+		return nil, fmt.Errorf("construct request: %w", err)
+	}
+
+	resp := new(api.ImportBooksResp)
+	err = c.doRequestWithBody(ctx, "/api/v2/books/import/marc", params, http.MethodPost, "application/marc", bytes.NewReader(req.Data), resp)
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
@@ -104,9 +636,71 @@ func (c *Client) GetCollections(ctx context.Context, req *api.GetCollectionsReq)
 	return resp, nil
 }
 
-func (c *Client) CreateCollection(ctx context.Context, req *api.CreateCollectionReq) (*api.CreateCollectionResp, error) {
-	resp := new(api.CreateCollectionResp)
-	err := c.doRequestWithJSON(ctx, collectionsPath(0), http.MethodPost, req, resp)
+func (c *Client) ExportBook(ctx context.Context, req *api.ExportBookReq) (*api.ExportResp, error) {
+	blob, err := c.doBlobRequest(ctx, bookActionPath(req.BookID, "export"), req, "")
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return &api.ExportResp{
+		ContentType: blob.contentType,
+		FileName:    blob.fileName,
+		Data:        blob.data,
+	}, nil
+}
+
+func (c *Client) ExportCollection(ctx context.Context, req *api.ExportCollectionReq) (*api.ExportResp, error) {
+	blob, err := c.doBlobRequest(ctx, collectionActionPath(req.CollectionID, "export"), req, "")
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return &api.ExportResp{
+		ContentType: blob.contentType,
+		FileName:    blob.fileName,
+		Data:        blob.data,
+	}, nil
+}
+
+func (c *Client) GetOPDSFeed(ctx context.Context, req *api.GetOPDSFeedReq) (*api.OPDSResp, error) {
+	p := "/api/v2/opds"
+	switch {
+	case req.CollectionID != 0:
+		p = path.Join(p, "collections", strconv.FormatInt(req.CollectionID, 10))
+
+	case req.Recent:
+		p = path.Join(p, "recent")
+
+	case req.Query != "":
+		p = path.Join(p, "search")
+	}
+
+	blob, err := c.doBlobRequest(ctx, p, req, "")
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return &api.OPDSResp{
+		ContentType: blob.contentType,
+		Data:        blob.data,
+	}, nil
+}
+
+func (c *Client) GetOpenSearch(ctx context.Context, _ *api.GetOpenSearchReq) (*api.OPDSResp, error) {
+	blob, err := c.doBlobRequest(ctx, "/api/v2/opds/opensearch.xml", nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return &api.OPDSResp{
+		ContentType: blob.contentType,
+		Data:        blob.data,
+	}, nil
+}
+
+func (c *Client) GetLoans(ctx context.Context, req *api.GetLoansReq) (*api.GetLoansResp, error) {
+	resp := new(api.GetLoansResp)
+	err := c.doRequestWithURLParams(ctx, "/api/v2/loans", req, resp)
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
@@ -114,8 +708,18 @@ func (c *Client) CreateCollection(ctx context.Context, req *api.CreateCollection
 	return resp, nil
 }
 
-func (c *Client) UpdateCollection(ctx context.Context, req *api.UpdateCollectionReq) (bool, error) {
-	err := c.doRequestWithJSON(ctx, collectionsPath(req.ID), http.MethodPut, req, nil)
+func (c *Client) GetOverdueLoans(ctx context.Context, req *api.GetLoansReq) (*api.GetLoansResp, error) {
+	resp := new(api.GetLoansResp)
+	err := c.doRequestWithURLParams(ctx, "/api/v2/loans/overdue", req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) MoveBooks(ctx context.Context, req *api.MoveBooksReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, "/api/v2/admin/books/move", http.MethodPost, req, nil)
 	if err != nil {
 		return false, fmt.Errorf("do request: %w", err)
 	}
@@ -123,8 +727,18 @@ func (c *Client) UpdateCollection(ctx context.Context, req *api.UpdateCollection
 	return true, nil
 }
 
-func (c *Client) DeleteCollection(ctx context.Context, req *api.DeleteCollectionReq) (bool, error) {
-	err := c.doRequestWithJSON(ctx, collectionsPath(req.ID), http.MethodDelete, nil, nil)
+func (c *Client) GetCacheStats(ctx context.Context) (*api.GetCacheStatsResp, error) {
+	resp := new(api.GetCacheStatsResp)
+	err := c.doRequestWithURLParams(ctx, "/api/v2/admin/cache", nil, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) MoveCollection(ctx context.Context, req *api.MoveCollectionReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, moveCollectionPath(req.ID), http.MethodPost, req, nil)
 	if err != nil {
 		return false, fmt.Errorf("do request: %w", err)
 	}
@@ -132,8 +746,49 @@ func (c *Client) DeleteCollection(ctx context.Context, req *api.DeleteCollection
 	return true, nil
 }
 
-func (c *Client) CreateBooksCollection(ctx context.Context, req *api.CreateBooksCollectionReq) (bool, error) {
-	err := c.doRequestWithJSON(ctx, booksCollectionPath(req.CID), http.MethodPost, req, nil)
+// GraphQL executes a GraphQL operation. Errors of the operation are returned within the response.
+func (c *Client) GraphQL(ctx context.Context, req *api.GraphQLReq) (*api.GraphQLResp, error) {
+	resp := new(api.GraphQLResp)
+	err := c.doRequestWithJSON(ctx, "/api/v2/graphql", http.MethodPost, req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetWebhook(ctx context.Context, req *api.GetWebhookReq) (*api.GetWebhookResp, error) {
+	resp := new(api.GetWebhookResp)
+	err := c.doRequestWithURLParams(ctx, webhooksPath(req.ID), nil, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetWebhooks(ctx context.Context, req *api.GetWebhooksReq) (*api.GetWebhooksResp, error) {
+	resp := new(api.GetWebhooksResp)
+	err := c.doRequestWithURLParams(ctx, webhooksPath(0), req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) CreateWebhook(ctx context.Context, req *api.CreateWebhookReq) (*api.CreateWebhookResp, error) {
+	resp := new(api.CreateWebhookResp)
+	err := c.doRequestWithJSON(ctx, webhooksPath(0), http.MethodPost, req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) UpdateWebhook(ctx context.Context, req *api.UpdateWebhookReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, webhooksPath(req.ID), http.MethodPut, req, nil)
 	if err != nil {
 		return false, fmt.Errorf("do request: %w", err)
 	}
@@ -141,8 +796,8 @@ func (c *Client) CreateBooksCollection(ctx context.Context, req *api.CreateBooks
 	return true, nil
 }
 
-func (c *Client) DeleteBooksCollection(ctx context.Context, req *api.DeleteBooksCollectionReq) (bool, error) {
-	err := c.doRequestWithJSON(ctx, booksCollectionPath(req.CID), http.MethodDelete, req, nil)
+func (c *Client) DeleteWebhook(ctx context.Context, req *api.DeleteWebhookReq) (bool, error) {
+	err := c.doRequestWithJSON(ctx, webhooksPath(req.ID), http.MethodDelete, nil, nil)
 	if err != nil {
 		return false, fmt.Errorf("do request: %w", err)
 	}
@@ -150,24 +805,167 @@ func (c *Client) DeleteBooksCollection(ctx context.Context, req *api.DeleteBooks
 	return true, nil
 }
 
+func (c *Client) GetWebhookDeliveries(ctx context.Context, req *api.GetWebhookDeliveriesReq) (*api.GetWebhookDeliveriesResp, error) {
+	resp := new(api.GetWebhookDeliveriesResp)
+	err := c.doRequestWithURLParams(ctx, webhookDeliveriesPath(req.WebhookID, 0), req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetWebhookDelivery(ctx context.Context, req *api.GetWebhookDeliveryReq) (*api.GetWebhookDeliveryResp, error) {
+	resp := new(api.GetWebhookDeliveryResp)
+	err := c.doRequestWithURLParams(ctx, webhookDeliveriesPath(req.WebhookID, req.ID), nil, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) RedeliverWebhookEvent(ctx context.Context, req *api.RedeliverWebhookEventReq) (*api.RedeliverWebhookEventResp, error) {
+	resp := new(api.RedeliverWebhookEventResp)
+	p := path.Join(webhookDeliveriesPath(req.WebhookID, req.DeliveryID), "redeliver")
+	err := c.doRequestWithJSON(ctx, p, http.MethodPost, nil, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetChanges(ctx context.Context, req *api.GetChangesReq) (*api.GetChangesResp, error) {
+	resp := new(api.GetChangesResp)
+	err := c.doRequestWithURLParams(ctx, changesPath, req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
+
+// StreamChanges subscribes to changes following req.Since and passes them to handle in order until the context
+// is done, the stream breaks or handle fails; the error of handle is returned as is. A consumer resumes
+// the stream after the seq of the last handled change. The stream isn't limited by the timeout of the client.
+func (c *Client) StreamChanges(ctx context.Context, req *api.StreamChangesReq, handle func(api.Change) error) (err error) {
+	u, err := url.Parse(c.cfg.Address)
+	if err != nil {
+		return fmt.Errorf("parse url: %w", err)
+	}
+
+	u.Path = path.Join(u.Path, changesPath, "stream")
+
+	vals, err := query.Values(req)
+	if err != nil {
+		return fmt.Errorf("construct request: %w", err)
+	}
+
+	u.RawQuery = vals.Encode()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("construct request: %w", err)
+	}
+
+	httpReq.Header.Set("Accept", "text/event-stream")
+	c.authorize(httpReq)
+
+	streamClient := *c.httpClient
+	streamClient.Timeout = 0
+
+	resp, err := streamClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+
+	defer func() {
+		err = bm.HandleErrPair(resp.Body.Close(), err)
+	}()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return newRateLimitError(resp.Header)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("get error http status: %d", resp.StatusCode)
+	}
+
+	// Events are separated by empty lines, only their data lines are needed.
+	var data strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			if data.Len() != 0 {
+				data.WriteByte('\n')
+			}
+
+			data.WriteString(strings.TrimPrefix(value, " "))
+
+			continue
+		}
+
+		if line != "" || data.Len() == 0 {
+			continue
+		}
+
+		var change api.Change
+		if err = json.Unmarshal([]byte(data.String()), &change); err != nil {
+			return fmt.Errorf("decode change: %w", err)
+		}
+
+		data.Reset()
+
+		if err = handle(change); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("read stream: %w", err)
+	}
+
+	return errors.New("stream is closed by the server")
+}
+
+func (c *Client) authorize(req *http.Request) {
+	if c.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	}
+}
+
 func (c *Client) doRequestWithJSON(ctx context.Context, urlPath, method string, reqData, respData any) (err error) {
 	body, err := json.Marshal(reqData)
 	if err != nil {
 		return fmt.Errorf("marshal data: %w", err)
 	}
 
+	return c.doRequestWithBody(ctx, urlPath, nil, method, "application/json", bytes.NewReader(body), respData)
+}
+
+func (c *Client) doRequestWithBody(ctx context.Context, urlPath string, params url.Values, method, contentType string, body io.Reader, respData any) (err error) {
 	u, err := url.Parse(c.cfg.Address)
 	if err != nil {
 		return fmt.Errorf("parse url: %w", err)
 	}
 
 	u.Path = path.Join(u.Path, urlPath)
+	u.RawQuery = params.Encode()
 
-	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	req, err := http.NewRequest(method, u.String(), body)
 	if err != nil {
 		return fmt.Errorf("construct request: %w", err)
 	}
 
+	req.Header.Set("Content-Type", contentType)
+	c.authorize(req)
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("do request: %w", err)
@@ -177,6 +975,10 @@ func (c *Client) doRequestWithJSON(ctx context.Context, urlPath, method string,
 		err = bm.HandleErrPair(resp.Body.Close(), err)
 	}()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return newRateLimitError(resp.Header)
+	}
+
 	if resp.StatusCode >= http.StatusInternalServerError {
 		return fmt.Errorf("server error: %d", resp.StatusCode)
 	}
@@ -223,6 +1025,8 @@ func (c *Client) doRequestWithURLParams(ctx context.Context, urlPath string, req
 		return fmt.Errorf("construct request: %w", err)
 	}
 
+	c.authorize(req)
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("do request: %w", err)
@@ -232,6 +1036,10 @@ func (c *Client) doRequestWithURLParams(ctx context.Context, urlPath string, req
 		err = bm.HandleErrPair(resp.Body.Close(), err)
 	}()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return newRateLimitError(resp.Header)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("get error http status: %d", resp.StatusCode)
 	}
@@ -244,3 +1052,79 @@ func (c *Client) doRequestWithURLParams(ctx context.Context, urlPath string, req
 
 	return nil
 }
+
+// blobResp is a binary response of the server.
+type blobResp struct {
+	contentType string
+	fileName    string
+	etag        string
+	notModified bool
+	data        []byte
+}
+
+func (c *Client) doBlobRequest(ctx context.Context, urlPath string, reqData any, etag string) (resp *blobResp, err error) {
+	u, err := url.Parse(c.cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
+	}
+
+	u.Path = path.Join(u.Path, urlPath)
+
+	if reqData != nil {
+		vals, err := query.Values(reqData)
+		if err != nil {
+			return nil, fmt.Errorf("construct request: %w", err)
+		}
+
+		u.RawQuery = vals.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("construct request: %w", err)
+	}
+
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	c.authorize(req)
+
+	httpResp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	defer func() {
+		err = bm.HandleErrPair(httpResp.Body.Close(), err)
+	}()
+
+	resp = &blobResp{
+		contentType: httpResp.Header.Get("Content-Type"),
+		etag:        httpResp.Header.Get("ETag"),
+	}
+
+	if _, params, err := mime.ParseMediaType(httpResp.Header.Get("Content-Disposition")); err == nil {
+		resp.fileName = params["filename"]
+	}
+
+	switch httpResp.StatusCode {
+	case http.StatusOK:
+		if resp.data, err = io.ReadAll(httpResp.Body); err != nil {
+			return nil, fmt.Errorf("read response: %w", err)
+		}
+
+		return resp, nil
+
+	case http.StatusNotModified:
+		resp.notModified = true
+
+		return resp, nil
+
+	case http.StatusTooManyRequests:
+		return nil, newRateLimitError(httpResp.Header)
+
+	default:
+		return nil, fmt.Errorf("get error http status: %d", httpResp.StatusCode)
+	}
+}